@@ -58,13 +58,17 @@ func main() {
 		os.Exit(1)
 	}
 
-	application := app.New(cfg, db, logger)
-
-	application.Router = app.SetupRouter(db, application.JWT, application.Logger)
-
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
 	defer cancel()
 
+	application, err := app.New(cfg, db, logger)
+	if err != nil {
+		logger.Error("Failed to create application", zap.Error(err))
+		os.Exit(1)
+	}
+
+	application.Router = app.SetupRouter(ctx, cfg, db, application.JWT, application.Logger)
+
 	if err := application.Start(ctx); err != nil {
 		logger.Error("Application error", zap.Error(err))
 		os.Exit(1)
@@ -0,0 +1,78 @@
+// Command create-admin bootstraps an ADMIN account. Public registration cannot
+// create admins, so the first one has to be created from the command line.
+//
+// Usage:
+//
+//	YADWY_ADMIN_PASSWORD=secret go run ./cmd/create-admin -name "Jane Admin" -email jane@example.com
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"yadwy-backend/internal/common"
+	"yadwy-backend/internal/config"
+	"yadwy-backend/internal/database"
+	"yadwy-backend/internal/users/application"
+	"yadwy-backend/internal/users/db"
+	"yadwy-backend/internal/users/domain/modles"
+
+	"go.uber.org/zap"
+)
+
+func main() {
+	name := flag.String("name", "", "admin display name")
+	email := flag.String("email", "", "admin email")
+	password := flag.String("password", "", "admin password (defaults to $YADWY_ADMIN_PASSWORD)")
+	flag.Parse()
+
+	if *password == "" {
+		*password = os.Getenv("YADWY_ADMIN_PASSWORD")
+	}
+	if *name == "" || *email == "" || *password == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	logger, err := common.NewLogger()
+	if err != nil {
+		panic(err)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		logger.Error("Failed to load config", zap.Error(err))
+		os.Exit(1)
+	}
+
+	if err := database.RunMigrations(cfg.Database); err != nil {
+		logger.Error("Failed to run migrations", zap.Error(err))
+		os.Exit(1)
+	}
+
+	conn, err := database.NewPostgresDB(cfg.Database)
+	if err != nil {
+		logger.Error("Failed to connect to database", zap.Error(err))
+		os.Exit(1)
+	}
+	defer conn.Close()
+
+	// creating an account never signs anyone out, so no session revoker is
+	// needed, and only the admin role has to be defined
+	svc := application.NewAdminService(db.NewUserRepo(conn), db.NewAuditLog(conn), nil,
+		application.NewLoginGuard(db.NewLoginAttemptStore(conn), application.LoginGuardConfig{}),
+		common.NewPermissionPolicy(map[string][]string{modles.RoleAdmin.String(): nil}))
+	admin, err := svc.CreateAccount(context.Background(), 0, application.CreateAccountReq{
+		Name:     *name,
+		Email:    *email,
+		Password: *password,
+		Role:     modles.RoleAdmin.String(),
+	})
+	if err != nil {
+		logger.Error("Failed to create admin", zap.Error(err))
+		os.Exit(1)
+	}
+
+	fmt.Printf("Created admin %s (id %d)\n", admin.Email, admin.ID)
+}
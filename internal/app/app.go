@@ -7,8 +7,15 @@ import (
 	"go.uber.org/zap"
 	"log/slog"
 	"net/http"
+	"os"
+	"sort"
 	"time"
 	"yadwy-backend/internal/common"
+	userapp "yadwy-backend/internal/users/application"
+	userdb "yadwy-backend/internal/users/db"
+	usercontracts "yadwy-backend/internal/users/domain/contracts"
+	usermodles "yadwy-backend/internal/users/domain/modles"
+	uh "yadwy-backend/internal/users/handlers"
 
 	"github.com/jmoiron/sqlx"
 	"yadwy-backend/internal/config"
@@ -22,14 +29,64 @@ type App struct {
 	JWT    *common.JWTGenerator
 }
 
-func New(cfg *config.Config, db *sqlx.DB, logger *zap.Logger) *App {
-	jwt := common.NewJWTGenerator(cfg.JWT.Secret)
+func New(cfg *config.Config, db *sqlx.DB, logger *zap.Logger) (*App, error) {
+	jwt, err := newJWTGenerator(cfg.JWT)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up JWT keys: %w", err)
+	}
 	return &App{
 		DB:     db,
 		Config: cfg,
 		Logger: logger,
 		JWT:    jwt,
+	}, nil
+}
+
+// newJWTGenerator loads the signing key and the verification keys selected
+// by the JWT config.
+func newJWTGenerator(cfg config.JWT) (*common.JWTGenerator, error) {
+	var signingKey *common.JWTKey
+	switch cfg.Algorithm {
+	case "HS256":
+		key, err := common.NewHMACKey(cfg.KeyID, cfg.Secret)
+		if err != nil {
+			return nil, err
+		}
+		signingKey = key
+	case "RS256", "EdDSA":
+		pemBytes, err := os.ReadFile(cfg.PrivateKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("error reading private key: %w", err)
+		}
+		key, err := common.ParsePrivateKeyPEM(cfg.KeyID, pemBytes)
+		if err != nil {
+			return nil, err
+		}
+		if key.Algorithm() != cfg.Algorithm {
+			return nil, fmt.Errorf("private key is for %s, not %s", key.Algorithm(), cfg.Algorithm)
+		}
+		if cfg.KeyID == "" {
+			return nil, fmt.Errorf("key_id is required for %s", cfg.Algorithm)
+		}
+		signingKey = key
+	default:
+		return nil, fmt.Errorf("unsupported JWT algorithm %q", cfg.Algorithm)
+	}
+
+	verificationKeys := make([]*common.JWTKey, 0, len(cfg.VerificationKeys))
+	for _, vk := range cfg.VerificationKeys {
+		pemBytes, err := os.ReadFile(vk.PublicKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("error reading verification key %q: %w", vk.ID, err)
+		}
+		key, err := common.ParsePublicKeyPEM(vk.ID, pemBytes)
+		if err != nil {
+			return nil, err
+		}
+		verificationKeys = append(verificationKeys, key)
 	}
+
+	return common.NewJWTGeneratorWithKeys(signingKey, verificationKeys...)
 }
 
 // Start starts the HTTP server and handles graceful shutdown
@@ -66,3 +123,159 @@ func (a *App) Start(ctx context.Context) error {
 		return server.Shutdown(timeout)
 	}
 }
+
+// newUserServices builds the services of the users module. It registers the
+// token validators and the API key authenticator on jwt, loads the role
+// permissions into policy when they are kept in Postgres, and starts the
+// background jobs of the module until ctx is cancelled.
+func newUserServices(
+	ctx context.Context,
+	cfg *config.Config,
+	b *sqlx.DB,
+	jwt *common.JWTGenerator,
+	policy *common.PermissionPolicy,
+	personalData userapp.PersonalDataModules,
+	logger *zap.Logger) uh.UserServices {
+	userRepo := userdb.NewUserRepo(b)
+	refreshTokenRepo := userdb.NewRefreshTokenRepo(b)
+	revocations := newTokenRevocationStore(b, cfg.Auth)
+	loginGuard := userapp.NewLoginGuard(newLoginAttemptStore(b, cfg.Auth.Login), userapp.LoginGuardConfig{
+		FreeAttempts:            cfg.Auth.Login.FreeAttempts,
+		BackoffBase:             cfg.Auth.Login.BackoffBase,
+		MaxBackoff:              cfg.Auth.Login.MaxBackoff,
+		AccountLockoutThreshold: cfg.Auth.Login.AccountLockoutThreshold,
+		IPLockoutThreshold:      cfg.Auth.Login.IPLockoutThreshold,
+		LockoutDuration:         cfg.Auth.Login.LockoutDuration,
+		ResetAfter:              cfg.Auth.Login.ResetAfter,
+	})
+	actionTokenRepo := userdb.NewActionTokenRepo(b)
+
+	mailer, err := newMailer(cfg.Mail, logger)
+	if err != nil {
+		logger.Fatal("Failed to create mailer", zap.Error(err))
+	}
+
+	verificationSvc := userapp.NewEmailVerificationService(userRepo, actionTokenRepo, mailer, userapp.EmailVerificationConfig{
+		TokenTTL:       cfg.Auth.EmailVerificationTTL,
+		ResendInterval: cfg.Auth.VerificationResendInterval,
+		VerifyURL:      cfg.Auth.EmailVerificationURL,
+	})
+	mfaRepo := userdb.NewMFARepo(b)
+	mfaPolicy := userapp.MFAPolicy{
+		RequiredRoles: mfaRequiredRoles(cfg.Auth.MFA.RequiredRoles, logger),
+		ChallengeTTL:  cfg.Auth.MFA.ChallengeTTL,
+	}
+	sessionRepo := userdb.NewSessionRepo(b)
+	userSvc := userapp.NewUserService(userRepo, refreshTokenRepo, sessionRepo, revocations, verificationSvc, loginGuard, mfaRepo, mfaPolicy, jwt, userapp.TokenConfig{
+		AccessTokenTTL:  cfg.JWT.AccessTokenTTL,
+		RefreshTokenTTL: cfg.JWT.RefreshTokenTTL,
+	}, logger)
+	auditLog := userdb.NewAuditLog(b)
+	addressRepo := userdb.NewAddressRepo(b)
+	apiKeyRepo := userdb.NewAPIKeyRepo(b)
+	apiKeySvc := userapp.NewAPIKeyService(userRepo, apiKeyRepo, policy, logger)
+	identityRepo := userdb.NewIdentityRepo(b)
+	privacySvc := userapp.NewPrivacyService(userRepo, addressRepo, identityRepo, sessionRepo, apiKeyRepo, auditLog,
+		userdb.NewDataExportRepo(b), userSvc, loginGuard, personalData, userapp.PrivacyConfig{
+			ExportDir: cfg.Privacy.ExportDir,
+			ExportTTL: cfg.Privacy.ExportTTL,
+		}, logger)
+
+	jwt.AddValidator(userapp.NewRevocationValidator(revocations))
+	jwt.AddValidator(userapp.NewMFAPolicyValidator(mfaPolicy))
+	jwt.AddValidator(userapp.NewSessionValidator(sessionRepo, logger))
+	jwt.SetAPIKeyAuthenticator(apiKeySvc)
+	go userapp.RunRevocationCleanup(ctx, revocations, cfg.Auth.RevocationCleanupInterval, logger)
+	go userapp.RunLoginAttemptCleanup(ctx, loginGuard, cfg.Auth.Login.CleanupInterval, logger)
+	go userapp.RunOIDCStateCleanup(ctx, identityRepo, cfg.Auth.OIDC.CleanupInterval, logger)
+	go userapp.RunDataExports(ctx, privacySvc, cfg.Privacy.WorkerInterval, logger)
+
+	if cfg.Authorization.Source == "postgres" {
+		rolePermissions := userdb.NewRolePermissionRepo(b)
+		if err := userapp.LoadPermissions(ctx, rolePermissions, policy); err != nil {
+			logger.Fatal("Failed to load role permissions", zap.Error(err))
+		}
+		go userapp.RunPermissionReload(ctx, rolePermissions, policy, cfg.Authorization.ReloadInterval, logger)
+	}
+
+	return uh.UserServices{
+		Users:        userSvc,
+		Admin:        userapp.NewAdminService(userRepo, auditLog, userSvc, loginGuard, policy),
+		Verification: verificationSvc,
+		Profile:      userapp.NewProfileService(userRepo, userSvc),
+		Sessions:     userapp.NewSessionService(sessionRepo, refreshTokenRepo),
+		Addresses:    userapp.NewAddressService(addressRepo),
+		APIKeys:      apiKeySvc,
+		Privacy:      privacySvc,
+		OIDC: userapp.NewOIDCService(userRepo, identityRepo, verificationSvc, userSvc,
+			oidcProviders(cfg.Auth.OIDC), cfg.Auth.OIDC.StateTTL, logger),
+		MFA: userapp.NewMFAService(userRepo, mfaRepo, cfg.Auth.MFA.Issuer),
+		PasswordReset: userapp.NewPasswordResetService(userRepo, actionTokenRepo, mailer, userSvc, userapp.PasswordResetConfig{
+			TokenTTL:       cfg.Auth.PasswordResetTTL,
+			ResendInterval: cfg.Auth.VerificationResendInterval,
+			ResetURL:       cfg.Auth.PasswordResetURL,
+		}, logger),
+	}
+}
+
+func newMailer(cfg config.Mail, logger *zap.Logger) (common.Mailer, error) {
+	if cfg.Driver == "smtp" {
+		return common.NewSMTPMailer(cfg.Host, cfg.Port, cfg.Username, cfg.Password, cfg.From), nil
+	}
+	return common.NewLogMailer(cfg.From, cfg.Dir, logger)
+}
+
+func newTokenRevocationStore(b *sqlx.DB, cfg config.Auth) usercontracts.TokenRevocationStore {
+	if cfg.RevocationStore == "memory" {
+		return userdb.NewMemoryTokenRevocationStore()
+	}
+	return userdb.NewTokenRevocationStore(b)
+}
+
+func mfaRequiredRoles(roles []string, logger *zap.Logger) []usermodles.Role {
+	res := make([]usermodles.Role, 0, len(roles))
+	for _, r := range roles {
+		role, err := usermodles.NewRole(r)
+		if err != nil {
+			logger.Fatal("Invalid role in auth.mfa.required_roles", zap.String("role", r))
+		}
+		res = append(res, role)
+	}
+	return res
+}
+
+// oidcProviders returns the configured providers that have a client ID.
+func oidcProviders(cfg config.OIDC) []*common.OIDCProvider {
+	names := make([]string, 0, len(cfg.Providers))
+	for name, p := range cfg.Providers {
+		if p.ClientID != "" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	providers := make([]*common.OIDCProvider, 0, len(names))
+	for _, name := range names {
+		p := cfg.Providers[name]
+		providers = append(providers, common.NewOIDCProvider(common.OIDCProviderConfig{
+			Name:                  name,
+			ClientID:              p.ClientID,
+			ClientSecret:          p.ClientSecret,
+			Issuer:                p.Issuer,
+			AuthorizationEndpoint: p.AuthorizationEndpoint,
+			TokenEndpoint:         p.TokenEndpoint,
+			JWKSURI:               p.JWKSURI,
+			RedirectURL:           p.RedirectURL,
+			Scopes:                p.Scopes,
+			ResponseMode:          p.ResponseMode,
+		}, nil))
+	}
+	return providers
+}
+
+func newLoginAttemptStore(b *sqlx.DB, cfg config.LoginProtection) usercontracts.LoginAttemptStore {
+	if cfg.Store == "memory" {
+		return userdb.NewMemoryLoginAttemptStore()
+	}
+	return userdb.NewLoginAttemptStore(b)
+}
@@ -1,13 +1,20 @@
 package app
 
 import (
+	"context"
 	"net/http"
 	_ "yadwy-backend/api/swagger"
 	bh "yadwy-backend/internal/banner"
+	cartapp "yadwy-backend/internal/cart/application"
 	carth "yadwy-backend/internal/cart/infra"
 	ch "yadwy-backend/internal/category/infra"
 	"yadwy-backend/internal/common"
+	"yadwy-backend/internal/config"
+	prodapp "yadwy-backend/internal/prodcuts/application"
 	ph "yadwy-backend/internal/prodcuts/infra"
+	shopapp "yadwy-backend/internal/shops/application"
+	shoph "yadwy-backend/internal/shops/infra"
+	userapp "yadwy-backend/internal/users/application"
 	uh "yadwy-backend/internal/users/handlers"
 
 	"github.com/go-chi/chi/v5"
@@ -17,7 +24,7 @@ import (
 	"go.uber.org/zap"
 )
 
-func SetupRouter(db *sqlx.DB, jwt *common.JWTGenerator, logger *zap.Logger) http.Handler {
+func SetupRouter(ctx context.Context, cfg *config.Config, db *sqlx.DB, jwt *common.JWTGenerator, logger *zap.Logger) http.Handler {
 	router := chi.NewRouter()
 
 	// Middleware
@@ -38,13 +45,35 @@ func SetupRouter(db *sqlx.DB, jwt *common.JWTGenerator, logger *zap.Logger) http
 		http.ServeFile(w, r, "/home/nerd/images/"+chi.URLParam(r, "image"))
 	})
 
-	router.Route("/users", func(r chi.Router) {
-		uh.LoadUserRoutes(db, r, jwt)
-	})
+	// Public keys for services that verify our tokens
+	router.Get("/.well-known/jwks.json", common.JWKSHandler(jwt))
+
+	files, err := common.NewFileService("/home/nerd/images", "http://localhost:3000/images")
+	if err != nil {
+		logger.Fatal("Failed to create file storage", zap.Error(err))
+	}
+
+	// Loaded from the database by newUserServices when configured so
+	policy := common.NewPermissionPolicy(cfg.Authorization.Roles)
+
+	// Modules holding personal data take part in exports and erasure
+	carts := cartapp.NewCartService(carth.NewCartRepository(db, logger), logger)
+	products := prodapp.NewProductService(ph.NewProductRepository(db), files, policy, logger)
+	shops := shopapp.NewShopService(shoph.NewShopRepository(db, logger), products, files, logger)
+	personalData := userapp.PersonalDataModules{
+		Sources: []common.PersonalDataSource{carts, products, shops},
+		Erasers: []common.PersonalDataEraser{carts, shops},
+		Files:   files,
+	}
+
+	users := newUserServices(ctx, cfg, db, jwt, policy, personalData, logger)
+
+	uh.LoadUserRoutes(router, users, jwt, policy)
+	shoph.LoadShopRoutes(router, shops, jwt, policy, logger)
 
-	router.Mount("/category", ch.LoadCategoryRoutes(db, logger, jwt))
-	router.Mount("/banners", bh.LoadBannerRoutes(db, logger, jwt))
-	router.Mount("/products", ph.LoadProductsRoutes(db, logger, jwt))
+	router.Mount("/category", ch.LoadCategoryRoutes(db, logger, jwt, policy))
+	router.Mount("/banners", bh.LoadBannerRoutes(db, logger, jwt, policy))
+	router.Mount("/products", ph.LoadProductsRoutes(db, logger, jwt, policy, files))
 	router.Mount("/cart", carth.LoadCartRoutes(db, logger, jwt))
 	return router
 }
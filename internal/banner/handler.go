@@ -105,7 +105,7 @@ func handleError(w http.ResponseWriter, err error) {
 	common.SendError(w, http.StatusInternalServerError, "internal_server_error", err.Error())
 }
 
-func LoadBannerRoutes(b *sqlx.DB, logger *zap.Logger, jwt *common.JWTGenerator) http.Handler {
+func LoadBannerRoutes(b *sqlx.DB, logger *zap.Logger, jwt *common.JWTGenerator, policy *common.PermissionPolicy) http.Handler {
 	ar := chi.NewRouter()
 	br := NewRepo(b, logger)
 	files, _ := common.NewFileService("/home/nerd/images", "http://localhost:3000/images")
@@ -115,6 +115,6 @@ func LoadBannerRoutes(b *sqlx.DB, logger *zap.Logger, jwt *common.JWTGenerator)
 	ar.Use(common.GetAuthMiddlewareFunc(jwt))
 
 	ar.Get("/", handler.GetBanners)
-	ar.With(common.GetAdminMiddlewareFun(jwt)).Post("/", handler.CreateBanner)
+	ar.With(common.RequirePermission(policy, common.PermissionBannerManage)).Post("/", handler.CreateBanner)
 	return ar
 }
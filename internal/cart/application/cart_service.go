@@ -2,6 +2,7 @@ package application
 
 import (
 	"context"
+	"errors"
 	"yadwy-backend/internal/cart/domain"
 	"yadwy-backend/internal/common"
 
@@ -29,44 +30,52 @@ func (s *CartService) GetCart(ctx context.Context, userID int64) (*domain.Cart,
 	return cart, nil
 }
 
-func (s *CartService) AddItem(ctx context.Context, userID int64, productID int64, quantity int) error {
+func (s *CartService) AddItem(ctx context.Context, userID int64, productID int64, variantID int64, quantity int) error {
 	if quantity <= 0 {
 		return common.NewErrorf(domain.InvalidQuantityError, "quantity must be greater than 0")
 	}
 
-	err := s.repo.AddItem(ctx, userID, productID, quantity)
+	err := s.repo.AddItem(ctx, userID, productID, variantID, quantity)
 	if err != nil {
 		s.logger.Error("Failed to add item to cart",
 			zap.Int64("userID", userID),
 			zap.Int64("productID", productID),
+			zap.Int64("variantID", variantID),
 			zap.Error(err))
+		// keep the codes of missing products and variants for the handler
+		var appErr *common.Error
+		if errors.As(err, &appErr) {
+			return err
+		}
 		return common.NewErrorf(domain.FailedToAddItem, "failed to add item to cart: %v", err)
 	}
 	return nil
 }
 
-func (s *CartService) UpdateItem(ctx context.Context, userID int64, productID int64, quantity int) error {
+func (s *CartService) UpdateItem(ctx context.Context, userID int64, productID int64, variantID int64, quantity int) error {
 	if quantity <= 0 {
 		return common.NewErrorf(domain.InvalidQuantityError, "quantity must be greater than 0")
 	}
 
-	err := s.repo.UpdateItem(ctx, userID, productID, quantity)
+	err := s.repo.UpdateItem(ctx, userID, productID, variantID, quantity)
 	if err != nil {
 		s.logger.Error("Failed to update cart item",
 			zap.Int64("userID", userID),
 			zap.Int64("productID", productID),
+			zap.Int64("variantID", variantID),
 			zap.Error(err))
 		return common.NewErrorf(domain.FailedToUpdateItem, "failed to update cart item: %v", err)
 	}
 	return nil
 }
 
-func (s *CartService) RemoveItem(ctx context.Context, userID int64, productID int64) error {
-	err := s.repo.RemoveItem(ctx, userID, productID)
+func (s *CartService) RemoveItem(ctx context.Context, userID int64, productID int64, variantID int64) error {
+	err := s.repo.RemoveItem(ctx, userID, productID, variantID)
 	if err != nil {
 		s.logger.Error("Failed to remove item from cart",
 			zap.Int64("userID", userID),
 			zap.Int64("productID", productID),
+			zap.Int64("variantID", variantID),
 			zap.Error(err))
 		return common.NewErrorf(domain.FailedToRemoveItem, "failed to remove item from cart: %v", err)
 	}
@@ -83,3 +92,17 @@ func (s *CartService) ClearCart(ctx context.Context, userID int64) error {
 	}
 	return nil
 }
+
+// ExportPersonalData adds the cart of the user to a personal data export.
+func (s *CartService) ExportPersonalData(ctx context.Context, userID int64) (*common.PersonalDataSection, error) {
+	cart, err := s.GetCart(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &common.PersonalDataSection{Name: "cart", Data: cart}, nil
+}
+
+// ErasePersonalData empties the cart of an erased user.
+func (s *CartService) ErasePersonalData(ctx context.Context, userID int64) error {
+	return s.ClearCart(ctx, userID)
+}
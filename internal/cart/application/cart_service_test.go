@@ -99,7 +99,7 @@ func TestCartService_AddItem(t *testing.T) {
 			quantity:  2,
 			mock: func() *mock.CartRepository {
 				return &mock.CartRepository{
-					AddItemFunc: func(ctx context.Context, userID int64, productID int64, quantity int) error {
+					AddItemFunc: func(ctx context.Context, userID int64, productID int64, variantID int64, quantity int) error {
 						return nil
 					},
 				}
@@ -123,7 +123,7 @@ func TestCartService_AddItem(t *testing.T) {
 			quantity:  2,
 			mock: func() *mock.CartRepository {
 				return &mock.CartRepository{
-					AddItemFunc: func(ctx context.Context, userID int64, productID int64, quantity int) error {
+					AddItemFunc: func(ctx context.Context, userID int64, productID int64, variantID int64, quantity int) error {
 						return common.NewErrorf(domain.FailedToAddItem, "database error")
 					},
 				}
@@ -137,7 +137,7 @@ func TestCartService_AddItem(t *testing.T) {
 			repo := tt.mock()
 			service := NewCartService(repo, logger)
 
-			err := service.AddItem(ctx, tt.userID, tt.productID, tt.quantity)
+			err := service.AddItem(ctx, tt.userID, tt.productID, 0, tt.quantity)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("CartService.AddItem() error = %v, wantErr %v", err, tt.wantErr)
 			}
@@ -164,7 +164,7 @@ func TestCartService_UpdateItem(t *testing.T) {
 			quantity:  3,
 			mock: func() *mock.CartRepository {
 				return &mock.CartRepository{
-					UpdateItemFunc: func(ctx context.Context, userID int64, productID int64, quantity int) error {
+					UpdateItemFunc: func(ctx context.Context, userID int64, productID int64, variantID int64, quantity int) error {
 						return nil
 					},
 				}
@@ -188,7 +188,7 @@ func TestCartService_UpdateItem(t *testing.T) {
 			quantity:  3,
 			mock: func() *mock.CartRepository {
 				return &mock.CartRepository{
-					UpdateItemFunc: func(ctx context.Context, userID int64, productID int64, quantity int) error {
+					UpdateItemFunc: func(ctx context.Context, userID int64, productID int64, variantID int64, quantity int) error {
 						return common.NewErrorf(domain.FailedToUpdateItem, "database error")
 					},
 				}
@@ -202,7 +202,7 @@ func TestCartService_UpdateItem(t *testing.T) {
 			repo := tt.mock()
 			service := NewCartService(repo, logger)
 
-			err := service.UpdateItem(ctx, tt.userID, tt.productID, tt.quantity)
+			err := service.UpdateItem(ctx, tt.userID, tt.productID, 0, tt.quantity)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("CartService.UpdateItem() error = %v, wantErr %v", err, tt.wantErr)
 			}
@@ -227,7 +227,7 @@ func TestCartService_RemoveItem(t *testing.T) {
 			productID: 1,
 			mock: func() *mock.CartRepository {
 				return &mock.CartRepository{
-					RemoveItemFunc: func(ctx context.Context, userID int64, productID int64) error {
+					RemoveItemFunc: func(ctx context.Context, userID int64, productID int64, variantID int64) error {
 						return nil
 					},
 				}
@@ -240,7 +240,7 @@ func TestCartService_RemoveItem(t *testing.T) {
 			productID: 1,
 			mock: func() *mock.CartRepository {
 				return &mock.CartRepository{
-					RemoveItemFunc: func(ctx context.Context, userID int64, productID int64) error {
+					RemoveItemFunc: func(ctx context.Context, userID int64, productID int64, variantID int64) error {
 						return common.NewErrorf(domain.FailedToRemoveItem, "database error")
 					},
 				}
@@ -254,7 +254,7 @@ func TestCartService_RemoveItem(t *testing.T) {
 			repo := tt.mock()
 			service := NewCartService(repo, logger)
 
-			err := service.RemoveItem(ctx, tt.userID, tt.productID)
+			err := service.RemoveItem(ctx, tt.userID, tt.productID, 0)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("CartService.RemoveItem() error = %v, wantErr %v", err, tt.wantErr)
 			}
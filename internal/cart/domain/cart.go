@@ -5,9 +5,11 @@ import (
 )
 
 type CartItem struct {
-	ID        int64   `json:"id"`
-	CartID    int64   `json:"-"`
-	ProductID int64   `json:"product_id"`
+	ID        int64 `json:"id"`
+	CartID    int64 `json:"-"`
+	ProductID int64 `json:"product_id"`
+	// VariantID is set for products with variants.
+	VariantID int64   `json:"variant_id,omitempty"`
 	Quantity  int     `json:"quantity"`
 	Price     float64 `json:"price"`
 }
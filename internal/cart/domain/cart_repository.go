@@ -7,8 +7,10 @@ import "context"
 type CartRepository interface {
 	CreateCart(ctx context.Context, userID int64) (*Cart, error)
 	GetCart(ctx context.Context, userID int64) (*Cart, error)
-	AddItem(ctx context.Context, userID int64, productID int64, quantity int) error
-	UpdateItem(ctx context.Context, userID int64, productID int64, quantity int) error
-	RemoveItem(ctx context.Context, userID int64, productID int64) error
+	// AddItem adds a product to the cart. variantID is 0 for products
+	// without variants and required for the others.
+	AddItem(ctx context.Context, userID int64, productID int64, variantID int64, quantity int) error
+	UpdateItem(ctx context.Context, userID int64, productID int64, variantID int64, quantity int) error
+	RemoveItem(ctx context.Context, userID int64, productID int64, variantID int64) error
 	ClearCart(ctx context.Context, userID int64) error
 }
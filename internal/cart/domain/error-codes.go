@@ -14,4 +14,6 @@ const (
 	FailedToClearCart      common.ErrorCode = "failed-to-clear-cart"
 	FailedToGetCart        common.ErrorCode = "failed-to-get-cart"
 	InsufficientStockError common.ErrorCode = "insufficient-stock"
+	VariantNotFoundError   common.ErrorCode = "variant-not-found"
+	VariantRequiredError   common.ErrorCode = "variant-required"
 )
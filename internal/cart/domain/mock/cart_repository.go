@@ -9,9 +9,9 @@ import (
 type CartRepository struct {
 	CreateCartFunc func(ctx context.Context, userID int64) (*domain.Cart, error)
 	GetCartFunc    func(ctx context.Context, userID int64) (*domain.Cart, error)
-	AddItemFunc    func(ctx context.Context, userID int64, productID int64, quantity int) error
-	UpdateItemFunc func(ctx context.Context, userID int64, productID int64, quantity int) error
-	RemoveItemFunc func(ctx context.Context, userID int64, productID int64) error
+	AddItemFunc    func(ctx context.Context, userID int64, productID int64, variantID int64, quantity int) error
+	UpdateItemFunc func(ctx context.Context, userID int64, productID int64, variantID int64, quantity int) error
+	RemoveItemFunc func(ctx context.Context, userID int64, productID int64, variantID int64) error
 	ClearCartFunc  func(ctx context.Context, userID int64) error
 }
 
@@ -29,23 +29,23 @@ func (m *CartRepository) GetCart(ctx context.Context, userID int64) (*domain.Car
 	return nil, nil
 }
 
-func (m *CartRepository) AddItem(ctx context.Context, userID int64, productID int64, quantity int) error {
+func (m *CartRepository) AddItem(ctx context.Context, userID int64, productID int64, variantID int64, quantity int) error {
 	if m.AddItemFunc != nil {
-		return m.AddItemFunc(ctx, userID, productID, quantity)
+		return m.AddItemFunc(ctx, userID, productID, variantID, quantity)
 	}
 	return nil
 }
 
-func (m *CartRepository) UpdateItem(ctx context.Context, userID int64, productID int64, quantity int) error {
+func (m *CartRepository) UpdateItem(ctx context.Context, userID int64, productID int64, variantID int64, quantity int) error {
 	if m.UpdateItemFunc != nil {
-		return m.UpdateItemFunc(ctx, userID, productID, quantity)
+		return m.UpdateItemFunc(ctx, userID, productID, variantID, quantity)
 	}
 	return nil
 }
 
-func (m *CartRepository) RemoveItem(ctx context.Context, userID int64, productID int64) error {
+func (m *CartRepository) RemoveItem(ctx context.Context, userID int64, productID int64, variantID int64) error {
 	if m.RemoveItemFunc != nil {
-		return m.RemoveItemFunc(ctx, userID, productID)
+		return m.RemoveItemFunc(ctx, userID, productID, variantID)
 	}
 	return nil
 }
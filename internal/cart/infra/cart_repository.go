@@ -24,11 +24,12 @@ type cartDbo struct {
 }
 
 type cartItemDbo struct {
-	ID        int64   `db:"id"`
-	CartID    int64   `db:"cart_id"`
-	ProductID int64   `db:"product_id"`
-	Quantity  int     `db:"quantity"`
-	Price     float64 `db:"price"`
+	ID        int64         `db:"id"`
+	CartID    int64         `db:"cart_id"`
+	ProductID int64         `db:"product_id"`
+	VariantID sql.NullInt64 `db:"variant_id"`
+	Quantity  int           `db:"quantity"`
+	Price     float64       `db:"price"`
 }
 
 func NewCartRepository(db *sqlx.DB, logger *zap.Logger) domain.CartRepository {
@@ -68,7 +69,7 @@ func (r *CartRepositoryImpl) GetCart(ctx context.Context, userID int64) (*domain
 
 	var items []cartItemDbo
 	err = r.db.SelectContext(ctx, &items,
-		"SELECT * FROM cart_items WHERE cart_id = $1", cart.ID)
+		"SELECT id, cart_id, product_id, variant_id, quantity, price FROM cart_items WHERE cart_id = $1", cart.ID)
 	if err != nil {
 		return nil, common.NewErrorf(domain.FailedToGetCart, "failed to get cart items: %v", err)
 	}
@@ -79,6 +80,7 @@ func (r *CartRepositoryImpl) GetCart(ctx context.Context, userID int64) (*domain
 			ID:        item.ID,
 			CartID:    item.CartID,
 			ProductID: item.ProductID,
+			VariantID: item.VariantID.Int64,
 			Quantity:  item.Quantity,
 			Price:     item.Price,
 		}
@@ -93,7 +95,7 @@ func (r *CartRepositoryImpl) GetCart(ctx context.Context, userID int64) (*domain
 	}, nil
 }
 
-func (r *CartRepositoryImpl) AddItem(ctx context.Context, userID int64, productID int64, quantity int) error {
+func (r *CartRepositoryImpl) AddItem(ctx context.Context, userID int64, productID int64, variantID int64, quantity int) error {
 	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
 		return common.NewErrorf(domain.FailedToAddItem, "failed to start transaction: %v", err)
@@ -114,26 +116,24 @@ func (r *CartRepositoryImpl) AddItem(ctx context.Context, userID int64, productI
 		return common.NewErrorf(domain.FailedToGetCart, "failed to get cart: %v", err)
 	}
 
-	var price float64
-	err = tx.GetContext(ctx, &price, "SELECT price FROM products WHERE id = $1", productID)
-	if err == sql.ErrNoRows {
-		return common.NewErrorf(domain.ProductNotFoundError, "product not found")
-	}
+	price, err := itemPrice(ctx, tx, productID, variantID)
 	if err != nil {
-		return common.NewErrorf(domain.FailedToAddItem, "failed to get product price: %v", err)
+		return err
 	}
 
 	var existingItem cartItemDbo
-	err = tx.GetContext(ctx, &existingItem,
-		"SELECT * FROM cart_items WHERE cart_id = $1 AND product_id = $2", cartID, productID)
+	err = tx.GetContext(ctx, &existingItem, `
+		SELECT id, cart_id, product_id, variant_id, quantity, price FROM cart_items
+		WHERE cart_id = $1 AND product_id = $2 AND COALESCE(variant_id, 0) = $3`,
+		cartID, productID, variantID)
 	if err == sql.ErrNoRows {
 		_, err = tx.ExecContext(ctx,
-			"INSERT INTO cart_items (cart_id, product_id, quantity, price) VALUES ($1, $2, $3, $4)",
-			cartID, productID, quantity, price)
+			"INSERT INTO cart_items (cart_id, product_id, variant_id, quantity, price) VALUES ($1, $2, NULLIF($3, 0), $4, $5)",
+			cartID, productID, variantID, quantity, price)
 	} else if err == nil {
 		_, err = tx.ExecContext(ctx,
-			"UPDATE cart_items SET quantity = quantity + $1 WHERE cart_id = $2 AND product_id = $3",
-			quantity, cartID, productID)
+			"UPDATE cart_items SET quantity = quantity + $1 WHERE id = $2",
+			quantity, existingItem.ID)
 	}
 	if err != nil {
 		return common.NewErrorf(domain.FailedToAddItem, "failed to add item to cart: %v", err)
@@ -142,15 +142,54 @@ func (r *CartRepositoryImpl) AddItem(ctx context.Context, userID int64, productI
 	return tx.Commit()
 }
 
-func (r *CartRepositoryImpl) UpdateItem(ctx context.Context, userID int64, productID int64, quantity int) error {
+// itemPrice returns the price of an available variant, or of the product when
+// variantID is 0. Products with variants can only be added by variant.
+func itemPrice(ctx context.Context, tx *sqlx.Tx, productID int64, variantID int64) (float64, error) {
+	if variantID != 0 {
+		var price float64
+		err := tx.GetContext(ctx, &price, `
+			SELECT COALESCE(v.price, p.price) FROM product_variants v
+			JOIN products p ON p.id = v.product_id
+			WHERE v.id = $1 AND v.product_id = $2 AND v.is_available AND p.deleted_at IS NULL`,
+			variantID, productID)
+		if err == sql.ErrNoRows {
+			return 0, common.NewErrorf(domain.VariantNotFoundError, "variant not found or unavailable")
+		}
+		if err != nil {
+			return 0, common.NewErrorf(domain.FailedToAddItem, "failed to get variant price: %v", err)
+		}
+		return price, nil
+	}
+
+	var product struct {
+		Price       float64 `db:"price"`
+		HasVariants bool    `db:"has_variants"`
+	}
+	err := tx.GetContext(ctx, &product, `
+		SELECT price, EXISTS (SELECT 1 FROM product_variants v WHERE v.product_id = p.id) AS has_variants
+		FROM products p WHERE id = $1 AND deleted_at IS NULL`, productID)
+	if err == sql.ErrNoRows {
+		return 0, common.NewErrorf(domain.ProductNotFoundError, "product not found")
+	}
+	if err != nil {
+		return 0, common.NewErrorf(domain.FailedToAddItem, "failed to get product price: %v", err)
+	}
+	if product.HasVariants {
+		return 0, common.NewErrorf(domain.VariantRequiredError, "product has variants, choose one")
+	}
+	return product.Price, nil
+}
+
+func (r *CartRepositoryImpl) UpdateItem(ctx context.Context, userID int64, productID int64, variantID int64, quantity int) error {
 	result, err := r.db.ExecContext(ctx, `
 		UPDATE cart_items ci
 		SET quantity = $1
 		FROM carts c
 		WHERE c.id = ci.cart_id
 		AND c.user_id = $2
-		AND ci.product_id = $3`,
-		quantity, userID, productID)
+		AND ci.product_id = $3
+		AND COALESCE(ci.variant_id, 0) = $4`,
+		quantity, userID, productID, variantID)
 
 	if err != nil {
 		return common.NewErrorf(domain.FailedToUpdateItem, "failed to update item: %v", err)
@@ -167,14 +206,15 @@ func (r *CartRepositoryImpl) UpdateItem(ctx context.Context, userID int64, produ
 	return nil
 }
 
-func (r *CartRepositoryImpl) RemoveItem(ctx context.Context, userID int64, productID int64) error {
+func (r *CartRepositoryImpl) RemoveItem(ctx context.Context, userID int64, productID int64, variantID int64) error {
 	result, err := r.db.ExecContext(ctx, `
 		DELETE FROM cart_items ci
 		USING carts c
 		WHERE c.id = ci.cart_id
 		AND c.user_id = $1
-		AND ci.product_id = $2`,
-		userID, productID)
+		AND ci.product_id = $2
+		AND COALESCE(ci.variant_id, 0) = $3`,
+		userID, productID, variantID)
 
 	if err != nil {
 		return common.NewErrorf(domain.FailedToRemoveItem, "failed to remove item: %v", err)
@@ -107,24 +107,24 @@ func (s *CartRepositoryTestSuite) TestAddItem() {
 			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(cartID))
 
 		// Mock getting product price
-		s.mock.ExpectQuery("SELECT price FROM products").
+		s.mock.ExpectQuery("SELECT price, (.+) FROM products").
 			WithArgs(productID).
-			WillReturnRows(sqlmock.NewRows([]string{"price"}).AddRow(price))
+			WillReturnRows(sqlmock.NewRows([]string{"price", "has_variants"}).AddRow(price, false))
 
 		// Mock checking if item exists
 		s.mock.ExpectQuery("SELECT (.+) FROM cart_items").
-			WithArgs(cartID, productID).
+			WithArgs(cartID, productID, int64(0)).
 			WillReturnError(sql.ErrNoRows)
 
 		// Mock inserting new item
 		s.mock.ExpectExec("INSERT INTO cart_items").
-			WithArgs(cartID, productID, quantity, price).
+			WithArgs(cartID, productID, int64(0), quantity, price).
 			WillReturnResult(sqlmock.NewResult(1, 1))
 
 		// Mock commit
 		s.mock.ExpectCommit()
 
-		err := s.repo.AddItem(ctx, userID, productID, quantity)
+		err := s.repo.AddItem(ctx, userID, productID, 0, quantity)
 		s.Require().NoError(err)
 	})
 
@@ -138,27 +138,86 @@ func (s *CartRepositoryTestSuite) TestAddItem() {
 			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(cartID))
 
 		// Mock getting product price
-		s.mock.ExpectQuery("SELECT price FROM products").
+		s.mock.ExpectQuery("SELECT price, (.+) FROM products").
 			WithArgs(productID).
-			WillReturnRows(sqlmock.NewRows([]string{"price"}).AddRow(price))
+			WillReturnRows(sqlmock.NewRows([]string{"price", "has_variants"}).AddRow(price, false))
 
 		// Mock checking if item exists
 		s.mock.ExpectQuery("SELECT (.+) FROM cart_items").
-			WithArgs(cartID, productID).
+			WithArgs(cartID, productID, int64(0)).
 			WillReturnRows(sqlmock.NewRows([]string{"id", "cart_id", "product_id", "quantity", "price"}).
 				AddRow(1, cartID, productID, 1, price))
 
 		// Mock updating existing item
 		s.mock.ExpectExec("UPDATE cart_items SET quantity").
-			WithArgs(quantity, cartID, productID).
+			WithArgs(quantity, int64(1)).
 			WillReturnResult(sqlmock.NewResult(0, 1))
 
 		// Mock commit
 		s.mock.ExpectCommit()
 
-		err := s.repo.AddItem(ctx, userID, productID, quantity)
+		err := s.repo.AddItem(ctx, userID, productID, 0, quantity)
 		s.Require().NoError(err)
 	})
+	s.Run("should add a variant at its price", func() {
+		variantID := int64(7)
+		variantPrice := 12.25
+		s.mock.ExpectBegin()
+		s.mock.ExpectQuery("SELECT id FROM carts").
+			WithArgs(userID).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(cartID))
+		s.mock.ExpectQuery("SELECT COALESCE(.+) FROM product_variants").
+			WithArgs(variantID, productID).
+			WillReturnRows(sqlmock.NewRows([]string{"price"}).AddRow(variantPrice))
+		s.mock.ExpectQuery("SELECT (.+) FROM cart_items").
+			WithArgs(cartID, productID, variantID).
+			WillReturnError(sql.ErrNoRows)
+		s.mock.ExpectExec("INSERT INTO cart_items").
+			WithArgs(cartID, productID, variantID, quantity, variantPrice).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		s.mock.ExpectCommit()
+
+		err := s.repo.AddItem(ctx, userID, productID, variantID, quantity)
+		s.Require().NoError(err)
+	})
+
+	s.Run("should require a variant for products with variants", func() {
+		s.mock.ExpectBegin()
+		s.mock.ExpectQuery("SELECT id FROM carts").
+			WithArgs(userID).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(cartID))
+		s.mock.ExpectQuery("SELECT price, (.+) FROM products").
+			WithArgs(productID).
+			WillReturnRows(sqlmock.NewRows([]string{"price", "has_variants"}).AddRow(price, true))
+		s.mock.ExpectRollback()
+
+		err := s.repo.AddItem(ctx, userID, productID, 0, quantity)
+		s.Require().Error(err)
+		if e, ok := err.(*common.Error); ok {
+			s.Equal(domain.VariantRequiredError, e.Code())
+		} else {
+			s.Fail("Expected *common.Error type")
+		}
+	})
+
+	s.Run("should reject unavailable variants", func() {
+		s.mock.ExpectBegin()
+		s.mock.ExpectQuery("SELECT id FROM carts").
+			WithArgs(userID).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(cartID))
+		s.mock.ExpectQuery("SELECT COALESCE(.+) FROM product_variants").
+			WithArgs(int64(8), productID).
+			WillReturnError(sql.ErrNoRows)
+		s.mock.ExpectRollback()
+
+		err := s.repo.AddItem(ctx, userID, productID, 8, quantity)
+		s.Require().Error(err)
+		if e, ok := err.(*common.Error); ok {
+			s.Equal(domain.VariantNotFoundError, e.Code())
+		} else {
+			s.Fail("Expected *common.Error type")
+		}
+	})
 }
 
 func (s *CartRepositoryTestSuite) TestUpdateItem() {
@@ -169,19 +228,19 @@ func (s *CartRepositoryTestSuite) TestUpdateItem() {
 
 	s.Run("should update item quantity successfully", func() {
 		s.mock.ExpectExec("UPDATE cart_items").
-			WithArgs(quantity, userID, productID).
+			WithArgs(quantity, userID, productID, int64(0)).
 			WillReturnResult(sqlmock.NewResult(0, 1))
 
-		err := s.repo.UpdateItem(ctx, userID, productID, quantity)
+		err := s.repo.UpdateItem(ctx, userID, productID, 0, quantity)
 		s.Require().NoError(err)
 	})
 
 	s.Run("should return error when item not found", func() {
 		s.mock.ExpectExec("UPDATE cart_items").
-			WithArgs(quantity, userID, productID).
+			WithArgs(quantity, userID, productID, int64(0)).
 			WillReturnResult(sqlmock.NewResult(0, 0))
 
-		err := s.repo.UpdateItem(ctx, userID, productID, quantity)
+		err := s.repo.UpdateItem(ctx, userID, productID, 0, quantity)
 		s.Require().Error(err)
 		if e, ok := err.(*common.Error); ok {
 			s.Equal(domain.CartItemNotFoundError, e.Code())
@@ -198,19 +257,19 @@ func (s *CartRepositoryTestSuite) TestRemoveItem() {
 
 	s.Run("should remove item successfully", func() {
 		s.mock.ExpectExec("DELETE FROM cart_items").
-			WithArgs(userID, productID).
+			WithArgs(userID, productID, int64(0)).
 			WillReturnResult(sqlmock.NewResult(0, 1))
 
-		err := s.repo.RemoveItem(ctx, userID, productID)
+		err := s.repo.RemoveItem(ctx, userID, productID, 0)
 		s.Require().NoError(err)
 	})
 
 	s.Run("should return error when item not found", func() {
 		s.mock.ExpectExec("DELETE FROM cart_items").
-			WithArgs(userID, productID).
+			WithArgs(userID, productID, int64(0)).
 			WillReturnResult(sqlmock.NewResult(0, 0))
 
-		err := s.repo.RemoveItem(ctx, userID, productID)
+		err := s.repo.RemoveItem(ctx, userID, productID, 0)
 		s.Require().Error(err)
 		if e, ok := err.(*common.Error); ok {
 			s.Equal(domain.CartItemNotFoundError, e.Code())
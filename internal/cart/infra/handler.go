@@ -2,6 +2,7 @@ package infra
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 	"yadwy-backend/internal/cart/application"
@@ -21,6 +22,8 @@ type CartHandler struct {
 
 type addToCartRequest struct {
 	ProductID int64 `json:"product_id" validate:"required"`
+	// VariantID is required for products with variants
+	VariantID int64 `json:"variant_id"`
 	Quantity  int   `json:"quantity" validate:"required,gt=0"`
 }
 
@@ -77,15 +80,16 @@ func (h *CartHandler) GetCart(w http.ResponseWriter, r *http.Request) {
 }
 
 // @Summary Add item to cart
-// @Description Add a product to the user's shopping cart
+// @Description Add a product to the user's shopping cart. Products with variants are added by variant, at the price of the variant.
 // @Tags cart
 // @Security BearerAuth
 // @Accept json
 // @Produce json
 // @Param request body addToCartRequest true "Product details to add to cart"
 // @Success 201 "Item added to cart"
-// @Failure 400 {object} common.ErrorResponse "Invalid request"
+// @Failure 400 {object} common.ErrorResponse "Invalid request or missing variant"
 // @Failure 401 {object} common.ErrorResponse "Unauthorized"
+// @Failure 404 {object} common.ErrorResponse "Product or variant not found"
 // @Failure 500 {object} common.ErrorResponse "Server error"
 // @Router /cart/items [post]
 func (h *CartHandler) AddToCart(w http.ResponseWriter, r *http.Request) {
@@ -101,10 +105,10 @@ func (h *CartHandler) AddToCart(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = h.service.AddItem(r.Context(), claims.ID, req.ProductID, req.Quantity)
+	err = h.service.AddItem(r.Context(), claims.ID, req.ProductID, req.VariantID, req.Quantity)
 	if err != nil {
 		h.logger.Error("Failed to add item to cart", zap.Error(err))
-		common.SendError(w, http.StatusInternalServerError, string(domain.FailedToAddItem), err.Error())
+		handleError(w, err)
 		return
 	}
 
@@ -118,6 +122,7 @@ func (h *CartHandler) AddToCart(w http.ResponseWriter, r *http.Request) {
 // @Accept json
 // @Produce json
 // @Param productId path integer true "Product ID"
+// @Param variant_id query integer false "Variant ID, for products with variants"
 // @Param request body updateCartItemRequest true "Updated quantity"
 // @Success 200 "Item updated"
 // @Failure 400 {object} common.ErrorResponse "Invalid request"
@@ -125,9 +130,8 @@ func (h *CartHandler) AddToCart(w http.ResponseWriter, r *http.Request) {
 // @Failure 500 {object} common.ErrorResponse "Server error"
 // @Router /cart/items/{productId} [put]
 func (h *CartHandler) UpdateCartItem(w http.ResponseWriter, r *http.Request) {
-	productID, err := strconv.ParseInt(chi.URLParam(r, "productId"), 10, 64)
-	if err != nil {
-		common.SendError(w, http.StatusBadRequest, "invalid-product-id", "invalid product ID")
+	productID, variantID, ok := cartItemID(w, r)
+	if !ok {
 		return
 	}
 
@@ -143,7 +147,7 @@ func (h *CartHandler) UpdateCartItem(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = h.service.UpdateItem(r.Context(), claims.ID, productID, req.Quantity)
+	err = h.service.UpdateItem(r.Context(), claims.ID, productID, variantID, req.Quantity)
 	if err != nil {
 		h.logger.Error("Failed to update cart item", zap.Error(err))
 		common.SendError(w, http.StatusInternalServerError, string(domain.FailedToUpdateItem), err.Error())
@@ -159,15 +163,15 @@ func (h *CartHandler) UpdateCartItem(w http.ResponseWriter, r *http.Request) {
 // @Security BearerAuth
 // @Produce json
 // @Param productId path integer true "Product ID"
+// @Param variant_id query integer false "Variant ID, for products with variants"
 // @Success 200 "Item removed"
 // @Failure 400 {object} common.ErrorResponse "Invalid product ID"
 // @Failure 401 {object} common.ErrorResponse "Unauthorized"
 // @Failure 500 {object} common.ErrorResponse "Server error"
 // @Router /cart/items/{productId} [delete]
 func (h *CartHandler) RemoveFromCart(w http.ResponseWriter, r *http.Request) {
-	productID, err := strconv.ParseInt(chi.URLParam(r, "productId"), 10, 64)
-	if err != nil {
-		common.SendError(w, http.StatusBadRequest, "invalid-product-id", "invalid product ID")
+	productID, variantID, ok := cartItemID(w, r)
+	if !ok {
 		return
 	}
 
@@ -177,7 +181,7 @@ func (h *CartHandler) RemoveFromCart(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = h.service.RemoveItem(r.Context(), claims.ID, productID)
+	err = h.service.RemoveItem(r.Context(), claims.ID, productID, variantID)
 	if err != nil {
 		h.logger.Error("Failed to remove item from cart", zap.Error(err))
 		common.SendError(w, http.StatusInternalServerError, string(domain.FailedToRemoveItem), err.Error())
@@ -213,6 +217,42 @@ func (h *CartHandler) ClearCart(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 }
 
+func handleError(w http.ResponseWriter, err error) {
+	var appErr *common.Error
+	if errors.As(err, &appErr) {
+		switch appErr.Code() {
+		case domain.InvalidQuantityError, domain.VariantRequiredError:
+			common.SendError(w, http.StatusBadRequest, string(appErr.Code()), appErr.Error())
+		case domain.ProductNotFoundError, domain.VariantNotFoundError:
+			common.SendError(w, http.StatusNotFound, string(appErr.Code()), appErr.Error())
+		default:
+			common.SendError(w, http.StatusInternalServerError, string(appErr.Code()), appErr.Error())
+		}
+		return
+	}
+
+	common.SendError(w, http.StatusInternalServerError, "internal_server_error", err.Error())
+}
+
+// cartItemID reads the {productId} path parameter and the optional variant_id
+// query parameter, writing the error response on failure.
+func cartItemID(w http.ResponseWriter, r *http.Request) (int64, int64, bool) {
+	productID, err := strconv.ParseInt(chi.URLParam(r, "productId"), 10, 64)
+	if err != nil {
+		common.SendError(w, http.StatusBadRequest, "invalid-product-id", "invalid product ID")
+		return 0, 0, false
+	}
+
+	var variantID int64
+	if v := r.URL.Query().Get("variant_id"); v != "" {
+		if variantID, err = strconv.ParseInt(v, 10, 64); err != nil {
+			common.SendError(w, http.StatusBadRequest, "invalid-variant-id", "invalid variant ID")
+			return 0, 0, false
+		}
+	}
+	return productID, variantID, true
+}
+
 func LoadCartRoutes(db *sqlx.DB, logger *zap.Logger, jwt *common.JWTGenerator) http.Handler {
 	router := chi.NewRouter()
 	repo := NewCartRepository(db, logger)
@@ -222,8 +262,9 @@ func LoadCartRoutes(db *sqlx.DB, logger *zap.Logger, jwt *common.JWTGenerator) h
 	router.Use(common.GetAuthMiddlewareFunc(jwt))
 
 	router.Get("/", handler.GetCart)
-	router.Post("/items", handler.AddToCart)
-	router.Put("/items/{productId}", handler.UpdateCartItem)
+	// Unverified users can look at their cart but not add to it.
+	router.With(common.RequireVerifiedEmail).Post("/items", handler.AddToCart)
+	router.With(common.RequireVerifiedEmail).Put("/items/{productId}", handler.UpdateCartItem)
 	router.Delete("/items/{productId}", handler.RemoveFromCart)
 	router.Delete("/", handler.ClearCart)
 
@@ -122,7 +122,7 @@ func TestCartHandler_AddToCart(t *testing.T) {
 			},
 			mock: func() *mock.CartRepository {
 				return &mock.CartRepository{
-					AddItemFunc: func(ctx context.Context, userID int64, productID int64, quantity int) error {
+					AddItemFunc: func(ctx context.Context, userID int64, productID int64, variantID int64, quantity int) error {
 						return nil
 					},
 				}
@@ -137,13 +137,44 @@ func TestCartHandler_AddToCart(t *testing.T) {
 			},
 			mock: func() *mock.CartRepository {
 				return &mock.CartRepository{
-					AddItemFunc: func(ctx context.Context, userID int64, productID int64, quantity int) error {
+					AddItemFunc: func(ctx context.Context, userID int64, productID int64, variantID int64, quantity int) error {
 						return common.NewErrorf(domain.FailedToAddItem, "database error")
 					},
 				}
 			},
 			expectedStatus: http.StatusInternalServerError,
 		},
+		{
+			name: "should return bad request when the product needs a variant",
+			request: &addToCartRequest{
+				ProductID: 1,
+				Quantity:  2,
+			},
+			mock: func() *mock.CartRepository {
+				return &mock.CartRepository{
+					AddItemFunc: func(ctx context.Context, userID int64, productID int64, variantID int64, quantity int) error {
+						return common.NewErrorf(domain.VariantRequiredError, "product has variants, choose one")
+					},
+				}
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "should return not found when the variant does not exist",
+			request: &addToCartRequest{
+				ProductID: 1,
+				VariantID: 99,
+				Quantity:  2,
+			},
+			mock: func() *mock.CartRepository {
+				return &mock.CartRepository{
+					AddItemFunc: func(ctx context.Context, userID int64, productID int64, variantID int64, quantity int) error {
+						return common.NewErrorf(domain.VariantNotFoundError, "variant not found or unavailable")
+					},
+				}
+			},
+			expectedStatus: http.StatusNotFound,
+		},
 	}
 
 	for _, tt := range tests {
@@ -181,7 +212,7 @@ func TestCartHandler_UpdateCartItem(t *testing.T) {
 			},
 			mock: func() *mock.CartRepository {
 				return &mock.CartRepository{
-					UpdateItemFunc: func(ctx context.Context, userID int64, productID int64, quantity int) error {
+					UpdateItemFunc: func(ctx context.Context, userID int64, productID int64, variantID int64, quantity int) error {
 						return nil
 					},
 				}
@@ -196,7 +227,7 @@ func TestCartHandler_UpdateCartItem(t *testing.T) {
 			},
 			mock: func() *mock.CartRepository {
 				return &mock.CartRepository{
-					UpdateItemFunc: func(ctx context.Context, userID int64, productID int64, quantity int) error {
+					UpdateItemFunc: func(ctx context.Context, userID int64, productID int64, variantID int64, quantity int) error {
 						return common.NewErrorf(domain.FailedToUpdateItem, "database error")
 					},
 				}
@@ -253,7 +284,7 @@ func TestCartHandler_RemoveFromCart(t *testing.T) {
 			productID: "1",
 			mock: func() *mock.CartRepository {
 				return &mock.CartRepository{
-					RemoveItemFunc: func(ctx context.Context, userID int64, productID int64) error {
+					RemoveItemFunc: func(ctx context.Context, userID int64, productID int64, variantID int64) error {
 						return nil
 					},
 				}
@@ -265,7 +296,7 @@ func TestCartHandler_RemoveFromCart(t *testing.T) {
 			productID: "1",
 			mock: func() *mock.CartRepository {
 				return &mock.CartRepository{
-					RemoveItemFunc: func(ctx context.Context, userID int64, productID int64) error {
+					RemoveItemFunc: func(ctx context.Context, userID int64, productID int64, variantID int64) error {
 						return common.NewErrorf(domain.FailedToRemoveItem, "database error")
 					},
 				}
@@ -90,7 +90,7 @@ func (h *Handler) GetAllCategories(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func LoadCategoryRoutes(b *sqlx.DB, logger *zap.Logger, jwt *common.JWTGenerator) http.Handler {
+func LoadCategoryRoutes(b *sqlx.DB, logger *zap.Logger, jwt *common.JWTGenerator, policy *common.PermissionPolicy) http.Handler {
 	ar := chi.NewRouter()
 	cr := NewCategoryRepo(b, logger)
 	files, _ := common.NewFileService("/home/nerd/images", "http://localhost:3000/images")
@@ -101,7 +101,7 @@ func LoadCategoryRoutes(b *sqlx.DB, logger *zap.Logger, jwt *common.JWTGenerator
 	ar.Get("/", ch.GetAllCategories)
 
 	// Admin routes
-	ar.With(common.GetAdminMiddlewareFun(jwt)).Post("/", ch.Create)
+	ar.With(common.RequirePermission(policy, common.PermissionCategoryManage)).Post("/", ch.Create)
 	return ar
 }
 
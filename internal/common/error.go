@@ -11,7 +11,11 @@ const (
 	AuthHeaderMissingErrorCode        ErrorCode = "authorization-header-missing"
 	AuthHeaderTokenMissingErrorCode   ErrorCode = "authorization-token-missing"
 	AuthHeaderTokenVerificationFailed ErrorCode = "authorization-token-verification-failed"
+	AuthHeaderTokenRevokedErrorCode   ErrorCode = "authorization-token-revoked"
 	InvalidUserRoleErrorCode          ErrorCode = "invalid-user-role"
+	EmailNotVerifiedErrorCode         ErrorCode = "email-not-verified"
+	MFARequiredErrorCode              ErrorCode = "mfa-required"
+	PermissionDeniedErrorCode         ErrorCode = "permission-denied"
 )
 
 type ErrorResponse struct {
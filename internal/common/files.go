@@ -70,6 +70,16 @@ func (fs *FileService) GetFileURL(filename string) string {
 	return baseURL + filename
 }
 
+// FileName returns the stored file name of a URL returned by SaveFile, or
+// false when the URL does not point into the storage.
+func (fs *FileService) FileName(fileURL string) (string, bool) {
+	name, ok := strings.CutPrefix(fileURL, fs.GetFileURL(""))
+	if !ok || name == "" || strings.Contains(name, "/") {
+		return "", false
+	}
+	return name, true
+}
+
 func (fs *FileService) ServeFile(w http.ResponseWriter, filename string) {
 	filePath := filepath.Join(fs.StoragePath, filename)
 	http.ServeFile(w, nil, filePath)
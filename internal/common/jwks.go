@@ -0,0 +1,156 @@
+package common
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"net/http"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// JWTKey is a key used to sign or verify tokens. Keys built from a public key
+// can only verify.
+type JWTKey struct {
+	id        string
+	method    jwt.SigningMethod
+	signKey   interface{}
+	verifyKey interface{}
+}
+
+// NewHMACKey returns an HS256 key. HMAC keys are secret and are never
+// published in the JWKS.
+func NewHMACKey(id, secret string) (*JWTKey, error) {
+	if secret == "" {
+		return nil, fmt.Errorf("HMAC secret is empty")
+	}
+	return &JWTKey{
+		id:        id,
+		method:    jwt.SigningMethodHS256,
+		signKey:   []byte(secret),
+		verifyKey: []byte(secret),
+	}, nil
+}
+
+// ParsePrivateKeyPEM reads an RSA (RS256) or Ed25519 (EdDSA) private key.
+func ParsePrivateKeyPEM(id string, pemBytes []byte) (*JWTKey, error) {
+	if key, err := jwt.ParseRSAPrivateKeyFromPEM(pemBytes); err == nil {
+		return &JWTKey{id: id, method: jwt.SigningMethodRS256, signKey: key, verifyKey: &key.PublicKey}, nil
+	}
+	if key, err := jwt.ParseEdPrivateKeyFromPEM(pemBytes); err == nil {
+		priv := key.(ed25519.PrivateKey)
+		return &JWTKey{id: id, method: jwt.SigningMethodEdDSA, signKey: priv, verifyKey: priv.Public()}, nil
+	}
+	return nil, fmt.Errorf("key %q is not an RSA or Ed25519 private key", id)
+}
+
+// ParsePublicKeyPEM reads an RSA or Ed25519 public key that can only verify
+// tokens, such as a key that was rotated out but may still have live tokens.
+func ParsePublicKeyPEM(id string, pemBytes []byte) (*JWTKey, error) {
+	if key, err := jwt.ParseRSAPublicKeyFromPEM(pemBytes); err == nil {
+		return &JWTKey{id: id, method: jwt.SigningMethodRS256, verifyKey: key}, nil
+	}
+	if key, err := jwt.ParseEdPublicKeyFromPEM(pemBytes); err == nil {
+		return &JWTKey{id: id, method: jwt.SigningMethodEdDSA, verifyKey: key}, nil
+	}
+	return nil, fmt.Errorf("key %q is not an RSA or Ed25519 public key", id)
+}
+
+func (k *JWTKey) ID() string {
+	return k.id
+}
+
+func (k *JWTKey) Algorithm() string {
+	return k.method.Alg()
+}
+
+func (k *JWTKey) canSign() bool {
+	return k.signKey != nil
+}
+
+// JWK is a public key in JSON Web Key format (RFC 7517).
+// @Description Public JSON Web Key
+type JWK struct {
+	Kty string `json:"kty" example:"RSA"`
+	Kid string `json:"kid" example:"2025-01"`
+	Use string `json:"use" example:"sig"`
+	Alg string `json:"alg" example:"RS256"`
+	// RSA modulus and exponent
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// Ed25519 curve and public key
+	Crv string `json:"crv,omitempty" example:"Ed25519"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKSet is the document served at /.well-known/jwks.json.
+// @Description JSON Web Key Set
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// ParseJWK reads a public RSA or Ed25519 key published by another issuer,
+// such as an OpenID Connect provider. The key can only verify tokens.
+func ParseJWK(k JWK) (*JWTKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("key %q has an invalid modulus: %w", k.Kid, err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil || len(e) == 0 || len(e) > 4 {
+			return nil, fmt.Errorf("key %q has an invalid exponent", k.Kid)
+		}
+		pub := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+		return &JWTKey{id: k.Kid, method: jwt.SigningMethodRS256, verifyKey: pub}, nil
+	case "OKP":
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if k.Crv != "Ed25519" || err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("key %q is not an Ed25519 key", k.Kid)
+		}
+		return &JWTKey{id: k.Kid, method: jwt.SigningMethodEdDSA, verifyKey: ed25519.PublicKey(x)}, nil
+	}
+	return nil, fmt.Errorf("key %q has unsupported type %q", k.Kid, k.Kty)
+}
+
+func (k *JWTKey) jwk() (JWK, bool) {
+	switch pub := k.verifyKey.(type) {
+	case *rsa.PublicKey:
+		return JWK{
+			Kty: "RSA",
+			Kid: k.id,
+			Use: "sig",
+			Alg: k.method.Alg(),
+			N:   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}, true
+	case ed25519.PublicKey:
+		return JWK{
+			Kty: "OKP",
+			Kid: k.id,
+			Use: "sig",
+			Alg: k.method.Alg(),
+			Crv: "Ed25519",
+			X:   base64.RawURLEncoding.EncodeToString(pub),
+		}, true
+	}
+	return JWK{}, false
+}
+
+// @Summary JSON Web Key Set
+// @Description Public keys that verify Yadwy tokens. Empty when tokens are signed with HMAC.
+// @Tags auth
+// @Produce json
+// @Success 200 {object} common.JWKSet
+// @Router /.well-known/jwks.json [get]
+func JWKSHandler(generator *JWTGenerator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		if err := Encode(w, http.StatusOK, generator.JWKS()); err != nil {
+			SendError(w, http.StatusInternalServerError, "internal-server-error", err.Error())
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package common
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func pemKeys(t *testing.T, priv interface{}, pub interface{}) ([]byte, []byte) {
+	t.Helper()
+	privDER, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		t.Fatalf("MarshalPKCS8PrivateKey() error = %v", err)
+	}
+	pubDER, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		t.Fatalf("MarshalPKIXPublicKey() error = %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privDER}),
+		pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER})
+}
+
+func newEd25519PEM(t *testing.T) ([]byte, []byte) {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	return pemKeys(t, priv, pub)
+}
+
+func newRSAPEM(t *testing.T) ([]byte, []byte) {
+	t.Helper()
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	return pemKeys(t, priv, &priv.PublicKey)
+}
+
+func TestJWTGenerator_AsymmetricKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		newPEM  func(t *testing.T) ([]byte, []byte)
+		wantAlg string
+		wantKty string
+	}{
+		{name: "RS256", newPEM: newRSAPEM, wantAlg: "RS256", wantKty: "RSA"},
+		{name: "EdDSA", newPEM: newEd25519PEM, wantAlg: "EdDSA", wantKty: "OKP"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			privPEM, _ := tt.newPEM(t)
+			key, err := ParsePrivateKeyPEM("key-1", privPEM)
+			if err != nil {
+				t.Fatalf("ParsePrivateKeyPEM() error = %v", err)
+			}
+			generator, err := NewJWTGeneratorWithKeys(key)
+			if err != nil {
+				t.Fatalf("NewJWTGeneratorWithKeys() error = %v", err)
+			}
+
+			tokenStr, _, err := generator.CreateToken(TokenSubject{ID: 1, Email: "john@example.com", Role: "CUSTOMER"}, time.Minute)
+			if err != nil {
+				t.Fatalf("CreateToken() error = %v", err)
+			}
+			parsed, _, err := jwt.NewParser().ParseUnverified(tokenStr, &UserClaims{})
+			if err != nil {
+				t.Fatalf("ParseUnverified() error = %v", err)
+			}
+			if parsed.Header["kid"] != "key-1" || parsed.Header["alg"] != tt.wantAlg {
+				t.Errorf("token header = %v, want kid key-1 and alg %s", parsed.Header, tt.wantAlg)
+			}
+			if _, err := generator.VerifyToken(tokenStr); err != nil {
+				t.Errorf("VerifyToken() error = %v", err)
+			}
+
+			jwks := generator.JWKS()
+			if len(jwks.Keys) != 1 || jwks.Keys[0].Kid != "key-1" || jwks.Keys[0].Kty != tt.wantKty {
+				t.Errorf("JWKS() = %+v, want one %s key with kid key-1", jwks, tt.wantKty)
+			}
+		})
+	}
+}
+
+func TestJWTGenerator_KeyRotation(t *testing.T) {
+	oldPriv, oldPub := newEd25519PEM(t)
+	newPriv, _ := newRSAPEM(t)
+
+	oldKey, err := ParsePrivateKeyPEM("old", oldPriv)
+	if err != nil {
+		t.Fatalf("ParsePrivateKeyPEM() error = %v", err)
+	}
+	oldGenerator, _ := NewJWTGeneratorWithKeys(oldKey)
+	oldToken, _, err := oldGenerator.CreateToken(TokenSubject{ID: 1, Email: "john@example.com", Role: "CUSTOMER"}, time.Minute)
+	if err != nil {
+		t.Fatalf("CreateToken() error = %v", err)
+	}
+
+	newKey, _ := ParsePrivateKeyPEM("new", newPriv)
+	retiredKey, err := ParsePublicKeyPEM("old", oldPub)
+	if err != nil {
+		t.Fatalf("ParsePublicKeyPEM() error = %v", err)
+	}
+	rotated, err := NewJWTGeneratorWithKeys(newKey, retiredKey)
+	if err != nil {
+		t.Fatalf("NewJWTGeneratorWithKeys() error = %v", err)
+	}
+
+	if _, err := rotated.VerifyToken(oldToken); err != nil {
+		t.Errorf("VerifyToken() with retired key error = %v", err)
+	}
+	if got := len(rotated.JWKS().Keys); got != 2 {
+		t.Errorf("JWKS() has %d keys, want 2", got)
+	}
+
+	withoutOld, _ := NewJWTGeneratorWithKeys(newKey)
+	if _, err := withoutOld.VerifyToken(oldToken); err == nil {
+		t.Errorf("VerifyToken() accepted a token signed by an unknown key")
+	}
+}
+
+func TestJWTGenerator_RejectsAlgorithmConfusion(t *testing.T) {
+	privPEM, pubPEM := newRSAPEM(t)
+	key, _ := ParsePrivateKeyPEM("key-1", privPEM)
+	generator, _ := NewJWTGeneratorWithKeys(key)
+
+	// A token signed with HS256 using the public key as the secret.
+	claims, _ := NewUserClaims(TokenSubject{ID: 1, Email: "john@example.com", Role: "ADMIN"}, AccessTokenType, time.Minute)
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	forged.Header["kid"] = "key-1"
+	forgedStr, err := forged.SignedString(pubPEM)
+	if err != nil {
+		t.Fatalf("SignedString() error = %v", err)
+	}
+
+	if _, err := generator.VerifyToken(forgedStr); err == nil {
+		t.Errorf("VerifyToken() accepted an HS256 token for an RS256 key")
+	}
+	if got := len(NewJWTGenerator("secret").JWKS().Keys); got != 0 {
+		t.Errorf("JWKS() published %d HMAC keys", got)
+	}
+}
@@ -1,7 +1,9 @@
 package common
 
 import (
+	"context"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/google/uuid"
@@ -9,20 +11,126 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
+const (
+	AccessTokenType  = "access"
+	RefreshTokenType = "refresh"
+	// MFAChallengeTokenType proves the password step of a two-step login.
+	MFAChallengeTokenType = "mfa_challenge"
+	// MFAEnrollmentTokenType only allows a user that must use MFA to enroll.
+	MFAEnrollmentTokenType = "mfa_enrollment"
+	// APIKeyTokenType marks claims authenticated by an API key rather than a
+	// signed token.
+	APIKeyTokenType = "api_key"
+)
+
+// TokenSubject is the user a token is issued for.
+type TokenSubject struct {
+	ID            int64
+	Email         string
+	Role          string
+	EmailVerified bool
+	// MFA tells the user completed a second factor when logging in.
+	MFA bool
+	// SessionID is the login session the token belongs to.
+	SessionID string
+	// SellerStatus is the approval of a seller, empty for other users.
+	SellerStatus string
+}
+
+// APIKeyAuthenticator resolves an API key to the claims of its owner, with
+// the scopes of the key.
+type APIKeyAuthenticator interface {
+	AuthenticateAPIKey(ctx context.Context, key string) (*UserClaims, error)
+}
+
+// TokenValidator runs additional checks, such as revocation, on the claims of
+// an access token whose signature and expiry have already been verified.
+type TokenValidator interface {
+	ValidateToken(ctx context.Context, claims *UserClaims) error
+}
+
+// JWTGenerator signs tokens with one key and verifies them with any of its
+// verification keys, selected by the kid header. Keeping the previous keys for
+// verification lets the signing key rotate without invalidating live tokens.
 type JWTGenerator struct {
-	secretKey string
+	signingKey *JWTKey
+	verifyKeys map[string]*JWTKey
+	validators []TokenValidator
+	apiKeys    APIKeyAuthenticator
 }
 
+// NewJWTGenerator returns a generator that signs and verifies with a shared
+// HS256 secret.
 func NewJWTGenerator(secretKey string) *JWTGenerator {
-	return &JWTGenerator{secretKey}
+	key := &JWTKey{method: jwt.SigningMethodHS256, signKey: []byte(secretKey), verifyKey: []byte(secretKey)}
+	return &JWTGenerator{signingKey: key, verifyKeys: map[string]*JWTKey{"": key}}
+}
+
+// NewJWTGeneratorWithKeys returns a generator that signs with signingKey and
+// also accepts tokens signed by the verification keys.
+func NewJWTGeneratorWithKeys(signingKey *JWTKey, verificationKeys ...*JWTKey) (*JWTGenerator, error) {
+	if signingKey == nil || !signingKey.canSign() {
+		return nil, fmt.Errorf("signing key must include a private key or secret")
+	}
+
+	keys := map[string]*JWTKey{signingKey.id: signingKey}
+	for _, k := range verificationKeys {
+		if _, ok := keys[k.id]; ok {
+			return nil, fmt.Errorf("duplicate key id %q", k.id)
+		}
+		keys[k.id] = k
+	}
+	return &JWTGenerator{signingKey: signingKey, verifyKeys: keys}, nil
+}
+
+// JWKS returns the public verification keys. HMAC keys are never included.
+func (maker *JWTGenerator) JWKS() JWKSet {
+	set := JWKSet{Keys: []JWK{}}
+	for _, k := range maker.verifyKeys {
+		if jwk, ok := k.jwk(); ok {
+			set.Keys = append(set.Keys, jwk)
+		}
+	}
+	sort.Slice(set.Keys, func(i, j int) bool { return set.Keys[i].Kid < set.Keys[j].Kid })
+	return set
+}
+
+// AddValidator registers a validator that the auth middleware runs for every
+// authenticated request.
+func (maker *JWTGenerator) AddValidator(v TokenValidator) {
+	maker.validators = append(maker.validators, v)
+}
+
+// SetAPIKeyAuthenticator lets routes that accept APIKeyTokenType authenticate
+// requests with an API key.
+func (maker *JWTGenerator) SetAPIKeyAuthenticator(a APIKeyAuthenticator) {
+	maker.apiKeys = a
+}
+
+func (maker *JWTGenerator) ValidateClaims(ctx context.Context, claims *UserClaims) error {
+	for _, v := range maker.validators {
+		if err := v.ValidateToken(ctx, claims); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // UserClaims represents the custom claims for the JWT token
 // @Description JWT token claims containing user information
 type UserClaims struct {
-	ID                   int64            `json:"id" example:"1"`
-	Email                string           `json:"email" example:"john@example.com"`
-	Role                 string           `json:"role" example:"CUSTOMER"`
+	ID            int64  `json:"id" example:"1"`
+	Email         string `json:"email" example:"john@example.com"`
+	Role          string `json:"role" example:"CUSTOMER"`
+	EmailVerified bool   `json:"email_verified" example:"true"`
+	MFA           bool   `json:"mfa,omitempty" example:"false"`
+	SessionID     string `json:"sid,omitempty" example:"6f1c2a57-0b8e-4a53-9d3e-2f4f7c1b8e11"`
+	// SellerStatus is set for sellers, who only hold the permissions of
+	// their role once approved.
+	SellerStatus string `json:"seller_status,omitempty" example:"APPROVED"`
+	// Scopes limits the permissions of requests made with an API key.
+	Scopes               []string         `json:"scopes,omitempty" example:"product:create"`
+	TokenType            string           `json:"token_type,omitempty" example:"access"`
 	TokenID              string           `json:"jti,omitempty" example:"123e4567-e89b-12d3-a456-426614174000"`
 	Subject              string           `json:"sub,omitempty" example:"john@example.com"`
 	IssuedAt             *jwt.NumericDate `json:"iat,omitempty" swaggertype:"primitive,integer" example:"1618317375"`
@@ -30,33 +138,70 @@ type UserClaims struct {
 	jwt.RegisteredClaims `json:"-"`
 }
 
-func NewUserClaims(id int64, email, role string, duration time.Duration) (*UserClaims, error) {
+func NewUserClaims(sub TokenSubject, tokenType string, duration time.Duration) (*UserClaims, error) {
 	tokenID, err := uuid.NewRandom()
 	if err != nil {
 		return nil, fmt.Errorf("error generating token ID: %w", err)
 	}
 
+	now := time.Now()
 	return &UserClaims{
-		Email: email,
-		ID:    id,
-		Role:  role,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ID:        tokenID.String(),
-			Subject:   email,
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(duration)),
-		},
+		Email:         sub.Email,
+		ID:            sub.ID,
+		Role:          sub.Role,
+		EmailVerified: sub.EmailVerified,
+		MFA:           sub.MFA,
+		SessionID:     sub.SessionID,
+		SellerStatus:  sub.SellerStatus,
+		TokenType:     tokenType,
+		TokenID:       tokenID.String(),
+		Subject:       sub.Email,
+		IssuedAt:      jwt.NewNumericDate(now),
+		ExpiresAt:     jwt.NewNumericDate(now.Add(duration)),
 	}, nil
 }
 
-func (maker *JWTGenerator) CreateToken(id int64, email, role string, duration time.Duration) (string, *UserClaims, error) {
-	claims, err := NewUserClaims(id, email, role, duration)
+// SellerNotApproved reports whether the user is a seller pending approval
+// or rejected.
+func (c *UserClaims) SellerNotApproved() bool {
+	return c.SellerStatus != "" && c.SellerStatus != SellerStatusApproved
+}
+
+// GetExpirationTime, GetIssuedAt and GetSubject read the serialized claim
+// fields, since the embedded RegisteredClaims is never written to the token.
+func (c *UserClaims) GetExpirationTime() (*jwt.NumericDate, error) {
+	return c.ExpiresAt, nil
+}
+
+func (c *UserClaims) GetIssuedAt() (*jwt.NumericDate, error) {
+	return c.IssuedAt, nil
+}
+
+func (c *UserClaims) GetSubject() (string, error) {
+	return c.Subject, nil
+}
+
+func (maker *JWTGenerator) CreateToken(sub TokenSubject, duration time.Duration) (string, *UserClaims, error) {
+	return maker.CreateTokenOfType(sub, AccessTokenType, duration)
+}
+
+func (maker *JWTGenerator) CreateRefreshToken(sub TokenSubject, duration time.Duration) (string, *UserClaims, error) {
+	return maker.CreateTokenOfType(sub, RefreshTokenType, duration)
+}
+
+// CreateTokenOfType issues a token of any type, such as an MFA challenge.
+// Only access tokens pass the auth middleware.
+func (maker *JWTGenerator) CreateTokenOfType(sub TokenSubject, tokenType string, duration time.Duration) (string, *UserClaims, error) {
+	claims, err := NewUserClaims(sub, tokenType, duration)
 	if err != nil {
 		return "", nil, err
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenStr, err := token.SignedString([]byte(maker.secretKey))
+	token := jwt.NewWithClaims(maker.signingKey.method, claims)
+	if maker.signingKey.id != "" {
+		token.Header["kid"] = maker.signingKey.id
+	}
+	tokenStr, err := token.SignedString(maker.signingKey.signKey)
 	if err != nil {
 		return "", nil, fmt.Errorf("error signing token: %w", err)
 	}
@@ -66,14 +211,20 @@ func (maker *JWTGenerator) CreateToken(id int64, email, role string, duration ti
 
 func (maker *JWTGenerator) VerifyToken(tokenStr string) (*UserClaims, error) {
 	token, err := jwt.ParseWithClaims(tokenStr, &UserClaims{}, func(token *jwt.Token) (interface{}, error) {
-		// verify the signing method
-		_, ok := token.Method.(*jwt.SigningMethodHMAC)
+		kid, _ := token.Header["kid"].(string)
+		key, ok := maker.verifyKeys[kid]
 		if !ok {
+			return nil, fmt.Errorf("unknown signing key %q", kid)
+		}
+
+		// verify the signing method matches the key, so a public key can
+		// never be used as an HMAC secret
+		if token.Method.Alg() != key.method.Alg() {
 			return nil, fmt.Errorf("invalid token signing method")
 		}
 
-		return []byte(maker.secretKey), nil
-	})
+		return key.verifyKey, nil
+	}, jwt.WithExpirationRequired())
 	if err != nil {
 		return nil, fmt.Errorf("error parsing token: %w", err)
 	}
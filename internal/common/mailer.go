@@ -0,0 +1,114 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net/mail"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Mail is a plain text email message.
+type Mail struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+type Mailer interface {
+	Send(ctx context.Context, mail Mail) error
+}
+
+// SMTPMailer delivers mail through an SMTP relay.
+type SMTPMailer struct {
+	addr     string
+	from     string
+	envelope string
+	auth     smtp.Auth
+}
+
+func NewSMTPMailer(host string, port int, username, password, from string) *SMTPMailer {
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+	envelope := from
+	if addr, err := mail.ParseAddress(from); err == nil {
+		envelope = addr.Address
+	}
+	return &SMTPMailer{
+		addr:     fmt.Sprintf("%s:%d", host, port),
+		from:     from,
+		envelope: envelope,
+		auth:     auth,
+	}
+}
+
+func (m *SMTPMailer) Send(ctx context.Context, msg Mail) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	body := formatMail(m.from, msg)
+	if err := smtp.SendMail(m.addr, m.auth, m.envelope, []string{msg.To}, []byte(body)); err != nil {
+		return fmt.Errorf("error sending mail: %w", err)
+	}
+	return nil
+}
+
+// LogMailer writes mail to the logger and, when dir is set, to .eml files in
+// dir. It is meant for local development and tests.
+type LogMailer struct {
+	from   string
+	dir    string
+	logger *zap.Logger
+}
+
+func NewLogMailer(from, dir string, logger *zap.Logger) (*LogMailer, error) {
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create mail directory: %w", err)
+		}
+	}
+	return &LogMailer{
+		from:   from,
+		dir:    dir,
+		logger: logger,
+	}, nil
+}
+
+func (m *LogMailer) Send(ctx context.Context, msg Mail) error {
+	// the body holds single-use links, so it is only logged at debug level
+	m.logger.Info("Sending mail",
+		zap.String("to", msg.To),
+		zap.String("subject", msg.Subject))
+	m.logger.Debug("Mail body", zap.String("to", msg.To), zap.String("body", msg.Body))
+
+	if m.dir == "" {
+		return nil
+	}
+
+	name := fmt.Sprintf("%d-%s.eml", time.Now().UnixNano(), strings.NewReplacer("@", "_at_", "/", "_").Replace(msg.To))
+	if err := os.WriteFile(filepath.Join(m.dir, name), []byte(formatMail(m.from, msg)), 0644); err != nil {
+		return fmt.Errorf("error writing mail file: %w", err)
+	}
+	return nil
+}
+
+func formatMail(from string, msg Mail) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(msg.Body)
+	return b.String()
+}
@@ -4,15 +4,33 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"slices"
 	"strings"
 )
 
 type AuthKey struct{}
 
+// APIKeyHeader carries the API key of server-to-server requests.
+const APIKeyHeader = "X-API-Key"
+
 func GetAuthMiddlewareFunc(generator *JWTGenerator) func(http.Handler) http.Handler {
+	return GetTokenTypeMiddlewareFunc(generator, AccessTokenType)
+}
+
+// GetAPIKeyAuthMiddlewareFunc authenticates requests with an access token or
+// an API key. Routes using it should require a permission, since that is what
+// API key scopes are checked against.
+func GetAPIKeyAuthMiddlewareFunc(generator *JWTGenerator) func(http.Handler) http.Handler {
+	return GetTokenTypeMiddlewareFunc(generator, AccessTokenType, APIKeyTokenType)
+}
+
+// GetTokenTypeMiddlewareFunc authenticates requests with any of the given
+// token types, for routes that also accept limited tokens such as
+// MFAEnrollmentTokenType.
+func GetTokenTypeMiddlewareFunc(generator *JWTGenerator, tokenTypes ...string) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			claims, err := verifyClaimsFromAuthHeader(r, generator)
+			claims, err := verifyClaimsFromAuthHeader(r, generator, tokenTypes...)
 			if err != nil {
 				handleError(w, err)
 				return
@@ -23,27 +41,30 @@ func GetAuthMiddlewareFunc(generator *JWTGenerator) func(http.Handler) http.Hand
 	}
 }
 
-func GetAdminMiddlewareFun(generator *JWTGenerator) func(http.Handler) http.Handler {
-	return func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+// RequireVerifiedEmail rejects users whose email is not verified. It must run
+// after the auth middleware and is meant for actions such as checkout, while
+// unverified users can still browse.
+func RequireVerifiedEmail(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, err := GetLoggedInUser(r)
+		if err != nil {
+			handleError(w, NewErrorf(AuthHeaderMissingErrorCode, "authorization header is missing"))
+			return
+		}
 
-			claims, err := verifyClaimsFromAuthHeader(r, generator)
-			if err != nil {
-				handleError(w, err)
-				return
-			}
+		if !claims.EmailVerified {
+			handleError(w, NewErrorf(EmailNotVerifiedErrorCode, "email address is not verified"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
 
-			if claims.Role != "ADMIN" {
-				handleError(w, NewErrorf(InvalidUserRoleErrorCode, "user is not an admin"))
-				return
-			}
-			ctx := context.WithValue(r.Context(), AuthKey{}, claims)
-			next.ServeHTTP(w, r.WithContext(ctx))
-		})
+func verifyClaimsFromAuthHeader(r *http.Request, generator *JWTGenerator, tokenTypes ...string) (*UserClaims, error) {
+	if key := r.Header.Get(APIKeyHeader); key != "" {
+		return verifyAPIKey(r, generator, key, tokenTypes...)
 	}
-}
 
-func verifyClaimsFromAuthHeader(r *http.Request, generator *JWTGenerator) (*UserClaims, error) {
 	authHeader := r.Header.Get("Authorization")
 	if authHeader == "" {
 		return nil, NewErrorf(AuthHeaderMissingErrorCode, "authorization header is missing")
@@ -55,7 +76,36 @@ func verifyClaimsFromAuthHeader(r *http.Request, generator *JWTGenerator) (*User
 	}
 
 	claims, err := generator.VerifyToken(token)
+	if err != nil || !slices.Contains(tokenTypes, claims.TokenType) {
+		return nil, NewErrorf(AuthHeaderTokenVerificationFailed, "authorization token is invalid")
+	}
+
+	return validateClaims(r, generator, claims)
+}
+
+func verifyAPIKey(r *http.Request, generator *JWTGenerator, key string, tokenTypes ...string) (*UserClaims, error) {
+	if generator.apiKeys == nil || !slices.Contains(tokenTypes, APIKeyTokenType) {
+		return nil, NewErrorf(AuthHeaderTokenVerificationFailed, "API keys are not accepted for this route")
+	}
+
+	claims, err := generator.apiKeys.AuthenticateAPIKey(r.Context(), key)
 	if err != nil {
+		var appErr *Error
+		if errors.As(err, &appErr) {
+			return nil, err
+		}
+		return nil, NewErrorf(AuthHeaderTokenVerificationFailed, "API key is invalid")
+	}
+
+	return validateClaims(r, generator, claims)
+}
+
+func validateClaims(r *http.Request, generator *JWTGenerator, claims *UserClaims) (*UserClaims, error) {
+	if err := generator.ValidateClaims(r.Context(), claims); err != nil {
+		var appErr *Error
+		if errors.As(err, &appErr) {
+			return nil, err
+		}
 		return nil, NewErrorf(AuthHeaderTokenVerificationFailed, "authorization token is invalid")
 	}
 
@@ -80,8 +130,16 @@ func handleError(w http.ResponseWriter, err error) {
 			SendError(w, http.StatusUnauthorized, string(appErr.Code()), appErr.Error())
 		case AuthHeaderTokenVerificationFailed:
 			SendError(w, http.StatusUnauthorized, string(appErr.Code()), appErr.Error())
+		case AuthHeaderTokenRevokedErrorCode:
+			SendError(w, http.StatusUnauthorized, string(appErr.Code()), appErr.Error())
 		case InvalidUserRoleErrorCode:
 			SendError(w, http.StatusForbidden, string(appErr.Code()), appErr.Error())
+		case PermissionDeniedErrorCode:
+			SendError(w, http.StatusForbidden, string(appErr.Code()), appErr.Error())
+		case EmailNotVerifiedErrorCode:
+			SendError(w, http.StatusForbidden, string(appErr.Code()), appErr.Error())
+		case MFARequiredErrorCode:
+			SendError(w, http.StatusUnauthorized, string(appErr.Code()), appErr.Error())
 		default:
 			SendError(w, http.StatusInternalServerError, "internal-server-error", appErr.Error())
 		}
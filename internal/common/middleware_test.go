@@ -0,0 +1,61 @@
+package common
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type staticAPIKeys map[string]*UserClaims
+
+func (k staticAPIKeys) AuthenticateAPIKey(ctx context.Context, key string) (*UserClaims, error) {
+	claims, ok := k[key]
+	if !ok {
+		return nil, NewErrorf(AuthHeaderTokenVerificationFailed, "API key is invalid")
+	}
+	return claims, nil
+}
+
+func TestAPIKeyAuthentication(t *testing.T) {
+	generator := NewJWTGenerator("test-secret")
+	generator.SetAPIKeyAuthenticator(staticAPIKeys{
+		"ydw_products": {ID: 5, Role: "SELLER", TokenType: APIKeyTokenType, Scopes: []string{"product:create"}},
+		"ydw_readonly": {ID: 5, Role: "SELLER", TokenType: APIKeyTokenType, Scopes: []string{}},
+	})
+	policy := NewPermissionPolicy(map[string][]string{"SELLER": {"product:create", "api_key:manage"}})
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	access, _, err := generator.CreateToken(TokenSubject{ID: 5, Role: "SELLER"}, time.Minute)
+	if err != nil {
+		t.Fatalf("CreateToken() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		handler http.Handler
+		header  string
+		value   string
+		want    int
+	}{
+		{"scoped key", GetAPIKeyAuthMiddlewareFunc(generator)(RequirePermission(policy, PermissionProductCreate)(ok)), APIKeyHeader, "ydw_products", http.StatusNoContent},
+		{"key without the scope", GetAPIKeyAuthMiddlewareFunc(generator)(RequirePermission(policy, PermissionProductCreate)(ok)), APIKeyHeader, "ydw_readonly", http.StatusForbidden},
+		{"unknown key", GetAPIKeyAuthMiddlewareFunc(generator)(ok), APIKeyHeader, "ydw_unknown", http.StatusUnauthorized},
+		{"access token", GetAPIKeyAuthMiddlewareFunc(generator)(RequirePermission(policy, PermissionProductCreate)(ok)), "Authorization", "Bearer " + access, http.StatusNoContent},
+		{"key on a token only route", GetAuthMiddlewareFunc(generator)(ok), APIKeyHeader, "ydw_products", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/products", nil)
+			r.Header.Set(tt.header, tt.value)
+			w := httptest.NewRecorder()
+			tt.handler.ServeHTTP(w, r)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
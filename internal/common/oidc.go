@@ -0,0 +1,261 @@
+package common
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// jwksRefreshInterval limits how often an unknown kid triggers a JWKS fetch.
+const jwksRefreshInterval = time.Minute
+
+// OIDCProviderConfig describes an OpenID Connect provider and the client
+// registered with it. The endpoints are configured explicitly so that tests
+// can point them at a local server.
+type OIDCProviderConfig struct {
+	Name                  string
+	ClientID              string
+	ClientSecret          string
+	Issuer                string
+	AuthorizationEndpoint string
+	TokenEndpoint         string
+	JWKSURI               string
+	RedirectURL           string
+	Scopes                []string
+	// ResponseMode is sent as response_mode when set, e.g. "form_post" which
+	// Apple requires when asking for the email scope.
+	ResponseMode string
+}
+
+// OIDCIdentity is the user described by a verified ID token.
+type OIDCIdentity struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// OIDCProvider runs the authorization code flow with PKCE against a provider
+// and verifies the ID tokens it returns against the provider JWKS.
+type OIDCProvider struct {
+	cfg    OIDCProviderConfig
+	client *http.Client
+
+	mu            sync.Mutex
+	keys          map[string]*JWTKey
+	keysFetchedAt time.Time
+}
+
+func NewOIDCProvider(cfg OIDCProviderConfig, client *http.Client) *OIDCProvider {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &OIDCProvider{cfg: cfg, client: client, keys: map[string]*JWTKey{}}
+}
+
+func (p *OIDCProvider) Name() string {
+	return p.cfg.Name
+}
+
+// AuthCodeURL returns the provider page that asks the user to sign in.
+func (p *OIDCProvider) AuthCodeURL(state, nonce, codeChallenge string) string {
+	scopes := p.cfg.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{"openid", "email", "profile"}
+	}
+
+	q := url.Values{}
+	q.Set("response_type", "code")
+	q.Set("client_id", p.cfg.ClientID)
+	q.Set("redirect_uri", p.cfg.RedirectURL)
+	q.Set("scope", strings.Join(scopes, " "))
+	q.Set("state", state)
+	q.Set("nonce", nonce)
+	q.Set("code_challenge", codeChallenge)
+	q.Set("code_challenge_method", "S256")
+	if p.cfg.ResponseMode != "" {
+		q.Set("response_mode", p.cfg.ResponseMode)
+	}
+
+	sep := "?"
+	if strings.Contains(p.cfg.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+	return p.cfg.AuthorizationEndpoint + sep + q.Encode()
+}
+
+// Exchange redeems an authorization code and returns the identity from the
+// verified ID token. nonce must match the nonce sent with AuthCodeURL.
+func (p *OIDCProvider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*OIDCIdentity, error) {
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.cfg.RedirectURL)
+	form.Set("client_id", p.cfg.ClientID)
+	form.Set("client_secret", p.cfg.ClientSecret)
+	form.Set("code_verifier", codeVerifier)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.cfg.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("error building token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error calling token endpoint: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&body); err != nil {
+		return nil, fmt.Errorf("error decoding token response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK || body.Error != "" {
+		return nil, fmt.Errorf("token endpoint returned %d: %s %s", resp.StatusCode, body.Error, body.ErrorDescription)
+	}
+	if body.IDToken == "" {
+		return nil, fmt.Errorf("token response has no id_token")
+	}
+
+	return p.verifyIDToken(ctx, body.IDToken, nonce)
+}
+
+// oidcClaims are the ID token claims used to identify the user.
+type oidcClaims struct {
+	Nonce         string   `json:"nonce"`
+	Email         string   `json:"email"`
+	EmailVerified flexBool `json:"email_verified"`
+	Name          string   `json:"name"`
+	jwt.RegisteredClaims
+}
+
+// flexBool reads booleans sent as JSON booleans or strings, as Apple sends
+// email_verified as "true".
+type flexBool bool
+
+func (b *flexBool) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `"`)
+	*b = flexBool(s == "true")
+	return nil
+}
+
+func (p *OIDCProvider) verifyIDToken(ctx context.Context, idToken, nonce string) (*OIDCIdentity, error) {
+	claims := &oidcClaims{}
+	_, err := jwt.ParseWithClaims(idToken, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, err := p.key(ctx, kid)
+		if err != nil {
+			return nil, err
+		}
+		if token.Method.Alg() != key.method.Alg() {
+			return nil, fmt.Errorf("invalid token signing method")
+		}
+		return key.verifyKey, nil
+	},
+		jwt.WithIssuer(p.cfg.Issuer),
+		jwt.WithAudience(p.cfg.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(time.Minute),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ID token: %w", err)
+	}
+
+	if nonce == "" || claims.Nonce != nonce {
+		return nil, fmt.Errorf("invalid ID token: nonce mismatch")
+	}
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("invalid ID token: missing subject")
+	}
+
+	return &OIDCIdentity{
+		Subject:       claims.Subject,
+		Email:         strings.ToLower(claims.Email),
+		EmailVerified: bool(claims.EmailVerified),
+		Name:          claims.Name,
+	}, nil
+}
+
+// key returns the provider key with the kid, fetching the JWKS again when the
+// kid is unknown so that provider key rotation is picked up.
+func (p *OIDCProvider) key(ctx context.Context, kid string) (*JWTKey, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if key, ok := p.keys[kid]; ok {
+		return key, nil
+	}
+	if time.Since(p.keysFetchedAt) < jwksRefreshInterval {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	keys, err := p.fetchKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	p.keys = keys
+	p.keysFetchedAt = time.Now()
+
+	key, ok := p.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	return key, nil
+}
+
+func (p *OIDCProvider) fetchKeys(ctx context.Context) (map[string]*JWTKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.cfg.JWKSURI, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error building JWKS request: %w", err)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("JWKS endpoint returned %d", resp.StatusCode)
+	}
+
+	var set JWKSet
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&set); err != nil {
+		return nil, fmt.Errorf("error decoding JWKS: %w", err)
+	}
+
+	keys := make(map[string]*JWTKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		// skip key types we cannot verify with, providers publish several
+		key, err := ParseJWK(k)
+		if err != nil {
+			continue
+		}
+		keys[k.Kid] = key
+	}
+	return keys, nil
+}
+
+// PKCEChallenge returns the S256 code challenge of a PKCE code verifier.
+func PKCEChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
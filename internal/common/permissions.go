@@ -0,0 +1,179 @@
+package common
+
+import (
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Permission is an action a role may perform, written as resource:action.
+type Permission string
+
+const (
+	// PermissionAll grants every permission.
+	PermissionAll Permission = "*"
+
+	PermissionProductCreate Permission = "product:create"
+	// PermissionProductManage allows changing products of any seller, sellers
+	// can always change their own.
+	PermissionProductManage  Permission = "product:manage"
+	PermissionCategoryManage Permission = "category:manage"
+	PermissionBannerManage   Permission = "banner:manage"
+	PermissionOrderRefund    Permission = "order:refund"
+	PermissionUserRead       Permission = "user:read"
+	PermissionUserManage     Permission = "user:manage"
+	// PermissionRoleAssign allows creating accounts with, and moving users to,
+	// any role. It is kept apart from PermissionUserManage since it lets the
+	// holder grant permissions they do not have.
+	PermissionRoleAssign   Permission = "role:assign"
+	PermissionSellerReview Permission = "seller:review"
+	PermissionAPIKeyManage Permission = "api_key:manage"
+	// PermissionShopCreate allows opening a shop and managing one's own shop.
+	PermissionShopCreate Permission = "shop:create"
+	// PermissionShopReview allows approving and suspending any shop.
+	PermissionShopReview Permission = "shop:review"
+)
+
+// definedPermissions are the permissions checked somewhere in the application.
+var definedPermissions = []Permission{
+	PermissionProductCreate, PermissionProductManage, PermissionCategoryManage, PermissionBannerManage,
+	PermissionOrderRefund, PermissionUserRead, PermissionUserManage, PermissionRoleAssign,
+	PermissionSellerReview, PermissionAPIKeyManage, PermissionShopCreate, PermissionShopReview,
+}
+
+// Defined reports whether p is a permission the application checks.
+// PermissionAll is not one.
+func (p Permission) Defined() bool {
+	return slices.Contains(definedPermissions, p)
+}
+
+// SellerStatusApproved is the status of sellers allowed to sell.
+const SellerStatusApproved = "APPROVED"
+
+// PermissionPolicy maps roles to their permissions. The mapping can be
+// replaced while serving, so roles and permissions stored in the database
+// apply without a restart.
+type PermissionPolicy struct {
+	mu    sync.RWMutex
+	roles map[string]map[Permission]struct{}
+}
+
+// NewPermissionPolicy returns a policy for the given role to permissions
+// mapping. Role names are upper cased, since config keys are read lower case.
+func NewPermissionPolicy(roles map[string][]string) *PermissionPolicy {
+	p := &PermissionPolicy{}
+	p.Replace(roles)
+	return p
+}
+
+// Replace swaps the whole mapping.
+func (p *PermissionPolicy) Replace(roles map[string][]string) {
+	mapped := make(map[string]map[Permission]struct{}, len(roles))
+	for role, perms := range roles {
+		set := make(map[Permission]struct{}, len(perms))
+		for _, perm := range perms {
+			set[Permission(strings.TrimSpace(perm))] = struct{}{}
+		}
+		mapped[strings.ToUpper(role)] = set
+	}
+
+	p.mu.Lock()
+	p.roles = mapped
+	p.mu.Unlock()
+}
+
+// HasRole reports whether the role is defined, even without permissions.
+func (p *PermissionPolicy) HasRole(role string) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	_, ok := p.roles[role]
+	return ok
+}
+
+// Roles returns the defined roles sorted by name.
+func (p *PermissionPolicy) Roles() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	roles := make([]string, 0, len(p.roles))
+	for role := range p.roles {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+	return roles
+}
+
+// HasPermission reports whether the role is granted every given permission.
+func (p *PermissionPolicy) HasPermission(role string, perms ...Permission) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	granted, ok := p.roles[role]
+	if !ok {
+		return false
+	}
+	if _, all := granted[PermissionAll]; all {
+		return true
+	}
+	for _, perm := range perms {
+		if _, ok := granted[perm]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// Authorize checks that the user holds every given permission. Sellers
+// pending approval or rejected hold none of the permissions of their role, and
+// API keys only hold the permissions of their role that are in their scopes.
+func (p *PermissionPolicy) Authorize(claims *UserClaims, perms ...Permission) error {
+	if claims == nil {
+		return NewErrorf(AuthHeaderMissingErrorCode, "authorization header is missing")
+	}
+	if claims.SellerNotApproved() {
+		return NewErrorf(PermissionDeniedErrorCode, "seller account is not approved")
+	}
+	if !p.HasPermission(claims.Role, perms...) {
+		return NewErrorf(PermissionDeniedErrorCode, "permission denied")
+	}
+	if claims.TokenType == APIKeyTokenType {
+		for _, perm := range perms {
+			if !slices.Contains(claims.Scopes, string(perm)) {
+				return NewErrorf(PermissionDeniedErrorCode, "API key is missing the %s scope", perm)
+			}
+		}
+	}
+	return nil
+}
+
+// RequirePermission rejects users whose role lacks any of the permissions,
+// and API keys missing any of them in their scopes. It must run after the
+// auth middleware.
+func RequirePermission(policy *PermissionPolicy, perms ...Permission) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, err := GetLoggedInUser(r)
+			if err != nil {
+				handleError(w, NewErrorf(AuthHeaderMissingErrorCode, "authorization header is missing"))
+				return
+			}
+
+			if err := policy.Authorize(claims, perms...); err != nil {
+				handleError(w, err)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// CheckOwnership allows access to a resource owned by ownerID, such as a
+// seller's product, when the user owns it or holds the manageAny permission.
+// Sellers pending approval or rejected cannot change what they own, and API
+// keys need the manageAny scope to change what others own.
+func CheckOwnership(policy *PermissionPolicy, claims *UserClaims, ownerID int64, manageAny Permission) error {
+	if claims != nil && claims.ID == ownerID && !claims.SellerNotApproved() {
+		return nil
+	}
+	return policy.Authorize(claims, manageAny)
+}
@@ -0,0 +1,146 @@
+package common
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func testPolicy() *PermissionPolicy {
+	// keys are lower case, as read from the config
+	return NewPermissionPolicy(map[string][]string{
+		"admin":    {"*"},
+		"seller":   {"product:create"},
+		"support":  {"user:read", "order:refund"},
+		"customer": nil,
+	})
+}
+
+func TestPermissionPolicy(t *testing.T) {
+	policy := testPolicy()
+
+	tests := []struct {
+		role  string
+		perms []Permission
+		want  bool
+	}{
+		{"ADMIN", []Permission{PermissionBannerManage, PermissionRoleAssign}, true},
+		{"SELLER", []Permission{PermissionProductCreate}, true},
+		{"SELLER", []Permission{PermissionProductManage}, false},
+		{"SUPPORT", []Permission{PermissionUserRead, PermissionOrderRefund}, true},
+		{"SUPPORT", []Permission{PermissionUserRead, PermissionUserManage}, false},
+		{"CUSTOMER", []Permission{PermissionProductCreate}, false},
+		{"OWNER", nil, false},
+	}
+	for _, tt := range tests {
+		if got := policy.HasPermission(tt.role, tt.perms...); got != tt.want {
+			t.Errorf("HasPermission(%s, %v) = %v, want %v", tt.role, tt.perms, got, tt.want)
+		}
+	}
+
+	if !policy.HasRole("CUSTOMER") || policy.HasRole("OWNER") {
+		t.Errorf("HasRole() should report CUSTOMER only, roles = %v", policy.Roles())
+	}
+
+	policy.Replace(map[string][]string{"SELLER": {"product:create", "product:manage"}})
+	if !policy.HasPermission("SELLER", PermissionProductManage) || policy.HasRole("ADMIN") {
+		t.Errorf("Replace() did not swap the mapping, roles = %v", policy.Roles())
+	}
+}
+
+func TestRequirePermission(t *testing.T) {
+	handler := RequirePermission(testPolicy(), PermissionBannerManage)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	tests := []struct {
+		name   string
+		claims *UserClaims
+		want   int
+	}{
+		{"no user", nil, http.StatusUnauthorized},
+		{"missing permission", &UserClaims{ID: 1, Role: "SELLER"}, http.StatusForbidden},
+		{"granted", &UserClaims{ID: 2, Role: "ADMIN"}, http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/banners", nil)
+			if tt.claims != nil {
+				r = r.WithContext(context.WithValue(r.Context(), AuthKey{}, tt.claims))
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequirePermission_Seller(t *testing.T) {
+	handler := RequirePermission(testPolicy(), PermissionProductCreate)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	tests := []struct {
+		status string
+		want   int
+	}{
+		{"PENDING", http.StatusForbidden},
+		{"REJECTED", http.StatusForbidden},
+		{SellerStatusApproved, http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			claims := &UserClaims{ID: 1, Role: "SELLER", SellerStatus: tt.status}
+			r := httptest.NewRequest(http.MethodPost, "/products", nil)
+			r = r.WithContext(context.WithValue(r.Context(), AuthKey{}, claims))
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckOwnership(t *testing.T) {
+	policy := testPolicy()
+
+	if err := CheckOwnership(policy, &UserClaims{ID: 7, Role: "SELLER"}, 7, PermissionProductManage); err != nil {
+		t.Errorf("CheckOwnership() owner error = %v", err)
+	}
+	if err := CheckOwnership(policy, &UserClaims{ID: 1, Role: "ADMIN"}, 7, PermissionProductManage); err != nil {
+		t.Errorf("CheckOwnership() admin error = %v", err)
+	}
+
+	err := CheckOwnership(policy, &UserClaims{ID: 8, Role: "SELLER"}, 7, PermissionProductManage)
+	if appErr, ok := err.(*Error); !ok || appErr.Code() != PermissionDeniedErrorCode {
+		t.Errorf("CheckOwnership() other seller error = %v, want %v", err, PermissionDeniedErrorCode)
+	}
+
+	err = CheckOwnership(policy, &UserClaims{ID: 7, Role: "SELLER", SellerStatus: "REJECTED"}, 7, PermissionProductManage)
+	if appErr, ok := err.(*Error); !ok || appErr.Code() != PermissionDeniedErrorCode {
+		t.Errorf("CheckOwnership() rejected owner error = %v, want %v", err, PermissionDeniedErrorCode)
+	}
+}
+
+func TestCheckOwnership_APIKey(t *testing.T) {
+	policy := testPolicy()
+	adminKey := func(scopes ...string) *UserClaims {
+		return &UserClaims{ID: 1, Role: "ADMIN", TokenType: APIKeyTokenType, Scopes: scopes}
+	}
+
+	err := CheckOwnership(policy, adminKey(string(PermissionProductCreate)), 7, PermissionProductManage)
+	if appErr, ok := err.(*Error); !ok || appErr.Code() != PermissionDeniedErrorCode {
+		t.Errorf("CheckOwnership() key without the scope error = %v, want %v", err, PermissionDeniedErrorCode)
+	}
+	if err := CheckOwnership(policy, adminKey(string(PermissionProductManage)), 7, PermissionProductManage); err != nil {
+		t.Errorf("CheckOwnership() key with the scope error = %v", err)
+	}
+	sellerKey := &UserClaims{ID: 7, Role: "SELLER", TokenType: APIKeyTokenType, Scopes: []string{string(PermissionProductCreate)}}
+	if err := CheckOwnership(policy, sellerKey, 7, PermissionProductManage); err != nil {
+		t.Errorf("CheckOwnership() owner key error = %v", err)
+	}
+}
@@ -0,0 +1,27 @@
+package common
+
+import "context"
+
+// PersonalDataSection is the part of a personal data export held by one
+// module, such as the cart of the user.
+type PersonalDataSection struct {
+	// Name is the key of the section in the export.
+	Name string
+	Data any
+	// Files are names of uploaded files in the file storage that belong to
+	// the user and are added to the export archive.
+	Files []string
+}
+
+// PersonalDataSource lets a module contribute to the personal data export of
+// a user. A nil section means the module holds nothing about the user.
+type PersonalDataSource interface {
+	ExportPersonalData(ctx context.Context, userID int64) (*PersonalDataSection, error)
+}
+
+// PersonalDataEraser lets a module remove what it holds about a user whose
+// account is erased. Records that must be kept, such as payments, are left in
+// place since they only reference the anonymized user.
+type PersonalDataEraser interface {
+	ErasePersonalData(ctx context.Context, userID int64) error
+}
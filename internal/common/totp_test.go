@@ -0,0 +1,48 @@
+package common
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTOTPCode(t *testing.T) {
+	// RFC 6238 appendix B SHA1 vectors, truncated to 6 digits.
+	secret := totpEncoding.EncodeToString([]byte("12345678901234567890"))
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{unix: 59, want: "287082"},
+		{unix: 1111111109, want: "081804"},
+		{unix: 1234567890, want: "005924"},
+		{unix: 20000000000, want: "353130"},
+	}
+	for _, tt := range tests {
+		got, err := TOTPCode(secret, TOTPStep(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatalf("TOTPCode() error = %v", err)
+		}
+		if got != tt.want {
+			t.Errorf("TOTPCode(%d) = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatalf("GenerateTOTPSecret() error = %v", err)
+	}
+	now := time.Now()
+	step := TOTPStep(now)
+
+	previous, _ := TOTPCode(secret, step-1)
+	if got, ok := ValidateTOTP(secret, previous, now); !ok || got != step-1 {
+		t.Errorf("ValidateTOTP() = %d, %v, want %d, true", got, ok, step-1)
+	}
+
+	stale, _ := TOTPCode(secret, step-3)
+	if _, ok := ValidateTOTP(secret, stale, now); ok {
+		t.Errorf("ValidateTOTP() accepted a code outside the skew window")
+	}
+}
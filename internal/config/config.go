@@ -1,6 +1,8 @@
 package config
 
 import (
+	"time"
+
 	"github.com/spf13/viper"
 
 	"yadwy-backend/internal/database"
@@ -10,6 +12,11 @@ type Config struct {
 	Server   ServerConfig
 	Database database.Config
 	JWT      JWT
+	Auth     Auth
+	Mail     Mail
+	// Authorization maps roles to permissions.
+	Authorization Authorization
+	Privacy       Privacy
 }
 
 type ServerConfig struct {
@@ -18,7 +25,113 @@ type ServerConfig struct {
 }
 
 type JWT struct {
-	Secret string
+	// Algorithm selects how tokens are signed: "HS256" with Secret, or
+	// "RS256"/"EdDSA" with the PEM key in PrivateKeyFile.
+	Algorithm      string
+	Secret         string
+	KeyID          string `mapstructure:"key_id"`
+	PrivateKeyFile string `mapstructure:"private_key_file"`
+	// VerificationKeys are public keys of rotated out signing keys that still
+	// verify tokens until those expire.
+	VerificationKeys []JWTVerificationKey `mapstructure:"verification_keys"`
+	AccessTokenTTL   time.Duration        `mapstructure:"access_token_ttl"`
+	RefreshTokenTTL  time.Duration        `mapstructure:"refresh_token_ttl"`
+}
+
+type JWTVerificationKey struct {
+	ID            string
+	PublicKeyFile string `mapstructure:"public_key_file"`
+}
+
+type Auth struct {
+	// RevocationStore selects the token denylist backend: "postgres" or "memory".
+	RevocationStore           string        `mapstructure:"revocation_store"`
+	RevocationCleanupInterval time.Duration `mapstructure:"revocation_cleanup_interval"`
+	PasswordResetTTL          time.Duration `mapstructure:"password_reset_ttl"`
+	PasswordResetURL          string        `mapstructure:"password_reset_url"`
+	EmailVerificationTTL      time.Duration `mapstructure:"email_verification_ttl"`
+	EmailVerificationURL      string        `mapstructure:"email_verification_url"`
+	// VerificationResendInterval is the minimum time between two verification
+	// or password reset emails to the same user.
+	VerificationResendInterval time.Duration `mapstructure:"verification_resend_interval"`
+	Login                      LoginProtection
+	MFA                        MFA
+	OIDC                       OIDC
+}
+
+// OIDC configures login with external OpenID Connect providers.
+type OIDC struct {
+	// StateTTL is how long a user has to finish signing in at the provider.
+	StateTTL        time.Duration `mapstructure:"state_ttl"`
+	CleanupInterval time.Duration `mapstructure:"cleanup_interval"`
+	// Providers are keyed by the name used in the login URLs, providers
+	// without a client_id are disabled.
+	Providers map[string]OIDCProvider
+}
+
+type OIDCProvider struct {
+	ClientID              string   `mapstructure:"client_id"`
+	ClientSecret          string   `mapstructure:"client_secret"`
+	Issuer                string   `mapstructure:"issuer"`
+	AuthorizationEndpoint string   `mapstructure:"authorization_endpoint"`
+	TokenEndpoint         string   `mapstructure:"token_endpoint"`
+	JWKSURI               string   `mapstructure:"jwks_uri"`
+	RedirectURL           string   `mapstructure:"redirect_url"`
+	Scopes                []string `mapstructure:"scopes"`
+	ResponseMode          string   `mapstructure:"response_mode"`
+}
+
+type Authorization struct {
+	// Source selects where roles are read from: "config" uses Roles, while
+	// "postgres" uses the roles tables and reloads them every ReloadInterval.
+	Source         string
+	ReloadInterval time.Duration `mapstructure:"reload_interval"`
+	// Roles lists the permissions of each role, "*" grants all of them.
+	Roles map[string][]string
+}
+
+// Privacy configures personal data exports.
+type Privacy struct {
+	// ExportDir is where export archives are written until they expire.
+	ExportDir string        `mapstructure:"export_dir"`
+	ExportTTL time.Duration `mapstructure:"export_ttl"`
+	// WorkerInterval is how often pending exports are picked up and expired
+	// ones deleted. New requests start right away.
+	WorkerInterval time.Duration `mapstructure:"worker_interval"`
+}
+
+type MFA struct {
+	// Issuer is the account issuer shown in authenticator apps.
+	Issuer string
+	// RequiredRoles must log in with a second factor, e.g. ["ADMIN"].
+	RequiredRoles []string      `mapstructure:"required_roles"`
+	ChallengeTTL  time.Duration `mapstructure:"challenge_ttl"`
+}
+
+// LoginProtection configures the failed login backoff and lockout.
+type LoginProtection struct {
+	// Store selects the failed login counter backend: "postgres" or "memory".
+	Store                   string
+	FreeAttempts            int           `mapstructure:"free_attempts"`
+	BackoffBase             time.Duration `mapstructure:"backoff_base"`
+	MaxBackoff              time.Duration `mapstructure:"max_backoff"`
+	AccountLockoutThreshold int           `mapstructure:"account_lockout_threshold"`
+	IPLockoutThreshold      int           `mapstructure:"ip_lockout_threshold"`
+	LockoutDuration         time.Duration `mapstructure:"lockout_duration"`
+	ResetAfter              time.Duration `mapstructure:"reset_after"`
+	CleanupInterval         time.Duration `mapstructure:"cleanup_interval"`
+}
+
+type Mail struct {
+	// Driver selects the mailer: "smtp" or "log".
+	Driver   string
+	From     string
+	Host     string
+	Port     int
+	Username string
+	Password string
+	// Dir is where the log mailer writes .eml files, empty to only log.
+	Dir string
 }
 
 func Load() (*Config, error) {
@@ -32,6 +145,36 @@ func Load() (*Config, error) {
 	viper.SetDefault("database.host", "localhost")
 	viper.SetDefault("database.port", 5432)
 	viper.SetDefault("database.sslmode", "disable")
+	viper.SetDefault("jwt.algorithm", "HS256")
+	viper.SetDefault("jwt.access_token_ttl", "15m")
+	viper.SetDefault("jwt.refresh_token_ttl", "720h")
+	viper.SetDefault("auth.revocation_store", "postgres")
+	viper.SetDefault("auth.revocation_cleanup_interval", "10m")
+	viper.SetDefault("auth.password_reset_ttl", "1h")
+	viper.SetDefault("auth.email_verification_ttl", "48h")
+	viper.SetDefault("auth.verification_resend_interval", "1m")
+	viper.SetDefault("auth.login.store", "postgres")
+	viper.SetDefault("auth.login.free_attempts", 3)
+	viper.SetDefault("auth.login.backoff_base", "1s")
+	viper.SetDefault("auth.login.max_backoff", "5m")
+	viper.SetDefault("auth.login.account_lockout_threshold", 10)
+	viper.SetDefault("auth.login.ip_lockout_threshold", 100)
+	viper.SetDefault("auth.login.lockout_duration", "15m")
+	viper.SetDefault("auth.login.reset_after", "24h")
+	viper.SetDefault("auth.login.cleanup_interval", "1h")
+	viper.SetDefault("auth.mfa.issuer", "Yadwy")
+	viper.SetDefault("auth.mfa.required_roles", []string{"ADMIN"})
+	viper.SetDefault("auth.mfa.challenge_ttl", "5m")
+	viper.SetDefault("auth.oidc.state_ttl", "10m")
+	viper.SetDefault("auth.oidc.cleanup_interval", "1h")
+	viper.SetDefault("authorization.source", "config")
+	viper.SetDefault("authorization.reload_interval", "1m")
+	viper.SetDefault("privacy.export_dir", "./tmp/exports")
+	viper.SetDefault("privacy.export_ttl", "168h")
+	viper.SetDefault("privacy.worker_interval", "1m")
+	viper.SetDefault("mail.driver", "log")
+	viper.SetDefault("mail.from", "Yadwy <no-reply@yadwy.com>")
+	viper.SetDefault("mail.port", 587)
 
 	// Read environment variables
 	viper.AutomaticEnv()
@@ -2,6 +2,7 @@ package application
 
 import (
 	"context"
+	"errors"
 	"go.uber.org/zap"
 	"mime/multipart"
 	"strings"
@@ -14,23 +15,291 @@ const (
 	FailedToCreateProduct   = "failed-to-create-product"
 	FailedToRetrieveProduct = "failed-to-retrieve-product"
 	FailedToSearchProducts  = "failed-to-search-products"
+	FailedToUpdateProduct   = "failed-to-update-product"
+	FailedToDeleteProduct   = "failed-to-delete-product"
+	ProductNotFound         = "product-not-found"
+	ProductImageNotFound    = "product-image-not-found"
+	InvalidProduct          = "invalid-product"
+	InvalidImageOrder       = "invalid-image-order"
+	InvalidOptions          = "invalid-product-options"
+	OptionsInUse            = "product-options-in-use"
+	VariantNotFound         = "product-variant-not-found"
+	VariantExists           = "product-variant-exists"
+	InvalidVariant          = "invalid-variant"
+	SKUTaken                = "sku-taken"
+	InvalidCursor           = "invalid-cursor"
+	InvalidLabelsMode       = "invalid-labels-mode"
+	FailedToListLabels      = "failed-to-list-labels"
 )
 
+// maxLabelLength is the size of product_labels.label_name.
+const maxLabelLength = 50
+
+// ProductUpdate holds the product details to change, nil fields are kept.
+type ProductUpdate struct {
+	Name        *string
+	Description *string
+	Price       *float64
+	CategoryID  *string
+	Stock       *int
+	IsAvailable *bool
+}
+
 type ProductService struct {
 	repo   domain.ProductRepository // Changed from pointer to interface
 	files  *common.FileService
+	policy *common.PermissionPolicy
 	logger *zap.Logger
 }
 
-func NewProductService(repo domain.ProductRepository, files *common.FileService, logger *zap.Logger) *ProductService {
+func NewProductService(repo domain.ProductRepository, files *common.FileService, policy *common.PermissionPolicy, logger *zap.Logger) *ProductService {
 	return &ProductService{
 		repo:   repo,
 		files:  files,
+		policy: policy,
 		logger: logger,
 	}
 }
 
-func (s *ProductService) CreateProduct(ctx context.Context, p *domain.Product, images []*multipart.FileHeader) error {
+// CreateProduct lists a product for the authenticated seller. A product
+// without a seller belongs to the user creating it, and only users allowed
+// to manage any product, such as admins, may list one for another seller.
+func (s *ProductService) CreateProduct(ctx context.Context, claims *common.UserClaims, p *domain.Product, images []*multipart.FileHeader) error {
+	if claims != nil && p.SellerID == 0 {
+		p.SellerID = claims.ID
+	}
+	if err := s.authorize(claims, p.SellerID); err != nil {
+		return err
+	}
+	labels, err := normalizeLabels(p.Labels)
+	if err != nil {
+		return err
+	}
+	p.Labels = labels
+
+	savedImages, err := s.saveImages(images)
+	if err != nil {
+		return err
+	}
+
+	err = s.repo.CreateProduct(ctx, p, savedImages)
+	if err != nil {
+		s.logger.Error("Failed to create product", zap.Error(err))
+		s.deleteImages(savedImages)
+		return common.NewErrorf(FailedToCreateProduct, "failed to create product: %v", err)
+	}
+
+	s.logger.Info("Product created successfully",
+		zap.String("name", p.Name),
+		zap.Int("imagesCount", len(savedImages)))
+
+	return nil
+}
+
+// UpdateProduct changes the details of a product. Images and labels are
+// changed separately.
+func (s *ProductService) UpdateProduct(ctx context.Context, claims *common.UserClaims, id int64, update ProductUpdate) (*domain.Product, error) {
+	p, err := s.ownedProduct(ctx, claims, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if update.Name != nil {
+		p.Name = strings.TrimSpace(*update.Name)
+	}
+	if update.Description != nil {
+		p.Description = *update.Description
+	}
+	if update.Price != nil {
+		p.Price = *update.Price
+	}
+	if update.CategoryID != nil {
+		p.CategoryID = *update.CategoryID
+	}
+	if update.Stock != nil {
+		p.Stock = *update.Stock
+	}
+	if update.IsAvailable != nil {
+		p.IsAvailable = *update.IsAvailable
+	}
+	switch {
+	case p.Name == "":
+		return nil, common.NewErrorf(InvalidProduct, "name is required")
+	case p.Price <= 0:
+		return nil, common.NewErrorf(InvalidProduct, "price must be greater than 0")
+	case p.Stock < 0:
+		return nil, common.NewErrorf(InvalidProduct, "stock must not be negative")
+	case p.CategoryID == "":
+		return nil, common.NewErrorf(InvalidProduct, "category is required")
+	}
+
+	if err := s.repo.UpdateProduct(ctx, p); err != nil {
+		s.logger.Error("Failed to update product", zap.Int64("id", id), zap.Error(err))
+		return nil, common.NewErrorf(FailedToUpdateProduct, "failed to update product: %v", err)
+	}
+	return s.GetProduct(ctx, id)
+}
+
+// DeleteProduct hides a product from the catalog. The product and its images
+// are kept for the orders placed with it.
+func (s *ProductService) DeleteProduct(ctx context.Context, claims *common.UserClaims, id int64) error {
+	if _, err := s.ownedProduct(ctx, claims, id); err != nil {
+		return err
+	}
+
+	if err := s.repo.DeleteProduct(ctx, id); err != nil {
+		s.logger.Error("Failed to delete product", zap.Int64("id", id), zap.Error(err))
+		return common.NewErrorf(FailedToDeleteProduct, "failed to delete product: %v", err)
+	}
+
+	s.logger.Info("Product deleted", zap.Int64("id", id), zap.Int64("by", claims.ID))
+	return nil
+}
+
+// AddImages uploads images and appends them to the product images. Images
+// of one variant are given its ID, otherwise variantID is 0.
+func (s *ProductService) AddImages(ctx context.Context, claims *common.UserClaims, id, variantID int64, images []*multipart.FileHeader) ([]domain.Image, error) {
+	p, err := s.ownedProduct(ctx, claims, id)
+	if err != nil {
+		return nil, err
+	}
+	if variantID != 0 && p.Variant(variantID) == nil {
+		return nil, common.NewErrorf(VariantNotFound, "product %d has no variant %d", id, variantID)
+	}
+
+	saved, err := s.saveImages(images)
+	if err != nil {
+		return nil, err
+	}
+	for i := range saved {
+		saved[i].VariantID = variantID
+	}
+	added, err := s.repo.AddImages(ctx, id, saved)
+	if err != nil {
+		s.logger.Error("Failed to add product images", zap.Int64("id", id), zap.Error(err))
+		s.deleteImages(saved)
+		return nil, common.NewErrorf(FailedToUpdateProduct, "failed to add product images: %v", err)
+	}
+	return added, nil
+}
+
+// RemoveImage removes an image from the product and deletes its file.
+func (s *ProductService) RemoveImage(ctx context.Context, claims *common.UserClaims, id, imageID int64) error {
+	if _, err := s.ownedProduct(ctx, claims, id); err != nil {
+		return err
+	}
+
+	removed, err := s.repo.RemoveImage(ctx, id, imageID)
+	if err != nil {
+		s.logger.Error("Failed to remove product image", zap.Int64("id", id), zap.Error(err))
+		return common.NewErrorf(FailedToUpdateProduct, "failed to remove product image: %v", err)
+	}
+	if removed == nil {
+		return common.NewErrorf(ProductImageNotFound, "product %d has no image %d", id, imageID)
+	}
+
+	s.deleteImages([]domain.Image{*removed})
+	return nil
+}
+
+// ReorderImages sets the display order of the product images. imageIDs must
+// list every image of the product once.
+func (s *ProductService) ReorderImages(ctx context.Context, claims *common.UserClaims, id int64, imageIDs []int64) ([]domain.Image, error) {
+	p, err := s.ownedProduct(ctx, claims, id)
+	if err != nil {
+		return nil, err
+	}
+
+	current := make(map[int64]bool, len(p.Images))
+	for _, img := range p.Images {
+		current[img.ID] = true
+	}
+	if len(imageIDs) != len(current) {
+		return nil, common.NewErrorf(InvalidImageOrder, "the order must list all %d images of the product", len(current))
+	}
+	for _, imageID := range imageIDs {
+		if !current[imageID] {
+			return nil, common.NewErrorf(InvalidImageOrder, "image %d is missing, repeated or not an image of the product", imageID)
+		}
+		delete(current, imageID)
+	}
+
+	if err := s.repo.ReorderImages(ctx, id, imageIDs); err != nil {
+		s.logger.Error("Failed to reorder product images", zap.Int64("id", id), zap.Error(err))
+		return nil, common.NewErrorf(FailedToUpdateProduct, "failed to reorder product images: %v", err)
+	}
+
+	p, err = s.GetProduct(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return p.Images, nil
+}
+
+// ReplaceLabels replaces every label of the product. Labels are normalized,
+// and blank and repeated labels are dropped.
+func (s *ProductService) ReplaceLabels(ctx context.Context, claims *common.UserClaims, id int64, labels []string) ([]string, error) {
+	if _, err := s.ownedProduct(ctx, claims, id); err != nil {
+		return nil, err
+	}
+
+	cleaned, err := normalizeLabels(labels)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.ReplaceLabels(ctx, id, cleaned); err != nil {
+		s.logger.Error("Failed to replace product labels", zap.Int64("id", id), zap.Error(err))
+		return nil, common.NewErrorf(FailedToUpdateProduct, "failed to replace product labels: %v", err)
+	}
+	return cleaned, nil
+}
+
+// ListLabels returns the labels in use with the number of products having
+// each, most used first.
+func (s *ProductService) ListLabels(ctx context.Context) ([]domain.LabelCount, error) {
+	labels, err := s.repo.ListLabels(ctx)
+	if err != nil {
+		s.logger.Error("Failed to list product labels", zap.Error(err))
+		return nil, common.NewErrorf(FailedToListLabels, "failed to list product labels: %v", err)
+	}
+	return labels, nil
+}
+
+// normalizeLabels normalizes the labels of a product and checks they fit
+// the label column.
+func normalizeLabels(labels []string) ([]string, error) {
+	normalized := domain.NormalizeLabels(labels)
+	for _, label := range normalized {
+		if len([]rune(label)) > maxLabelLength {
+			return nil, common.NewErrorf(InvalidProduct, "label %q is longer than %d characters", label, maxLabelLength)
+		}
+	}
+	return normalized, nil
+}
+
+// authorize allows changing the products of ownerID by the owner and by
+// users allowed to manage any product. Owners that are sellers pending
+// approval or rejected are denied, with access tokens and API keys alike.
+func (s *ProductService) authorize(claims *common.UserClaims, ownerID int64) error {
+	return common.CheckOwnership(s.policy, claims, ownerID, common.PermissionProductManage)
+}
+
+// ownedProduct returns a product the user may change.
+func (s *ProductService) ownedProduct(ctx context.Context, claims *common.UserClaims, id int64) (*domain.Product, error) {
+	p, err := s.GetProduct(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorize(claims, p.SellerID); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// saveImages stores uploaded images. The image type prefixes the file name,
+// as in "thumbnail:photo.png", and defaults to main.
+func (s *ProductService) saveImages(images []*multipart.FileHeader) ([]domain.Image, error) {
 	var savedImages []domain.Image
 
 	for _, img := range images {
@@ -44,7 +313,8 @@ func (s *ProductService) CreateProduct(ctx context.Context, p *domain.Product, i
 
 		url, err := s.files.SaveFile(img)
 		if err != nil {
-			return common.NewErrorf(FailedToUploadImage, "failed to upload image: %v", err)
+			s.deleteImages(savedImages)
+			return nil, common.NewErrorf(FailedToUploadImage, "failed to upload image: %v", err)
 		}
 
 		savedImages = append(savedImages, domain.Image{
@@ -56,18 +326,20 @@ func (s *ProductService) CreateProduct(ctx context.Context, p *domain.Product, i
 			zap.String("url", url),
 			zap.String("type", imageType))
 	}
+	return savedImages, nil
+}
 
-	err := s.repo.CreateProduct(ctx, p, savedImages)
-	if err != nil {
-		s.logger.Error("Failed to create product", zap.Error(err))
-		return common.NewErrorf(FailedToCreateProduct, "failed to create product: %v", err)
+// deleteImages deletes the files of images no longer referenced.
+func (s *ProductService) deleteImages(images []domain.Image) {
+	for _, img := range images {
+		name, ok := s.files.FileName(img.URL)
+		if !ok {
+			continue
+		}
+		if err := s.files.DeleteFile(name); err != nil {
+			s.logger.Warn("Failed to delete product image", zap.String("file", name), zap.Error(err))
+		}
 	}
-
-	s.logger.Info("Product created successfully",
-		zap.String("name", p.Name),
-		zap.Int("imagesCount", len(savedImages)))
-
-	return nil
 }
 
 func (s *ProductService) GetProduct(ctx context.Context, id int64) (*domain.Product, error) {
@@ -76,6 +348,9 @@ func (s *ProductService) GetProduct(ctx context.Context, id int64) (*domain.Prod
 		s.logger.Error("Failed to retrieve product", zap.Error(err))
 		return nil, common.NewErrorf(FailedToRetrieveProduct, "failed to retrieve product: %v", err)
 	}
+	if product == nil {
+		return nil, common.NewErrorf(ProductNotFound, "product %d not found", id)
+	}
 
 	s.logger.Info("Product retrieved successfully",
 		zap.Int64("id", id),
@@ -91,7 +366,18 @@ func (s *ProductService) SearchProducts(ctx context.Context, params domain.Searc
 		zap.Int("limit", params.Limit),
 		zap.Int("offset", params.Offset))
 
+	switch params.LabelsMode {
+	case "":
+		params.LabelsMode = domain.LabelsModeAny
+	case domain.LabelsModeAny, domain.LabelsModeAll:
+	default:
+		return nil, common.NewErrorf(InvalidLabelsMode, "labels mode must be %s or %s", domain.LabelsModeAny, domain.LabelsModeAll)
+	}
+
 	result, err := s.repo.SearchProducts(ctx, params)
+	if errors.Is(err, domain.ErrInvalidCursor) {
+		return nil, common.NewErrorf(InvalidCursor, "the cursor is malformed or was issued for another sort")
+	}
 	if err != nil {
 		s.logger.Error("Failed to search products", zap.Error(err))
 		return nil, common.NewErrorf(FailedToSearchProducts, "failed to search products: %v", err)
@@ -103,3 +389,36 @@ func (s *ProductService) SearchProducts(ctx context.Context, params domain.Searc
 
 	return result, nil
 }
+
+// ExportPersonalData adds the products listed by a seller, with their images,
+// to a personal data export. Users without products get no section.
+func (s *ProductService) ExportPersonalData(ctx context.Context, userID int64) (*common.PersonalDataSection, error) {
+	var products []*domain.Product
+	var files []string
+	params := domain.SearchParams{
+		SellerID: &userID, Limit: 100, SortBy: "created_at", IncludeSuspendedShops: true, SkipCount: true,
+	}
+	for {
+		result, err := s.repo.SearchProducts(ctx, params)
+		if err != nil {
+			return nil, common.NewErrorf(FailedToSearchProducts, "failed to list seller products: %v", err)
+		}
+		for _, p := range result.Products {
+			for _, img := range p.Images {
+				if name, ok := s.files.FileName(img.URL); ok {
+					files = append(files, name)
+				}
+			}
+		}
+		products = append(products, result.Products...)
+		if result.NextCursor == "" {
+			break
+		}
+		params.Cursor = result.NextCursor
+	}
+
+	if len(products) == 0 {
+		return nil, nil
+	}
+	return &common.PersonalDataSection{Name: "products", Data: products, Files: files}, nil
+}
@@ -0,0 +1,358 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"yadwy-backend/internal/common"
+	"yadwy-backend/internal/prodcuts/domain"
+
+	"go.uber.org/zap"
+)
+
+// memoryProducts keeps products in memory.
+type memoryProducts struct {
+	domain.ProductRepository
+	created []*domain.Product
+	deleted map[int64]bool
+}
+
+func (m *memoryProducts) CreateProduct(ctx context.Context, p *domain.Product, images []domain.Image) error {
+	p.ID = int64(len(m.created) + 1)
+	for i := range images {
+		images[i].ID = int64(i + 1)
+		images[i].Position = i
+	}
+	p.Images = images
+	m.created = append(m.created, p)
+	return nil
+}
+
+func (m *memoryProducts) GetProduct(ctx context.Context, id int64) (*domain.Product, error) {
+	if id < 1 || int(id) > len(m.created) || m.deleted[id] {
+		return nil, nil
+	}
+	p := *m.created[id-1]
+	p.Images = append([]domain.Image(nil), p.Images...)
+	p.Variants = append([]domain.Variant(nil), p.Variants...)
+	return &p, nil
+}
+
+func (m *memoryProducts) UpdateProduct(ctx context.Context, p *domain.Product) error {
+	stored := *p
+	m.created[p.ID-1] = &stored
+	return nil
+}
+
+func (m *memoryProducts) DeleteProduct(ctx context.Context, id int64) error {
+	if m.deleted == nil {
+		m.deleted = map[int64]bool{}
+	}
+	m.deleted[id] = true
+	return nil
+}
+
+func (m *memoryProducts) RemoveImage(ctx context.Context, productID, imageID int64) (*domain.Image, error) {
+	p := m.created[productID-1]
+	for i, img := range p.Images {
+		if img.ID == imageID {
+			p.Images = append(p.Images[:i], p.Images[i+1:]...)
+			return &img, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *memoryProducts) ReorderImages(ctx context.Context, productID int64, imageIDs []int64) error {
+	p := m.created[productID-1]
+	for i, img := range p.Images {
+		p.Images[i].Position = slices.Index(imageIDs, img.ID)
+	}
+	slices.SortFunc(p.Images, func(a, b domain.Image) int { return a.Position - b.Position })
+	return nil
+}
+
+func (m *memoryProducts) ReplaceLabels(ctx context.Context, productID int64, labels []string) error {
+	m.created[productID-1].Labels = labels
+	return nil
+}
+
+func (m *memoryProducts) ReplaceOptions(ctx context.Context, productID int64, options []domain.Option) ([]domain.Option, error) {
+	for i := range options {
+		options[i].ID = int64(i + 1)
+		for j := range options[i].Values {
+			options[i].Values[j].ID = int64(10*(i+1) + j)
+		}
+	}
+	m.created[productID-1].Options = options
+	return options, nil
+}
+
+func (m *memoryProducts) CreateVariant(ctx context.Context, productID int64, v *domain.Variant) error {
+	p := m.created[productID-1]
+	for _, other := range p.Variants {
+		if other.SKU == v.SKU {
+			return domain.ErrDuplicateSKU
+		}
+	}
+	v.ID = int64(len(p.Variants) + 1)
+	p.Variants = append(p.Variants, *v)
+	return nil
+}
+
+func (m *memoryProducts) UpdateVariant(ctx context.Context, productID int64, v *domain.Variant) error {
+	*m.created[productID-1].Variant(v.ID) = *v
+	return nil
+}
+
+func newTestProductService(repo domain.ProductRepository) *ProductService {
+	policy := common.NewPermissionPolicy(map[string][]string{
+		"ADMIN":  {"*"},
+		"SELLER": {"product:create"},
+	})
+	return NewProductService(repo, &common.FileService{BaseURL: "http://localhost:3000/images"}, policy, zap.NewNop())
+}
+
+// newSellerProductTest stores a product of seller 5 with three image files.
+func newSellerProductTest(t *testing.T) (*ProductService, *memoryProducts, *domain.Product) {
+	t.Helper()
+	repo := &memoryProducts{}
+	service := newTestProductService(repo)
+	files := service.files
+	files.StoragePath = t.TempDir()
+
+	var images []domain.Image
+	for _, name := range []string{"a.png", "b.png", "c.png"} {
+		if err := os.WriteFile(filepath.Join(files.StoragePath, name), []byte("png"), 0600); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+		images = append(images, domain.Image{URL: files.GetFileURL(name), Type: "main"})
+	}
+	p := &domain.Product{Name: "Vase", Price: 120, CategoryID: "pottery", SellerID: 5, Stock: 3}
+	if err := repo.CreateProduct(context.Background(), p, images); err != nil {
+		t.Fatalf("CreateProduct() error = %v", err)
+	}
+	return service, repo, p
+}
+
+func errorCode(err error) common.ErrorCode {
+	var appErr *common.Error
+	if errors.As(err, &appErr) {
+		return appErr.Code()
+	}
+	return ""
+}
+
+func TestProductService_CreateProduct(t *testing.T) {
+	ctx := context.Background()
+	seller := &common.UserClaims{ID: 5, Role: "SELLER"}
+	admin := &common.UserClaims{ID: 1, Role: "ADMIN"}
+
+	tests := []struct {
+		name       string
+		claims     *common.UserClaims
+		sellerID   int64
+		wantSeller int64
+		wantErr    common.ErrorCode
+	}{
+		{name: "should list the product for the authenticated seller", claims: seller, wantSeller: 5},
+		{name: "should accept the seller's own ID", claims: seller, sellerID: 5, wantSeller: 5},
+		{name: "should reject listing for another seller", claims: seller, sellerID: 6, wantErr: common.PermissionDeniedErrorCode},
+		{name: "should let admins list for another seller", claims: admin, sellerID: 6, wantSeller: 6},
+		{name: "should require a user", claims: nil, sellerID: 6, wantErr: common.AuthHeaderMissingErrorCode},
+		{
+			name:    "should reject a seller pending approval",
+			claims:  &common.UserClaims{ID: 5, Role: "SELLER", SellerStatus: "PENDING"},
+			wantErr: common.PermissionDeniedErrorCode,
+		},
+		{
+			name: "should reject an API key of a rejected seller",
+			claims: &common.UserClaims{ID: 5, Role: "SELLER", SellerStatus: "REJECTED",
+				TokenType: common.APIKeyTokenType, Scopes: []string{"product:create"}},
+			wantErr: common.PermissionDeniedErrorCode,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &memoryProducts{}
+			service := newTestProductService(repo)
+
+			err := service.CreateProduct(ctx, tt.claims, &domain.Product{Name: "Vase", SellerID: tt.sellerID}, nil)
+			if got := errorCode(err); got != tt.wantErr {
+				t.Fatalf("CreateProduct() error = %v, want code %q", err, tt.wantErr)
+			}
+			if tt.wantErr != "" {
+				if len(repo.created) != 0 {
+					t.Errorf("CreateProduct() stored a rejected product")
+				}
+				return
+			}
+			if len(repo.created) != 1 || repo.created[0].SellerID != tt.wantSeller {
+				t.Errorf("CreateProduct() stored %+v, want seller %d", repo.created, tt.wantSeller)
+			}
+		})
+	}
+}
+
+func TestProductService_CreateProductLabels(t *testing.T) {
+	repo := &memoryProducts{}
+	service := newTestProductService(repo)
+
+	p := &domain.Product{Name: "Vase", Labels: []string{" Hand  Made", "GIFT", "handmade", "hand made", " "}}
+	if err := service.CreateProduct(context.Background(), &common.UserClaims{ID: 5, Role: "SELLER"}, p, nil); err != nil {
+		t.Fatalf("CreateProduct() error = %v", err)
+	}
+	if want := []string{"hand made", "gift", "handmade"}; !slices.Equal(repo.created[0].Labels, want) {
+		t.Errorf("CreateProduct() stored labels %v, want %v", repo.created[0].Labels, want)
+	}
+}
+
+func TestProductService_UpdateProduct(t *testing.T) {
+	ctx := context.Background()
+	seller := &common.UserClaims{ID: 5, Role: "SELLER"}
+
+	t.Run("should change only the given fields", func(t *testing.T) {
+		service, _, p := newSellerProductTest(t)
+		price := 90.0
+
+		got, err := service.UpdateProduct(ctx, seller, p.ID, ProductUpdate{Price: &price})
+		if err != nil {
+			t.Fatalf("UpdateProduct() error = %v", err)
+		}
+		if got.Price != 90 || got.Name != "Vase" || got.Stock != 3 {
+			t.Errorf("UpdateProduct() = %+v, want only the price changed", got)
+		}
+	})
+
+	t.Run("should reject invalid details", func(t *testing.T) {
+		service, _, p := newSellerProductTest(t)
+		stock := -1
+
+		_, err := service.UpdateProduct(ctx, seller, p.ID, ProductUpdate{Stock: &stock})
+		if got := errorCode(err); got != InvalidProduct {
+			t.Errorf("UpdateProduct() error code = %v, want %v", got, InvalidProduct)
+		}
+	})
+
+	t.Run("should only let the owner or an admin change a product", func(t *testing.T) {
+		service, _, p := newSellerProductTest(t)
+		name := "Bowl"
+
+		other := &common.UserClaims{ID: 6, Role: "SELLER"}
+		if _, err := service.UpdateProduct(ctx, other, p.ID, ProductUpdate{Name: &name}); errorCode(err) != common.PermissionDeniedErrorCode {
+			t.Errorf("UpdateProduct() by another seller error = %v, want %v", err, common.PermissionDeniedErrorCode)
+		}
+		if err := service.DeleteProduct(ctx, other, p.ID); errorCode(err) != common.PermissionDeniedErrorCode {
+			t.Errorf("DeleteProduct() by another seller error = %v, want %v", err, common.PermissionDeniedErrorCode)
+		}
+
+		admin := &common.UserClaims{ID: 1, Role: "ADMIN"}
+		if _, err := service.UpdateProduct(ctx, admin, p.ID, ProductUpdate{Name: &name}); err != nil {
+			t.Errorf("UpdateProduct() by admin error = %v", err)
+		}
+	})
+
+	t.Run("should not let a rejected seller change their products", func(t *testing.T) {
+		service, _, p := newSellerProductTest(t)
+		name := "Bowl"
+
+		rejected := &common.UserClaims{ID: 5, Role: "SELLER", SellerStatus: "REJECTED"}
+		if _, err := service.UpdateProduct(ctx, rejected, p.ID, ProductUpdate{Name: &name}); errorCode(err) != common.PermissionDeniedErrorCode {
+			t.Errorf("UpdateProduct() error = %v, want %v", err, common.PermissionDeniedErrorCode)
+		}
+		if err := service.DeleteProduct(ctx, rejected, p.ID); errorCode(err) != common.PermissionDeniedErrorCode {
+			t.Errorf("DeleteProduct() error = %v, want %v", err, common.PermissionDeniedErrorCode)
+		}
+	})
+}
+
+func TestProductService_DeleteProduct(t *testing.T) {
+	ctx := context.Background()
+	service, _, p := newSellerProductTest(t)
+	seller := &common.UserClaims{ID: 5, Role: "SELLER"}
+
+	if err := service.DeleteProduct(ctx, seller, p.ID); err != nil {
+		t.Fatalf("DeleteProduct() error = %v", err)
+	}
+	if _, err := service.GetProduct(ctx, p.ID); errorCode(err) != ProductNotFound {
+		t.Errorf("GetProduct() after delete error = %v, want %v", err, ProductNotFound)
+	}
+	if err := service.DeleteProduct(ctx, seller, p.ID); errorCode(err) != ProductNotFound {
+		t.Errorf("DeleteProduct() twice error = %v, want %v", err, ProductNotFound)
+	}
+	if _, err := os.Stat(filepath.Join(service.files.StoragePath, "a.png")); err != nil {
+		t.Errorf("DeleteProduct() removed the image files of a kept product: %v", err)
+	}
+}
+
+func TestProductService_Images(t *testing.T) {
+	ctx := context.Background()
+	seller := &common.UserClaims{ID: 5, Role: "SELLER"}
+
+	t.Run("should delete the file of a removed image", func(t *testing.T) {
+		service, _, p := newSellerProductTest(t)
+
+		if err := service.RemoveImage(ctx, seller, p.ID, 2); err != nil {
+			t.Fatalf("RemoveImage() error = %v", err)
+		}
+		if _, err := os.Stat(filepath.Join(service.files.StoragePath, "b.png")); !os.IsNotExist(err) {
+			t.Errorf("removed image file still exists, stat error = %v", err)
+		}
+		if err := service.RemoveImage(ctx, seller, p.ID, 2); errorCode(err) != ProductImageNotFound {
+			t.Errorf("RemoveImage() twice error = %v, want %v", err, ProductImageNotFound)
+		}
+	})
+
+	t.Run("should reorder all images", func(t *testing.T) {
+		service, _, p := newSellerProductTest(t)
+
+		images, err := service.ReorderImages(ctx, seller, p.ID, []int64{3, 1, 2})
+		if err != nil {
+			t.Fatalf("ReorderImages() error = %v", err)
+		}
+		if images[0].ID != 3 || images[1].ID != 1 || images[2].ID != 2 {
+			t.Errorf("ReorderImages() = %+v, want images 3, 1, 2", images)
+		}
+	})
+
+	for name, order := range map[string][]int64{
+		"missing":  {3, 1},
+		"repeated": {3, 1, 1},
+		"unknown":  {3, 1, 9},
+	} {
+		t.Run("should reject an order with "+name+" images", func(t *testing.T) {
+			service, _, p := newSellerProductTest(t)
+
+			_, err := service.ReorderImages(ctx, seller, p.ID, order)
+			if got := errorCode(err); got != InvalidImageOrder {
+				t.Errorf("ReorderImages() error code = %v, want %v", got, InvalidImageOrder)
+			}
+		})
+	}
+}
+
+func TestProductService_ReplaceLabels(t *testing.T) {
+	service, repo, p := newSellerProductTest(t)
+
+	labels, err := service.ReplaceLabels(context.Background(), &common.UserClaims{ID: 5, Role: "SELLER"}, p.ID,
+		[]string{" handmade ", "", "gift", "handmade"})
+	if err != nil {
+		t.Fatalf("ReplaceLabels() error = %v", err)
+	}
+	if !slices.Equal(labels, []string{"handmade", "gift"}) || !slices.Equal(repo.created[0].Labels, labels) {
+		t.Errorf("ReplaceLabels() = %v, stored %v, want [handmade gift]", labels, repo.created[0].Labels)
+	}
+}
+
+func TestProductService_SearchProductsLabelsMode(t *testing.T) {
+	service := newTestProductService(&memoryProducts{})
+
+	_, err := service.SearchProducts(context.Background(), domain.SearchParams{Labels: []string{"gift"}, LabelsMode: "some"})
+	if got := errorCode(err); got != InvalidLabelsMode {
+		t.Errorf("SearchProducts() error code = %v, want %v", got, InvalidLabelsMode)
+	}
+}
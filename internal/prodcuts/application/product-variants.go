@@ -0,0 +1,275 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sort"
+	"strings"
+	"yadwy-backend/internal/common"
+	"yadwy-backend/internal/prodcuts/domain"
+
+	"go.uber.org/zap"
+)
+
+const (
+	maxOptions      = 3
+	maxOptionValues = 50
+	// maxOptionLength is the size of the option name and value columns.
+	maxOptionLength = 50
+	maxSKULength    = 64
+)
+
+// OptionInput is an option of a product with its values, in display order.
+type OptionInput struct {
+	Name   string
+	Values []string
+}
+
+// VariantDetails are the variant fields a seller can change.
+type VariantDetails struct {
+	SKU         string
+	Price       *float64 // Overrides the product price, nil for the product price
+	Stock       int
+	IsAvailable bool
+}
+
+// VariantInput is a new variant with one value of each product option, by
+// option name.
+type VariantInput struct {
+	VariantDetails
+	Options map[string]string
+}
+
+// ReplaceOptions sets the options of a product. Once the product has
+// variants, values can be added but options can only be removed or added
+// with its variants deleted, and values only when no variant uses them.
+func (s *ProductService) ReplaceOptions(ctx context.Context, claims *common.UserClaims, id int64, input []OptionInput) ([]domain.Option, error) {
+	p, err := s.ownedProduct(ctx, claims, id)
+	if err != nil {
+		return nil, err
+	}
+	options, err := cleanOptions(input)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkOptionsInUse(p, options); err != nil {
+		return nil, err
+	}
+
+	stored, err := s.repo.ReplaceOptions(ctx, id, options)
+	if err != nil {
+		s.logger.Error("Failed to replace product options", zap.Int64("id", id), zap.Error(err))
+		return nil, common.NewErrorf(FailedToUpdateProduct, "failed to replace product options: %v", err)
+	}
+	return stored, nil
+}
+
+// CreateVariant adds a variant for a combination of option values not
+// already sold.
+func (s *ProductService) CreateVariant(ctx context.Context, claims *common.UserClaims, id int64, input VariantInput) (*domain.Variant, error) {
+	p, err := s.ownedProduct(ctx, claims, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(p.Options) == 0 {
+		return nil, common.NewErrorf(InvalidVariant, "product %d has no options, set them before adding variants", id)
+	}
+
+	v := &domain.Variant{}
+	if err := applyVariantDetails(v, input.VariantDetails); err != nil {
+		return nil, err
+	}
+	if v.Options, v.OptionValueIDs, err = selectOptionValues(p.Options, input.Options); err != nil {
+		return nil, err
+	}
+	for _, other := range p.Variants {
+		if sameValues(other.OptionValueIDs, v.OptionValueIDs) {
+			return nil, common.NewErrorf(VariantExists, "variant %d already has these options", other.ID)
+		}
+	}
+
+	if err := s.repo.CreateVariant(ctx, id, v); err != nil {
+		return nil, s.variantError(id, err)
+	}
+	p.Variants = append(p.Variants, *v)
+	p.ResolveVariants()
+	return p.Variant(v.ID), nil
+}
+
+// UpdateVariant changes the SKU, price, stock and availability of a variant.
+// Its options cannot change, a variant with other options is a new variant.
+func (s *ProductService) UpdateVariant(ctx context.Context, claims *common.UserClaims, id, variantID int64, details VariantDetails) (*domain.Variant, error) {
+	p, err := s.ownedProduct(ctx, claims, id)
+	if err != nil {
+		return nil, err
+	}
+	v := p.Variant(variantID)
+	if v == nil {
+		return nil, common.NewErrorf(VariantNotFound, "product %d has no variant %d", id, variantID)
+	}
+	if err := applyVariantDetails(v, details); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdateVariant(ctx, id, v); err != nil {
+		return nil, s.variantError(id, err)
+	}
+	p.ResolveVariants()
+	return p.Variant(variantID), nil
+}
+
+// DeleteVariant deletes a variant, removing it from carts. Its images are
+// kept as images of the product.
+func (s *ProductService) DeleteVariant(ctx context.Context, claims *common.UserClaims, id, variantID int64) error {
+	p, err := s.ownedProduct(ctx, claims, id)
+	if err != nil {
+		return err
+	}
+	if p.Variant(variantID) == nil {
+		return common.NewErrorf(VariantNotFound, "product %d has no variant %d", id, variantID)
+	}
+
+	if err := s.repo.DeleteVariant(ctx, id, variantID); err != nil {
+		s.logger.Error("Failed to delete product variant", zap.Int64("id", id), zap.Int64("variantID", variantID), zap.Error(err))
+		return common.NewErrorf(FailedToUpdateProduct, "failed to delete product variant: %v", err)
+	}
+	return nil
+}
+
+func (s *ProductService) variantError(id int64, err error) error {
+	if errors.Is(err, domain.ErrDuplicateSKU) {
+		return common.NewErrorf(SKUTaken, "the SKU is used by another variant")
+	}
+	s.logger.Error("Failed to store product variant", zap.Int64("id", id), zap.Error(err))
+	return common.NewErrorf(FailedToUpdateProduct, "failed to store product variant: %v", err)
+}
+
+// cleanOptions trims the option names and values, rejecting blank and
+// repeated ones.
+func cleanOptions(input []OptionInput) ([]domain.Option, error) {
+	if len(input) > maxOptions {
+		return nil, common.NewErrorf(InvalidOptions, "a product has at most %d options", maxOptions)
+	}
+
+	options := make([]domain.Option, len(input))
+	names := map[string]bool{}
+	for i, in := range input {
+		name := strings.TrimSpace(in.Name)
+		if err := checkOptionText("option name", name); err != nil {
+			return nil, err
+		}
+		if names[strings.ToLower(name)] {
+			return nil, common.NewErrorf(InvalidOptions, "option %q is repeated", name)
+		}
+		names[strings.ToLower(name)] = true
+
+		if len(in.Values) == 0 || len(in.Values) > maxOptionValues {
+			return nil, common.NewErrorf(InvalidOptions, "option %q must have 1 to %d values", name, maxOptionValues)
+		}
+		options[i] = domain.Option{Name: name, Values: make([]domain.OptionValue, len(in.Values))}
+		values := map[string]bool{}
+		for j, value := range in.Values {
+			value = strings.TrimSpace(value)
+			if err := checkOptionText("option value", value); err != nil {
+				return nil, err
+			}
+			if values[strings.ToLower(value)] {
+				return nil, common.NewErrorf(InvalidOptions, "value %q of option %q is repeated", value, name)
+			}
+			values[strings.ToLower(value)] = true
+			options[i].Values[j] = domain.OptionValue{Value: value}
+		}
+	}
+	return options, nil
+}
+
+func checkOptionText(field, text string) error {
+	if text == "" || len([]rune(text)) > maxOptionLength {
+		return common.NewErrorf(InvalidOptions, "%s must have 1 to %d characters", field, maxOptionLength)
+	}
+	return nil
+}
+
+// checkOptionsInUse rejects option changes that leave a variant without a
+// value of each option.
+func checkOptionsInUse(p *domain.Product, options []domain.Option) error {
+	if len(p.Variants) == 0 {
+		return nil
+	}
+
+	current := make([]string, len(p.Options))
+	for i, opt := range p.Options {
+		current[i] = opt.Name
+	}
+	next := make([]string, len(options))
+	for i, opt := range options {
+		next[i] = opt.Name
+	}
+	sort.Strings(current)
+	sort.Strings(next)
+	if !slices.Equal(current, next) {
+		return common.NewErrorf(OptionsInUse, "options can only be added or removed on a product without variants")
+	}
+
+	for _, v := range p.Variants {
+		for _, opt := range options {
+			used := v.Options[opt.Name]
+			if !slices.ContainsFunc(opt.Values, func(val domain.OptionValue) bool { return val.Value == used }) {
+				return common.NewErrorf(OptionsInUse, "value %q of option %q is used by variant %d", used, opt.Name, v.ID)
+			}
+		}
+	}
+	return nil
+}
+
+// selectOptionValues resolves a value of each product option, given by name.
+func selectOptionValues(options []domain.Option, selected map[string]string) (map[string]string, []int64, error) {
+	if len(selected) != len(options) {
+		return nil, nil, common.NewErrorf(InvalidVariant, "a variant must have exactly one value of each of the %d product options", len(options))
+	}
+
+	values := make(map[string]string, len(options))
+	ids := make([]int64, 0, len(options))
+	for _, opt := range options {
+		want, ok := selected[opt.Name]
+		if !ok {
+			return nil, nil, common.NewErrorf(InvalidVariant, "missing a value of option %q", opt.Name)
+		}
+		i := slices.IndexFunc(opt.Values, func(val domain.OptionValue) bool { return val.Value == strings.TrimSpace(want) })
+		if i < 0 {
+			return nil, nil, common.NewErrorf(InvalidVariant, "%q is not a value of option %q", want, opt.Name)
+		}
+		values[opt.Name] = opt.Values[i].Value
+		ids = append(ids, opt.Values[i].ID)
+	}
+	return values, ids, nil
+}
+
+func applyVariantDetails(v *domain.Variant, details VariantDetails) error {
+	sku := strings.TrimSpace(details.SKU)
+	switch {
+	case sku == "" || len(sku) > maxSKULength:
+		return common.NewErrorf(InvalidVariant, "SKU must have 1 to %d characters", maxSKULength)
+	case details.Price != nil && *details.Price <= 0:
+		return common.NewErrorf(InvalidVariant, "price must be greater than 0")
+	case details.Stock < 0:
+		return common.NewErrorf(InvalidVariant, "stock must not be negative")
+	}
+
+	v.SKU = sku
+	v.Price = details.Price
+	v.Stock = details.Stock
+	v.IsAvailable = details.IsAvailable
+	return nil
+}
+
+func sameValues(a, b []int64) bool {
+	return slices.Equal(sorted(a), sorted(b))
+}
+
+func sorted(ids []int64) []int64 {
+	ids = slices.Clone(ids)
+	slices.Sort(ids)
+	return ids
+}
@@ -0,0 +1,196 @@
+package application
+
+import (
+	"context"
+	"testing"
+	"yadwy-backend/internal/common"
+	"yadwy-backend/internal/prodcuts/domain"
+)
+
+// newVariantTest stores a product of seller 5 with Size and Color options.
+func newVariantTest(t *testing.T) (*ProductService, *memoryProducts, *domain.Product) {
+	t.Helper()
+	service, repo, p := newSellerProductTest(t)
+	_, err := service.ReplaceOptions(context.Background(), &common.UserClaims{ID: 5, Role: "SELLER"}, p.ID, []OptionInput{
+		{Name: " Size ", Values: []string{"S", "M", "L"}},
+		{Name: "Color", Values: []string{"Blue", "Red"}},
+	})
+	if err != nil {
+		t.Fatalf("ReplaceOptions() error = %v", err)
+	}
+	return service, repo, p
+}
+
+func TestProductService_ReplaceOptions(t *testing.T) {
+	ctx := context.Background()
+	seller := &common.UserClaims{ID: 5, Role: "SELLER"}
+
+	tests := []struct {
+		name    string
+		options []OptionInput
+	}{
+		{name: "should reject a blank option name", options: []OptionInput{{Name: " ", Values: []string{"S"}}}},
+		{name: "should reject an option without values", options: []OptionInput{{Name: "Size"}}},
+		{name: "should reject repeated options", options: []OptionInput{
+			{Name: "Size", Values: []string{"S"}}, {Name: "size", Values: []string{"M"}},
+		}},
+		{name: "should reject repeated values", options: []OptionInput{{Name: "Size", Values: []string{"S", " s"}}}},
+		{name: "should reject more than three options", options: []OptionInput{
+			{Name: "Size", Values: []string{"S"}}, {Name: "Color", Values: []string{"Blue"}},
+			{Name: "Material", Values: []string{"Clay"}}, {Name: "Finish", Values: []string{"Matte"}},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, _, p := newSellerProductTest(t)
+
+			_, err := service.ReplaceOptions(ctx, seller, p.ID, tt.options)
+			if got := errorCode(err); got != InvalidOptions {
+				t.Errorf("ReplaceOptions() error code = %v, want %v", got, InvalidOptions)
+			}
+		})
+	}
+
+	t.Run("should keep the values used by variants", func(t *testing.T) {
+		service, _, p := newVariantTest(t)
+		if _, err := service.CreateVariant(ctx, seller, p.ID, VariantInput{
+			VariantDetails: VariantDetails{SKU: "VASE-S-BLUE", IsAvailable: true},
+			Options:        map[string]string{"Size": "S", "Color": "Blue"},
+		}); err != nil {
+			t.Fatalf("CreateVariant() error = %v", err)
+		}
+
+		_, err := service.ReplaceOptions(ctx, seller, p.ID, []OptionInput{
+			{Name: "Size", Values: []string{"M", "L"}},
+			{Name: "Color", Values: []string{"Blue", "Red"}},
+		})
+		if got := errorCode(err); got != OptionsInUse {
+			t.Errorf("ReplaceOptions() removing a used value error code = %v, want %v", got, OptionsInUse)
+		}
+		_, err = service.ReplaceOptions(ctx, seller, p.ID, []OptionInput{{Name: "Size", Values: []string{"S"}}})
+		if got := errorCode(err); got != OptionsInUse {
+			t.Errorf("ReplaceOptions() removing an option error code = %v, want %v", got, OptionsInUse)
+		}
+		if _, err := service.ReplaceOptions(ctx, seller, p.ID, []OptionInput{
+			{Name: "Size", Values: []string{"S", "XL"}},
+			{Name: "Color", Values: []string{"Blue"}},
+		}); err != nil {
+			t.Errorf("ReplaceOptions() changing unused values error = %v", err)
+		}
+	})
+}
+
+func TestProductService_CreateVariant(t *testing.T) {
+	ctx := context.Background()
+	seller := &common.UserClaims{ID: 5, Role: "SELLER"}
+	price := 150.0
+
+	t.Run("should resolve the options and the price of the variant", func(t *testing.T) {
+		service, repo, p := newVariantTest(t)
+
+		v, err := service.CreateVariant(ctx, seller, p.ID, VariantInput{
+			VariantDetails: VariantDetails{SKU: " VASE-L-RED ", Price: &price, Stock: 2, IsAvailable: true},
+			Options:        map[string]string{"Size": "L", "Color": "Red"},
+		})
+		if err != nil {
+			t.Fatalf("CreateVariant() error = %v", err)
+		}
+		if v.SKU != "VASE-L-RED" || v.FinalPrice != price || v.Options["Size"] != "L" || v.Options["Color"] != "Red" {
+			t.Errorf("CreateVariant() = %+v, want VASE-L-RED, L and Red at %v", v, price)
+		}
+		if got := repo.created[0].Variants; len(got) != 1 || !sameValues(got[0].OptionValueIDs, []int64{12, 21}) {
+			t.Errorf("stored variants = %+v, want one with values 12 and 21", got)
+		}
+	})
+
+	tests := []struct {
+		name    string
+		input   VariantInput
+		wantErr common.ErrorCode
+	}{
+		{
+			name:    "should require a value of each option",
+			input:   VariantInput{VariantDetails: VariantDetails{SKU: "VASE-S"}, Options: map[string]string{"Size": "S"}},
+			wantErr: InvalidVariant,
+		},
+		{
+			name: "should reject unknown values",
+			input: VariantInput{VariantDetails: VariantDetails{SKU: "VASE-XL"},
+				Options: map[string]string{"Size": "XL", "Color": "Blue"}},
+			wantErr: InvalidVariant,
+		},
+		{
+			name: "should reject a negative stock",
+			input: VariantInput{VariantDetails: VariantDetails{SKU: "VASE-M", Stock: -1},
+				Options: map[string]string{"Size": "M", "Color": "Blue"}},
+			wantErr: InvalidVariant,
+		},
+		{
+			name: "should reject options of an existing variant",
+			input: VariantInput{VariantDetails: VariantDetails{SKU: "VASE-S-BLUE-2"},
+				Options: map[string]string{"Size": "S", "Color": "Blue"}},
+			wantErr: VariantExists,
+		},
+		{
+			name: "should reject a taken SKU",
+			input: VariantInput{VariantDetails: VariantDetails{SKU: "VASE-S-BLUE"},
+				Options: map[string]string{"Size": "M", "Color": "Blue"}},
+			wantErr: SKUTaken,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, _, p := newVariantTest(t)
+			if _, err := service.CreateVariant(ctx, seller, p.ID, VariantInput{
+				VariantDetails: VariantDetails{SKU: "VASE-S-BLUE", IsAvailable: true},
+				Options:        map[string]string{"Size": "S", "Color": "Blue"},
+			}); err != nil {
+				t.Fatalf("CreateVariant() error = %v", err)
+			}
+
+			_, err := service.CreateVariant(ctx, seller, p.ID, tt.input)
+			if got := errorCode(err); got != tt.wantErr {
+				t.Errorf("CreateVariant() error code = %v, want %v", got, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("should require options", func(t *testing.T) {
+		service, _, p := newSellerProductTest(t)
+
+		_, err := service.CreateVariant(ctx, seller, p.ID, VariantInput{VariantDetails: VariantDetails{SKU: "VASE"}})
+		if got := errorCode(err); got != InvalidVariant {
+			t.Errorf("CreateVariant() error code = %v, want %v", got, InvalidVariant)
+		}
+	})
+}
+
+func TestProductService_UpdateVariant(t *testing.T) {
+	ctx := context.Background()
+	seller := &common.UserClaims{ID: 5, Role: "SELLER"}
+	service, _, p := newVariantTest(t)
+	created, err := service.CreateVariant(ctx, seller, p.ID, VariantInput{
+		VariantDetails: VariantDetails{SKU: "VASE-S-BLUE", Stock: 4, IsAvailable: true},
+		Options:        map[string]string{"Size": "S", "Color": "Blue"},
+	})
+	if err != nil {
+		t.Fatalf("CreateVariant() error = %v", err)
+	}
+
+	v, err := service.UpdateVariant(ctx, seller, p.ID, created.ID, VariantDetails{SKU: "VASE-S-BLUE", Stock: 0})
+	if err != nil {
+		t.Fatalf("UpdateVariant() error = %v", err)
+	}
+	if v.Stock != 0 || v.IsAvailable || v.FinalPrice != p.Price || v.Options["Size"] != "S" {
+		t.Errorf("UpdateVariant() = %+v, want an unavailable S variant at the product price", v)
+	}
+	if _, err := service.UpdateVariant(ctx, seller, p.ID, 9, VariantDetails{SKU: "X"}); errorCode(err) != VariantNotFound {
+		t.Errorf("UpdateVariant() unknown variant error = %v, want %v", err, VariantNotFound)
+	}
+	if _, err := service.UpdateVariant(ctx, &common.UserClaims{ID: 6, Role: "SELLER"}, p.ID, created.ID,
+		VariantDetails{SKU: "X"}); errorCode(err) != common.PermissionDeniedErrorCode {
+		t.Errorf("UpdateVariant() by another seller error = %v, want %v", err, common.PermissionDeniedErrorCode)
+	}
+}
@@ -1,10 +1,26 @@
 package domain
 
-import "context"
+import (
+	"context"
+	"errors"
+)
+
+// ErrInvalidCursor is returned for a search cursor that is malformed or was
+// issued for another sort.
+var ErrInvalidCursor = errors.New("invalid search cursor")
+
+// LabelsMode tells whether a search matches products with any or with all of
+// the requested labels.
+type LabelsMode string
+
+const (
+	LabelsModeAny LabelsMode = "any"
+	LabelsModeAll LabelsMode = "all"
+)
 
 // SearchParams contains all possible search parameters
 type SearchParams struct {
-	Query      string   // Text search in name/description
+	Query      string   // Full-text search in name, description and labels
 	CategoryID string   // Filter by category
 	MinPrice   *float64 // Minimum price
 	MaxPrice   *float64 // Maximum price
@@ -13,22 +29,119 @@ type SearchParams struct {
 	Available  *bool    // Filter by availability
 	Limit      int      // Pagination limit
 	Offset     int      // Pagination offset
-	SortBy     string   // Field to sort by
+	SortBy     string   // Field to sort by, relevance by default with a query
 	SortDir    string   // Sort direction (asc/desc)
+	// LabelsMode matches products with any of the Labels by default, or
+	// with all of them.
+	LabelsMode LabelsMode
+	// ExcludeLabels leaves out products with any of these labels.
+	ExcludeLabels []string
+	// Cursor is the NextCursor or PrevCursor of a previous result, to page
+	// with the same filters and sort. It replaces Offset.
+	Cursor string
+	// SkipCount leaves out the total count, which is slow for broad searches.
+	SkipCount bool
+	// IncludeSuspendedShops also returns products of suspended shops, which
+	// are hidden from customers.
+	IncludeSuspendedShops bool
+	// Facets also counts all the matching products by category, label,
+	// seller, stock and price.
+	Facets bool
 }
 
 // SearchResult represents paginated search results
 type SearchResult struct {
-	Products    []*Product // List of products
-	TotalCount  int        // Total count of matching products
-	Limit       int        // Items per page
-	Offset      int        // Current page offset
-	HasNextPage bool       // Whether there are more results
+	Products    []*Product `json:"products"`      // List of products
+	TotalCount  int        `json:"total_count"`   // Total count of matching products, -1 when skipped
+	Limit       int        `json:"limit"`         // Items per page
+	Offset      int        `json:"offset"`        // Current page offset
+	HasNextPage bool       `json:"has_next_page"` // Whether there are more results
+	// NextCursor and PrevCursor page through the results sorted by price,
+	// creation date or name, and are empty on the last and first pages.
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+	// Snippets holds the highlighted matches of the query by product ID.
+	Snippets map[int64]Snippet `json:"snippets,omitempty"`
+	// Facets is set when requested by SearchParams.Facets.
+	Facets *Facets `json:"facets,omitempty"`
+}
+
+// Snippet is a product name and an excerpt of its description with the
+// words matching a search query marked. The text is HTML-escaped and the
+// matches are wrapped in <mark>.
+type Snippet struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// Facets count the products matching a search, with the same filters, by the
+// values they can be narrowed down by. Lists are sorted by count, largest
+// first.
+type Facets struct {
+	Categories []FacetCount  `json:"categories"`
+	Labels     []FacetCount  `json:"labels"`
+	Sellers    []SellerCount `json:"sellers"`
+	InStock    int           `json:"in_stock"`
+	OutOfStock int           `json:"out_of_stock"`
+	// Prices is a histogram of the product prices, in equal ranges from the
+	// lowest to the highest price.
+	Prices []PriceBucket `json:"prices"`
+}
+
+type FacetCount struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+type SellerCount struct {
+	SellerID int64 `json:"seller_id"`
+	Count    int   `json:"count"`
+}
+
+// PriceBucket counts the products priced from Min up to, but excluding, Max.
+// The last bucket includes Max.
+type PriceBucket struct {
+	Min   float64 `json:"min"`
+	Max   float64 `json:"max"`
+	Count int     `json:"count"`
+}
+
+// LabelCount is a label with the number of products using it.
+type LabelCount struct {
+	Label string `json:"label"`
+	Count int    `json:"count"`
 }
 
-// ProductRepository interface extension
+// ProductRepository interface extension. Deleted products are never returned.
 type ProductRepository interface {
 	CreateProduct(ctx context.Context, p *Product, images []Image) error
+	// GetProduct returns nil when there is no such product.
 	GetProduct(ctx context.Context, id int64) (*Product, error)
 	SearchProducts(ctx context.Context, params SearchParams) (*SearchResult, error)
+	// UpdateProduct stores the product details, leaving images and labels.
+	UpdateProduct(ctx context.Context, p *Product) error
+	// DeleteProduct soft deletes a product, keeping it for past orders.
+	DeleteProduct(ctx context.Context, id int64) error
+	// AddImages appends images after the existing ones and returns them with
+	// their IDs and positions.
+	AddImages(ctx context.Context, productID int64, images []Image) ([]Image, error)
+	// RemoveImage deletes an image and returns it, or nil when the product
+	// has no such image.
+	RemoveImage(ctx context.Context, productID, imageID int64) (*Image, error)
+	// ReorderImages sets the image positions in the order of imageIDs.
+	ReorderImages(ctx context.Context, productID int64, imageIDs []int64) error
+	ReplaceLabels(ctx context.Context, productID int64, labels []string) error
+	// ListLabels returns the labels of the products shown to customers, most
+	// used first.
+	ListLabels(ctx context.Context) ([]LabelCount, error)
+	// ReplaceOptions stores the options of a product, keeping the IDs of
+	// options and values matched by name, and returns them with their IDs.
+	ReplaceOptions(ctx context.Context, productID int64, options []Option) ([]Option, error)
+	// CreateVariant stores a variant with its option values and sets its ID.
+	// It fails with ErrDuplicateSKU when the SKU is taken.
+	CreateVariant(ctx context.Context, productID int64, v *Variant) error
+	// UpdateVariant stores the SKU, price, stock and availability of a
+	// variant. It fails with ErrDuplicateSKU when the SKU is taken.
+	UpdateVariant(ctx context.Context, productID int64, v *Variant) error
+	DeleteVariant(ctx context.Context, productID, variantID int64) error
 }
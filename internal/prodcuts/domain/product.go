@@ -1,5 +1,13 @@
 package domain
 
+import (
+	"errors"
+	"strings"
+)
+
+// ErrDuplicateSKU is returned when a variant SKU is used by another variant.
+var ErrDuplicateSKU = errors.New("sku already exists")
+
 type Product struct {
 	ID          int64    `json:"id"`
 	Name        string   `json:"name"`
@@ -13,9 +21,111 @@ type Product struct {
 	CreatedAt   string   `json:"created_at"`
 	UpdatedAt   string   `json:"updated_at"`
 	Labels      []string `json:"labels"`
+	// Options and Variants are set for products sold in several versions,
+	// such as sizes. Such products are bought by variant.
+	Options    []Option    `json:"options,omitempty"`
+	Variants   []Variant   `json:"variants,omitempty"`
+	PriceRange *PriceRange `json:"price_range,omitempty"`
+}
+
+// NormalizeLabel lowercases a label and collapses its whitespace, so labels
+// differing only in case or spacing are the same label.
+func NormalizeLabel(label string) string {
+	return strings.ToLower(strings.Join(strings.Fields(label), " "))
+}
+
+// NormalizeLabels normalizes labels, dropping blank and repeated ones.
+func NormalizeLabels(labels []string) []string {
+	normalized := make([]string, 0, len(labels))
+	seen := make(map[string]bool, len(labels))
+	for _, label := range labels {
+		label = NormalizeLabel(label)
+		if label == "" || seen[label] {
+			continue
+		}
+		seen[label] = true
+		normalized = append(normalized, label)
+	}
+	return normalized
 }
 
 type Image struct {
-	URL  string `json:"url"`
-	Type string `json:"type"` // "thumbnail", "main", "extra"
+	ID        int64  `json:"id"`
+	URL       string `json:"url"`
+	Type      string `json:"type"`                 // "thumbnail", "main", "extra"
+	Position  int    `json:"position"`             // Display order, lowest first
+	VariantID int64  `json:"variant_id,omitempty"` // Set for images of one variant
+}
+
+// Option is a way a product varies, such as Size, with the values its
+// variants choose from.
+type Option struct {
+	ID     int64         `json:"id"`
+	Name   string        `json:"name"`
+	Values []OptionValue `json:"values"`
+}
+
+type OptionValue struct {
+	ID    int64  `json:"id"`
+	Value string `json:"value"`
+}
+
+// Variant is a version of a product with one value of each product option.
+type Variant struct {
+	ID          int64    `json:"id"`
+	SKU         string   `json:"sku"`
+	Price       *float64 `json:"price,omitempty"` // Overrides the product price
+	FinalPrice  float64  `json:"final_price"`     // Price customers pay
+	Stock       int      `json:"stock"`
+	IsAvailable bool     `json:"is_available"`
+	// Options maps each option name to the value of the variant
+	Options        map[string]string `json:"options"`
+	OptionValueIDs []int64           `json:"-"`
+	Images         []Image           `json:"images,omitempty"`
+}
+
+// PriceRange is the lowest and highest price of the variants on sale.
+type PriceRange struct {
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
+}
+
+// Variant returns the variant with the given ID, or nil.
+func (p *Product) Variant(id int64) *Variant {
+	for i := range p.Variants {
+		if p.Variants[i].ID == id {
+			return &p.Variants[i]
+		}
+	}
+	return nil
+}
+
+// ResolveVariants sets the final price and images of each variant, and the
+// price range of the product. It runs once the options, variants and images
+// are loaded.
+func (p *Product) ResolveVariants() {
+	p.PriceRange = nil
+	for i := range p.Variants {
+		v := &p.Variants[i]
+		v.FinalPrice = p.Price
+		if v.Price != nil {
+			v.FinalPrice = *v.Price
+		}
+
+		v.Images = nil
+		for _, img := range p.Images {
+			if img.VariantID == v.ID {
+				v.Images = append(v.Images, img)
+			}
+		}
+
+		if !v.IsAvailable {
+			continue
+		}
+		if p.PriceRange == nil {
+			p.PriceRange = &PriceRange{Min: v.FinalPrice, Max: v.FinalPrice}
+		}
+		p.PriceRange.Min = min(p.PriceRange.Min, v.FinalPrice)
+		p.PriceRange.Max = max(p.PriceRange.Max, v.FinalPrice)
+	}
 }
@@ -2,6 +2,7 @@ package infra
 
 import (
 	"encoding/json"
+	"errors"
 	"mime/multipart"
 	"net/http"
 	"strconv"
@@ -11,6 +12,7 @@ import (
 	"yadwy-backend/internal/prodcuts/domain"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
 	"github.com/jmoiron/sqlx"
 	"go.uber.org/zap"
 )
@@ -18,6 +20,8 @@ import (
 const (
 	InvalidRequestBody = "invalid-request-body"
 	InvalidProductID   = "invalid-product-id"
+	InvalidImageID     = "invalid-image-id"
+	InvalidVariantID   = "invalid-variant-id"
 )
 
 type ProductHandler struct {
@@ -32,34 +36,110 @@ func NewProductHandler(service *application.ProductService, logger *zap.Logger)
 	}
 }
 
-func LoadProductsRoutes(b *sqlx.DB, logger *zap.Logger, jwt *common.JWTGenerator) http.Handler {
+func LoadProductsRoutes(b *sqlx.DB, logger *zap.Logger, jwt *common.JWTGenerator, policy *common.PermissionPolicy, files *common.FileService) http.Handler {
 	ar := chi.NewRouter()
 	repo := NewProductRepository(b)
-	files, _ := common.NewFileService("/home/nerd/images", "http://localhost:3000/images")
-	srv := application.NewProductService(repo, files, logger)
+	srv := application.NewProductService(repo, files, policy, logger)
 	h := NewProductHandler(srv, logger)
 
-	//ar.Use(common.GetAuthMiddlewareFunc(jwt))
 	ar.Get("/{id}", h.GetProduct)
-	ar.Post("/", h.CreateProduct)
 	ar.Get("/search", h.SearchProducts) // Add search endpoint
+	ar.Get("/labels", h.ListLabels)
+
+	// Seller routes, also open to API keys with the matching scope
+	ar.Group(func(r chi.Router) {
+		r.Use(common.GetAPIKeyAuthMiddlewareFunc(jwt))
+		r.Use(common.RequirePermission(policy, common.PermissionProductCreate))
+		r.Post("/", h.CreateProduct)
+		r.Put("/{id}", h.UpdateProduct)
+		r.Patch("/{id}", h.PatchProduct)
+		r.Delete("/{id}", h.DeleteProduct)
+		r.Post("/{id}/images", h.AddImages)
+		r.Delete("/{id}/images/{imageId}", h.RemoveImage)
+		r.Put("/{id}/images/order", h.ReorderImages)
+		r.Put("/{id}/labels", h.ReplaceLabels)
+		r.Put("/{id}/options", h.ReplaceOptions)
+		r.Post("/{id}/variants", h.CreateVariant)
+		r.Put("/{id}/variants/{variantId}", h.UpdateVariant)
+		r.Delete("/{id}/variants/{variantId}", h.DeleteVariant)
+	})
 	return ar
 }
 
 type createProductRequest struct {
-	Name        string   `json:"name" validate:"required"`
-	Description string   `json:"description"`
-	Price       float64  `json:"price" validate:"required,gt=0"`
-	CategoryID  string   `json:"category_id" validate:"required"`
-	SellerID    int64    `json:"seller_id" validate:"required"`
+	Name        string  `json:"name" validate:"required"`
+	Description string  `json:"description"`
+	Price       float64 `json:"price" validate:"required,gt=0"`
+	CategoryID  string  `json:"category_id" validate:"required"`
+	// SellerID defaults to the authenticated user, only admins may set it
+	SellerID    int64    `json:"seller_id"`
 	Stock       int      `json:"stock" validate:"required,gte=0"`
 	IsAvailable bool     `json:"is_available"`
 	Labels      []string `json:"labels"`
 }
 
+type updateProductRequest struct {
+	Name        string  `json:"name" validate:"required"`
+	Description string  `json:"description"`
+	Price       float64 `json:"price" validate:"required,gt=0"`
+	CategoryID  string  `json:"category_id" validate:"required"`
+	Stock       int     `json:"stock" validate:"gte=0"`
+	IsAvailable bool    `json:"is_available"`
+}
+
+// patchProductRequest changes only the fields present in the body
+type patchProductRequest struct {
+	Name        *string  `json:"name" validate:"omitempty,min=1"`
+	Description *string  `json:"description"`
+	Price       *float64 `json:"price" validate:"omitempty,gt=0"`
+	CategoryID  *string  `json:"category_id" validate:"omitempty,min=1"`
+	Stock       *int     `json:"stock" validate:"omitempty,gte=0"`
+	IsAvailable *bool    `json:"is_available"`
+}
+
+type reorderImagesRequest struct {
+	ImageIDs []int64 `json:"image_ids" validate:"required"`
+}
+
+type replaceLabelsRequest struct {
+	Labels []string `json:"labels"`
+}
+
+type optionRequest struct {
+	Name   string   `json:"name" validate:"required" example:"Size"`
+	Values []string `json:"values" validate:"required,min=1" example:"S,M,L"`
+}
+
+type replaceOptionsRequest struct {
+	Options []optionRequest `json:"options" validate:"dive"`
+}
+
+type variantDetailsRequest struct {
+	SKU         string   `json:"sku" validate:"required" example:"VASE-BLUE-L"`
+	Price       *float64 `json:"price" validate:"omitempty,gt=0"` // Omit to use the product price
+	Stock       int      `json:"stock" validate:"gte=0"`
+	IsAvailable bool     `json:"is_available"`
+}
+
+type createVariantRequest struct {
+	variantDetailsRequest
+	// Options maps each option name to the value of the variant
+	Options map[string]string `json:"options" validate:"required"`
+}
+
+func (req variantDetailsRequest) toDetails() application.VariantDetails {
+	return application.VariantDetails{
+		SKU:         req.SKU,
+		Price:       req.Price,
+		Stock:       req.Stock,
+		IsAvailable: req.IsAvailable,
+	}
+}
+
 // @Summary Create a new product
-// @Description Create a new product with images
+// @Description Create a new product with images, listed for the authenticated seller
 // @Tags products
+// @Security BearerAuth
 // @Accept multipart/form-data
 // @Produce json
 // @Param product formData string true "Product data in JSON format"
@@ -68,10 +148,18 @@ type createProductRequest struct {
 // @Param extra_images formData file false "Extra product images"
 // @Success 201 {object} domain.Product
 // @Failure 400 {object} common.ErrorResponse "Invalid input"
+// @Failure 401 {object} common.ErrorResponse "Unauthorized"
+// @Failure 403 {object} common.ErrorResponse "Forbidden - Sellers only, or listing for another seller"
 // @Failure 500 {object} common.ErrorResponse "Server error"
 // @Router /products [post]
 func (h *ProductHandler) CreateProduct(w http.ResponseWriter, r *http.Request) {
-	err := r.ParseMultipartForm(10 << 20) // 10 MB max
+	claims, err := common.GetLoggedInUser(r)
+	if err != nil {
+		common.SendError(w, http.StatusUnauthorized, "unauthorized", "user not authenticated")
+		return
+	}
+
+	err = r.ParseMultipartForm(10 << 20) // 10 MB max
 	if err != nil {
 		common.SendError(w, http.StatusBadRequest, InvalidRequestBody, "Failed to parse multipart form")
 		return
@@ -91,11 +179,7 @@ func (h *ProductHandler) CreateProduct(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	mainImages := r.MultipartForm.File["main_images"]
-	thumbnailImages := r.MultipartForm.File["thumbnail_images"]
-	extraImages := r.MultipartForm.File["extra_images"]
-
-	if len(mainImages) == 0 && len(thumbnailImages) == 0 {
+	if len(r.MultipartForm.File["main_images"]) == 0 && len(r.MultipartForm.File["thumbnail_images"]) == 0 {
 		common.SendError(w, http.StatusBadRequest, InvalidRequestBody, "At least one main or thumbnail image is required")
 		return
 	}
@@ -111,24 +195,10 @@ func (h *ProductHandler) CreateProduct(w http.ResponseWriter, r *http.Request) {
 		Labels:      req.Labels,
 	}
 
-	var allImages []*multipart.FileHeader
-	for _, img := range mainImages {
-		img.Filename = "main:" + img.Filename
-		allImages = append(allImages, img)
-	}
-	for _, img := range thumbnailImages {
-		img.Filename = "thumbnail:" + img.Filename
-		allImages = append(allImages, img)
-	}
-	for _, img := range extraImages {
-		img.Filename = "extra:" + img.Filename
-		allImages = append(allImages, img)
-	}
-
-	err = h.service.CreateProduct(r.Context(), product, allImages)
+	err = h.service.CreateProduct(r.Context(), claims, product, formImages(r))
 	if err != nil {
 		h.logger.Error("Failed to create product", zap.Error(err))
-		common.SendError(w, http.StatusInternalServerError, application.FailedToCreateProduct, err.Error())
+		handleError(w, err)
 		return
 	}
 
@@ -160,7 +230,7 @@ func (h *ProductHandler) GetProduct(w http.ResponseWriter, r *http.Request) {
 	product, err := h.service.GetProduct(r.Context(), id)
 	if err != nil {
 		h.logger.Error("Failed to get product", zap.Error(err))
-		common.SendError(w, http.StatusInternalServerError, application.FailedToRetrieveProduct, err.Error())
+		handleError(w, err)
 		return
 	}
 
@@ -172,7 +242,7 @@ func (h *ProductHandler) GetProduct(w http.ResponseWriter, r *http.Request) {
 }
 
 // @Summary Search products
-// @Description Search products with various filters
+// @Description Search products with various filters. The query is a full-text search of the names, descriptions and labels in Arabic and English, with "quoted phrases", or and -excluded words. Matches are sorted by relevance unless another sort is given, and the snippets mark the matching words with <mark>. Sorts by price, creation date or name return cursors, which page without skipping or repeating products added meanwhile.
 // @Tags products
 // @Produce json
 // @Param query query string false "Search query"
@@ -181,12 +251,17 @@ func (h *ProductHandler) GetProduct(w http.ResponseWriter, r *http.Request) {
 // @Param max_price query number false "Maximum price"
 // @Param seller_id query integer false "Seller ID"
 // @Param available query boolean false "Product availability"
-// @Param labels query string false "Comma-separated list of labels"
-// @Param sort_by query string false "Sort field (name, price, created_at)"
+// @Param labels query string false "Comma-separated list of labels, matched regardless of case and spacing"
+// @Param labels_mode query string false "Match products with any (default) or all of the labels"
+// @Param exclude_labels query string false "Comma-separated list of labels of products to leave out"
+// @Param sort_by query string false "Sort field (relevance, name, price, created_at)"
 // @Param sort_dir query string false "Sort direction (asc, desc)"
 // @Param limit query integer false "Number of items to return (default: 10)"
 // @Param offset query integer false "Number of items to skip (default: 0)"
-// @Success 200 {array} domain.Product
+// @Param cursor query string false "next_cursor or prev_cursor of a previous page, with the same filters and sort, instead of offset"
+// @Param skip_count query boolean false "Leave out the total count, returning total_count as -1"
+// @Param facets query boolean false "Also count the matching products by category, label, seller, stock and price"
+// @Success 200 {object} domain.SearchResult
 // @Failure 400 {object} common.ErrorResponse "Invalid parameters"
 // @Failure 500 {object} common.ErrorResponse "Server error"
 // @Router /products/search [get]
@@ -201,6 +276,7 @@ func (h *ProductHandler) SearchProducts(w http.ResponseWriter, r *http.Request)
 		Offset:     0,  // Default offset
 		SortBy:     query.Get("sort_by"),
 		SortDir:    query.Get("sort_dir"),
+		Cursor:     query.Get("cursor"),
 	}
 
 	if limitStr := query.Get("limit"); limitStr != "" {
@@ -246,11 +322,24 @@ func (h *ProductHandler) SearchProducts(w http.ResponseWriter, r *http.Request)
 	if labelsStr := query.Get("labels"); labelsStr != "" {
 		params.Labels = strings.Split(labelsStr, ",")
 	}
+	params.LabelsMode = domain.LabelsMode(query.Get("labels_mode"))
+
+	if excludeStr := query.Get("exclude_labels"); excludeStr != "" {
+		params.ExcludeLabels = strings.Split(excludeStr, ",")
+	}
+
+	if facetsStr := query.Get("facets"); facetsStr != "" {
+		params.Facets = facetsStr == "true" || facetsStr == "1"
+	}
+
+	if skipCountStr := query.Get("skip_count"); skipCountStr != "" {
+		params.SkipCount = skipCountStr == "true" || skipCountStr == "1"
+	}
 
 	result, err := h.service.SearchProducts(r.Context(), params)
 	if err != nil {
 		h.logger.Error("Failed to search products", zap.Error(err))
-		common.SendError(w, http.StatusInternalServerError, application.FailedToSearchProducts, err.Error())
+		handleError(w, err)
 		return
 	}
 
@@ -260,3 +349,512 @@ func (h *ProductHandler) SearchProducts(w http.ResponseWriter, r *http.Request)
 		return
 	}
 }
+
+// @Summary List product labels
+// @Description List the labels of the products shown to customers, with the number of products having each, most used first
+// @Tags products
+// @Produce json
+// @Success 200 {array} domain.LabelCount
+// @Failure 500 {object} common.ErrorResponse "Server error"
+// @Router /products/labels [get]
+func (h *ProductHandler) ListLabels(w http.ResponseWriter, r *http.Request) {
+	labels, err := h.service.ListLabels(r.Context())
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	if err := common.Encode(w, http.StatusOK, labels); err != nil {
+		h.logger.Error("Failed to encode labels", zap.Error(err))
+		common.SendError(w, http.StatusInternalServerError, "failed-to-encode-labels", err.Error())
+		return
+	}
+}
+
+// @Summary Update a product
+// @Description Replace the details of a product. Only the owning seller or an admin can change a product.
+// @Tags products
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path integer true "Product ID"
+// @Param request body updateProductRequest true "Product details"
+// @Success 200 {object} domain.Product
+// @Failure 400 {object} common.ErrorResponse "Invalid input"
+// @Failure 403 {object} common.ErrorResponse "Not the owner of the product"
+// @Failure 404 {object} common.ErrorResponse "Product not found"
+// @Router /products/{id} [put]
+func (h *ProductHandler) UpdateProduct(w http.ResponseWriter, r *http.Request) {
+	claims, id, ok := userAndProductID(w, r)
+	if !ok {
+		return
+	}
+
+	req, err := common.DecodeAndValidate[updateProductRequest](r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	product, err := h.service.UpdateProduct(r.Context(), claims, id, application.ProductUpdate{
+		Name:        &req.Name,
+		Description: &req.Description,
+		Price:       &req.Price,
+		CategoryID:  &req.CategoryID,
+		Stock:       &req.Stock,
+		IsAvailable: &req.IsAvailable,
+	})
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	if err := common.Encode(w, http.StatusOK, product); err != nil {
+		handleError(w, err)
+		return
+	}
+}
+
+// @Summary Patch a product
+// @Description Change some details of a product, fields missing from the body are kept
+// @Tags products
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path integer true "Product ID"
+// @Param request body patchProductRequest true "Product details to change"
+// @Success 200 {object} domain.Product
+// @Failure 400 {object} common.ErrorResponse "Invalid input"
+// @Failure 403 {object} common.ErrorResponse "Not the owner of the product"
+// @Failure 404 {object} common.ErrorResponse "Product not found"
+// @Router /products/{id} [patch]
+func (h *ProductHandler) PatchProduct(w http.ResponseWriter, r *http.Request) {
+	claims, id, ok := userAndProductID(w, r)
+	if !ok {
+		return
+	}
+
+	req, err := common.DecodeAndValidate[patchProductRequest](r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	product, err := h.service.UpdateProduct(r.Context(), claims, id, application.ProductUpdate{
+		Name:        req.Name,
+		Description: req.Description,
+		Price:       req.Price,
+		CategoryID:  req.CategoryID,
+		Stock:       req.Stock,
+		IsAvailable: req.IsAvailable,
+	})
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	if err := common.Encode(w, http.StatusOK, product); err != nil {
+		handleError(w, err)
+		return
+	}
+}
+
+// @Summary Delete a product
+// @Description Remove a product from the catalog. It is kept for past orders.
+// @Tags products
+// @Security BearerAuth
+// @Param id path integer true "Product ID"
+// @Success 204 "Product deleted"
+// @Failure 403 {object} common.ErrorResponse "Not the owner of the product"
+// @Failure 404 {object} common.ErrorResponse "Product not found"
+// @Router /products/{id} [delete]
+func (h *ProductHandler) DeleteProduct(w http.ResponseWriter, r *http.Request) {
+	claims, id, ok := userAndProductID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteProduct(r.Context(), claims, id); err != nil {
+		handleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// @Summary Add product images
+// @Description Upload images and add them after the current images of a product
+// @Tags products
+// @Security BearerAuth
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path integer true "Product ID"
+// @Param variant_id formData integer false "Variant the images show, omit for images of the product"
+// @Param main_images formData file false "Main product images"
+// @Param thumbnail_images formData file false "Thumbnail images"
+// @Param extra_images formData file false "Extra product images"
+// @Success 201 {array} domain.Image
+// @Failure 400 {object} common.ErrorResponse "Invalid input"
+// @Failure 403 {object} common.ErrorResponse "Not the owner of the product"
+// @Failure 404 {object} common.ErrorResponse "Product not found"
+// @Router /products/{id}/images [post]
+func (h *ProductHandler) AddImages(w http.ResponseWriter, r *http.Request) {
+	claims, id, ok := userAndProductID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := r.ParseMultipartForm(10 << 20); err != nil {
+		common.SendError(w, http.StatusBadRequest, InvalidRequestBody, "Failed to parse multipart form")
+		return
+	}
+	images := formImages(r)
+	if len(images) == 0 {
+		common.SendError(w, http.StatusBadRequest, InvalidRequestBody, "At least one image is required")
+		return
+	}
+	var variantID int64
+	if v := r.FormValue("variant_id"); v != "" {
+		var err error
+		if variantID, err = strconv.ParseInt(v, 10, 64); err != nil {
+			common.SendError(w, http.StatusBadRequest, InvalidVariantID, "Invalid variant ID")
+			return
+		}
+	}
+
+	added, err := h.service.AddImages(r.Context(), claims, id, variantID, images)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	if err := common.Encode(w, http.StatusCreated, added); err != nil {
+		handleError(w, err)
+		return
+	}
+}
+
+// @Summary Remove a product image
+// @Description Remove an image from a product and delete its file
+// @Tags products
+// @Security BearerAuth
+// @Param id path integer true "Product ID"
+// @Param imageId path integer true "Image ID"
+// @Success 204 "Image removed"
+// @Failure 403 {object} common.ErrorResponse "Not the owner of the product"
+// @Failure 404 {object} common.ErrorResponse "Product or image not found"
+// @Router /products/{id}/images/{imageId} [delete]
+func (h *ProductHandler) RemoveImage(w http.ResponseWriter, r *http.Request) {
+	claims, id, ok := userAndProductID(w, r)
+	if !ok {
+		return
+	}
+	imageID, err := strconv.ParseInt(chi.URLParam(r, "imageId"), 10, 64)
+	if err != nil {
+		common.SendError(w, http.StatusBadRequest, InvalidImageID, "Invalid image ID")
+		return
+	}
+
+	if err := h.service.RemoveImage(r.Context(), claims, id, imageID); err != nil {
+		handleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// @Summary Reorder product images
+// @Description Set the display order of the images of a product. The order must list every image once.
+// @Tags products
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path integer true "Product ID"
+// @Param request body reorderImagesRequest true "Image IDs in display order"
+// @Success 200 {array} domain.Image
+// @Failure 400 {object} common.ErrorResponse "Invalid order"
+// @Failure 403 {object} common.ErrorResponse "Not the owner of the product"
+// @Failure 404 {object} common.ErrorResponse "Product not found"
+// @Router /products/{id}/images/order [put]
+func (h *ProductHandler) ReorderImages(w http.ResponseWriter, r *http.Request) {
+	claims, id, ok := userAndProductID(w, r)
+	if !ok {
+		return
+	}
+
+	req, err := common.DecodeAndValidate[reorderImagesRequest](r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	images, err := h.service.ReorderImages(r.Context(), claims, id, req.ImageIDs)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	if err := common.Encode(w, http.StatusOK, images); err != nil {
+		handleError(w, err)
+		return
+	}
+}
+
+// @Summary Replace product labels
+// @Description Replace every label of a product
+// @Tags products
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path integer true "Product ID"
+// @Param request body replaceLabelsRequest true "New labels"
+// @Success 200 {array} string
+// @Failure 400 {object} common.ErrorResponse "Invalid labels"
+// @Failure 403 {object} common.ErrorResponse "Not the owner of the product"
+// @Failure 404 {object} common.ErrorResponse "Product not found"
+// @Router /products/{id}/labels [put]
+func (h *ProductHandler) ReplaceLabels(w http.ResponseWriter, r *http.Request) {
+	claims, id, ok := userAndProductID(w, r)
+	if !ok {
+		return
+	}
+
+	req, err := common.DecodeAndValidate[replaceLabelsRequest](r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	labels, err := h.service.ReplaceLabels(r.Context(), claims, id, req.Labels)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	if err := common.Encode(w, http.StatusOK, labels); err != nil {
+		handleError(w, err)
+		return
+	}
+}
+
+// @Summary Set product options
+// @Description Set the options of a product, such as Size: S, M, L, that its variants choose from. Once the product has variants, options can only be added or removed after deleting them, and values only when no variant uses them.
+// @Tags products
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path integer true "Product ID"
+// @Param request body replaceOptionsRequest true "Options in display order"
+// @Success 200 {array} domain.Option
+// @Failure 400 {object} common.ErrorResponse "Invalid options"
+// @Failure 403 {object} common.ErrorResponse "Not the owner of the product"
+// @Failure 404 {object} common.ErrorResponse "Product not found"
+// @Failure 409 {object} common.ErrorResponse "An option or value is used by a variant"
+// @Router /products/{id}/options [put]
+func (h *ProductHandler) ReplaceOptions(w http.ResponseWriter, r *http.Request) {
+	claims, id, ok := userAndProductID(w, r)
+	if !ok {
+		return
+	}
+
+	req, err := common.DecodeAndValidate[replaceOptionsRequest](r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	input := make([]application.OptionInput, len(req.Options))
+	for i, opt := range req.Options {
+		input[i] = application.OptionInput{Name: opt.Name, Values: opt.Values}
+	}
+
+	options, err := h.service.ReplaceOptions(r.Context(), claims, id, input)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	if err := common.Encode(w, http.StatusOK, options); err != nil {
+		handleError(w, err)
+		return
+	}
+}
+
+// @Summary Add a product variant
+// @Description Add a variant with a value of each product option, its own SKU, stock and an optional price override
+// @Tags products
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path integer true "Product ID"
+// @Param request body createVariantRequest true "Variant"
+// @Success 201 {object} domain.Variant
+// @Failure 400 {object} common.ErrorResponse "Invalid variant"
+// @Failure 403 {object} common.ErrorResponse "Not the owner of the product"
+// @Failure 404 {object} common.ErrorResponse "Product not found"
+// @Failure 409 {object} common.ErrorResponse "The SKU or the options are used by another variant"
+// @Router /products/{id}/variants [post]
+func (h *ProductHandler) CreateVariant(w http.ResponseWriter, r *http.Request) {
+	claims, id, ok := userAndProductID(w, r)
+	if !ok {
+		return
+	}
+
+	req, err := common.DecodeAndValidate[createVariantRequest](r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	variant, err := h.service.CreateVariant(r.Context(), claims, id, application.VariantInput{
+		VariantDetails: req.toDetails(),
+		Options:        req.Options,
+	})
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	if err := common.Encode(w, http.StatusCreated, variant); err != nil {
+		handleError(w, err)
+		return
+	}
+}
+
+// @Summary Update a product variant
+// @Description Replace the SKU, price override, stock and availability of a variant. Its options cannot change.
+// @Tags products
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path integer true "Product ID"
+// @Param variantId path integer true "Variant ID"
+// @Param request body variantDetailsRequest true "Variant details"
+// @Success 200 {object} domain.Variant
+// @Failure 400 {object} common.ErrorResponse "Invalid variant"
+// @Failure 403 {object} common.ErrorResponse "Not the owner of the product"
+// @Failure 404 {object} common.ErrorResponse "Product or variant not found"
+// @Failure 409 {object} common.ErrorResponse "The SKU is used by another variant"
+// @Router /products/{id}/variants/{variantId} [put]
+func (h *ProductHandler) UpdateVariant(w http.ResponseWriter, r *http.Request) {
+	claims, id, variantID, ok := userProductAndVariantID(w, r)
+	if !ok {
+		return
+	}
+
+	req, err := common.DecodeAndValidate[variantDetailsRequest](r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	variant, err := h.service.UpdateVariant(r.Context(), claims, id, variantID, req.toDetails())
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	if err := common.Encode(w, http.StatusOK, variant); err != nil {
+		handleError(w, err)
+		return
+	}
+}
+
+// @Summary Delete a product variant
+// @Description Delete a variant and remove it from carts. Its images are kept as images of the product.
+// @Tags products
+// @Security BearerAuth
+// @Param id path integer true "Product ID"
+// @Param variantId path integer true "Variant ID"
+// @Success 204 "Variant deleted"
+// @Failure 403 {object} common.ErrorResponse "Not the owner of the product"
+// @Failure 404 {object} common.ErrorResponse "Product or variant not found"
+// @Router /products/{id}/variants/{variantId} [delete]
+func (h *ProductHandler) DeleteVariant(w http.ResponseWriter, r *http.Request) {
+	claims, id, variantID, ok := userProductAndVariantID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteVariant(r.Context(), claims, id, variantID); err != nil {
+		handleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// formImages returns the uploaded images, their type prefixed to the file
+// name as the service expects.
+func formImages(r *http.Request) []*multipart.FileHeader {
+	var images []*multipart.FileHeader
+	for _, imageType := range []string{"main", "thumbnail", "extra"} {
+		for _, img := range r.MultipartForm.File[imageType+"_images"] {
+			img.Filename = imageType + ":" + img.Filename
+			images = append(images, img)
+		}
+	}
+	return images
+}
+
+// userAndProductID reads the authenticated user and the {id} path parameter,
+// writing the error response on failure.
+func userAndProductID(w http.ResponseWriter, r *http.Request) (*common.UserClaims, int64, bool) {
+	claims, err := common.GetLoggedInUser(r)
+	if err != nil {
+		common.SendError(w, http.StatusUnauthorized, "unauthorized", "user not authenticated")
+		return nil, 0, false
+	}
+
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		common.SendError(w, http.StatusBadRequest, InvalidProductID, "Invalid product ID")
+		return nil, 0, false
+	}
+	return claims, id, true
+}
+
+// userProductAndVariantID reads the authenticated user and the {id} and
+// {variantId} path parameters, writing the error response on failure.
+func userProductAndVariantID(w http.ResponseWriter, r *http.Request) (*common.UserClaims, int64, int64, bool) {
+	claims, id, ok := userAndProductID(w, r)
+	if !ok {
+		return nil, 0, 0, false
+	}
+
+	variantID, err := strconv.ParseInt(chi.URLParam(r, "variantId"), 10, 64)
+	if err != nil {
+		common.SendError(w, http.StatusBadRequest, InvalidVariantID, "Invalid variant ID")
+		return nil, 0, 0, false
+	}
+	return claims, id, variantID, true
+}
+
+func handleError(w http.ResponseWriter, err error) {
+	var appErr *common.Error
+	if errors.As(err, &appErr) {
+		switch appErr.Code() {
+		case application.ProductNotFound, application.ProductImageNotFound, application.VariantNotFound:
+			common.SendError(w, http.StatusNotFound, string(appErr.Code()), appErr.Error())
+		case application.InvalidProduct, application.InvalidImageOrder, application.InvalidOptions,
+			application.InvalidVariant, application.InvalidCursor, application.InvalidLabelsMode:
+			common.SendError(w, http.StatusBadRequest, string(appErr.Code()), appErr.Error())
+		case application.OptionsInUse, application.VariantExists, application.SKUTaken:
+			common.SendError(w, http.StatusConflict, string(appErr.Code()), appErr.Error())
+		case common.AuthHeaderMissingErrorCode:
+			common.SendError(w, http.StatusUnauthorized, string(appErr.Code()), appErr.Error())
+		case common.PermissionDeniedErrorCode:
+			common.SendError(w, http.StatusForbidden, string(appErr.Code()), appErr.Error())
+		default:
+			common.SendError(w, http.StatusInternalServerError, string(appErr.Code()), appErr.Error())
+		}
+		return
+	}
+
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		common.SendError(w, http.StatusBadRequest, "validation_error", common.FormatValidationError(err))
+		return
+	}
+
+	common.SendError(w, http.StatusInternalServerError, "internal_server_error", err.Error())
+}
@@ -2,13 +2,28 @@ package infra
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"slices"
 	"strings"
 	"time"
 	"yadwy-backend/internal/prodcuts/domain"
 )
 
+// productColumns are the columns of productDB. The search columns of the
+// products table are only read by SearchProducts.
+const productColumns = `p.id, p.name, p.description, p.price, p.category_id, p.seller_id, p.stock,
+       p.is_available, p.created_at, p.updated_at, p.deleted_at`
+
+// Options of ts_headline for the snippets of search results.
+const (
+	nameHeadline        = "StartSel=<mark>, StopSel=</mark>, HighlightAll=true"
+	descriptionHeadline = "StartSel=<mark>, StopSel=</mark>, MinWords=10, MaxWords=30, MaxFragments=2, FragmentDelimiter=\" … \""
+)
+
 type ProductRepositoryImpl struct {
 	db *sqlx.DB
 }
@@ -18,16 +33,26 @@ func NewProductRepository(db *sqlx.DB) domain.ProductRepository {
 }
 
 type productDB struct {
-	ID          int64   `db:"id"`
-	Name        string  `db:"name"`
-	Description string  `db:"description"`
-	Price       float64 `db:"price"`
-	CategoryID  string  `db:"category_id"`
-	SellerID    int64   `db:"seller_id"`
-	Stock       int     `db:"stock"`
-	IsAvailable bool    `db:"is_available"`
-	CreatedAt   string  `db:"created_at"`
-	UpdatedAt   string  `db:"updated_at"`
+	ID          int64      `db:"id"`
+	Name        string     `db:"name"`
+	Description string     `db:"description"`
+	Price       float64    `db:"price"`
+	CategoryID  string     `db:"category_id"`
+	SellerID    int64      `db:"seller_id"`
+	Stock       int        `db:"stock"`
+	IsAvailable bool       `db:"is_available"`
+	CreatedAt   string     `db:"created_at"`
+	UpdatedAt   string     `db:"updated_at"`
+	DeletedAt   *time.Time `db:"deleted_at"`
+}
+
+// searchRowDB is a product row of a text search, with its relevance and
+// snippets.
+type searchRowDB struct {
+	productDB
+	Rank               float64 `db:"rank"`
+	NameSnippet        string  `db:"name_snippet"`
+	DescriptionSnippet string  `db:"description_snippet"`
 }
 
 type imageDB struct {
@@ -35,29 +60,86 @@ type imageDB struct {
 	ProductID int64     `db:"product_id"`
 	ImageURL  string    `db:"image_url"`
 	ImageType string    `db:"image_type"`
+	Position  int       `db:"position"`
+	VariantID *int64    `db:"variant_id"`
 	CreatedAt time.Time `db:"created_at"`
 }
 
+type labelDB struct {
+	ProductID int64  `db:"product_id"`
+	Name      string `db:"label_name"`
+}
+
+type optionValueDB struct {
+	ProductID  int64  `db:"product_id"`
+	OptionID   int64  `db:"option_id"`
+	OptionName string `db:"option_name"`
+	ValueID    int64  `db:"value_id"`
+	Value      string `db:"value"`
+}
+
+type variantDB struct {
+	ID          int64    `db:"id"`
+	ProductID   int64    `db:"product_id"`
+	SKU         string   `db:"sku"`
+	Price       *float64 `db:"price"`
+	Stock       int      `db:"stock"`
+	IsAvailable bool     `db:"is_available"`
+}
+
+type variantValueDB struct {
+	VariantID     int64 `db:"variant_id"`
+	OptionValueID int64 `db:"option_value_id"`
+}
+
+func (pdb productDB) toDomain() *domain.Product {
+	return &domain.Product{
+		ID:          pdb.ID,
+		Name:        pdb.Name,
+		Description: pdb.Description,
+		Price:       pdb.Price,
+		CategoryID:  pdb.CategoryID,
+		SellerID:    pdb.SellerID,
+		Stock:       pdb.Stock,
+		IsAvailable: pdb.IsAvailable,
+		CreatedAt:   pdb.CreatedAt,
+		UpdatedAt:   pdb.UpdatedAt,
+	}
+}
+
+func (img imageDB) toDomain() domain.Image {
+	image := domain.Image{
+		ID:       img.ID,
+		URL:      img.ImageURL,
+		Type:     img.ImageType,
+		Position: img.Position,
+	}
+	if img.VariantID != nil {
+		image.VariantID = *img.VariantID
+	}
+	return image
+}
+
 func (r *ProductRepositoryImpl) CreateProduct(ctx context.Context, p *domain.Product, images []domain.Image) error {
 	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
 		return err
 	}
 
-	query := `INSERT INTO products (name, description, price, category_id, seller_id, stock, is_available)
-              VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`
+	query := `INSERT INTO products (name, description, price, category_id, seller_id, stock, is_available, search_labels)
+              VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id`
 
 	err = tx.QueryRowxContext(ctx, query, p.Name, p.Description, p.Price, p.CategoryID,
-		p.SellerID, p.Stock, p.IsAvailable).Scan(&p.ID)
+		p.SellerID, p.Stock, p.IsAvailable, strings.Join(p.Labels, " ")).Scan(&p.ID)
 	if err != nil {
 		tx.Rollback()
 		return err
 	}
 
-	for _, img := range images {
-		query := `INSERT INTO product_images (product_id, image_url, image_type)
-                 VALUES ($1, $2, $3)`
-		_, err = tx.ExecContext(ctx, query, p.ID, img.URL, img.Type)
+	for i, img := range images {
+		query := `INSERT INTO product_images (product_id, image_url, image_type, position)
+                 VALUES ($1, $2, $3, $4)`
+		_, err = tx.ExecContext(ctx, query, p.ID, img.URL, img.Type, i)
 		if err != nil {
 			tx.Rollback()
 			return err
@@ -81,230 +163,480 @@ func (r *ProductRepositoryImpl) CreateProduct(ctx context.Context, p *domain.Pro
 func (r *ProductRepositoryImpl) GetProduct(ctx context.Context, id int64) (*domain.Product, error) {
 	var pdb productDB
 	err := r.db.GetContext(ctx, &pdb,
-		"SELECT * FROM products WHERE id = $1", id)
+		"SELECT "+productColumns+" FROM products p WHERE p.id = $1 AND p.deleted_at IS NULL", id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	product := &domain.Product{
-		ID:          pdb.ID,
-		Name:        pdb.Name,
-		Description: pdb.Description,
-		Price:       pdb.Price,
-		CategoryID:  pdb.CategoryID,
-		SellerID:    pdb.SellerID,
-		Stock:       pdb.Stock,
-		IsAvailable: pdb.IsAvailable,
-		CreatedAt:   pdb.CreatedAt,
-		UpdatedAt:   pdb.UpdatedAt,
+	product := pdb.toDomain()
+	if err := r.loadDetails(ctx, []*domain.Product{product}); err != nil {
+		return nil, err
 	}
 
-	rows, err := r.db.QueryxContext(ctx,
-		"SELECT * FROM product_images WHERE product_id = $1", id)
+	return product, nil
+}
+
+func (r *ProductRepositoryImpl) SearchProducts(ctx context.Context, params domain.SearchParams) (*domain.SearchResult, error) {
+	filter := newSearchFilter(params)
+	textSearch := filter.textQuery != ""
+
+	// Start building the query
+	query := "SELECT " + productColumns
+	if textSearch {
+		// Snippets are marked in the language of the query
+		config := textSearchConfig(params.Query)
+		query += fmt.Sprintf(`, ts_rank(p.search_vector, %[1]s) AS rank,
+       ts_headline('%[2]s', %[6]s, websearch_to_tsquery('%[2]s', %[3]s), '%[4]s') AS name_snippet,
+       ts_headline('%[2]s', %[7]s, websearch_to_tsquery('%[2]s', %[3]s), '%[5]s') AS description_snippet`,
+			filter.tsQuery(), config, filter.textQuery, nameHeadline, descriptionHeadline,
+			escapeHTML("p.name"), escapeHTML("COALESCE(p.description, '')"))
+	}
+	query += " FROM products p" + filter.where()
+	countQuery := "SELECT COUNT(*) FROM products p" + filter.where()
+
+	// Add keyset pagination
+	order := newSearchOrder(params, textSearch)
+	args := slices.Clip(filter.args)
+	var cursor *searchCursor
+	if params.Cursor != "" {
+		var err error
+		if cursor, err = decodeSearchCursor(params.Cursor, order); err != nil {
+			return nil, err
+		}
+		args = append(args, cursor.Value, cursor.ID)
+		query += fmt.Sprintf(" AND %s", cursor.condition(order, len(args)-1, len(args)))
+	}
+	backward := cursor != nil && cursor.Before
+
+	// Add sorting, reversed to page backwards
+	query += " ORDER BY " + order.clause(backward)
+
+	// Add pagination
+	limit := 10 // Default limit
+	if params.Limit > 0 {
+		limit = params.Limit
+	}
+
+	offset := 0 // Default offset
+	if params.Offset > 0 && cursor == nil {
+		offset = params.Offset
+	}
+
+	// Fetch one more product to tell if there is another page
+	args = append(args, limit+1, offset)
+	query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+
+	// Execute count query first
+	totalCount := -1
+	if !params.SkipCount {
+		err := r.db.GetContext(ctx, &totalCount, countQuery, filter.args...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count search results: %w", err)
+		}
+	}
+
+	// Execute main query
+	rows, err := r.db.QueryxContext(ctx, query, args...)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to search products: %w", err)
 	}
 	defer rows.Close()
 
+	// Parse results
+	var found []searchRowDB
 	for rows.Next() {
-		var img imageDB
-		if err := rows.StructScan(&img); err != nil {
+		var row searchRowDB
+		if err := rows.StructScan(&row); err != nil {
+			return nil, fmt.Errorf("failed to scan product row: %w", err)
+		}
+		found = append(found, row)
+	}
+	hasMore := len(found) > limit
+	if hasMore {
+		found = found[:limit]
+	}
+	if backward {
+		slices.Reverse(found)
+	}
+
+	products := []*domain.Product{}
+	var snippets map[int64]domain.Snippet
+	if textSearch {
+		snippets = map[int64]domain.Snippet{}
+	}
+	for _, row := range found {
+		pdb := row.productDB
+		if textSearch {
+			snippets[pdb.ID] = domain.Snippet{Name: row.NameSnippet, Description: row.DescriptionSnippet}
+		}
+
+		products = append(products, pdb.toDomain())
+	}
+
+	if err := r.loadDetails(ctx, products); err != nil {
+		return nil, err
+	}
+
+	result := &domain.SearchResult{
+		Products:    products,
+		TotalCount:  totalCount,
+		Limit:       limit,
+		Offset:      offset,
+		HasNextPage: hasMore || backward,
+		Snippets:    snippets,
+	}
+
+	// A backward page always has a next page, the one it was reached from
+	if order.keyset() && len(found) > 0 {
+		if hasMore || backward {
+			result.NextCursor = newSearchCursor(order, found[len(found)-1].productDB, false).encode()
+		}
+		if (hasMore && backward) || (!backward && (cursor != nil || offset > 0)) {
+			result.PrevCursor = newSearchCursor(order, found[0].productDB, true).encode()
+		}
+	}
+
+	if params.Facets {
+		if result.Facets, err = r.searchFacets(ctx, filter); err != nil {
 			return nil, err
 		}
-		product.Images = append(product.Images, domain.Image{
-			URL:  img.ImageURL,
-			Type: img.ImageType,
-		})
 	}
 
-	return product, nil
+	return result, nil
 }
 
-func (r *ProductRepositoryImpl) SearchProducts(ctx context.Context, params domain.SearchParams) (*domain.SearchResult, error) {
-	// Start building the query
-	query := "SELECT DISTINCT p.* FROM products p"
-	countQuery := "SELECT COUNT(DISTINCT p.id) FROM products p"
+func (r *ProductRepositoryImpl) UpdateProduct(ctx context.Context, p *domain.Product) error {
+	query := `UPDATE products SET name = $1, description = $2, price = $3, category_id = $4, stock = $5,
+              is_available = $6, updated_at = CURRENT_TIMESTAMP WHERE id = $7 AND deleted_at IS NULL`
+	_, err := r.db.ExecContext(ctx, query, p.Name, p.Description, p.Price, p.CategoryID, p.Stock, p.IsAvailable, p.ID)
+	return err
+}
 
-	// Initialize where clauses and arguments
-	whereClauses := []string{}
-	args := []interface{}{}
-	argIndex := 1
+func (r *ProductRepositoryImpl) DeleteProduct(ctx context.Context, id int64) error {
+	_, err := r.db.ExecContext(ctx,
+		"UPDATE products SET deleted_at = CURRENT_TIMESTAMP WHERE id = $1 AND deleted_at IS NULL", id)
+	return err
+}
 
-	// Add join for labels if needed
-	if len(params.Labels) > 0 {
-		query += " LEFT JOIN product_labels pl ON p.id = pl.product_id"
-		countQuery += " LEFT JOIN product_labels pl ON p.id = pl.product_id"
+func (r *ProductRepositoryImpl) AddImages(ctx context.Context, productID int64, images []domain.Image) ([]domain.Image, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
 	}
+	defer tx.Rollback()
 
-	// Add search conditions
-	if params.Query != "" {
-		whereClauses = append(whereClauses, fmt.Sprintf("(p.name ILIKE $%d OR p.description ILIKE $%d)", argIndex, argIndex))
-		args = append(args, "%"+params.Query+"%")
-		argIndex++
+	// Lock the product so concurrent uploads get distinct positions
+	var id int64
+	err = tx.GetContext(ctx, &id, "SELECT id FROM products WHERE id = $1 AND deleted_at IS NULL FOR UPDATE", productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock product: %w", err)
 	}
-
-	if params.CategoryID != "" {
-		whereClauses = append(whereClauses, fmt.Sprintf("p.category_id = $%d", argIndex))
-		args = append(args, params.CategoryID)
-		argIndex++
+	var next int
+	err = tx.GetContext(ctx, &next,
+		"SELECT COALESCE(MAX(position) + 1, 0) FROM product_images WHERE product_id = $1", productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get next image position: %w", err)
 	}
 
-	if params.MinPrice != nil {
-		whereClauses = append(whereClauses, fmt.Sprintf("p.price >= $%d", argIndex))
-		args = append(args, *params.MinPrice)
-		argIndex++
+	added := make([]domain.Image, len(images))
+	for i, img := range images {
+		img.Position = next + i
+		err = tx.QueryRowxContext(ctx,
+			`INSERT INTO product_images (product_id, image_url, image_type, position, variant_id)
+             VALUES ($1, $2, $3, $4, NULLIF($5, 0)) RETURNING id`,
+			productID, img.URL, img.Type, img.Position, img.VariantID).Scan(&img.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to add product image: %w", err)
+		}
+		added[i] = img
 	}
+	return added, tx.Commit()
+}
 
-	if params.MaxPrice != nil {
-		whereClauses = append(whereClauses, fmt.Sprintf("p.price <= $%d", argIndex))
-		args = append(args, *params.MaxPrice)
-		argIndex++
+func (r *ProductRepositoryImpl) RemoveImage(ctx context.Context, productID, imageID int64) (*domain.Image, error) {
+	var img imageDB
+	err := r.db.GetContext(ctx, &img,
+		"DELETE FROM product_images WHERE id = $1 AND product_id = $2 RETURNING *", imageID, productID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to remove product image: %w", err)
 	}
+	removed := img.toDomain()
+	return &removed, nil
+}
 
-	if params.SellerID != nil {
-		whereClauses = append(whereClauses, fmt.Sprintf("p.seller_id = $%d", argIndex))
-		args = append(args, *params.SellerID)
-		argIndex++
+func (r *ProductRepositoryImpl) ReorderImages(ctx context.Context, productID int64, imageIDs []int64) error {
+	// The position of each image is its index in the array
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE product_images i SET position = o.position - 1
+         FROM unnest($1::bigint[]) WITH ORDINALITY AS o(id, position)
+         WHERE i.id = o.id AND i.product_id = $2`,
+		pq.Array(imageIDs), productID)
+	if err != nil {
+		return fmt.Errorf("failed to reorder product images: %w", err)
 	}
+	return nil
+}
 
-	if params.Available != nil {
-		whereClauses = append(whereClauses, fmt.Sprintf("p.is_available = $%d", argIndex))
-		args = append(args, *params.Available)
-		argIndex++
+func (r *ProductRepositoryImpl) ReplaceLabels(ctx context.Context, productID int64, labels []string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
 	}
+	defer tx.Rollback()
 
-	// Handle labels filtering
-	if len(params.Labels) > 0 {
-		placeholders := make([]string, len(params.Labels))
-		for i := range params.Labels {
-			placeholders[i] = fmt.Sprintf("$%d", argIndex)
-			args = append(args, params.Labels[i])
-			argIndex++
+	if _, err = tx.ExecContext(ctx, "DELETE FROM product_labels WHERE product_id = $1", productID); err != nil {
+		return fmt.Errorf("failed to clear product labels: %w", err)
+	}
+	if len(labels) > 0 {
+		_, err = tx.ExecContext(ctx,
+			"INSERT INTO product_labels (product_id, label_name) SELECT $1, unnest($2::varchar[])",
+			productID, pq.Array(labels))
+		if err != nil {
+			return fmt.Errorf("failed to add product labels: %w", err)
 		}
-		whereClauses = append(whereClauses, fmt.Sprintf("pl.label_name IN (%s)", strings.Join(placeholders, ", ")))
 	}
+	_, err = tx.ExecContext(ctx, "UPDATE products SET search_labels = $1 WHERE id = $2",
+		strings.Join(labels, " "), productID)
+	if err != nil {
+		return fmt.Errorf("failed to update product search labels: %w", err)
+	}
+	return tx.Commit()
+}
 
-	// Add WHERE clause if we have conditions
-	if len(whereClauses) > 0 {
-		whereClause := " WHERE " + strings.Join(whereClauses, " AND ")
-		query += whereClause
-		countQuery += whereClause
+func (r *ProductRepositoryImpl) ListLabels(ctx context.Context) ([]domain.LabelCount, error) {
+	// Count the products a search without filters would show
+	f := newSearchFilter(domain.SearchParams{})
+	rows, err := r.db.QueryxContext(ctx, fmt.Sprintf(`SELECT pl.label_name, COUNT(*) FROM products p
+        JOIN product_labels pl ON pl.product_id = p.id%s
+        GROUP BY pl.label_name ORDER BY COUNT(*) DESC, pl.label_name`, f.where()), f.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count products by label: %w", err)
 	}
+	defer rows.Close()
 
-	// Add sorting
-	if params.SortBy != "" {
-		allowedSortFields := map[string]bool{
-			"price": true, "created_at": true, "name": true,
-		}
-		if allowedSortFields[params.SortBy] {
-			sortDir := "ASC"
-			if params.SortDir == "desc" {
-				sortDir = "DESC"
-			}
-			query += fmt.Sprintf(" ORDER BY p.%s %s", params.SortBy, sortDir)
+	labels := []domain.LabelCount{}
+	for rows.Next() {
+		var c domain.LabelCount
+		if err := rows.Scan(&c.Label, &c.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan label count: %w", err)
 		}
-	} else {
-		query += " ORDER BY p.created_at DESC"
+		labels = append(labels, c)
 	}
+	return labels, rows.Err()
+}
 
-	// Add pagination
-	limit := 10 // Default limit
-	if params.Limit > 0 {
-		limit = params.Limit
+// loadDetails loads the images, labels, options and variants of products
+// with one query each, however many products there are.
+func (r *ProductRepositoryImpl) loadDetails(ctx context.Context, products []*domain.Product) error {
+	if len(products) == 0 {
+		return nil
 	}
-
-	offset := 0 // Default offset
-	if params.Offset > 0 {
-		offset = params.Offset
+	ids := make([]int64, len(products))
+	byID := make(map[int64]*domain.Product, len(products))
+	for i, p := range products {
+		ids[i] = p.ID
+		byID[p.ID] = p
+		p.Images, p.Labels, p.Options, p.Variants = nil, nil, nil, []domain.Variant{}
 	}
 
-	query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", argIndex, argIndex+1)
-	args = append(args, limit, offset)
+	var images []imageDB
+	err := r.db.SelectContext(ctx, &images,
+		`SELECT id, product_id, image_url, image_type, position, variant_id, created_at FROM product_images
+         WHERE product_id = ANY($1) ORDER BY product_id, position, id`, pq.Array(ids))
+	if err != nil {
+		return fmt.Errorf("failed to fetch product images: %w", err)
+	}
+	for _, img := range images {
+		p := byID[img.ProductID]
+		p.Images = append(p.Images, img.toDomain())
+	}
 
-	// Execute count query first
-	var totalCount int
-	err := r.db.GetContext(ctx, &totalCount, countQuery, args[:argIndex-1]...)
+	var labels []labelDB
+	err = r.db.SelectContext(ctx, &labels,
+		`SELECT product_id, label_name FROM product_labels
+         WHERE product_id = ANY($1) ORDER BY product_id, label_name`, pq.Array(ids))
 	if err != nil {
-		return nil, fmt.Errorf("failed to count search results: %w", err)
+		return fmt.Errorf("failed to fetch product labels: %w", err)
+	}
+	for _, l := range labels {
+		p := byID[l.ProductID]
+		p.Labels = append(p.Labels, l.Name)
 	}
 
-	// Execute main query
-	rows, err := r.db.QueryxContext(ctx, query, args...)
+	var values []optionValueDB
+	err = r.db.SelectContext(ctx, &values,
+		`SELECT o.product_id, o.id AS option_id, o.name AS option_name, v.id AS value_id, v.value
+         FROM product_options o JOIN product_option_values v ON v.option_id = o.id
+         WHERE o.product_id = ANY($1) ORDER BY o.product_id, o.position, o.id, v.position, v.id`, pq.Array(ids))
 	if err != nil {
-		return nil, fmt.Errorf("failed to search products: %w", err)
+		return fmt.Errorf("failed to fetch product options: %w", err)
+	}
+	optionNames := map[int64]string{}
+	valueNames := map[int64]string{}
+	valueOptions := map[int64]int64{}
+	for _, v := range values {
+		p := byID[v.ProductID]
+		if len(p.Options) == 0 || p.Options[len(p.Options)-1].ID != v.OptionID {
+			p.Options = append(p.Options, domain.Option{ID: v.OptionID, Name: v.OptionName})
+		}
+		opt := &p.Options[len(p.Options)-1]
+		opt.Values = append(opt.Values, domain.OptionValue{ID: v.ValueID, Value: v.Value})
+		optionNames[v.OptionID] = v.OptionName
+		valueNames[v.ValueID] = v.Value
+		valueOptions[v.ValueID] = v.OptionID
 	}
-	defer rows.Close()
 
-	// Parse results
-	products := []*domain.Product{}
-	for rows.Next() {
-		var pdb productDB
-		if err := rows.StructScan(&pdb); err != nil {
-			return nil, fmt.Errorf("failed to scan product row: %w", err)
+	var variants []variantDB
+	err = r.db.SelectContext(ctx, &variants,
+		`SELECT id, product_id, sku, price, stock, is_available FROM product_variants
+         WHERE product_id = ANY($1) ORDER BY product_id, id`, pq.Array(ids))
+	if err != nil {
+		return fmt.Errorf("failed to fetch product variants: %w", err)
+	}
+	var links []variantValueDB
+	if len(variants) > 0 {
+		err = r.db.SelectContext(ctx, &links,
+			`SELECT vv.variant_id, vv.option_value_id FROM product_variant_values vv
+             JOIN product_variants v ON v.id = vv.variant_id WHERE v.product_id = ANY($1)`, pq.Array(ids))
+		if err != nil {
+			return fmt.Errorf("failed to fetch variant option values: %w", err)
 		}
+	}
 
-		product := &domain.Product{
-			ID:          pdb.ID,
-			Name:        pdb.Name,
-			Description: pdb.Description,
-			Price:       pdb.Price,
-			CategoryID:  pdb.CategoryID,
-			SellerID:    pdb.SellerID,
-			Stock:       pdb.Stock,
-			IsAvailable: pdb.IsAvailable,
-			CreatedAt:   pdb.CreatedAt,
-			UpdatedAt:   pdb.UpdatedAt,
+	// Variants are appended before taking pointers to them
+	for _, v := range variants {
+		p := byID[v.ProductID]
+		p.Variants = append(p.Variants, domain.Variant{
+			ID:          v.ID,
+			SKU:         v.SKU,
+			Price:       v.Price,
+			Stock:       v.Stock,
+			IsAvailable: v.IsAvailable,
+			Options:     map[string]string{},
+		})
+	}
+	variantOf := make(map[int64]*domain.Variant, len(variants))
+	for _, p := range products {
+		for i := range p.Variants {
+			variantOf[p.Variants[i].ID] = &p.Variants[i]
 		}
+	}
+	for _, link := range links {
+		v := variantOf[link.VariantID]
+		v.OptionValueIDs = append(v.OptionValueIDs, link.OptionValueID)
+		v.Options[optionNames[valueOptions[link.OptionValueID]]] = valueNames[link.OptionValueID]
+	}
 
-		products = append(products, product)
+	for _, p := range products {
+		p.ResolveVariants()
 	}
+	return nil
+}
 
-	// Load images for each product
-	for _, product := range products {
-		imgRows, err := r.db.QueryxContext(ctx,
-			"SELECT * FROM product_images WHERE product_id = $1", product.ID)
+func (r *ProductRepositoryImpl) ReplaceOptions(ctx context.Context, productID int64, options []domain.Option) ([]domain.Option, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	stored := make([]domain.Option, len(options))
+	optionIDs := make([]int64, len(options))
+	for i, opt := range options {
+		err = tx.GetContext(ctx, &opt.ID,
+			`INSERT INTO product_options (product_id, name, position) VALUES ($1, $2, $3)
+             ON CONFLICT (product_id, name) DO UPDATE SET position = EXCLUDED.position RETURNING id`,
+			productID, opt.Name, i)
 		if err != nil {
-			return nil, fmt.Errorf("failed to fetch product images: %w", err)
+			return nil, fmt.Errorf("failed to store product option: %w", err)
 		}
 
-		for imgRows.Next() {
-			var img imageDB
-			if err := imgRows.StructScan(&img); err != nil {
-				imgRows.Close()
-				return nil, fmt.Errorf("failed to scan image row: %w", err)
+		values := make([]domain.OptionValue, len(opt.Values))
+		valueIDs := make([]int64, len(opt.Values))
+		for j, val := range opt.Values {
+			err = tx.GetContext(ctx, &val.ID,
+				`INSERT INTO product_option_values (option_id, value, position) VALUES ($1, $2, $3)
+                 ON CONFLICT (option_id, value) DO UPDATE SET position = EXCLUDED.position RETURNING id`,
+				opt.ID, val.Value, j)
+			if err != nil {
+				return nil, fmt.Errorf("failed to store product option value: %w", err)
 			}
-			product.Images = append(product.Images, domain.Image{
-				URL:  img.ImageURL,
-				Type: img.ImageType,
-			})
+			values[j] = val
+			valueIDs[j] = val.ID
 		}
-		imgRows.Close()
 
-		// Fetch labels for this product
-		labelRows, err := r.db.QueryxContext(ctx,
-			"SELECT label_name FROM product_labels WHERE product_id = $1", product.ID)
+		_, err = tx.ExecContext(ctx,
+			"DELETE FROM product_option_values WHERE option_id = $1 AND NOT (id = ANY($2))", opt.ID, pq.Array(valueIDs))
 		if err != nil {
-			return nil, fmt.Errorf("failed to fetch product labels: %w", err)
+			return nil, fmt.Errorf("failed to remove product option values: %w", err)
 		}
+		opt.Values = values
+		stored[i] = opt
+		optionIDs[i] = opt.ID
+	}
 
-		for labelRows.Next() {
-			var label string
-			if err := labelRows.Scan(&label); err != nil {
-				labelRows.Close()
-				return nil, fmt.Errorf("failed to scan label row: %w", err)
-			}
-			product.Labels = append(product.Labels, label)
-		}
-		labelRows.Close()
+	_, err = tx.ExecContext(ctx,
+		"DELETE FROM product_options WHERE product_id = $1 AND NOT (id = ANY($2))", productID, pq.Array(optionIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to remove product options: %w", err)
 	}
+	return stored, tx.Commit()
+}
 
-	result := &domain.SearchResult{
-		Products:    products,
-		TotalCount:  totalCount,
-		Limit:       limit,
-		Offset:      offset,
-		HasNextPage: (offset + len(products)) < totalCount,
+func (r *ProductRepositoryImpl) CreateVariant(ctx context.Context, productID int64, v *domain.Variant) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
 	}
+	defer tx.Rollback()
 
-	return result, nil
+	err = tx.GetContext(ctx, &v.ID,
+		`INSERT INTO product_variants (product_id, sku, price, stock, is_available)
+         VALUES ($1, $2, $3, $4, $5) RETURNING id`,
+		productID, v.SKU, v.Price, v.Stock, v.IsAvailable)
+	if err != nil {
+		return variantError(err)
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"INSERT INTO product_variant_values (variant_id, option_value_id) SELECT $1, unnest($2::int[])",
+		v.ID, pq.Array(v.OptionValueIDs))
+	if err != nil {
+		return fmt.Errorf("failed to store variant option values: %w", err)
+	}
+	return tx.Commit()
+}
+
+func (r *ProductRepositoryImpl) UpdateVariant(ctx context.Context, productID int64, v *domain.Variant) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE product_variants SET sku = $1, price = $2, stock = $3, is_available = $4, updated_at = CURRENT_TIMESTAMP
+         WHERE id = $5 AND product_id = $6`,
+		v.SKU, v.Price, v.Stock, v.IsAvailable, v.ID, productID)
+	if err != nil {
+		return variantError(err)
+	}
+	return nil
+}
+
+func (r *ProductRepositoryImpl) DeleteVariant(ctx context.Context, productID, variantID int64) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM product_variants WHERE id = $1 AND product_id = $2", variantID, productID)
+	if err != nil {
+		return fmt.Errorf("failed to delete product variant: %w", err)
+	}
+	return nil
+}
+
+// variantError reports a taken SKU as domain.ErrDuplicateSKU.
+func variantError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return domain.ErrDuplicateSKU
+	}
+	return fmt.Errorf("failed to store product variant: %w", err)
 }
@@ -0,0 +1,198 @@
+package infra
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"yadwy-backend/internal/prodcuts/domain"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/suite"
+)
+
+var productRowColumns = []string{"id", "name", "description", "price", "category_id", "seller_id", "stock",
+	"is_available", "created_at", "updated_at"}
+
+type ProductRepositoryTestSuite struct {
+	suite.Suite
+	db   *sqlx.DB
+	mock sqlmock.Sqlmock
+	repo domain.ProductRepository
+}
+
+func (s *ProductRepositoryTestSuite) SetupTest() {
+	db, mock, err := sqlmock.New()
+	s.Require().NoError(err)
+
+	s.db = sqlx.NewDb(db, "sqlmock")
+	s.mock = mock
+	s.repo = NewProductRepository(s.db)
+}
+
+func (s *ProductRepositoryTestSuite) TearDownTest() {
+	s.Require().NoError(s.mock.ExpectationsWereMet())
+	s.db.Close()
+}
+
+func TestProductRepository(t *testing.T) {
+	suite.Run(t, new(ProductRepositoryTestSuite))
+}
+
+// productRows returns the rows of products 1 to n.
+func productRows(n int) *sqlmock.Rows {
+	rows := sqlmock.NewRows(productRowColumns)
+	for id := 1; id <= n; id++ {
+		rows.AddRow(id, fmt.Sprintf("Vase %d", id), "Handmade", 10.5*float64(id), "pottery", 5, 3, true,
+			"2024-05-01T10:00:00Z", "2024-05-01T10:00:00Z")
+	}
+	return rows
+}
+
+// productIDs returns the array argument of products 1 to n.
+func productIDs(n int) string {
+	ids := make([]string, n)
+	for i := range ids {
+		ids[i] = fmt.Sprint(i + 1)
+	}
+	return "{" + strings.Join(ids, ",") + "}"
+}
+
+// expectDetails expects the queries loading the details of products 1 to n,
+// with images of product 1 and, when loaded, a variant of product 2.
+func expectDetails(mock sqlmock.Sqlmock, n int) {
+	ids := productIDs(n)
+	labels := sqlmock.NewRows([]string{"product_id", "label_name"}).AddRow(1, "gift").AddRow(1, "handmade")
+	options := sqlmock.NewRows([]string{"product_id", "option_id", "option_name", "value_id", "value"})
+	variants := sqlmock.NewRows([]string{"id", "product_id", "sku", "price", "stock", "is_available"})
+	if n >= 2 {
+		labels.AddRow(2, "gift")
+		options.AddRow(2, 1, "Size", 11, "S").AddRow(2, 1, "Size", 12, "L")
+		variants.AddRow(7, 2, "VASE-L", 30.0, 2, true)
+	}
+
+	mock.ExpectQuery("FROM product_images WHERE product_id = ANY").WithArgs(ids).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "product_id", "image_url", "image_type", "position", "variant_id"}).
+			AddRow(1, 1, "http://localhost/a.png", "main", 0, nil).
+			AddRow(2, 1, "http://localhost/b.png", "extra", 1, 7))
+	mock.ExpectQuery("FROM product_labels WHERE product_id = ANY").WithArgs(ids).WillReturnRows(labels)
+	mock.ExpectQuery("FROM product_options o").WithArgs(ids).WillReturnRows(options)
+	mock.ExpectQuery("FROM product_variants WHERE product_id = ANY").WithArgs(ids).WillReturnRows(variants)
+	if n >= 2 {
+		mock.ExpectQuery("FROM product_variant_values vv").WithArgs(ids).
+			WillReturnRows(sqlmock.NewRows([]string{"variant_id", "option_value_id"}).AddRow(7, 12))
+	}
+}
+
+func (s *ProductRepositoryTestSuite) TestGetProduct() {
+	ctx := context.Background()
+
+	s.Run("should load the product with its details", func() {
+		s.mock.ExpectQuery("SELECT (.+) FROM products p WHERE p.id").WithArgs(int64(1)).WillReturnRows(productRows(1))
+		expectDetails(s.mock, 1)
+
+		p, err := s.repo.GetProduct(ctx, 1)
+		s.Require().NoError(err)
+		s.Len(p.Images, 2)
+		s.Equal(int64(7), p.Images[1].VariantID)
+		s.Equal([]string{"gift", "handmade"}, p.Labels)
+	})
+
+	s.Run("should return nil when not found", func() {
+		s.mock.ExpectQuery("SELECT (.+) FROM products p WHERE p.id").WithArgs(int64(9)).
+			WillReturnRows(sqlmock.NewRows(productRowColumns))
+
+		p, err := s.repo.GetProduct(ctx, 9)
+		s.Require().NoError(err)
+		s.Nil(p)
+	})
+}
+
+func (s *ProductRepositoryTestSuite) TestSearchProducts() {
+	ctx := context.Background()
+
+	s.Run("should load a page with a constant number of queries", func() {
+		s.mock.ExpectQuery("SELECT COUNT").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+		s.mock.ExpectQuery("SELECT (.+) FROM products p").WithArgs(3, 0).WillReturnRows(productRows(3))
+		expectDetails(s.mock, 2)
+
+		result, err := s.repo.SearchProducts(ctx, domain.SearchParams{Limit: 2, SortBy: "price"})
+		s.Require().NoError(err)
+		s.Require().Len(result.Products, 2)
+		s.Equal(3, result.TotalCount)
+		s.True(result.HasNextPage)
+		s.NotEmpty(result.NextCursor)
+		s.Empty(result.PrevCursor)
+
+		first, second := result.Products[0], result.Products[1]
+		s.Len(first.Images, 2)
+		s.Equal([]string{"gift", "handmade"}, first.Labels)
+		s.Empty(first.Variants)
+		s.Equal([]string{"gift"}, second.Labels)
+		s.Require().Len(second.Variants, 1)
+		s.Equal(map[string]string{"Size": "L"}, second.Variants[0].Options)
+		s.Equal(30.0, second.Variants[0].FinalPrice)
+		s.Require().Len(second.Options, 1)
+		s.Len(second.Options[0].Values, 2)
+	})
+
+	s.Run("should skip the count and detail queries", func() {
+		s.mock.ExpectQuery("SELECT (.+) FROM products p").WithArgs(11, 0).WillReturnRows(sqlmock.NewRows(productRowColumns))
+
+		result, err := s.repo.SearchProducts(ctx, domain.SearchParams{SkipCount: true})
+		s.Require().NoError(err)
+		s.Empty(result.Products)
+		s.Equal(-1, result.TotalCount)
+		s.False(result.HasNextPage)
+	})
+}
+
+func (s *ProductRepositoryTestSuite) TestListLabels() {
+	s.Run("should count the labels of visible products", func() {
+		s.mock.ExpectQuery("SELECT pl.label_name, COUNT(.+) WHERE p.deleted_at IS NULL AND NOT EXISTS (.+) GROUP BY pl.label_name").
+			WillReturnRows(sqlmock.NewRows([]string{"label_name", "count"}).AddRow("gift", 4).AddRow("handmade", 2))
+
+		labels, err := s.repo.ListLabels(context.Background())
+		s.Require().NoError(err)
+		s.Equal([]domain.LabelCount{{Label: "gift", Count: 4}, {Label: "handmade", Count: 2}}, labels)
+	})
+
+	s.Run("should return an empty list without labels", func() {
+		s.mock.ExpectQuery("SELECT pl.label_name").WillReturnRows(sqlmock.NewRows([]string{"label_name", "count"}))
+
+		labels, err := s.repo.ListLabels(context.Background())
+		s.Require().NoError(err)
+		s.NotNil(labels)
+		s.Empty(labels)
+	})
+}
+
+// BenchmarkSearchProducts loads pages of 50 products, which take the same
+// seven queries as a page of one.
+func BenchmarkSearchProducts(b *testing.B) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		b.Fatalf("sqlmock.New() error = %v", err)
+	}
+	defer db.Close()
+	repo := NewProductRepository(sqlx.NewDb(db, "sqlmock"))
+	ctx := context.Background()
+	params := domain.SearchParams{Limit: 50, SortBy: "price"}
+
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		mock.ExpectQuery("SELECT COUNT").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(60))
+		mock.ExpectQuery("SELECT (.+) FROM products p").WithArgs(51, 0).WillReturnRows(productRows(51))
+		expectDetails(mock, 50)
+		b.StartTimer()
+
+		if _, err := repo.SearchProducts(ctx, params); err != nil {
+			b.Fatalf("SearchProducts() error = %v", err)
+		}
+	}
+	b.StopTimer()
+	if err := mock.ExpectationsWereMet(); err != nil {
+		b.Fatalf("unexpected queries: %v", err)
+	}
+}
@@ -0,0 +1,375 @@
+package infra
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"yadwy-backend/internal/prodcuts/domain"
+
+	"github.com/lib/pq"
+)
+
+const (
+	// facetLimit is the most values returned by a facet.
+	facetLimit = 50
+	// priceBuckets is the most ranges of the price histogram.
+	priceBuckets = 5
+)
+
+// inStock tells a product can be bought: one of its variants is available
+// and in stock, or it has no variants and is in stock.
+const inStock = `CASE WHEN EXISTS (SELECT 1 FROM product_variants v WHERE v.product_id = p.id)
+    THEN EXISTS (SELECT 1 FROM product_variants v WHERE v.product_id = p.id AND v.is_available AND v.stock > 0)
+    ELSE COALESCE(p.stock, 0) > 0 END`
+
+// htmlEscapes are the characters escapeHTML replaces, & first so that the
+// other escapes are kept.
+var htmlEscapes = [][2]string{{"&", "&amp;"}, {"<", "&lt;"}, {">", "&gt;"}, {`"`, "&quot;"}, {"'", "&#39;"}}
+
+// escapeHTML wraps a text expression so that it is HTML-escaped. Snippets are
+// escaped before ts_headline adds <mark>, so seller text cannot add markup;
+// the parser reads the escapes as entities and still finds the words.
+func escapeHTML(expr string) string {
+	for _, e := range htmlEscapes {
+		expr = fmt.Sprintf("replace(%s, '%s', '%s')", expr, strings.ReplaceAll(e[0], "'", "''"), e[1])
+	}
+	return expr
+}
+
+// sortColumns are the columns of the sorts paged with cursors.
+var sortColumns = map[string]string{
+	"price":      "p.price",
+	"created_at": "p.created_at",
+	"name":       "p.name",
+}
+
+// searchOrder is the sort of a product search. Products with the same sort
+// value are sorted by ID, so that a cursor points between two products.
+type searchOrder struct {
+	// Field is a key of sortColumns, or relevance.
+	Field string
+	Desc  bool
+}
+
+func newSearchOrder(params domain.SearchParams, textSearch bool) searchOrder {
+	if textSearch && (params.SortBy == "" || params.SortBy == "relevance") {
+		return searchOrder{Field: "relevance", Desc: true}
+	}
+	if _, ok := sortColumns[params.SortBy]; ok {
+		return searchOrder{Field: params.SortBy, Desc: params.SortDir == "desc"}
+	}
+	return searchOrder{Field: "created_at", Desc: true}
+}
+
+// keyset tells the results can be paged with cursors. The relevance of a
+// product depends on the query, so relevance is paged with offsets.
+func (o searchOrder) keyset() bool {
+	return o.Field != "relevance"
+}
+
+// clause returns the ORDER BY clause, reversed to page backwards.
+func (o searchOrder) clause(reverse bool) string {
+	if !o.keyset() {
+		return "rank DESC, p.created_at DESC, p.id DESC"
+	}
+	dir := "ASC"
+	if o.Desc != reverse {
+		dir = "DESC"
+	}
+	return fmt.Sprintf("%[1]s %[2]s, p.id %[2]s", sortColumns[o.Field], dir)
+}
+
+// searchCursor points after a product in a sort, or before it. Clients get
+// it as opaque base64 encoded JSON.
+type searchCursor struct {
+	Field  string `json:"f"`
+	Desc   bool   `json:"d,omitempty"`
+	Value  string `json:"v"`
+	ID     int64  `json:"i"`
+	Before bool   `json:"b,omitempty"`
+}
+
+func newSearchCursor(o searchOrder, p productDB, before bool) searchCursor {
+	c := searchCursor{Field: o.Field, Desc: o.Desc, ID: p.ID, Before: before}
+	switch o.Field {
+	case "price":
+		c.Value = strconv.FormatFloat(p.Price, 'f', -1, 64)
+	case "created_at":
+		c.Value = p.CreatedAt
+	case "name":
+		c.Value = p.Name
+	}
+	return c
+}
+
+// decodeSearchCursor reads a cursor, which must have been issued for the
+// same sort.
+func decodeSearchCursor(token string, o searchOrder) (*searchCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, domain.ErrInvalidCursor
+	}
+	var c searchCursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, domain.ErrInvalidCursor
+	}
+	if !o.keyset() || c.Field != o.Field || c.Desc != o.Desc || c.ID <= 0 {
+		return nil, domain.ErrInvalidCursor
+	}
+
+	switch c.Field {
+	case "price":
+		_, err = strconv.ParseFloat(c.Value, 64)
+	case "created_at":
+		_, err = time.Parse(time.RFC3339Nano, c.Value)
+	}
+	if err != nil {
+		return nil, domain.ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+func (c searchCursor) encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// condition selects the products after the cursor in the sort, or before it,
+// given the placeholders of its value and ID.
+func (c searchCursor) condition(o searchOrder, valueArg, idArg int) string {
+	cmp := ">"
+	if o.Desc != c.Before {
+		cmp = "<"
+	}
+	return fmt.Sprintf("(%s, p.id) %s ($%d, $%d)", sortColumns[o.Field], cmp, valueArg, idArg)
+}
+
+// searchFilter is the WHERE clause of a product search, shared by its
+// results, count and facets so that they always agree.
+type searchFilter struct {
+	clauses []string
+	args    []interface{}
+	// textQuery is the placeholder of the full-text query, empty without one.
+	textQuery string
+}
+
+func newSearchFilter(params domain.SearchParams) *searchFilter {
+	f := &searchFilter{}
+	f.add("p.deleted_at IS NULL")
+
+	if strings.TrimSpace(params.Query) != "" {
+		f.textQuery = f.arg(params.Query)
+		f.add("p.search_vector @@ " + f.tsQuery())
+	}
+	if params.CategoryID != "" {
+		f.add("p.category_id = " + f.arg(params.CategoryID))
+	}
+	if params.MinPrice != nil {
+		f.add("p.price >= " + f.arg(*params.MinPrice))
+	}
+	if params.MaxPrice != nil {
+		f.add("p.price <= " + f.arg(*params.MaxPrice))
+	}
+	if params.SellerID != nil {
+		f.add("p.seller_id = " + f.arg(*params.SellerID))
+	}
+	if params.Available != nil {
+		f.add("p.is_available = " + f.arg(*params.Available))
+	}
+
+	// Hide products of suspended shops
+	if !params.IncludeSuspendedShops {
+		f.add("NOT EXISTS (SELECT 1 FROM shops s WHERE s.seller_id = p.seller_id AND s.status = 'SUSPENDED')")
+	}
+
+	// Labels are stored normalized, and a product has each label once
+	if labels := domain.NormalizeLabels(params.Labels); len(labels) > 0 {
+		if params.LabelsMode == domain.LabelsModeAll {
+			f.add(fmt.Sprintf(
+				"(SELECT COUNT(*) FROM product_labels pl WHERE pl.product_id = p.id AND pl.label_name IN (%s)) = %d",
+				f.list(labels), len(labels)))
+		} else {
+			f.add(fmt.Sprintf(
+				"EXISTS (SELECT 1 FROM product_labels pl WHERE pl.product_id = p.id AND pl.label_name IN (%s))",
+				f.list(labels)))
+		}
+	}
+	if labels := domain.NormalizeLabels(params.ExcludeLabels); len(labels) > 0 {
+		f.add(fmt.Sprintf(
+			"NOT EXISTS (SELECT 1 FROM product_labels pl WHERE pl.product_id = p.id AND pl.label_name IN (%s))",
+			f.list(labels)))
+	}
+	return f
+}
+
+func (f *searchFilter) add(clause string) {
+	f.clauses = append(f.clauses, clause)
+}
+
+// arg adds an argument and returns its placeholder.
+func (f *searchFilter) arg(v interface{}) string {
+	f.args = append(f.args, v)
+	return fmt.Sprintf("$%d", len(f.args))
+}
+
+// list adds the values as arguments and returns their placeholders.
+func (f *searchFilter) list(values []string) string {
+	placeholders := make([]string, len(values))
+	for i, v := range values {
+		placeholders[i] = f.arg(v)
+	}
+	return strings.Join(placeholders, ", ")
+}
+
+func (f *searchFilter) where() string {
+	return " WHERE " + strings.Join(f.clauses, " AND ")
+}
+
+// tsQuery matches the word forms of both languages of the catalog.
+func (f *searchFilter) tsQuery() string {
+	return fmt.Sprintf("(websearch_to_tsquery('english', %[1]s) || websearch_to_tsquery('arabic', %[1]s))", f.textQuery)
+}
+
+// textSearchConfig returns the text search configuration of the language of
+// a query, arabic when it has any Arabic letter and english otherwise.
+func textSearchConfig(query string) string {
+	for _, r := range query {
+		if unicode.Is(unicode.Arabic, r) {
+			return "arabic"
+		}
+	}
+	return "english"
+}
+
+func (r *ProductRepositoryImpl) searchFacets(ctx context.Context, f *searchFilter) (*domain.Facets, error) {
+	facets := &domain.Facets{
+		Categories: []domain.FacetCount{},
+		Labels:     []domain.FacetCount{},
+		Sellers:    []domain.SellerCount{},
+		Prices:     []domain.PriceBucket{},
+	}
+	where := f.where()
+
+	rows, err := r.db.QueryxContext(ctx, fmt.Sprintf(`SELECT p.category_id, COUNT(*) FROM products p%s AND p.category_id IS NOT NULL
+        GROUP BY p.category_id ORDER BY COUNT(*) DESC, p.category_id LIMIT %d`, where, facetLimit), f.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count products by category: %w", err)
+	}
+	for rows.Next() {
+		var c domain.FacetCount
+		if err := rows.Scan(&c.Value, &c.Count); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan category count: %w", err)
+		}
+		facets.Categories = append(facets.Categories, c)
+	}
+	rows.Close()
+
+	rows, err = r.db.QueryxContext(ctx, fmt.Sprintf(`SELECT fl.label_name, COUNT(*) FROM products p
+        JOIN product_labels fl ON fl.product_id = p.id%s
+        GROUP BY fl.label_name ORDER BY COUNT(*) DESC, fl.label_name LIMIT %d`, where, facetLimit), f.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count products by label: %w", err)
+	}
+	for rows.Next() {
+		var c domain.FacetCount
+		if err := rows.Scan(&c.Value, &c.Count); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan label count: %w", err)
+		}
+		facets.Labels = append(facets.Labels, c)
+	}
+	rows.Close()
+
+	rows, err = r.db.QueryxContext(ctx, fmt.Sprintf(`SELECT p.seller_id, COUNT(*) FROM products p%s AND p.seller_id IS NOT NULL
+        GROUP BY p.seller_id ORDER BY COUNT(*) DESC, p.seller_id LIMIT %d`, where, facetLimit), f.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count products by seller: %w", err)
+	}
+	for rows.Next() {
+		var c domain.SellerCount
+		if err := rows.Scan(&c.SellerID, &c.Count); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan seller count: %w", err)
+		}
+		facets.Sellers = append(facets.Sellers, c)
+	}
+	rows.Close()
+
+	var summary struct {
+		InStock    int      `db:"in_stock"`
+		OutOfStock int      `db:"out_of_stock"`
+		MinPrice   *float64 `db:"min_price"`
+		MaxPrice   *float64 `db:"max_price"`
+	}
+	err = r.db.GetContext(ctx, &summary, fmt.Sprintf(`SELECT COUNT(*) FILTER (WHERE %[1]s) AS in_stock,
+        COUNT(*) FILTER (WHERE NOT (%[1]s)) AS out_of_stock,
+        MIN(p.price) AS min_price, MAX(p.price) AS max_price
+        FROM products p%[2]s`, inStock, where), f.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count products by stock: %w", err)
+	}
+	facets.InStock = summary.InStock
+	facets.OutOfStock = summary.OutOfStock
+
+	if summary.MinPrice != nil && summary.MaxPrice != nil {
+		if facets.Prices, err = r.priceHistogram(ctx, f, *summary.MinPrice, *summary.MaxPrice); err != nil {
+			return nil, err
+		}
+	}
+	return facets, nil
+}
+
+// priceHistogram counts the matching products in the price ranges between
+// the lowest and the highest price.
+func (r *ProductRepositoryImpl) priceHistogram(ctx context.Context, f *searchFilter, lowest, highest float64) ([]domain.PriceBucket, error) {
+	edges := priceBucketEdges(lowest, highest, priceBuckets)
+
+	// width_bucket numbers the buckets from 1, after the products below the
+	// first edge, and puts the highest price in the last one
+	args := append(slices.Clip(f.args), pq.Array(edges))
+	rows, err := r.db.QueryxContext(ctx, fmt.Sprintf(`SELECT width_bucket(p.price, $%d::numeric[]) AS bucket, COUNT(*)
+        FROM products p%s GROUP BY bucket`, len(args), f.where()), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count products by price: %w", err)
+	}
+	defer rows.Close()
+
+	buckets := make([]domain.PriceBucket, len(edges))
+	for i, edge := range edges {
+		buckets[i] = domain.PriceBucket{Min: edge, Max: highest}
+		if i+1 < len(edges) {
+			buckets[i].Max = edges[i+1]
+		}
+	}
+	for rows.Next() {
+		var bucket, count int
+		if err := rows.Scan(&bucket, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan price count: %w", err)
+		}
+		if bucket >= 1 && bucket <= len(buckets) {
+			buckets[bucket-1].Count += count
+		}
+	}
+	return buckets, rows.Err()
+}
+
+// priceBucketEdges returns the lower edges of at most n price ranges of equal
+// width, in whole cents, from lowest up to highest.
+func priceBucketEdges(lowest, highest float64, n int) []float64 {
+	low := int64(lowest*100 + 0.5)
+	high := int64(highest*100 + 0.5)
+	width := max((high-low+int64(n)-1)/int64(n), 1)
+
+	var edges []float64
+	for edge := low; edge <= high && len(edges) < n; edge += width {
+		edges = append(edges, float64(edge)/100)
+	}
+	return edges
+}
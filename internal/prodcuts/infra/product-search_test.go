@@ -0,0 +1,181 @@
+package infra
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"yadwy-backend/internal/prodcuts/domain"
+)
+
+func TestTextSearchConfig(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{query: "blue vase", want: "english"},
+		{query: "مزهرية زرقاء", want: "arabic"},
+		{query: "vase فخار", want: "arabic"},
+		{query: "\"hand made\" -plastic", want: "english"},
+	}
+
+	for _, tt := range tests {
+		if got := textSearchConfig(tt.query); got != tt.want {
+			t.Errorf("textSearchConfig(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestEscapeHTML(t *testing.T) {
+	want := `replace(replace(replace(replace(replace(p.name, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;'), '''', '&#39;')`
+	if got := escapeHTML("p.name"); got != want {
+		t.Errorf("escapeHTML() = %s, want %s", got, want)
+	}
+}
+
+func TestNewSearchFilter(t *testing.T) {
+	minPrice := 10.0
+	sellerID := int64(5)
+	f := newSearchFilter(domain.SearchParams{
+		Query:                 "vase",
+		MinPrice:              &minPrice,
+		SellerID:              &sellerID,
+		Labels:                []string{"gift", "handmade"},
+		IncludeSuspendedShops: true,
+	})
+
+	want := " WHERE p.deleted_at IS NULL" +
+		" AND p.search_vector @@ (websearch_to_tsquery('english', $1) || websearch_to_tsquery('arabic', $1))" +
+		" AND p.price >= $2 AND p.seller_id = $3" +
+		" AND EXISTS (SELECT 1 FROM product_labels pl WHERE pl.product_id = p.id AND pl.label_name IN ($4, $5))"
+	if got := f.where(); got != want {
+		t.Errorf("where() = %v, want %v", got, want)
+	}
+	if len(f.args) != 5 || f.args[0] != "vase" || f.args[4] != "handmade" {
+		t.Errorf("args = %v, want the query, price, seller and labels", f.args)
+	}
+	if f.textQuery != "$1" {
+		t.Errorf("textQuery = %v, want $1", f.textQuery)
+	}
+
+	if f := newSearchFilter(domain.SearchParams{Query: "  "}); f.textQuery != "" || !strings.Contains(f.where(), "SUSPENDED") {
+		t.Errorf("blank query filter = %v, want no text search and suspended shops hidden", f.where())
+	}
+}
+
+func TestNewSearchFilterLabels(t *testing.T) {
+	f := newSearchFilter(domain.SearchParams{
+		Labels:                []string{" Hand  Made", "gift", "GIFT"},
+		LabelsMode:            domain.LabelsModeAll,
+		ExcludeLabels:         []string{"Fragile", " "},
+		IncludeSuspendedShops: true,
+	})
+
+	want := " WHERE p.deleted_at IS NULL" +
+		" AND (SELECT COUNT(*) FROM product_labels pl WHERE pl.product_id = p.id AND pl.label_name IN ($1, $2)) = 2" +
+		" AND NOT EXISTS (SELECT 1 FROM product_labels pl WHERE pl.product_id = p.id AND pl.label_name IN ($3))"
+	if got := f.where(); got != want {
+		t.Errorf("where() = %v, want %v", got, want)
+	}
+	if len(f.args) != 3 || f.args[0] != "hand made" || f.args[1] != "gift" || f.args[2] != "fragile" {
+		t.Errorf("args = %v, want the normalized labels", f.args)
+	}
+}
+
+func TestPriceBucketEdges(t *testing.T) {
+	tests := []struct {
+		name            string
+		lowest, highest float64
+		want            []float64
+	}{
+		{name: "should split the range evenly", lowest: 0, highest: 100, want: []float64{0, 20, 40, 60, 80}},
+		{name: "should round the width up to a cent", lowest: 10, highest: 10.21, want: []float64{10, 10.05, 10.1, 10.15, 10.2}},
+		{name: "should use fewer buckets for a small range", lowest: 1, highest: 1.02, want: []float64{1, 1.01, 1.02}},
+		{name: "should use one bucket for one price", lowest: 25.5, highest: 25.5, want: []float64{25.5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := priceBucketEdges(tt.lowest, tt.highest, 5); !slices.Equal(got, tt.want) {
+				t.Errorf("priceBucketEdges(%v, %v) = %v, want %v", tt.lowest, tt.highest, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchOrder(t *testing.T) {
+	tests := []struct {
+		name       string
+		params     domain.SearchParams
+		textSearch bool
+		reverse    bool
+		want       string
+	}{
+		{name: "should sort by newest by default", want: "p.created_at DESC, p.id DESC"},
+		{name: "should sort by relevance with a query", params: domain.SearchParams{Query: "vase"}, textSearch: true,
+			want: "rank DESC, p.created_at DESC, p.id DESC"},
+		{name: "should sort by a field with a query", params: domain.SearchParams{Query: "vase", SortBy: "price"}, textSearch: true,
+			want: "p.price ASC, p.id ASC"},
+		{name: "should reverse to page backwards", params: domain.SearchParams{SortBy: "name", SortDir: "desc"}, reverse: true,
+			want: "p.name ASC, p.id ASC"},
+		{name: "should ignore unknown fields", params: domain.SearchParams{SortBy: "stock"}, want: "p.created_at DESC, p.id DESC"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newSearchOrder(tt.params, tt.textSearch).clause(tt.reverse); got != tt.want {
+				t.Errorf("clause() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchCursor(t *testing.T) {
+	byPrice := searchOrder{Field: "price", Desc: true}
+	product := productDB{ID: 42, Name: "Vase", Price: 12.5, CreatedAt: "2024-05-01T10:00:00.123456Z"}
+
+	t.Run("should decode an encoded cursor", func(t *testing.T) {
+		token := newSearchCursor(byPrice, product, true).encode()
+
+		c, err := decodeSearchCursor(token, byPrice)
+		if err != nil {
+			t.Fatalf("decodeSearchCursor() error = %v", err)
+		}
+		if c.Value != "12.5" || c.ID != 42 || !c.Before {
+			t.Errorf("decodeSearchCursor() = %+v, want before product 42 at 12.5", c)
+		}
+		if got, want := c.condition(byPrice, 3, 4), "(p.price, p.id) > ($3, $4)"; got != want {
+			t.Errorf("condition() = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("should select the products after the cursor", func(t *testing.T) {
+		byName := searchOrder{Field: "name"}
+		c := newSearchCursor(byName, product, false)
+		if got, want := c.condition(byName, 1, 2), "(p.name, p.id) > ($1, $2)"; got != want {
+			t.Errorf("condition() = %v, want %v", got, want)
+		}
+		c = newSearchCursor(searchOrder{Field: "created_at", Desc: true}, product, false)
+		if c.Value != product.CreatedAt {
+			t.Errorf("created_at cursor value = %v, want %v", c.Value, product.CreatedAt)
+		}
+	})
+
+	tests := map[string]struct {
+		token string
+		order searchOrder
+	}{
+		"malformed":           {token: "not a cursor!", order: byPrice},
+		"not JSON":            {token: "bm90IGpzb24", order: byPrice},
+		"of another field":    {token: newSearchCursor(searchOrder{Field: "name", Desc: true}, product, false).encode(), order: byPrice},
+		"of another order":    {token: newSearchCursor(searchOrder{Field: "price"}, product, false).encode(), order: byPrice},
+		"of a relevance sort": {token: newSearchCursor(byPrice, product, false).encode(), order: searchOrder{Field: "relevance", Desc: true}},
+		"with a bad value":    {token: searchCursor{Field: "price", Desc: true, Value: "cheap", ID: 1}.encode(), order: byPrice},
+	}
+	for name, tt := range tests {
+		t.Run("should reject a cursor "+name, func(t *testing.T) {
+			if _, err := decodeSearchCursor(tt.token, tt.order); err != domain.ErrInvalidCursor {
+				t.Errorf("decodeSearchCursor() error = %v, want %v", err, domain.ErrInvalidCursor)
+			}
+		})
+	}
+}
@@ -0,0 +1,328 @@
+package application
+
+import (
+	"context"
+	"mime/multipart"
+	"yadwy-backend/internal/common"
+	pdomain "yadwy-backend/internal/prodcuts/domain"
+	"yadwy-backend/internal/shops/domain"
+
+	"go.uber.org/zap"
+)
+
+const (
+	defaultShopsLimit = 20
+	maxShopsLimit     = 100
+	// erasedShopReason is the suspension reason of shops whose seller erased
+	// their account.
+	erasedShopReason = "account erased"
+)
+
+// ProductSearcher lists products, implemented by the products module.
+type ProductSearcher interface {
+	SearchProducts(ctx context.Context, params pdomain.SearchParams) (*pdomain.SearchResult, error)
+}
+
+type ShopService struct {
+	repo     domain.ShopRepository
+	products ProductSearcher
+	files    *common.FileService
+	logger   *zap.Logger
+}
+
+func NewShopService(repo domain.ShopRepository, products ProductSearcher, files *common.FileService, logger *zap.Logger) *ShopService {
+	return &ShopService{
+		repo:     repo,
+		products: products,
+		files:    files,
+		logger:   logger,
+	}
+}
+
+// CreateShop opens the shop of an approved seller, shown to customers once an
+// admin approves it. The logo and banner are optional.
+func (s *ShopService) CreateShop(ctx context.Context, claims *common.UserClaims, req CreateShopReq, logo, banner *multipart.FileHeader) (*AdminShopRes, error) {
+	sellerID, err := approvedSeller(claims)
+	if err != nil {
+		return nil, err
+	}
+	shop, err := domain.NewShop(sellerID, req.Name, req.Slug, req.Description, req.Location, req.Policies)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := s.repo.GetShopBySeller(ctx, sellerID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, common.NewErrorf(domain.ShopAlreadyExistsError, "seller %d already has a shop", sellerID)
+	}
+
+	uploaded, err := s.saveImages(shop, logo, banner)
+	if err != nil {
+		return nil, err
+	}
+	created, err := s.repo.CreateShop(ctx, shop)
+	if err != nil {
+		s.deleteFiles(uploaded...)
+		return nil, err
+	}
+
+	s.logger.Info("Shop created",
+		zap.Int64("shopID", created.ID()),
+		zap.Int64("sellerID", sellerID),
+		zap.String("slug", created.Slug()))
+	res := MapToAdminShopRes(created)
+	return &res, nil
+}
+
+// GetMyShop returns the shop of a seller whatever its status.
+func (s *ShopService) GetMyShop(ctx context.Context, sellerID int64) (*AdminShopRes, error) {
+	shop, err := s.sellerShop(ctx, sellerID)
+	if err != nil {
+		return nil, err
+	}
+	res := MapToAdminShopRes(shop)
+	return &res, nil
+}
+
+// UpdateShop changes the profile of an approved seller's shop. A new logo or
+// banner replaces the stored one, whose file is deleted.
+func (s *ShopService) UpdateShop(ctx context.Context, claims *common.UserClaims, req UpdateShopReq, logo, banner *multipart.FileHeader) (*AdminShopRes, error) {
+	sellerID, err := approvedSeller(claims)
+	if err != nil {
+		return nil, err
+	}
+	shop, err := s.sellerShop(ctx, sellerID)
+	if err != nil {
+		return nil, err
+	}
+	oldLogo, oldBanner := shop.LogoURL(), shop.BannerURL()
+	if err := shop.UpdateProfile(req.Name, req.Description, req.Location, req.Policies); err != nil {
+		return nil, err
+	}
+
+	uploaded, err := s.saveImages(shop, logo, banner)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateShop(ctx, shop); err != nil {
+		s.deleteFiles(uploaded...)
+		return nil, err
+	}
+
+	if logo != nil {
+		s.deleteFiles(oldLogo)
+	}
+	if banner != nil {
+		s.deleteFiles(oldBanner)
+	}
+	res := MapToAdminShopRes(shop)
+	return &res, nil
+}
+
+// GetShop returns an approved shop by its slug. Pending and suspended shops
+// are reported as not found.
+func (s *ShopService) GetShop(ctx context.Context, slug string) (*ShopRes, error) {
+	shop, err := s.publicShop(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+	res := MapToShopRes(shop)
+	return &res, nil
+}
+
+// ListShopProducts searches the products of an approved shop. The seller
+// filter of params is replaced by the shop's seller.
+func (s *ShopService) ListShopProducts(ctx context.Context, slug string, params pdomain.SearchParams) (*pdomain.SearchResult, error) {
+	shop, err := s.publicShop(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+	sellerID := shop.SellerID()
+	params.SellerID = &sellerID
+	return s.products.SearchProducts(ctx, params)
+}
+
+// ListShops lists shops for review, optionally only those in a status.
+func (s *ShopService) ListShops(ctx context.Context, status string, limit, offset int) ([]AdminShopRes, error) {
+	if status != "" && !domain.ShopStatus(status).IsValid() {
+		return nil, common.NewErrorf(domain.InvalidShopError, "unknown shop status %q", status)
+	}
+	if limit <= 0 {
+		limit = defaultShopsLimit
+	}
+	limit = min(limit, maxShopsLimit)
+	offset = max(offset, 0)
+
+	shops, err := s.repo.ListShops(ctx, domain.ShopStatus(status), limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]AdminShopRes, len(shops))
+	for i := range shops {
+		res[i] = MapToAdminShopRes(&shops[i])
+	}
+	return res, nil
+}
+
+// ApproveShop shows a pending shop to customers, or reinstates a suspended
+// shop and its products.
+func (s *ShopService) ApproveShop(ctx context.Context, adminID, shopID int64) (*AdminShopRes, error) {
+	shop, err := s.shopByID(ctx, shopID)
+	if err != nil {
+		return nil, err
+	}
+	if err := shop.Approve(); err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateShopStatus(ctx, shop, adminID); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Shop approved", zap.Int64("shopID", shopID), zap.Int64("adminID", adminID))
+	res := MapToAdminShopRes(shop)
+	return &res, nil
+}
+
+// SuspendShop hides a shop and removes its products from search.
+func (s *ShopService) SuspendShop(ctx context.Context, adminID, shopID int64, req SuspendShopReq) (*AdminShopRes, error) {
+	shop, err := s.shopByID(ctx, shopID)
+	if err != nil {
+		return nil, err
+	}
+	if err := shop.Suspend(req.Reason); err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateShopStatus(ctx, shop, adminID); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Shop suspended",
+		zap.Int64("shopID", shopID),
+		zap.Int64("adminID", adminID),
+		zap.String("reason", req.Reason))
+	res := MapToAdminShopRes(shop)
+	return &res, nil
+}
+
+// ExportPersonalData adds the shop of a seller, with its logo and banner, to
+// a personal data export. Users without a shop get no section.
+func (s *ShopService) ExportPersonalData(ctx context.Context, userID int64) (*common.PersonalDataSection, error) {
+	shop, err := s.repo.GetShopBySeller(ctx, userID)
+	if err != nil || shop == nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, url := range []string{shop.LogoURL(), shop.BannerURL()} {
+		if name, ok := s.files.FileName(url); ok {
+			files = append(files, name)
+		}
+	}
+	return &common.PersonalDataSection{Name: "shop", Data: MapToAdminShopRes(shop), Files: files}, nil
+}
+
+// ErasePersonalData suspends the shop of an erased seller and clears its
+// address. The shop name and slug are kept for the orders placed with it.
+func (s *ShopService) ErasePersonalData(ctx context.Context, userID int64) error {
+	shop, err := s.repo.GetShopBySeller(ctx, userID)
+	if err != nil || shop == nil {
+		return err
+	}
+
+	loc := shop.Location()
+	loc.Address = ""
+	if err := shop.UpdateProfile(shop.Name(), shop.Description(), loc, shop.Policies()); err != nil {
+		return err
+	}
+	if err := s.repo.UpdateShop(ctx, shop); err != nil {
+		return err
+	}
+	if shop.Status() == domain.ShopSuspended {
+		return nil
+	}
+	if err := shop.Suspend(erasedShopReason); err != nil {
+		return err
+	}
+	return s.repo.UpdateShopStatus(ctx, shop, 0)
+}
+
+func (s *ShopService) sellerShop(ctx context.Context, sellerID int64) (*domain.Shop, error) {
+	shop, err := s.repo.GetShopBySeller(ctx, sellerID)
+	if err != nil {
+		return nil, err
+	}
+	if shop == nil {
+		return nil, common.NewErrorf(domain.ShopNotFoundError, "seller %d has no shop", sellerID)
+	}
+	return shop, nil
+}
+
+func (s *ShopService) publicShop(ctx context.Context, slug string) (*domain.Shop, error) {
+	shop, err := s.repo.GetShopBySlug(ctx, domain.NormalizeSlug(slug))
+	if err != nil {
+		return nil, err
+	}
+	if shop == nil || !shop.IsPublic() {
+		return nil, common.NewErrorf(domain.ShopNotFoundError, "shop %q not found", slug)
+	}
+	return shop, nil
+}
+
+func (s *ShopService) shopByID(ctx context.Context, id int64) (*domain.Shop, error) {
+	shop, err := s.repo.GetShop(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if shop == nil {
+		return nil, common.NewErrorf(domain.ShopNotFoundError, "shop %d not found", id)
+	}
+	return shop, nil
+}
+
+// saveImages stores the given logo and banner on the shop and returns the
+// URLs of the saved files.
+func (s *ShopService) saveImages(shop *domain.Shop, logo, banner *multipart.FileHeader) ([]string, error) {
+	var uploaded []string
+	for _, img := range []struct {
+		file *multipart.FileHeader
+		set  func(string) string
+	}{{logo, shop.SetLogo}, {banner, shop.SetBanner}} {
+		if img.file == nil {
+			continue
+		}
+		url, err := s.files.SaveFile(img.file)
+		if err != nil {
+			s.deleteFiles(uploaded...)
+			return nil, common.NewErrorf(domain.FailedToUploadImage, "failed to upload image: %v", err)
+		}
+		img.set(url)
+		uploaded = append(uploaded, url)
+	}
+	return uploaded, nil
+}
+
+func (s *ShopService) deleteFiles(urls ...string) {
+	for _, url := range urls {
+		name, ok := s.files.FileName(url)
+		if !ok {
+			continue
+		}
+		if err := s.files.DeleteFile(name); err != nil {
+			s.logger.Warn("Failed to delete shop image", zap.String("file", name), zap.Error(err))
+		}
+	}
+}
+
+// approvedSeller returns the ID of the seller making the request. Sellers
+// pending approval or rejected cannot open or change a shop.
+func approvedSeller(claims *common.UserClaims) (int64, error) {
+	if claims == nil {
+		return 0, common.NewErrorf(common.AuthHeaderMissingErrorCode, "authorization header is missing")
+	}
+	if claims.SellerNotApproved() {
+		return 0, common.NewErrorf(common.PermissionDeniedErrorCode, "seller account is not approved")
+	}
+	return claims.ID, nil
+}
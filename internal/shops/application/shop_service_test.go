@@ -0,0 +1,284 @@
+package application
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"mime/multipart"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"yadwy-backend/internal/common"
+	pdomain "yadwy-backend/internal/prodcuts/domain"
+	"yadwy-backend/internal/shops/domain"
+	"yadwy-backend/internal/shops/domain/mock"
+
+	"go.uber.org/zap"
+)
+
+type fakeProducts struct {
+	params pdomain.SearchParams
+}
+
+func (f *fakeProducts) SearchProducts(ctx context.Context, params pdomain.SearchParams) (*pdomain.SearchResult, error) {
+	f.params = params
+	return &pdomain.SearchResult{Products: []*pdomain.Product{{ID: 7, SellerID: *params.SellerID}}, TotalCount: 1}, nil
+}
+
+func errorCode(err error) common.ErrorCode {
+	var appErr *common.Error
+	if errors.As(err, &appErr) {
+		return appErr.Code()
+	}
+	return ""
+}
+
+// seller is the approved seller of testShop.
+var seller = &common.UserClaims{ID: 5, Role: "SELLER", SellerStatus: "APPROVED"}
+
+func testShop(status domain.ShopStatus) *domain.Shop {
+	return domain.NewShopFromParams(domain.ShopParams{
+		ID: 3, SellerID: 5, Name: "Cairo Crafts", Slug: "cairo-crafts", Status: status,
+		Location: domain.Location{Governorate: "Cairo", City: "Cairo", Address: "12 El Moez St"},
+	})
+}
+
+func newTestShopService(t *testing.T, repo *mock.ShopRepository) (*ShopService, *fakeProducts, *common.FileService) {
+	t.Helper()
+	files := &common.FileService{StoragePath: t.TempDir(), BaseURL: "http://localhost:3000/images"}
+	products := &fakeProducts{}
+	return NewShopService(repo, products, files, zap.NewNop()), products, files
+}
+
+// fileHeader builds an uploaded file as the multipart parser does.
+func fileHeader(t *testing.T, field, name string) *multipart.FileHeader {
+	t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	part, err := mw.CreateFormFile(field, name)
+	if err != nil {
+		t.Fatalf("CreateFormFile() error = %v", err)
+	}
+	part.Write([]byte("png"))
+	mw.Close()
+
+	r := httptest.NewRequest("POST", "/", &body)
+	r.Header.Set("Content-Type", mw.FormDataContentType())
+	if err := r.ParseMultipartForm(1 << 20); err != nil {
+		t.Fatalf("ParseMultipartForm() error = %v", err)
+	}
+	return r.MultipartForm.File[field][0]
+}
+
+func TestShopService_CreateShop(t *testing.T) {
+	ctx := context.Background()
+	req := CreateShopReq{Name: "Cairo Crafts", Slug: "cairo-crafts", Location: domain.Location{City: "Cairo"}}
+
+	t.Run("should create a pending shop with its logo", func(t *testing.T) {
+		service, _, files := newTestShopService(t, &mock.ShopRepository{})
+
+		res, err := service.CreateShop(ctx, seller, req, fileHeader(t, "logo", "logo.png"), nil)
+		if err != nil {
+			t.Fatalf("CreateShop() error = %v", err)
+		}
+		if res.Status != string(domain.ShopPending) || res.SellerID != 5 {
+			t.Errorf("CreateShop() = %+v, want pending shop of seller 5", res)
+		}
+		name, ok := files.FileName(res.LogoURL)
+		if !ok {
+			t.Fatalf("logo URL %q is not stored", res.LogoURL)
+		}
+		if _, err := os.Stat(filepath.Join(files.StoragePath, name)); err != nil {
+			t.Errorf("logo file is missing: %v", err)
+		}
+	})
+
+	t.Run("should reject a second shop", func(t *testing.T) {
+		service, _, _ := newTestShopService(t, &mock.ShopRepository{
+			GetShopBySellerFunc: func(ctx context.Context, sellerID int64) (*domain.Shop, error) {
+				return testShop(domain.ShopApproved), nil
+			},
+		})
+
+		_, err := service.CreateShop(ctx, seller, req, nil, nil)
+		if got := errorCode(err); got != domain.ShopAlreadyExistsError {
+			t.Errorf("CreateShop() error code = %v, want %v", got, domain.ShopAlreadyExistsError)
+		}
+	})
+
+	t.Run("should delete uploaded images when the slug is taken", func(t *testing.T) {
+		service, _, files := newTestShopService(t, &mock.ShopRepository{
+			CreateShopFunc: func(ctx context.Context, shop *domain.Shop) (*domain.Shop, error) {
+				return nil, common.NewErrorf(domain.SlugTakenError, "shop slug %q is taken", shop.Slug())
+			},
+		})
+
+		_, err := service.CreateShop(ctx, seller, req, fileHeader(t, "logo", "logo.png"), fileHeader(t, "banner", "banner.png"))
+		if got := errorCode(err); got != domain.SlugTakenError {
+			t.Fatalf("CreateShop() error code = %v, want %v", got, domain.SlugTakenError)
+		}
+		if left, _ := os.ReadDir(files.StoragePath); len(left) != 0 {
+			t.Errorf("CreateShop() left %d files in the storage", len(left))
+		}
+	})
+	t.Run("should reject sellers that are not approved", func(t *testing.T) {
+		service, _, files := newTestShopService(t, &mock.ShopRepository{})
+
+		for _, status := range []string{"PENDING", "REJECTED"} {
+			claims := &common.UserClaims{ID: 5, Role: "SELLER", SellerStatus: status}
+			_, err := service.CreateShop(ctx, claims, req, fileHeader(t, "logo", "logo.png"), nil)
+			if got := errorCode(err); got != common.PermissionDeniedErrorCode {
+				t.Errorf("CreateShop() %s error code = %v, want %v", status, got, common.PermissionDeniedErrorCode)
+			}
+			_, err = service.UpdateShop(ctx, claims, UpdateShopReq{Name: "Fustat Pottery"}, nil, fileHeader(t, "banner", "banner.png"))
+			if got := errorCode(err); got != common.PermissionDeniedErrorCode {
+				t.Errorf("UpdateShop() %s error code = %v, want %v", status, got, common.PermissionDeniedErrorCode)
+			}
+		}
+		if left, _ := os.ReadDir(files.StoragePath); len(left) != 0 {
+			t.Errorf("rejected requests stored %d files", len(left))
+		}
+	})
+}
+
+func TestShopService_UpdateShop(t *testing.T) {
+	service, _, files := newTestShopService(t, nil)
+	old := filepath.Join(files.StoragePath, "old.png")
+	if err := os.WriteFile(old, []byte("png"), 0600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	shop := testShop(domain.ShopApproved)
+	shop.SetLogo(files.GetFileURL("old.png"))
+	var stored *domain.Shop
+	service.repo = &mock.ShopRepository{
+		GetShopBySellerFunc: func(ctx context.Context, sellerID int64) (*domain.Shop, error) {
+			return shop, nil
+		},
+		UpdateShopFunc: func(ctx context.Context, s *domain.Shop) error {
+			stored = s
+			return nil
+		},
+	}
+
+	res, err := service.UpdateShop(context.Background(), seller, UpdateShopReq{Name: "Fustat Pottery"}, fileHeader(t, "logo", "new.png"), nil)
+	if err != nil {
+		t.Fatalf("UpdateShop() error = %v", err)
+	}
+	if stored == nil || stored.Name() != "Fustat Pottery" || res.Slug != "cairo-crafts" {
+		t.Errorf("UpdateShop() stored %+v, want renamed shop keeping its slug", res)
+	}
+	if res.LogoURL == files.GetFileURL("old.png") {
+		t.Errorf("UpdateShop() kept the old logo")
+	}
+	if _, err := os.Stat(old); !os.IsNotExist(err) {
+		t.Errorf("old logo still exists, stat error = %v", err)
+	}
+}
+
+func TestShopService_PublicShop(t *testing.T) {
+	ctx := context.Background()
+
+	for _, status := range []domain.ShopStatus{domain.ShopPending, domain.ShopSuspended} {
+		t.Run("should hide "+string(status)+" shops", func(t *testing.T) {
+			service, _, _ := newTestShopService(t, &mock.ShopRepository{
+				GetShopBySlugFunc: func(ctx context.Context, slug string) (*domain.Shop, error) {
+					return testShop(status), nil
+				},
+			})
+
+			if _, err := service.GetShop(ctx, "cairo-crafts"); errorCode(err) != domain.ShopNotFoundError {
+				t.Errorf("GetShop() error = %v, want %v", err, domain.ShopNotFoundError)
+			}
+			if _, err := service.ListShopProducts(ctx, "cairo-crafts", pdomain.SearchParams{}); errorCode(err) != domain.ShopNotFoundError {
+				t.Errorf("ListShopProducts() error = %v, want %v", err, domain.ShopNotFoundError)
+			}
+		})
+	}
+
+	t.Run("should list the products of the shop seller", func(t *testing.T) {
+		var lookedUp string
+		service, products, _ := newTestShopService(t, &mock.ShopRepository{
+			GetShopBySlugFunc: func(ctx context.Context, slug string) (*domain.Shop, error) {
+				lookedUp = slug
+				return testShop(domain.ShopApproved), nil
+			},
+		})
+		other := int64(9)
+
+		res, err := service.ListShopProducts(ctx, "Cairo-Crafts", pdomain.SearchParams{SellerID: &other, Limit: 10})
+		if err != nil {
+			t.Fatalf("ListShopProducts() error = %v", err)
+		}
+		if lookedUp != "cairo-crafts" {
+			t.Errorf("GetShopBySlug() slug = %q, want normalized slug", lookedUp)
+		}
+		if *products.params.SellerID != 5 || len(res.Products) != 1 {
+			t.Errorf("SearchProducts() seller = %d, want 5", *products.params.SellerID)
+		}
+	})
+}
+
+func TestShopService_Review(t *testing.T) {
+	ctx := context.Background()
+	var reviewer int64
+	var stored *domain.Shop
+	shop := testShop(domain.ShopPending)
+	service, _, _ := newTestShopService(t, &mock.ShopRepository{
+		GetShopFunc: func(ctx context.Context, id int64) (*domain.Shop, error) {
+			if id != shop.ID() {
+				return nil, nil
+			}
+			return shop, nil
+		},
+		UpdateShopStatusFunc: func(ctx context.Context, s *domain.Shop, reviewerID int64) error {
+			stored, reviewer = s, reviewerID
+			return nil
+		},
+	})
+
+	if _, err := service.ApproveShop(ctx, 1, shop.ID()); err != nil || stored.Status() != domain.ShopApproved || reviewer != 1 {
+		t.Fatalf("ApproveShop() error = %v, status = %v, reviewer = %d", err, shop.Status(), reviewer)
+	}
+
+	res, err := service.SuspendShop(ctx, 1, shop.ID(), SuspendShopReq{Reason: "counterfeit products"})
+	if err != nil {
+		t.Fatalf("SuspendShop() error = %v", err)
+	}
+	if res.Status != string(domain.ShopSuspended) || res.SuspensionReason != "counterfeit products" {
+		t.Errorf("SuspendShop() = %+v, want suspended with reason", res)
+	}
+	if _, err := service.SuspendShop(ctx, 1, shop.ID(), SuspendShopReq{}); errorCode(err) != domain.InvalidShopStatusError {
+		t.Errorf("SuspendShop() twice error = %v, want %v", err, domain.InvalidShopStatusError)
+	}
+	if _, err := service.ApproveShop(ctx, 1, 99); errorCode(err) != domain.ShopNotFoundError {
+		t.Errorf("ApproveShop() unknown shop error = %v, want %v", err, domain.ShopNotFoundError)
+	}
+	if _, err := service.ListShops(ctx, "CLOSED", 0, 0); errorCode(err) != domain.InvalidShopError {
+		t.Errorf("ListShops() unknown status error = %v, want %v", err, domain.InvalidShopError)
+	}
+}
+
+func TestShopService_ErasePersonalData(t *testing.T) {
+	shop := testShop(domain.ShopApproved)
+	var reviewer int64 = -1
+	service, _, _ := newTestShopService(t, &mock.ShopRepository{
+		GetShopBySellerFunc: func(ctx context.Context, sellerID int64) (*domain.Shop, error) {
+			return shop, nil
+		},
+		UpdateShopStatusFunc: func(ctx context.Context, s *domain.Shop, reviewerID int64) error {
+			reviewer = reviewerID
+			return nil
+		},
+	})
+
+	if err := service.ErasePersonalData(context.Background(), 5); err != nil {
+		t.Fatalf("ErasePersonalData() error = %v", err)
+	}
+	if shop.Status() != domain.ShopSuspended || reviewer != 0 {
+		t.Errorf("erased shop status = %v by %d, want suspended by 0", shop.Status(), reviewer)
+	}
+	if shop.Location().Address != "" {
+		t.Errorf("erased shop keeps its address %q", shop.Location().Address)
+	}
+}
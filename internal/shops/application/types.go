@@ -0,0 +1,74 @@
+package application
+
+import (
+	"time"
+	"yadwy-backend/internal/shops/domain"
+)
+
+// CreateShopReq represents the shop details of a new shop. The slug is used
+// in the shop URL and cannot be changed later.
+type CreateShopReq struct {
+	Name        string          `json:"name" validate:"required,max=100" example:"Cairo Crafts"`
+	Slug        string          `json:"slug" validate:"required,max=60" example:"cairo-crafts"`
+	Description string          `json:"description" validate:"max=2000" example:"Handmade pottery from Fustat"`
+	Location    domain.Location `json:"location"`
+	Policies    domain.Policies `json:"policies"`
+}
+
+// UpdateShopReq represents the shop details a seller can change
+type UpdateShopReq struct {
+	Name        string          `json:"name" validate:"required,max=100" example:"Cairo Crafts"`
+	Description string          `json:"description" validate:"max=2000" example:"Handmade pottery from Fustat"`
+	Location    domain.Location `json:"location"`
+	Policies    domain.Policies `json:"policies"`
+}
+
+// SuspendShopReq represents the payload for suspending a shop
+type SuspendShopReq struct {
+	Reason string `json:"reason" validate:"max=500" example:"Counterfeit products"`
+}
+
+type ShopRes struct {
+	ID          int64           `json:"id"`
+	SellerID    int64           `json:"seller_id"`
+	Name        string          `json:"name"`
+	Slug        string          `json:"slug"`
+	Description string          `json:"description,omitempty"`
+	LogoURL     string          `json:"logo_url,omitempty"`
+	BannerURL   string          `json:"banner_url,omitempty"`
+	Location    domain.Location `json:"location"`
+	Policies    domain.Policies `json:"policies"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+// AdminShopRes adds the review state, shown to the owner and admins
+type AdminShopRes struct {
+	ShopRes
+	Status           string    `json:"status"`
+	SuspensionReason string    `json:"suspension_reason,omitempty"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+func MapToShopRes(s *domain.Shop) ShopRes {
+	return ShopRes{
+		ID:          s.ID(),
+		SellerID:    s.SellerID(),
+		Name:        s.Name(),
+		Slug:        s.Slug(),
+		Description: s.Description(),
+		LogoURL:     s.LogoURL(),
+		BannerURL:   s.BannerURL(),
+		Location:    s.Location(),
+		Policies:    s.Policies(),
+		CreatedAt:   s.CreatedAt(),
+	}
+}
+
+func MapToAdminShopRes(s *domain.Shop) AdminShopRes {
+	return AdminShopRes{
+		ShopRes:          MapToShopRes(s),
+		Status:           string(s.Status()),
+		SuspensionReason: s.SuspensionReason(),
+		UpdatedAt:        s.UpdatedAt(),
+	}
+}
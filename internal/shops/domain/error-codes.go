@@ -0,0 +1,12 @@
+package domain
+
+import "yadwy-backend/internal/common"
+
+const (
+	ShopNotFoundError      common.ErrorCode = "shop-not-found"
+	ShopAlreadyExistsError common.ErrorCode = "shop-already-exists"
+	SlugTakenError         common.ErrorCode = "shop-slug-taken"
+	InvalidShopError       common.ErrorCode = "invalid-shop"
+	InvalidShopStatusError common.ErrorCode = "invalid-shop-status"
+	FailedToUploadImage    common.ErrorCode = "failed-to-upload-image"
+)
@@ -0,0 +1,66 @@
+package mock
+
+import (
+	"context"
+	"yadwy-backend/internal/shops/domain"
+)
+
+// ShopRepository is a simple mock implementation of domain.ShopRepository
+type ShopRepository struct {
+	CreateShopFunc       func(ctx context.Context, shop *domain.Shop) (*domain.Shop, error)
+	GetShopFunc          func(ctx context.Context, id int64) (*domain.Shop, error)
+	GetShopBySlugFunc    func(ctx context.Context, slug string) (*domain.Shop, error)
+	GetShopBySellerFunc  func(ctx context.Context, sellerID int64) (*domain.Shop, error)
+	ListShopsFunc        func(ctx context.Context, status domain.ShopStatus, limit, offset int) ([]domain.Shop, error)
+	UpdateShopFunc       func(ctx context.Context, shop *domain.Shop) error
+	UpdateShopStatusFunc func(ctx context.Context, shop *domain.Shop, reviewerID int64) error
+}
+
+func (m *ShopRepository) CreateShop(ctx context.Context, shop *domain.Shop) (*domain.Shop, error) {
+	if m.CreateShopFunc != nil {
+		return m.CreateShopFunc(ctx, shop)
+	}
+	return shop, nil
+}
+
+func (m *ShopRepository) GetShop(ctx context.Context, id int64) (*domain.Shop, error) {
+	if m.GetShopFunc != nil {
+		return m.GetShopFunc(ctx, id)
+	}
+	return nil, nil
+}
+
+func (m *ShopRepository) GetShopBySlug(ctx context.Context, slug string) (*domain.Shop, error) {
+	if m.GetShopBySlugFunc != nil {
+		return m.GetShopBySlugFunc(ctx, slug)
+	}
+	return nil, nil
+}
+
+func (m *ShopRepository) GetShopBySeller(ctx context.Context, sellerID int64) (*domain.Shop, error) {
+	if m.GetShopBySellerFunc != nil {
+		return m.GetShopBySellerFunc(ctx, sellerID)
+	}
+	return nil, nil
+}
+
+func (m *ShopRepository) ListShops(ctx context.Context, status domain.ShopStatus, limit, offset int) ([]domain.Shop, error) {
+	if m.ListShopsFunc != nil {
+		return m.ListShopsFunc(ctx, status, limit, offset)
+	}
+	return nil, nil
+}
+
+func (m *ShopRepository) UpdateShop(ctx context.Context, shop *domain.Shop) error {
+	if m.UpdateShopFunc != nil {
+		return m.UpdateShopFunc(ctx, shop)
+	}
+	return nil
+}
+
+func (m *ShopRepository) UpdateShopStatus(ctx context.Context, shop *domain.Shop, reviewerID int64) error {
+	if m.UpdateShopStatusFunc != nil {
+		return m.UpdateShopStatusFunc(ctx, shop, reviewerID)
+	}
+	return nil
+}
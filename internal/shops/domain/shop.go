@@ -0,0 +1,234 @@
+package domain
+
+import (
+	"regexp"
+	"strings"
+	"time"
+	"yadwy-backend/internal/common"
+)
+
+// ShopStatus tracks the review of a shop. Only approved shops are shown to
+// customers, and the products of suspended shops are hidden from search.
+type ShopStatus string
+
+const (
+	ShopPending   ShopStatus = "PENDING"
+	ShopApproved  ShopStatus = "APPROVED"
+	ShopSuspended ShopStatus = "SUSPENDED"
+)
+
+func (s ShopStatus) IsValid() bool {
+	switch s {
+	case ShopPending, ShopApproved, ShopSuspended:
+		return true
+	}
+	return false
+}
+
+const (
+	maxShopNameLength = 100
+	maxSlugLength     = 60
+)
+
+// slugPattern allows lower case words joined by single dashes, e.g.
+// "cairo-crafts".
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// reservedSlugs collide with the routes under /shops.
+var reservedSlugs = map[string]bool{"me": true}
+
+// Location is where a shop is based.
+type Location struct {
+	Governorate string `json:"governorate"`
+	City        string `json:"city"`
+	Address     string `json:"address,omitempty"`
+}
+
+// Policies are the terms a shop shows to customers.
+type Policies struct {
+	Shipping string `json:"shipping,omitempty"`
+	Returns  string `json:"returns,omitempty"`
+}
+
+// Shop is the storefront of a seller. A seller has at most one shop, found
+// by its slug in public URLs.
+type Shop struct {
+	id               int64
+	sellerID         int64
+	name             string
+	slug             string
+	description      string
+	logoURL          string
+	bannerURL        string
+	location         Location
+	policies         Policies
+	status           ShopStatus
+	suspensionReason string
+	createdAt        time.Time
+	updatedAt        time.Time
+}
+
+// ShopParams holds every shop attribute, used to rebuild a Shop from storage.
+type ShopParams struct {
+	ID               int64
+	SellerID         int64
+	Name             string
+	Slug             string
+	Description      string
+	LogoURL          string
+	BannerURL        string
+	Location         Location
+	Policies         Policies
+	Status           ShopStatus
+	SuspensionReason string
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+}
+
+// NewShop validates a new shop, which waits for approval.
+func NewShop(sellerID int64, name, slug, description string, location Location, policies Policies) (*Shop, error) {
+	s := &Shop{
+		sellerID: sellerID,
+		slug:     NormalizeSlug(slug),
+		status:   ShopPending,
+	}
+	if !slugPattern.MatchString(s.slug) || len(s.slug) > maxSlugLength {
+		return nil, common.NewErrorf(InvalidShopError, "slug must be lower case letters, digits and dashes, at most %d characters", maxSlugLength)
+	}
+	if reservedSlugs[s.slug] {
+		return nil, common.NewErrorf(SlugTakenError, "shop slug %q is reserved", s.slug)
+	}
+	if err := s.UpdateProfile(name, description, location, policies); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func NewShopFromParams(p ShopParams) *Shop {
+	return &Shop{
+		id:               p.ID,
+		sellerID:         p.SellerID,
+		name:             p.Name,
+		slug:             p.Slug,
+		description:      p.Description,
+		logoURL:          p.LogoURL,
+		bannerURL:        p.BannerURL,
+		location:         p.Location,
+		policies:         p.Policies,
+		status:           p.Status,
+		suspensionReason: p.SuspensionReason,
+		createdAt:        p.CreatedAt,
+		updatedAt:        p.UpdatedAt,
+	}
+}
+
+// NormalizeSlug trims and lower cases a slug, so lookups match however the
+// URL was typed.
+func NormalizeSlug(slug string) string {
+	return strings.ToLower(strings.TrimSpace(slug))
+}
+
+func (s *Shop) ID() int64 {
+	return s.id
+}
+
+func (s *Shop) SellerID() int64 {
+	return s.sellerID
+}
+
+func (s *Shop) Name() string {
+	return s.name
+}
+
+func (s *Shop) Slug() string {
+	return s.slug
+}
+
+func (s *Shop) Description() string {
+	return s.description
+}
+
+func (s *Shop) LogoURL() string {
+	return s.logoURL
+}
+
+func (s *Shop) BannerURL() string {
+	return s.bannerURL
+}
+
+func (s *Shop) Location() Location {
+	return s.location
+}
+
+func (s *Shop) Policies() Policies {
+	return s.policies
+}
+
+func (s *Shop) Status() ShopStatus {
+	return s.status
+}
+
+func (s *Shop) SuspensionReason() string {
+	return s.suspensionReason
+}
+
+func (s *Shop) CreatedAt() time.Time {
+	return s.createdAt
+}
+
+func (s *Shop) UpdatedAt() time.Time {
+	return s.updatedAt
+}
+
+// IsPublic tells customers can see the shop.
+func (s *Shop) IsPublic() bool {
+	return s.status == ShopApproved
+}
+
+// UpdateProfile replaces the details the seller manages. The slug is kept so
+// that shared links keep working.
+func (s *Shop) UpdateProfile(name, description string, location Location, policies Policies) error {
+	name = strings.TrimSpace(name)
+	if name == "" || len([]rune(name)) > maxShopNameLength {
+		return common.NewErrorf(InvalidShopError, "name is required and must be at most %d characters", maxShopNameLength)
+	}
+	s.name = name
+	s.description = description
+	s.location = location
+	s.policies = policies
+	return nil
+}
+
+// SetLogo replaces the logo and returns the previous URL, if any.
+func (s *Shop) SetLogo(url string) string {
+	old := s.logoURL
+	s.logoURL = url
+	return old
+}
+
+// SetBanner replaces the banner and returns the previous URL, if any.
+func (s *Shop) SetBanner(url string) string {
+	old := s.bannerURL
+	s.bannerURL = url
+	return old
+}
+
+// Approve shows the shop to customers, and reinstates a suspended shop.
+func (s *Shop) Approve() error {
+	if s.status == ShopApproved {
+		return common.NewErrorf(InvalidShopStatusError, "shop %d is already approved", s.id)
+	}
+	s.status = ShopApproved
+	s.suspensionReason = ""
+	return nil
+}
+
+// Suspend hides the shop and its products until it is approved again.
+func (s *Shop) Suspend(reason string) error {
+	if s.status == ShopSuspended {
+		return common.NewErrorf(InvalidShopStatusError, "shop %d is already suspended", s.id)
+	}
+	s.status = ShopSuspended
+	s.suspensionReason = reason
+	return nil
+}
@@ -0,0 +1,22 @@
+package domain
+
+import "context"
+
+type ShopRepository interface {
+	// CreateShop fails with ShopAlreadyExistsError when the seller has a shop
+	// and with SlugTakenError when the slug is used.
+	CreateShop(ctx context.Context, shop *Shop) (*Shop, error)
+	// GetShop, GetShopBySlug and GetShopBySeller return nil when there is no
+	// such shop.
+	GetShop(ctx context.Context, id int64) (*Shop, error)
+	GetShopBySlug(ctx context.Context, slug string) (*Shop, error)
+	GetShopBySeller(ctx context.Context, sellerID int64) (*Shop, error)
+	// ListShops returns shops in the given status, or all shops for an empty
+	// status, oldest first.
+	ListShops(ctx context.Context, status ShopStatus, limit, offset int) ([]Shop, error)
+	// UpdateShop stores the profile and images of the shop.
+	UpdateShop(ctx context.Context, shop *Shop) error
+	// UpdateShopStatus stores a review along with the reviewing admin, 0 when
+	// the change is not made by an admin.
+	UpdateShopStatus(ctx context.Context, shop *Shop, reviewerID int64) error
+}
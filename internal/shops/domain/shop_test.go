@@ -0,0 +1,79 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"yadwy-backend/internal/common"
+)
+
+func errorCode(err error) common.ErrorCode {
+	var appErr *common.Error
+	if errors.As(err, &appErr) {
+		return appErr.Code()
+	}
+	return ""
+}
+
+func TestNewShop(t *testing.T) {
+	tests := []struct {
+		name     string
+		shopName string
+		slug     string
+		wantSlug string
+		wantErr  common.ErrorCode
+	}{
+		{name: "should accept a valid slug", shopName: "Cairo Crafts", slug: "cairo-crafts", wantSlug: "cairo-crafts"},
+		{name: "should normalize the slug", shopName: "Cairo Crafts", slug: " Cairo-Crafts ", wantSlug: "cairo-crafts"},
+		{name: "should reject spaces in the slug", shopName: "Cairo Crafts", slug: "cairo crafts", wantErr: InvalidShopError},
+		{name: "should reject a trailing dash", shopName: "Cairo Crafts", slug: "cairo-", wantErr: InvalidShopError},
+		{name: "should reject double dashes", shopName: "Cairo Crafts", slug: "cairo--crafts", wantErr: InvalidShopError},
+		{name: "should reject reserved slugs", shopName: "Cairo Crafts", slug: "me", wantErr: SlugTakenError},
+		{name: "should require a name", shopName: "  ", slug: "cairo-crafts", wantErr: InvalidShopError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shop, err := NewShop(1, tt.shopName, tt.slug, "", Location{City: "Cairo"}, Policies{})
+			if got := errorCode(err); got != tt.wantErr {
+				t.Fatalf("NewShop() error = %v, want code %q", err, tt.wantErr)
+			}
+			if tt.wantErr != "" {
+				return
+			}
+			if shop.Slug() != tt.wantSlug {
+				t.Errorf("Slug() = %q, want %q", shop.Slug(), tt.wantSlug)
+			}
+			if shop.Status() != ShopPending || shop.IsPublic() {
+				t.Errorf("new shop status = %v, want pending and hidden", shop.Status())
+			}
+		})
+	}
+}
+
+func TestShop_Review(t *testing.T) {
+	shop, err := NewShop(1, "Cairo Crafts", "cairo-crafts", "", Location{}, Policies{})
+	if err != nil {
+		t.Fatalf("NewShop() error = %v", err)
+	}
+
+	if err := shop.Approve(); err != nil || !shop.IsPublic() {
+		t.Fatalf("Approve() error = %v, public = %v", err, shop.IsPublic())
+	}
+	if err := shop.Approve(); errorCode(err) != InvalidShopStatusError {
+		t.Errorf("Approve() twice error = %v, want %q", err, InvalidShopStatusError)
+	}
+
+	if err := shop.Suspend("counterfeit products"); err != nil {
+		t.Fatalf("Suspend() error = %v", err)
+	}
+	if shop.IsPublic() || shop.SuspensionReason() != "counterfeit products" {
+		t.Errorf("suspended shop public = %v, reason = %q", shop.IsPublic(), shop.SuspensionReason())
+	}
+	if err := shop.Suspend("again"); errorCode(err) != InvalidShopStatusError {
+		t.Errorf("Suspend() twice error = %v, want %q", err, InvalidShopStatusError)
+	}
+
+	if err := shop.Approve(); err != nil || shop.SuspensionReason() != "" {
+		t.Errorf("Approve() after suspension error = %v, reason = %q", err, shop.SuspensionReason())
+	}
+}
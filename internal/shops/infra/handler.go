@@ -0,0 +1,412 @@
+package infra
+
+import (
+	"encoding/json"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"yadwy-backend/internal/common"
+	papp "yadwy-backend/internal/prodcuts/application"
+	pdomain "yadwy-backend/internal/prodcuts/domain"
+	"yadwy-backend/internal/shops/application"
+	"yadwy-backend/internal/shops/domain"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+)
+
+const (
+	InvalidRequestBody = "invalid-request-body"
+	InvalidShopID      = "invalid-shop-id"
+
+	// maxShopFormSize bounds the multipart form holding the logo and banner.
+	maxShopFormSize = 10 << 20
+)
+
+var validate = validator.New()
+
+type ShopHandler struct {
+	service *application.ShopService
+	logger  *zap.Logger
+}
+
+func NewShopHandler(service *application.ShopService, logger *zap.Logger) *ShopHandler {
+	return &ShopHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// LoadShopRoutes registers the seller and public routes under /shops and the
+// review routes under /admin/shops.
+func LoadShopRoutes(router chi.Router, service *application.ShopService, jwt *common.JWTGenerator, policy *common.PermissionPolicy, logger *zap.Logger) {
+	h := NewShopHandler(service, logger)
+
+	router.Route("/shops", func(r chi.Router) {
+		// Seller routes
+		r.Group(func(r chi.Router) {
+			r.Use(common.GetAuthMiddlewareFunc(jwt))
+			r.Use(common.RequirePermission(policy, common.PermissionShopCreate))
+			r.Post("/", h.CreateShop)
+			r.Get("/me", h.GetMyShop)
+			r.Put("/me", h.UpdateShop)
+		})
+
+		// Public routes
+		r.Get("/{slug}", h.GetShop)
+		r.Get("/{slug}/products", h.ListShopProducts)
+	})
+
+	router.Route("/admin/shops", func(r chi.Router) {
+		r.Use(common.GetAuthMiddlewareFunc(jwt))
+		r.Use(common.RequirePermission(policy, common.PermissionShopReview))
+		r.Get("/", h.ListShops)
+		r.Post("/{id}/approve", h.ApproveShop)
+		r.Post("/{id}/suspend", h.SuspendShop)
+	})
+}
+
+// @Summary Create a shop
+// @Description Open the shop of the authenticated seller. The shop is shown to customers once an admin approves it.
+// @Tags shops
+// @Security BearerAuth
+// @Accept multipart/form-data
+// @Produce json
+// @Param shop formData string true "Shop data in JSON format, see application.CreateShopReq"
+// @Param logo formData file false "Shop logo"
+// @Param banner formData file false "Shop banner"
+// @Success 201 {object} application.AdminShopRes
+// @Failure 400 {object} common.ErrorResponse "Invalid input"
+// @Failure 401 {object} common.ErrorResponse
+// @Failure 403 {object} common.ErrorResponse "Forbidden - Approved sellers only"
+// @Failure 409 {object} common.ErrorResponse "The seller has a shop or the slug is taken"
+// @Router /shops [post]
+func (h *ShopHandler) CreateShop(w http.ResponseWriter, r *http.Request) {
+	claims, err := common.GetLoggedInUser(r)
+	if err != nil {
+		common.SendError(w, http.StatusUnauthorized, "unauthorized", "user not authenticated")
+		return
+	}
+
+	req, logo, banner, ok := decodeShopForm[application.CreateShopReq](w, r)
+	if !ok {
+		return
+	}
+
+	res, err := h.service.CreateShop(r.Context(), claims, req, logo, banner)
+	if err != nil {
+		h.logger.Error("Failed to create shop", zap.Error(err))
+		handleError(w, err)
+		return
+	}
+
+	if err = common.Encode(w, http.StatusCreated, res); err != nil {
+		handleError(w, err)
+		return
+	}
+}
+
+// @Summary Get my shop
+// @Description Get the shop of the authenticated seller, with its review status
+// @Tags shops
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} application.AdminShopRes
+// @Failure 401 {object} common.ErrorResponse
+// @Failure 404 {object} common.ErrorResponse "The seller has no shop"
+// @Router /shops/me [get]
+func (h *ShopHandler) GetMyShop(w http.ResponseWriter, r *http.Request) {
+	claims, err := common.GetLoggedInUser(r)
+	if err != nil {
+		common.SendError(w, http.StatusUnauthorized, "unauthorized", "user not authenticated")
+		return
+	}
+
+	res, err := h.service.GetMyShop(r.Context(), claims.ID)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	if err = common.Encode(w, http.StatusOK, res); err != nil {
+		handleError(w, err)
+		return
+	}
+}
+
+// @Summary Update my shop
+// @Description Update the profile of the authenticated seller's shop. A new logo or banner replaces the current one.
+// @Tags shops
+// @Security BearerAuth
+// @Accept multipart/form-data
+// @Produce json
+// @Param shop formData string true "Shop data in JSON format, see application.UpdateShopReq"
+// @Param logo formData file false "Shop logo"
+// @Param banner formData file false "Shop banner"
+// @Success 200 {object} application.AdminShopRes
+// @Failure 400 {object} common.ErrorResponse "Invalid input"
+// @Failure 401 {object} common.ErrorResponse
+// @Failure 403 {object} common.ErrorResponse "Forbidden - Approved sellers only"
+// @Failure 404 {object} common.ErrorResponse "The seller has no shop"
+// @Router /shops/me [put]
+func (h *ShopHandler) UpdateShop(w http.ResponseWriter, r *http.Request) {
+	claims, err := common.GetLoggedInUser(r)
+	if err != nil {
+		common.SendError(w, http.StatusUnauthorized, "unauthorized", "user not authenticated")
+		return
+	}
+
+	req, logo, banner, ok := decodeShopForm[application.UpdateShopReq](w, r)
+	if !ok {
+		return
+	}
+
+	res, err := h.service.UpdateShop(r.Context(), claims, req, logo, banner)
+	if err != nil {
+		h.logger.Error("Failed to update shop", zap.Error(err))
+		handleError(w, err)
+		return
+	}
+
+	if err = common.Encode(w, http.StatusOK, res); err != nil {
+		handleError(w, err)
+		return
+	}
+}
+
+// @Summary Get a shop
+// @Description Get the public page of an approved shop by its slug
+// @Tags shops
+// @Produce json
+// @Param slug path string true "Shop slug"
+// @Success 200 {object} application.ShopRes
+// @Failure 404 {object} common.ErrorResponse
+// @Router /shops/{slug} [get]
+func (h *ShopHandler) GetShop(w http.ResponseWriter, r *http.Request) {
+	res, err := h.service.GetShop(r.Context(), chi.URLParam(r, "slug"))
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	if err = common.Encode(w, http.StatusOK, res); err != nil {
+		handleError(w, err)
+		return
+	}
+}
+
+// @Summary List shop products
+// @Description Search the products of an approved shop
+// @Tags shops
+// @Produce json
+// @Param slug path string true "Shop slug"
+// @Param query query string false "Search query"
+// @Param category_id query string false "Category ID"
+// @Param sort_by query string false "Sort field (relevance, name, price, created_at)"
+// @Param sort_dir query string false "Sort direction (asc, desc)"
+// @Param limit query integer false "Number of items to return (default: 10)"
+// @Param offset query integer false "Number of items to skip (default: 0)"
+// @Param cursor query string false "next_cursor or prev_cursor of a previous page, with the same filters and sort, instead of offset"
+// @Param skip_count query boolean false "Leave out the total count, returning total_count as -1"
+// @Param facets query boolean false "Also count the matching products by category, label, stock and price"
+// @Success 200 {object} domain.SearchResult
+// @Failure 400 {object} common.ErrorResponse "Invalid cursor"
+// @Failure 404 {object} common.ErrorResponse
+// @Router /shops/{slug}/products [get]
+func (h *ShopHandler) ListShopProducts(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	params := pdomain.SearchParams{
+		Query:      query.Get("query"),
+		CategoryID: query.Get("category_id"),
+		Limit:      10,
+		SortBy:     query.Get("sort_by"),
+		SortDir:    query.Get("sort_dir"),
+		Cursor:     query.Get("cursor"),
+		SkipCount:  query.Get("skip_count") == "true" || query.Get("skip_count") == "1",
+		Facets:     query.Get("facets") == "true" || query.Get("facets") == "1",
+	}
+	if limit, err := strconv.Atoi(query.Get("limit")); err == nil && limit > 0 {
+		params.Limit = limit
+	}
+	if offset, err := strconv.Atoi(query.Get("offset")); err == nil && offset >= 0 {
+		params.Offset = offset
+	}
+
+	res, err := h.service.ListShopProducts(r.Context(), chi.URLParam(r, "slug"), params)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	if err = common.Encode(w, http.StatusOK, res); err != nil {
+		handleError(w, err)
+		return
+	}
+}
+
+// @Summary List shops
+// @Description List shops for review, oldest first
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Param status query string false "Shop status (PENDING, APPROVED, SUSPENDED)"
+// @Param limit query integer false "Number of shops to return (default: 20, max: 100)"
+// @Param offset query integer false "Number of shops to skip (default: 0)"
+// @Success 200 {array} application.AdminShopRes
+// @Failure 400 {object} common.ErrorResponse
+// @Failure 403 {object} common.ErrorResponse "Forbidden - Admin only"
+// @Router /admin/shops [get]
+func (h *ShopHandler) ListShops(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	offset, _ := strconv.Atoi(query.Get("offset"))
+
+	res, err := h.service.ListShops(r.Context(), query.Get("status"), limit, offset)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	if err = common.Encode(w, http.StatusOK, res); err != nil {
+		handleError(w, err)
+		return
+	}
+}
+
+// @Summary Approve a shop
+// @Description Show a pending shop to customers, or reinstate a suspended shop and its products
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path integer true "Shop ID"
+// @Success 200 {object} application.AdminShopRes
+// @Failure 403 {object} common.ErrorResponse "Forbidden - Admin only"
+// @Failure 404 {object} common.ErrorResponse
+// @Failure 409 {object} common.ErrorResponse "The shop is already approved"
+// @Router /admin/shops/{id}/approve [post]
+func (h *ShopHandler) ApproveShop(w http.ResponseWriter, r *http.Request) {
+	admin, id, ok := adminAndShopID(w, r)
+	if !ok {
+		return
+	}
+
+	res, err := h.service.ApproveShop(r.Context(), admin.ID, id)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	if err = common.Encode(w, http.StatusOK, res); err != nil {
+		handleError(w, err)
+		return
+	}
+}
+
+// @Summary Suspend a shop
+// @Description Hide a shop and remove its products from search
+// @Tags admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path integer true "Shop ID"
+// @Param request body application.SuspendShopReq true "Suspension reason"
+// @Success 200 {object} application.AdminShopRes
+// @Failure 403 {object} common.ErrorResponse "Forbidden - Admin only"
+// @Failure 404 {object} common.ErrorResponse
+// @Failure 409 {object} common.ErrorResponse "The shop is already suspended"
+// @Router /admin/shops/{id}/suspend [post]
+func (h *ShopHandler) SuspendShop(w http.ResponseWriter, r *http.Request) {
+	admin, id, ok := adminAndShopID(w, r)
+	if !ok {
+		return
+	}
+
+	req, err := common.DecodeAndValidate[application.SuspendShopReq](r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	res, err := h.service.SuspendShop(r.Context(), admin.ID, id, req)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	if err = common.Encode(w, http.StatusOK, res); err != nil {
+		handleError(w, err)
+		return
+	}
+}
+
+// decodeShopForm reads the "shop" JSON form value and the optional logo and
+// banner files, writing the error response on failure.
+func decodeShopForm[T any](w http.ResponseWriter, r *http.Request) (T, *multipart.FileHeader, *multipart.FileHeader, bool) {
+	var req T
+	if err := r.ParseMultipartForm(maxShopFormSize); err != nil {
+		common.SendError(w, http.StatusBadRequest, InvalidRequestBody, "failed to parse multipart form")
+		return req, nil, nil, false
+	}
+	if err := json.Unmarshal([]byte(r.FormValue("shop")), &req); err != nil {
+		common.SendError(w, http.StatusBadRequest, InvalidRequestBody, "invalid shop data format")
+		return req, nil, nil, false
+	}
+	if err := validate.Struct(req); err != nil {
+		handleError(w, err)
+		return req, nil, nil, false
+	}
+	return req, formFile(r, "logo"), formFile(r, "banner"), true
+}
+
+func formFile(r *http.Request, field string) *multipart.FileHeader {
+	if files := r.MultipartForm.File[field]; len(files) > 0 {
+		return files[0]
+	}
+	return nil
+}
+
+// adminAndShopID reads the authenticated admin and the {id} path parameter,
+// writing the error response on failure.
+func adminAndShopID(w http.ResponseWriter, r *http.Request) (*common.UserClaims, int64, bool) {
+	claims, err := common.GetLoggedInUser(r)
+	if err != nil {
+		common.SendError(w, http.StatusUnauthorized, "unauthorized", "user not authenticated")
+		return nil, 0, false
+	}
+
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		common.SendError(w, http.StatusBadRequest, InvalidShopID, "invalid shop ID")
+		return nil, 0, false
+	}
+	return claims, id, true
+}
+
+func handleError(w http.ResponseWriter, err error) {
+	var appErr *common.Error
+	if errors.As(err, &appErr) {
+		switch appErr.Code() {
+		case domain.ShopNotFoundError:
+			common.SendError(w, http.StatusNotFound, string(appErr.Code()), appErr.Error())
+		case domain.ShopAlreadyExistsError, domain.SlugTakenError, domain.InvalidShopStatusError:
+			common.SendError(w, http.StatusConflict, string(appErr.Code()), appErr.Error())
+		case domain.InvalidShopError, papp.InvalidCursor:
+			common.SendError(w, http.StatusBadRequest, string(appErr.Code()), appErr.Error())
+		case common.PermissionDeniedErrorCode:
+			common.SendError(w, http.StatusForbidden, string(appErr.Code()), appErr.Error())
+		default:
+			common.SendError(w, http.StatusInternalServerError, string(appErr.Code()), appErr.Error())
+		}
+		return
+	}
+
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		common.SendError(w, http.StatusBadRequest, "validation_error", common.FormatValidationError(err))
+		return
+	}
+
+	common.SendError(w, http.StatusInternalServerError, "internal_server_error", err.Error())
+}
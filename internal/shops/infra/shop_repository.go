@@ -0,0 +1,169 @@
+package infra
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+	"yadwy-backend/internal/common"
+	"yadwy-backend/internal/shops/domain"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+)
+
+// uniqueViolation is the postgres error code of a unique constraint failure.
+const uniqueViolation = "23505"
+
+type ShopRepositoryImpl struct {
+	db     *sqlx.DB
+	logger *zap.Logger
+}
+
+type shopDbo struct {
+	ID               int64     `db:"id"`
+	SellerID         int64     `db:"seller_id"`
+	Name             string    `db:"name"`
+	Slug             string    `db:"slug"`
+	Description      string    `db:"description"`
+	LogoURL          string    `db:"logo_url"`
+	BannerURL        string    `db:"banner_url"`
+	Governorate      string    `db:"governorate"`
+	City             string    `db:"city"`
+	Address          string    `db:"address"`
+	ShippingPolicy   string    `db:"shipping_policy"`
+	ReturnPolicy     string    `db:"return_policy"`
+	Status           string    `db:"status"`
+	SuspensionReason string    `db:"suspension_reason"`
+	CreatedAt        time.Time `db:"created_at"`
+	UpdatedAt        time.Time `db:"updated_at"`
+}
+
+const shopColumns = `id, seller_id, name, slug, description, logo_url, banner_url, governorate, city, address,
+	shipping_policy, return_policy, status, suspension_reason, created_at, updated_at`
+
+func NewShopRepository(db *sqlx.DB, logger *zap.Logger) domain.ShopRepository {
+	return &ShopRepositoryImpl{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *ShopRepositoryImpl) CreateShop(ctx context.Context, shop *domain.Shop) (*domain.Shop, error) {
+	query := `INSERT INTO shops (seller_id, name, slug, description, logo_url, banner_url, governorate, city, address,
+		shipping_policy, return_policy, status)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING ` + shopColumns
+	loc, pol := shop.Location(), shop.Policies()
+
+	var dbo shopDbo
+	err := r.db.QueryRowxContext(ctx, query, shop.SellerID(), shop.Name(), shop.Slug(), shop.Description(),
+		shop.LogoURL(), shop.BannerURL(), loc.Governorate, loc.City, loc.Address, pol.Shipping, pol.Returns,
+		string(shop.Status())).StructScan(&dbo)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+			if pqErr.Constraint == "shops_seller_id_key" {
+				return nil, common.NewErrorf(domain.ShopAlreadyExistsError, "seller %d already has a shop", shop.SellerID())
+			}
+			return nil, common.NewErrorf(domain.SlugTakenError, "shop slug %q is taken", shop.Slug())
+		}
+		return nil, fmt.Errorf("failed to create shop: %w", err)
+	}
+	return dbo.toDomain(), nil
+}
+
+func (r *ShopRepositoryImpl) GetShop(ctx context.Context, id int64) (*domain.Shop, error) {
+	return r.getShop(ctx, "id = $1", id)
+}
+
+func (r *ShopRepositoryImpl) GetShopBySlug(ctx context.Context, slug string) (*domain.Shop, error) {
+	return r.getShop(ctx, "slug = $1", slug)
+}
+
+func (r *ShopRepositoryImpl) GetShopBySeller(ctx context.Context, sellerID int64) (*domain.Shop, error) {
+	return r.getShop(ctx, "seller_id = $1", sellerID)
+}
+
+func (r *ShopRepositoryImpl) getShop(ctx context.Context, where string, arg any) (*domain.Shop, error) {
+	var dbo shopDbo
+	err := r.db.GetContext(ctx, &dbo, "SELECT "+shopColumns+" FROM shops WHERE "+where, arg)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shop: %w", err)
+	}
+	return dbo.toDomain(), nil
+}
+
+func (r *ShopRepositoryImpl) ListShops(ctx context.Context, status domain.ShopStatus, limit, offset int) ([]domain.Shop, error) {
+	var dbos []shopDbo
+	err := r.db.SelectContext(ctx, &dbos,
+		"SELECT "+shopColumns+" FROM shops WHERE ($1 = '' OR status = $1) ORDER BY created_at, id LIMIT $2 OFFSET $3",
+		string(status), limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list shops: %w", err)
+	}
+
+	shops := make([]domain.Shop, len(dbos))
+	for i, dbo := range dbos {
+		shops[i] = *dbo.toDomain()
+	}
+	return shops, nil
+}
+
+func (r *ShopRepositoryImpl) UpdateShop(ctx context.Context, shop *domain.Shop) error {
+	loc, pol := shop.Location(), shop.Policies()
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE shops SET name = $1, description = $2, logo_url = $3, banner_url = $4, governorate = $5, city = $6,
+		address = $7, shipping_policy = $8, return_policy = $9, updated_at = CURRENT_TIMESTAMP WHERE id = $10`,
+		shop.Name(), shop.Description(), shop.LogoURL(), shop.BannerURL(), loc.Governorate, loc.City, loc.Address,
+		pol.Shipping, pol.Returns, shop.ID())
+	if err != nil {
+		return fmt.Errorf("failed to update shop: %w", err)
+	}
+	return nil
+}
+
+func (r *ShopRepositoryImpl) UpdateShopStatus(ctx context.Context, shop *domain.Shop, reviewerID int64) error {
+	var reviewer *int64
+	if reviewerID != 0 {
+		reviewer = &reviewerID
+	}
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE shops SET status = $1, suspension_reason = $2, reviewed_by = $3, reviewed_at = CURRENT_TIMESTAMP,
+		updated_at = CURRENT_TIMESTAMP WHERE id = $4`,
+		string(shop.Status()), shop.SuspensionReason(), reviewer, shop.ID())
+	if err != nil {
+		return fmt.Errorf("failed to update shop status: %w", err)
+	}
+	return nil
+}
+
+func (dbo shopDbo) toDomain() *domain.Shop {
+	return domain.NewShopFromParams(domain.ShopParams{
+		ID:          dbo.ID,
+		SellerID:    dbo.SellerID,
+		Name:        dbo.Name,
+		Slug:        dbo.Slug,
+		Description: dbo.Description,
+		LogoURL:     dbo.LogoURL,
+		BannerURL:   dbo.BannerURL,
+		Location: domain.Location{
+			Governorate: dbo.Governorate,
+			City:        dbo.City,
+			Address:     dbo.Address,
+		},
+		Policies: domain.Policies{
+			Shipping: dbo.ShippingPolicy,
+			Returns:  dbo.ReturnPolicy,
+		},
+		Status:           domain.ShopStatus(dbo.Status),
+		SuspensionReason: dbo.SuspensionReason,
+		CreatedAt:        dbo.CreatedAt,
+		UpdatedAt:        dbo.UpdatedAt,
+	})
+}
@@ -0,0 +1,104 @@
+package infra
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+	"yadwy-backend/internal/common"
+	"yadwy-backend/internal/shops/domain"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+)
+
+type ShopRepositoryTestSuite struct {
+	suite.Suite
+	db   *sqlx.DB
+	mock sqlmock.Sqlmock
+	repo domain.ShopRepository
+}
+
+func (s *ShopRepositoryTestSuite) SetupTest() {
+	db, mock, err := sqlmock.New()
+	s.Require().NoError(err)
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	s.db = sqlxDB
+	s.mock = mock
+	s.repo = NewShopRepository(sqlxDB, zap.NewNop())
+}
+
+func (s *ShopRepositoryTestSuite) TearDownTest() {
+	s.db.Close()
+}
+
+func TestShopRepository(t *testing.T) {
+	suite.Run(t, new(ShopRepositoryTestSuite))
+}
+
+var shopRowColumns = []string{"id", "seller_id", "name", "slug", "description", "logo_url", "banner_url", "governorate",
+	"city", "address", "shipping_policy", "return_policy", "status", "suspension_reason", "created_at", "updated_at"}
+
+func (s *ShopRepositoryTestSuite) TestCreateShop() {
+	ctx := context.Background()
+	now := time.Now()
+	shop, err := domain.NewShop(5, "Cairo Crafts", "cairo-crafts", "", domain.Location{City: "Cairo"}, domain.Policies{})
+	s.Require().NoError(err)
+
+	s.Run("should return the stored shop", func() {
+		s.mock.ExpectQuery("INSERT INTO shops").
+			WillReturnRows(sqlmock.NewRows(shopRowColumns).
+				AddRow(3, 5, "Cairo Crafts", "cairo-crafts", "", "", "", "", "Cairo", "", "", "", "PENDING", "", now, now))
+
+		created, err := s.repo.CreateShop(ctx, shop)
+		s.Require().NoError(err)
+		s.Equal(int64(3), created.ID())
+		s.Equal(domain.ShopPending, created.Status())
+		s.Equal("Cairo", created.Location().City)
+	})
+
+	s.Run("should map unique violations", func() {
+		s.mock.ExpectQuery("INSERT INTO shops").
+			WillReturnError(&pq.Error{Code: uniqueViolation, Constraint: "shops_seller_id_key"})
+		_, err := s.repo.CreateShop(ctx, shop)
+		s.Equal(domain.ShopAlreadyExistsError, errorCode(err))
+
+		s.mock.ExpectQuery("INSERT INTO shops").
+			WillReturnError(&pq.Error{Code: uniqueViolation, Constraint: "shops_slug_key"})
+		_, err = s.repo.CreateShop(ctx, shop)
+		s.Equal(domain.SlugTakenError, errorCode(err))
+	})
+}
+
+func (s *ShopRepositoryTestSuite) TestGetShopBySlug() {
+	s.mock.ExpectQuery("SELECT (.+) FROM shops WHERE slug = ").
+		WithArgs("missing").
+		WillReturnError(sql.ErrNoRows)
+
+	shop, err := s.repo.GetShopBySlug(context.Background(), "missing")
+	s.Require().NoError(err)
+	s.Nil(shop)
+}
+
+func (s *ShopRepositoryTestSuite) TestUpdateShopStatus() {
+	shop := domain.NewShopFromParams(domain.ShopParams{ID: 3, Status: domain.ShopSuspended, SuspensionReason: "account erased"})
+
+	s.mock.ExpectExec("UPDATE shops SET status").
+		WithArgs("SUSPENDED", "account erased", nil, int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	s.Require().NoError(s.repo.UpdateShopStatus(context.Background(), shop, 0))
+	s.NoError(s.mock.ExpectationsWereMet())
+}
+
+func errorCode(err error) common.ErrorCode {
+	appErr, ok := err.(*common.Error)
+	if !ok {
+		return ""
+	}
+	return appErr.Code()
+}
@@ -0,0 +1,110 @@
+package application
+
+import (
+	"context"
+	"yadwy-backend/internal/common"
+	"yadwy-backend/internal/users/domain/contracts"
+	"yadwy-backend/internal/users/domain/modles"
+)
+
+// maxAddressesPerUser bounds the size of an address book.
+const maxAddressesPerUser = 20
+
+// AddressService manages the address book of the signed in user.
+type AddressService struct {
+	addresses contracts.AddressRepo
+}
+
+func NewAddressService(addresses contracts.AddressRepo) *AddressService {
+	return &AddressService{
+		addresses: addresses,
+	}
+}
+
+func (s *AddressService) ListAddresses(ctx context.Context, userID int) ([]AddressRes, error) {
+	addresses, err := s.addresses.ListAddresses(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]AddressRes, 0, len(addresses))
+	for i := range addresses {
+		res = append(res, toAddressRes(&addresses[i]))
+	}
+	return res, nil
+}
+
+func (s *AddressService) GetAddress(ctx context.Context, userID, addressID int) (*AddressRes, error) {
+	address, err := s.addresses.GetAddress(ctx, userID, addressID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := toAddressRes(address)
+	return &res, nil
+}
+
+// CreateAddress adds an address to the address book. The first address
+// becomes the default shipping and billing address.
+func (s *AddressService) CreateAddress(ctx context.Context, userID int, req AddressReq) (*AddressRes, error) {
+	count, err := s.addresses.CountAddresses(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= maxAddressesPerUser {
+		return nil, common.NewErrorf(modles.AddressLimitReachedError, "an address book holds at most %d addresses", maxAddressesPerUser)
+	}
+
+	params := req.params(userID)
+	if count == 0 {
+		params.DefaultShipping = true
+		params.DefaultBilling = true
+	}
+
+	address, err := modles.NewAddress(params)
+	if err != nil {
+		return nil, err
+	}
+
+	saved, err := s.addresses.CreateAddress(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+
+	res := toAddressRes(saved)
+	return &res, nil
+}
+
+// UpdateAddress replaces an address. Orders keep the snapshot taken at
+// checkout, so the change only affects future orders.
+func (s *AddressService) UpdateAddress(ctx context.Context, userID, addressID int, req AddressReq) (*AddressRes, error) {
+	address, err := s.addresses.GetAddress(ctx, userID, addressID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := address.Update(req.params(userID)); err != nil {
+		return nil, err
+	}
+
+	saved, err := s.addresses.UpdateAddress(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+
+	res := toAddressRes(saved)
+	return &res, nil
+}
+
+func (s *AddressService) DeleteAddress(ctx context.Context, userID, addressID int) error {
+	return s.addresses.DeleteAddress(ctx, userID, addressID)
+}
+
+// SnapshotAddress copies an address of the user for an order.
+func (s *AddressService) SnapshotAddress(ctx context.Context, userID, addressID int) (modles.AddressSnapshot, error) {
+	address, err := s.addresses.GetAddress(ctx, userID, addressID)
+	if err != nil {
+		return modles.AddressSnapshot{}, err
+	}
+	return address.Snapshot(), nil
+}
@@ -0,0 +1,108 @@
+package application
+
+import (
+	"context"
+	"testing"
+	"yadwy-backend/internal/users/domain/contracts/mock"
+	"yadwy-backend/internal/users/domain/modles"
+)
+
+func testAddressReq() AddressReq {
+	return AddressReq{
+		RecipientName: "John Doe",
+		Phone:         "01001234567",
+		Governorate:   "Cairo",
+		City:          "Nasr City",
+		District:      "8th District",
+		Street:        "Abbas El Akkad St.",
+		Building:      "12",
+	}
+}
+
+func TestAddressService(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should make the first address the default", func(t *testing.T) {
+		service := NewAddressService(mock.NewAddressRepo())
+
+		first, err := service.CreateAddress(ctx, 1, testAddressReq())
+		if err != nil {
+			t.Fatalf("CreateAddress() error = %v", err)
+		}
+		if !first.DefaultShipping || !first.DefaultBilling {
+			t.Errorf("CreateAddress() first = %+v, want both defaults", first)
+		}
+
+		second, err := service.CreateAddress(ctx, 1, testAddressReq())
+		if err != nil {
+			t.Fatalf("CreateAddress() error = %v", err)
+		}
+		if second.DefaultShipping || second.DefaultBilling {
+			t.Errorf("CreateAddress() second = %+v, want no defaults", second)
+		}
+	})
+
+	t.Run("should move the default to the updated address", func(t *testing.T) {
+		service := NewAddressService(mock.NewAddressRepo())
+		first, _ := service.CreateAddress(ctx, 1, testAddressReq())
+		second, _ := service.CreateAddress(ctx, 1, testAddressReq())
+
+		req := testAddressReq()
+		req.DefaultShipping = true
+		if _, err := service.UpdateAddress(ctx, 1, second.ID, req); err != nil {
+			t.Fatalf("UpdateAddress() error = %v", err)
+		}
+
+		got, err := service.GetAddress(ctx, 1, first.ID)
+		if err != nil {
+			t.Fatalf("GetAddress() error = %v", err)
+		}
+		if got.DefaultShipping || !got.DefaultBilling {
+			t.Errorf("GetAddress() first = %+v, want only the billing default", got)
+		}
+	})
+
+	t.Run("should hide addresses of other users", func(t *testing.T) {
+		service := NewAddressService(mock.NewAddressRepo())
+		address, _ := service.CreateAddress(ctx, 1, testAddressReq())
+
+		_, err := service.GetAddress(ctx, 2, address.ID)
+		if got := errorCode(err); got != modles.AddressNotFoundError {
+			t.Errorf("GetAddress() error code = %v, want %v", got, modles.AddressNotFoundError)
+		}
+		err = service.DeleteAddress(ctx, 2, address.ID)
+		if got := errorCode(err); got != modles.AddressNotFoundError {
+			t.Errorf("DeleteAddress() error code = %v, want %v", got, modles.AddressNotFoundError)
+		}
+	})
+
+	t.Run("should limit the size of the address book", func(t *testing.T) {
+		service := NewAddressService(mock.NewAddressRepo())
+		for i := 0; i < maxAddressesPerUser; i++ {
+			if _, err := service.CreateAddress(ctx, 1, testAddressReq()); err != nil {
+				t.Fatalf("CreateAddress() error = %v", err)
+			}
+		}
+
+		_, err := service.CreateAddress(ctx, 1, testAddressReq())
+		if got := errorCode(err); got != modles.AddressLimitReachedError {
+			t.Errorf("CreateAddress() error code = %v, want %v", got, modles.AddressLimitReachedError)
+		}
+	})
+
+	t.Run("should keep snapshots when the address changes", func(t *testing.T) {
+		service := NewAddressService(mock.NewAddressRepo())
+		address, _ := service.CreateAddress(ctx, 1, testAddressReq())
+
+		snapshot, err := service.SnapshotAddress(ctx, 1, address.ID)
+		if err != nil {
+			t.Fatalf("SnapshotAddress() error = %v", err)
+		}
+		if err := service.DeleteAddress(ctx, 1, address.ID); err != nil {
+			t.Fatalf("DeleteAddress() error = %v", err)
+		}
+		if snapshot.AddressID != address.ID || snapshot.Street != "Abbas El Akkad St." {
+			t.Errorf("SnapshotAddress() = %+v", snapshot)
+		}
+	})
+}
@@ -0,0 +1,335 @@
+package application
+
+import (
+	"context"
+	"time"
+	"yadwy-backend/internal/common"
+	"yadwy-backend/internal/users/domain/contracts"
+	"yadwy-backend/internal/users/domain/modles"
+)
+
+const (
+	defaultUserPageSize = 20
+	maxUserPageSize     = 100
+)
+
+// AdminService holds the user management operations reserved for admins.
+// Every change is recorded in the audit log along with the acting admin. Changes
+// to the user are stored in the same transaction as their audit entry; sign
+// outs and unlocks are recorded before they are carried out.
+type AdminService struct {
+	userRepo   contracts.UserRepo
+	audit      contracts.AuditLog
+	sessions   sessionRevoker
+	loginGuard *LoginGuard
+	roles      *common.PermissionPolicy
+}
+
+func NewAdminService(
+	repo contracts.UserRepo,
+	audit contracts.AuditLog,
+	sessions sessionRevoker,
+	loginGuard *LoginGuard,
+	roles *common.PermissionPolicy) *AdminService {
+	return &AdminService{
+		userRepo:   repo,
+		audit:      audit,
+		sessions:   sessions,
+		loginGuard: loginGuard,
+		roles:      roles,
+	}
+}
+
+// CreateAccount creates a user with any role. Accounts created by an admin
+// are verified, and sellers are approved right away. adminID is 0 when the
+// account is created from the CLI.
+func (s *AdminService) CreateAccount(ctx context.Context, adminID int, r CreateAccountReq) (*UserInfo, error) {
+	role, err := s.definedRole(r.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	audit := s.entry(adminID, modles.AuditAccountCreated, 0, map[string]string{"role": role.String()})
+	savedUser, err := createUser(ctx, s.userRepo, r.Email, r.Password, audit, func(hashPass string) (*modles.User, error) {
+		var sellerStatus modles.SellerStatus
+		if role == modles.RoleSeller {
+			sellerStatus = modles.SellerApproved
+		}
+		now := time.Now()
+		return modles.NewUserFromParams(modles.UserParams{
+			Name:            r.Name,
+			Email:           r.Email,
+			Password:        hashPass,
+			Role:            role,
+			SellerStatus:    sellerStatus,
+			EmailVerifiedAt: &now,
+		}), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	info := toUserInfo(savedUser)
+	return &info, nil
+}
+
+// ListUsers returns a page of users, newest first.
+func (s *AdminService) ListUsers(ctx context.Context, req ListUsersReq) (*UserListRes, error) {
+	filter := modles.UserFilter{
+		Email:       req.Email,
+		CreatedFrom: req.CreatedFrom,
+		CreatedTo:   req.CreatedTo,
+		Limit:       req.Limit,
+		Offset:      req.Offset,
+	}
+	if req.Role != "" {
+		role, err := s.definedRole(req.Role)
+		if err != nil {
+			return nil, err
+		}
+		filter.Role = role
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = defaultUserPageSize
+	}
+	if filter.Limit > maxUserPageSize {
+		filter.Limit = maxUserPageSize
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+
+	users, total, err := s.userRepo.ListUsers(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &UserListRes{
+		Users:       make([]AdminUserRes, 0, len(users)),
+		TotalCount:  total,
+		Limit:       filter.Limit,
+		Offset:      filter.Offset,
+		HasNextPage: filter.Offset+len(users) < total,
+	}
+	for i := range users {
+		res.Users = append(res.Users, toAdminUserRes(&users[i]))
+	}
+	return res, nil
+}
+
+func (s *AdminService) GetUser(ctx context.Context, userID int) (*AdminUserRes, error) {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := toAdminUserRes(user)
+	return &res, nil
+}
+
+// ChangeRole moves a user to another role and signs the user out, so that no
+// token keeps the old role.
+func (s *AdminService) ChangeRole(ctx context.Context, adminID, userID int, req ChangeRoleReq) (*AdminUserRes, error) {
+	user, err := s.otherUser(ctx, adminID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	role, err := s.definedRole(req.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	oldRole := user.Role()
+	if err := user.ChangeRole(role); err != nil {
+		return nil, err
+	}
+	audit := s.entry(adminID, modles.AuditRoleChanged, user.ID(), map[string]string{
+		"from": oldRole.String(),
+		"to":   user.Role().String(),
+	})
+	if err := s.userRepo.UpdateRole(ctx, user, audit); err != nil {
+		return nil, err
+	}
+	if err := s.sessions.LogoutAll(ctx, user.ID()); err != nil {
+		return nil, err
+	}
+
+	res := toAdminUserRes(user)
+	return &res, nil
+}
+
+// SuspendUser blocks a user from logging in and revokes every token.
+func (s *AdminService) SuspendUser(ctx context.Context, adminID, userID int, req SuspendUserReq) (*AdminUserRes, error) {
+	user, err := s.otherUser(ctx, adminID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := user.Suspend(time.Now()); err != nil {
+		return nil, err
+	}
+	var details map[string]string
+	if req.Reason != "" {
+		details = map[string]string{"reason": req.Reason}
+	}
+	audit := s.entry(adminID, modles.AuditUserSuspended, user.ID(), details)
+	if err := s.userRepo.UpdateSuspension(ctx, user, audit); err != nil {
+		return nil, err
+	}
+	if err := s.sessions.LogoutAll(ctx, user.ID()); err != nil {
+		return nil, err
+	}
+
+	res := toAdminUserRes(user)
+	return &res, nil
+}
+
+func (s *AdminService) ReactivateUser(ctx context.Context, adminID, userID int) (*AdminUserRes, error) {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := user.Reactivate(); err != nil {
+		return nil, err
+	}
+	audit := s.entry(adminID, modles.AuditUserReactivated, user.ID(), nil)
+	if err := s.userRepo.UpdateSuspension(ctx, user, audit); err != nil {
+		return nil, err
+	}
+
+	res := toAdminUserRes(user)
+	return &res, nil
+}
+
+// LogoutUser revokes every access and refresh token of a user.
+func (s *AdminService) LogoutUser(ctx context.Context, adminID, userID int) error {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.record(ctx, adminID, modles.AuditSessionsRevoked, user.ID(), nil); err != nil {
+		return err
+	}
+	return s.sessions.LogoutAll(ctx, user.ID())
+}
+
+// ListAuditEntries returns the actions recorded on a user, newest first.
+func (s *AdminService) ListAuditEntries(ctx context.Context, userID, limit, offset int) ([]AuditEntryRes, error) {
+	if limit <= 0 || limit > maxUserPageSize {
+		limit = defaultUserPageSize
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	entries, err := s.audit.ListAuditEntries(ctx, userID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]AuditEntryRes, 0, len(entries))
+	for i := range entries {
+		res = append(res, toAuditEntryRes(&entries[i]))
+	}
+	return res, nil
+}
+
+func (s *AdminService) ListSellers(ctx context.Context, status string) ([]UserInfo, error) {
+	sellerStatus, err := modles.NewSellerStatus(status)
+	if err != nil {
+		return nil, common.NewErrorf(modles.InvalidSellerStatusError, "%v", err)
+	}
+
+	sellers, err := s.userRepo.ListSellers(ctx, sellerStatus)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]UserInfo, 0, len(sellers))
+	for i := range sellers {
+		res = append(res, toUserInfo(&sellers[i]))
+	}
+	return res, nil
+}
+
+func (s *AdminService) ApproveSeller(ctx context.Context, adminID, sellerID int) (*UserInfo, error) {
+	return s.reviewSeller(ctx, adminID, sellerID, "", modles.AuditSellerApproved, (*modles.User).ApproveSeller)
+}
+
+func (s *AdminService) RejectSeller(ctx context.Context, adminID, sellerID int, reason string) (*UserInfo, error) {
+	return s.reviewSeller(ctx, adminID, sellerID, reason, modles.AuditSellerRejected, (*modles.User).RejectSeller)
+}
+
+// reviewSeller approves or rejects a seller and signs the seller out, since
+// tokens carry the seller status.
+func (s *AdminService) reviewSeller(
+	ctx context.Context,
+	adminID, sellerID int,
+	reason string,
+	action modles.AuditAction,
+	review func(*modles.User) error) (*UserInfo, error) {
+	seller, err := s.userRepo.GetUserByID(ctx, sellerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := review(seller); err != nil {
+		return nil, err
+	}
+
+	var details map[string]string
+	if reason != "" {
+		details = map[string]string{"reason": reason}
+	}
+	audit := s.entry(adminID, action, seller.ID(), details)
+	if err := s.userRepo.UpdateSellerStatus(ctx, seller, adminID, reason, audit); err != nil {
+		return nil, err
+	}
+	if err := s.sessions.LogoutAll(ctx, seller.ID()); err != nil {
+		return nil, err
+	}
+
+	info := toUserInfo(seller)
+	return &info, nil
+}
+
+// UnlockUser clears the failed logins that locked out a user.
+func (s *AdminService) UnlockUser(ctx context.Context, adminID, userID int) error {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.record(ctx, adminID, modles.AuditUserUnlocked, user.ID(), nil); err != nil {
+		return err
+	}
+	return s.loginGuard.Unlock(ctx, user.Email())
+}
+
+// otherUser loads a user that is not the acting admin, so that admins cannot
+// lock themselves out.
+func (s *AdminService) otherUser(ctx context.Context, adminID, userID int) (*modles.User, error) {
+	if adminID == userID {
+		return nil, common.NewErrorf(modles.CannotManageOwnAccountError, "admins cannot change their own account")
+	}
+	return s.userRepo.GetUserByID(ctx, userID)
+}
+
+func (s *AdminService) entry(adminID int, action modles.AuditAction, userID int, details map[string]string) *modles.AuditEntry {
+	return modles.NewAuditEntry(0, adminID, action, userID, details, time.Now())
+}
+
+func (s *AdminService) record(ctx context.Context, adminID int, action modles.AuditAction, userID int, details map[string]string) error {
+	return s.audit.RecordAudit(ctx, s.entry(adminID, action, userID, details))
+}
+
+// definedRole accepts the roles defined in the permission policy.
+func (s *AdminService) definedRole(name string) (modles.Role, error) {
+	role, err := modles.NewRole(name)
+	if err != nil || !s.roles.HasRole(role.String()) {
+		return "", common.NewErrorf(modles.InvalidUserRoleError, "role %s is not defined", name)
+	}
+	return role, nil
+}
@@ -0,0 +1,190 @@
+package application
+
+import (
+	"context"
+	"testing"
+	"yadwy-backend/internal/common"
+	"yadwy-backend/internal/users/domain/contracts/mock"
+	"yadwy-backend/internal/users/domain/modles"
+)
+
+func TestAdminService(t *testing.T) {
+	ctx := context.Background()
+	const adminID = 99
+
+	newService := func(users *mock.UserRepo, audit *mock.AuditLog, revoker *recordingRevoker) *AdminService {
+		users.Audit = audit
+		return NewAdminService(users, audit, revoker, newTestLoginGuard(), common.NewPermissionPolicy(map[string][]string{
+			"ADMIN":    {"*"},
+			"SELLER":   {"product:create"},
+			"CUSTOMER": nil,
+			"SUPPORT":  {"user:read"},
+		}))
+	}
+
+	t.Run("should cap the page size and report the next page", func(t *testing.T) {
+		var got modles.UserFilter
+		users := &mock.UserRepo{
+			ListUsersFunc: func(ctx context.Context, filter modles.UserFilter) ([]modles.User, int, error) {
+				got = filter
+				return []modles.User{*testUser()}, 150, nil
+			},
+		}
+		service := newService(users, &mock.AuditLog{}, &recordingRevoker{})
+
+		res, err := service.ListUsers(ctx, ListUsersReq{Role: "SELLER", Limit: 500})
+		if err != nil {
+			t.Fatalf("ListUsers() error = %v", err)
+		}
+		if got.Limit != maxUserPageSize || got.Role != modles.RoleSeller {
+			t.Errorf("ListUsers() filter = %+v, want limit %d and role SELLER", got, maxUserPageSize)
+		}
+		if !res.HasNextPage || res.TotalCount != 150 || len(res.Users) != 1 {
+			t.Errorf("ListUsers() = %+v, want one user and a next page", res)
+		}
+
+		_, err = service.ListUsers(ctx, ListUsersReq{Role: "OWNER"})
+		if got := errorCode(err); got != modles.InvalidUserRoleError {
+			t.Errorf("ListUsers() error code = %v, want %v", got, modles.InvalidUserRoleError)
+		}
+	})
+
+	t.Run("should change role, sign out and audit", func(t *testing.T) {
+		var saved *modles.User
+		users := &mock.UserRepo{
+			GetUserByIDFunc: func(ctx context.Context, id int) (*modles.User, error) {
+				return testUser(), nil
+			},
+			UpdateRoleFunc: func(ctx context.Context, user *modles.User) error {
+				saved = user
+				return nil
+			},
+		}
+		audit := &mock.AuditLog{}
+		revoker := &recordingRevoker{}
+		service := newService(users, audit, revoker)
+
+		res, err := service.ChangeRole(ctx, adminID, 1, ChangeRoleReq{Role: "SELLER"})
+		if err != nil {
+			t.Fatalf("ChangeRole() error = %v", err)
+		}
+		if res.Role != "SELLER" || saved.SellerStatus() != modles.SellerApproved {
+			t.Errorf("ChangeRole() = %+v, want an approved seller", res)
+		}
+		if len(revoker.userIDs) != 1 {
+			t.Errorf("ChangeRole() revoked %v, want the user signed out", revoker.userIDs)
+		}
+		if len(audit.Entries) != 1 {
+			t.Fatalf("ChangeRole() recorded %d audit entries, want 1", len(audit.Entries))
+		}
+		entry := audit.Entries[0]
+		if entry.ActorID() != adminID || entry.Action() != modles.AuditRoleChanged || entry.Details()["from"] != "CUSTOMER" {
+			t.Errorf("ChangeRole() audit entry = %+v", entry)
+		}
+	})
+
+	t.Run("should accept roles defined in the policy only", func(t *testing.T) {
+		users := &mock.UserRepo{
+			GetUserByIDFunc: func(ctx context.Context, id int) (*modles.User, error) {
+				return testUser(), nil
+			},
+			UpdateRoleFunc: func(ctx context.Context, user *modles.User) error {
+				return nil
+			},
+		}
+		service := newService(users, &mock.AuditLog{}, &recordingRevoker{})
+
+		res, err := service.ChangeRole(ctx, adminID, 1, ChangeRoleReq{Role: "SUPPORT"})
+		if err != nil {
+			t.Fatalf("ChangeRole() error = %v", err)
+		}
+		if res.Role != "SUPPORT" || res.SellerStatus != "" {
+			t.Errorf("ChangeRole() = %+v, want a support user", res)
+		}
+
+		_, err = service.ChangeRole(ctx, adminID, 1, ChangeRoleReq{Role: "MODERATOR"})
+		if got := errorCode(err); got != modles.InvalidUserRoleError {
+			t.Errorf("ChangeRole() error code = %v, want %v", got, modles.InvalidUserRoleError)
+		}
+	})
+
+	t.Run("should sign out a reviewed seller", func(t *testing.T) {
+		seller := modles.NewUserFromParams(modles.UserParams{
+			ID: 1, Name: "Jane Doe", Email: "jane@example.com", Role: modles.RoleSeller, SellerStatus: modles.SellerPending,
+		})
+		users := &mock.UserRepo{
+			GetUserByIDFunc: func(ctx context.Context, id int) (*modles.User, error) {
+				return seller, nil
+			},
+		}
+		revoker := &recordingRevoker{}
+		service := newService(users, &mock.AuditLog{}, revoker)
+
+		res, err := service.ApproveSeller(ctx, adminID, 1)
+		if err != nil {
+			t.Fatalf("ApproveSeller() error = %v", err)
+		}
+		if res.SellerStatus != string(modles.SellerApproved) || len(revoker.userIDs) != 1 {
+			t.Errorf("ApproveSeller() = %+v, revoked %v, want approved and signed out", res, revoker.userIDs)
+		}
+	})
+
+	t.Run("should not let admins manage their own account", func(t *testing.T) {
+		audit := &mock.AuditLog{}
+		service := newService(&mock.UserRepo{}, audit, &recordingRevoker{})
+
+		_, err := service.SuspendUser(ctx, adminID, adminID, SuspendUserReq{})
+		if got := errorCode(err); got != modles.CannotManageOwnAccountError {
+			t.Errorf("SuspendUser() error code = %v, want %v", got, modles.CannotManageOwnAccountError)
+		}
+		_, err = service.ChangeRole(ctx, adminID, adminID, ChangeRoleReq{Role: "CUSTOMER"})
+		if got := errorCode(err); got != modles.CannotManageOwnAccountError {
+			t.Errorf("ChangeRole() error code = %v, want %v", got, modles.CannotManageOwnAccountError)
+		}
+		if len(audit.Entries) != 0 {
+			t.Errorf("recorded %d audit entries for rejected actions, want 0", len(audit.Entries))
+		}
+	})
+
+	t.Run("should suspend and reactivate", func(t *testing.T) {
+		user := testUser()
+		users := &mock.UserRepo{
+			GetUserByIDFunc: func(ctx context.Context, id int) (*modles.User, error) {
+				return user, nil
+			},
+		}
+		audit := &mock.AuditLog{}
+		revoker := &recordingRevoker{}
+		service := newService(users, audit, revoker)
+
+		res, err := service.SuspendUser(ctx, adminID, 1, SuspendUserReq{Reason: "fraud"})
+		if err != nil {
+			t.Fatalf("SuspendUser() error = %v", err)
+		}
+		if res.SuspendedAt == nil || len(revoker.userIDs) != 1 {
+			t.Errorf("SuspendUser() = %+v, revoked %v", res, revoker.userIDs)
+		}
+
+		_, err = service.SuspendUser(ctx, adminID, 1, SuspendUserReq{})
+		if got := errorCode(err); got != modles.InvalidAccountStatusError {
+			t.Errorf("SuspendUser() twice error code = %v, want %v", got, modles.InvalidAccountStatusError)
+		}
+
+		res, err = service.ReactivateUser(ctx, adminID, 1)
+		if err != nil {
+			t.Fatalf("ReactivateUser() error = %v", err)
+		}
+		if res.SuspendedAt != nil {
+			t.Errorf("ReactivateUser() suspended_at = %v, want nil", res.SuspendedAt)
+		}
+
+		entries, err := service.ListAuditEntries(ctx, 1, 0, 0)
+		if err != nil {
+			t.Fatalf("ListAuditEntries() error = %v", err)
+		}
+		if len(entries) != 2 || entries[0].Action != string(modles.AuditUserReactivated) ||
+			entries[1].Details["reason"] != "fraud" {
+			t.Errorf("ListAuditEntries() = %+v", entries)
+		}
+	})
+}
@@ -0,0 +1,159 @@
+package application
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+	"yadwy-backend/internal/common"
+	"yadwy-backend/internal/users/domain/contracts"
+	"yadwy-backend/internal/users/domain/modles"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// apiKeyPrefix starts every key, so leaked keys are easy to recognize.
+	apiKeyPrefix = "ydw_"
+	// apiKeyDisplayLength is how much of the key is stored in the clear to
+	// tell keys apart.
+	apiKeyDisplayLength = len(apiKeyPrefix) + 8
+	maxAPIKeysPerUser   = 10
+	// apiKeyTouchInterval limits last use updates to one per key and interval.
+	apiKeyTouchInterval = time.Minute
+)
+
+// APIKeyService manages the API keys users create for server-to-server
+// integrations, and authenticates requests made with them.
+type APIKeyService struct {
+	userRepo contracts.UserRepo
+	keys     contracts.APIKeyRepo
+	policy   *common.PermissionPolicy
+	logger   *zap.Logger
+}
+
+func NewAPIKeyService(repo contracts.UserRepo, keys contracts.APIKeyRepo, policy *common.PermissionPolicy, logger *zap.Logger) *APIKeyService {
+	return &APIKeyService{
+		userRepo: repo,
+		keys:     keys,
+		policy:   policy,
+		logger:   logger,
+	}
+}
+
+// CreateAPIKey creates a key limited to the requested scopes, which must be
+// defined permissions granted by the role of the user.
+func (s *APIKeyService) CreateAPIKey(ctx context.Context, userID int, req CreateAPIKeyReq) (*CreateAPIKeyRes, error) {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.SellerNotApproved() {
+		return nil, common.NewErrorf(common.PermissionDeniedErrorCode, "seller account is not approved")
+	}
+
+	scopes := make([]string, 0, len(req.Scopes))
+	for _, scope := range req.Scopes {
+		scope = strings.TrimSpace(scope)
+		if !common.Permission(scope).Defined() {
+			return nil, common.NewErrorf(modles.InvalidAPIKeyScopeError, "unknown scope %s", scope)
+		}
+		if !s.policy.HasPermission(user.Role().String(), common.Permission(scope)) {
+			return nil, common.NewErrorf(modles.InvalidAPIKeyScopeError, "scope %s is not granted to your role", scope)
+		}
+		if !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, common.NewErrorf(modles.InvalidAPIKeyError, "expires_at must be in the future")
+	}
+
+	existing, err := s.keys.ListAPIKeys(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= maxAPIKeysPerUser {
+		return nil, common.NewErrorf(modles.APIKeyLimitReachedError, "a user can have at most %d API keys", maxAPIKeysPerUser)
+	}
+
+	secret, err := common.GenerateRandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	key := apiKeyPrefix + secret
+
+	saved, err := s.keys.CreateAPIKey(ctx, modles.NewAPIKey(userID, strings.TrimSpace(req.Name),
+		key[:apiKeyDisplayLength], common.HashToken(key), scopes, req.ExpiresAt))
+	if err != nil {
+		return nil, err
+	}
+
+	return &CreateAPIKeyRes{APIKeyRes: toAPIKeyRes(saved), Key: key}, nil
+}
+
+func (s *APIKeyService) ListAPIKeys(ctx context.Context, userID int) ([]APIKeyRes, error) {
+	keys, err := s.keys.ListAPIKeys(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]APIKeyRes, 0, len(keys))
+	for i := range keys {
+		res = append(res, toAPIKeyRes(&keys[i]))
+	}
+	return res, nil
+}
+
+func (s *APIKeyService) RevokeAPIKey(ctx context.Context, userID, keyID int) error {
+	return s.keys.RevokeAPIKey(ctx, userID, keyID)
+}
+
+// AuthenticateAPIKey returns the claims of the owner of the key, limited to
+// the scopes of the key. Keys stop working when the owner can no longer log
+// in.
+func (s *APIKeyService) AuthenticateAPIKey(ctx context.Context, key string) (*common.UserClaims, error) {
+	invalid := common.NewErrorf(common.AuthHeaderTokenVerificationFailed, "API key is invalid")
+	if !strings.HasPrefix(key, apiKeyPrefix) {
+		return nil, invalid
+	}
+
+	apiKey, err := s.keys.GetAPIKeyByHash(ctx, common.HashToken(key))
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if apiKey == nil || !apiKey.IsUsable(now) {
+		return nil, invalid
+	}
+
+	user, err := s.userRepo.GetUserByID(ctx, apiKey.UserID())
+	if err != nil {
+		if isUserNotFound(err) {
+			return nil, invalid
+		}
+		return nil, err
+	}
+	// keys created before a rejection stop working with it
+	if !user.IsActive() || user.IsSuspended() || user.SellerNotApproved() {
+		return nil, invalid
+	}
+
+	if last := apiKey.LastUsedAt(); last == nil || now.Sub(*last) >= apiKeyTouchInterval {
+		// a failed update must not fail the request
+		if err := s.keys.TouchAPIKey(ctx, apiKey.ID(), now); err != nil {
+			s.logger.Error("Failed to record API key use", zap.Int("api_key_id", apiKey.ID()), zap.Error(err))
+		}
+	}
+
+	return &common.UserClaims{
+		ID:            int64(user.ID()),
+		Email:         user.Email(),
+		Role:          user.Role().String(),
+		EmailVerified: user.IsEmailVerified(),
+		SellerStatus:  user.SellerStatus().String(),
+		Scopes:        apiKey.Scopes(),
+		TokenType:     common.APIKeyTokenType,
+		Subject:       user.Email(),
+	}, nil
+}
@@ -0,0 +1,164 @@
+package application
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+	"yadwy-backend/internal/common"
+	"yadwy-backend/internal/users/domain/contracts/mock"
+	"yadwy-backend/internal/users/domain/modles"
+
+	"go.uber.org/zap"
+)
+
+func TestAPIKeyService(t *testing.T) {
+	ctx := context.Background()
+	seller := modles.NewUser(5, "Jane Seller", "jane@example.com", "hashed", modles.RoleSeller)
+	policy := common.NewPermissionPolicy(map[string][]string{
+		"SELLER": {"product:create", "api_key:manage"},
+		"ADMIN":  {"*"},
+	})
+
+	newService := func(user *modles.User) (*APIKeyService, *mock.APIKeyRepo) {
+		users := &mock.UserRepo{
+			GetUserByIDFunc: func(ctx context.Context, id int) (*modles.User, error) {
+				if id != user.ID() {
+					return nil, common.NewErrorf(modles.UserNotFoundError, "user not found")
+				}
+				return user, nil
+			},
+		}
+		keys := mock.NewAPIKeyRepo()
+		return NewAPIKeyService(users, keys, policy, zap.NewNop()), keys
+	}
+
+	t.Run("should authenticate with the scopes of the key", func(t *testing.T) {
+		service, _ := newService(seller)
+
+		created, err := service.CreateAPIKey(ctx, seller.ID(), CreateAPIKeyReq{Name: "sync", Scopes: []string{"product:create", "product:create"}})
+		if err != nil {
+			t.Fatalf("CreateAPIKey() error = %v", err)
+		}
+		if !strings.HasPrefix(created.Key, "ydw_") || !strings.HasPrefix(created.Key, created.Prefix) || len(created.Scopes) != 1 {
+			t.Fatalf("CreateAPIKey() = %+v, want a prefixed key with one scope", created)
+		}
+
+		claims, err := service.AuthenticateAPIKey(ctx, created.Key)
+		if err != nil {
+			t.Fatalf("AuthenticateAPIKey() error = %v", err)
+		}
+		if claims.ID != 5 || claims.Role != "SELLER" || claims.TokenType != common.APIKeyTokenType || len(claims.Scopes) != 1 {
+			t.Errorf("AuthenticateAPIKey() = %+v, want seller claims with the key scope", claims)
+		}
+
+		keys, err := service.ListAPIKeys(ctx, seller.ID())
+		if err != nil {
+			t.Fatalf("ListAPIKeys() error = %v", err)
+		}
+		if len(keys) != 1 || keys[0].LastUsedAt == nil {
+			t.Errorf("ListAPIKeys() = %+v, want one key with a last use", keys)
+		}
+	})
+
+	t.Run("should only grant scopes of the role", func(t *testing.T) {
+		service, _ := newService(seller)
+
+		for _, scope := range []string{"banner:manage", "*"} {
+			_, err := service.CreateAPIKey(ctx, seller.ID(), CreateAPIKeyReq{Name: "sync", Scopes: []string{scope}})
+			if got := errorCode(err); got != modles.InvalidAPIKeyScopeError {
+				t.Errorf("CreateAPIKey(%s) error code = %v, want %v", scope, got, modles.InvalidAPIKeyScopeError)
+			}
+		}
+	})
+
+	t.Run("should reject unknown scopes for every role", func(t *testing.T) {
+		admin := modles.NewUser(1, "Jane Admin", "admin@example.com", "hashed", modles.RoleAdmin)
+		service, _ := newService(admin)
+
+		for _, scope := range []string{"foo", "user:manage:all"} {
+			_, err := service.CreateAPIKey(ctx, admin.ID(), CreateAPIKeyReq{Name: "sync", Scopes: []string{scope}})
+			if got := errorCode(err); got != modles.InvalidAPIKeyScopeError {
+				t.Errorf("CreateAPIKey(%s) error code = %v, want %v", scope, got, modles.InvalidAPIKeyScopeError)
+			}
+		}
+		created, err := service.CreateAPIKey(ctx, admin.ID(), CreateAPIKeyReq{Name: "sync", Scopes: []string{"user:manage "}})
+		if err != nil {
+			t.Fatalf("CreateAPIKey() error = %v", err)
+		}
+		if len(created.Scopes) != 1 || created.Scopes[0] != "user:manage" {
+			t.Errorf("CreateAPIKey() scopes = %v, want [user:manage]", created.Scopes)
+		}
+	})
+
+	t.Run("should reject revoked and expired keys", func(t *testing.T) {
+		service, keys := newService(seller)
+
+		created, err := service.CreateAPIKey(ctx, seller.ID(), CreateAPIKeyReq{Name: "sync", Scopes: []string{"product:create"}})
+		if err != nil {
+			t.Fatalf("CreateAPIKey() error = %v", err)
+		}
+		if err := service.RevokeAPIKey(ctx, seller.ID(), created.ID); err != nil {
+			t.Fatalf("RevokeAPIKey() error = %v", err)
+		}
+		_, err = service.AuthenticateAPIKey(ctx, created.Key)
+		if got := errorCode(err); got != common.AuthHeaderTokenVerificationFailed {
+			t.Errorf("AuthenticateAPIKey() revoked error code = %v, want %v", got, common.AuthHeaderTokenVerificationFailed)
+		}
+		if got := errorCode(service.RevokeAPIKey(ctx, seller.ID(), created.ID)); got != modles.APIKeyNotFoundError {
+			t.Errorf("RevokeAPIKey() twice error code = %v, want %v", got, modles.APIKeyNotFoundError)
+		}
+
+		past := time.Now().Add(-time.Minute)
+		_, err = keys.CreateAPIKey(ctx, modles.NewAPIKey(seller.ID(), "old", "ydw_expired", common.HashToken("ydw_expired"), []string{"product:create"}, &past))
+		if err != nil {
+			t.Fatalf("CreateAPIKey() error = %v", err)
+		}
+		_, err = service.AuthenticateAPIKey(ctx, "ydw_expired")
+		if got := errorCode(err); got != common.AuthHeaderTokenVerificationFailed {
+			t.Errorf("AuthenticateAPIKey() expired error code = %v, want %v", got, common.AuthHeaderTokenVerificationFailed)
+		}
+	})
+
+	t.Run("should reject keys of suspended users", func(t *testing.T) {
+		now := time.Now()
+		suspended := modles.NewUserFromParams(modles.UserParams{
+			ID: 6, Name: "Sam Seller", Email: "sam@example.com", Password: "hashed", Role: modles.RoleSeller, SuspendedAt: &now,
+		})
+		service, keys := newService(suspended)
+		if _, err := keys.CreateAPIKey(ctx, modles.NewAPIKey(6, "sync", "ydw_suspended", common.HashToken("ydw_suspended"), []string{"product:create"}, nil)); err != nil {
+			t.Fatalf("CreateAPIKey() error = %v", err)
+		}
+
+		_, err := service.AuthenticateAPIKey(ctx, "ydw_suspended")
+		if got := errorCode(err); got != common.AuthHeaderTokenVerificationFailed {
+			t.Errorf("AuthenticateAPIKey() error code = %v, want %v", got, common.AuthHeaderTokenVerificationFailed)
+		}
+	})
+	t.Run("should not create keys for sellers pending approval", func(t *testing.T) {
+		pending := modles.NewUserFromParams(modles.UserParams{
+			ID: 7, Name: "Pat Seller", Email: "pat@example.com", Password: "hashed", Role: modles.RoleSeller, SellerStatus: modles.SellerPending,
+		})
+		service, _ := newService(pending)
+
+		_, err := service.CreateAPIKey(ctx, pending.ID(), CreateAPIKeyReq{Name: "sync", Scopes: []string{"product:create"}})
+		if got := errorCode(err); got != common.PermissionDeniedErrorCode {
+			t.Errorf("CreateAPIKey() error code = %v, want %v", got, common.PermissionDeniedErrorCode)
+		}
+	})
+
+	t.Run("should reject keys of rejected sellers", func(t *testing.T) {
+		rejected := modles.NewUserFromParams(modles.UserParams{
+			ID: 8, Name: "Ray Seller", Email: "ray@example.com", Password: "hashed", Role: modles.RoleSeller, SellerStatus: modles.SellerRejected,
+		})
+		service, keys := newService(rejected)
+		if _, err := keys.CreateAPIKey(ctx, modles.NewAPIKey(8, "sync", "ydw_rejected", common.HashToken("ydw_rejected"), []string{"product:create"}, nil)); err != nil {
+			t.Fatalf("CreateAPIKey() error = %v", err)
+		}
+
+		_, err := service.AuthenticateAPIKey(ctx, "ydw_rejected")
+		if got := errorCode(err); got != common.AuthHeaderTokenVerificationFailed {
+			t.Errorf("AuthenticateAPIKey() error code = %v, want %v", got, common.AuthHeaderTokenVerificationFailed)
+		}
+	})
+}
@@ -0,0 +1,99 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"yadwy-backend/internal/common"
+	"yadwy-backend/internal/users/domain/contracts"
+	"yadwy-backend/internal/users/domain/modles"
+)
+
+// EmailVerificationConfig controls the verification tokens and the link mailed
+// to users.
+type EmailVerificationConfig struct {
+	TokenTTL time.Duration
+	// ResendInterval is the minimum time between two verification emails to
+	// the same user.
+	ResendInterval time.Duration
+	// VerifyURL is the page that accepts the token, the token is appended as
+	// the "token" query parameter.
+	VerifyURL string
+}
+
+type EmailVerificationService struct {
+	userRepo contracts.UserRepo
+	tokens   contracts.ActionTokenRepo
+	mailer   common.Mailer
+	cfg      EmailVerificationConfig
+}
+
+func NewEmailVerificationService(
+	repo contracts.UserRepo,
+	tokens contracts.ActionTokenRepo,
+	mailer common.Mailer,
+	cfg EmailVerificationConfig) *EmailVerificationService {
+	return &EmailVerificationService{
+		userRepo: repo,
+		tokens:   tokens,
+		mailer:   mailer,
+		cfg:      cfg,
+	}
+}
+
+// SendVerification mails a new verification link to the user, invalidating
+// the links sent before.
+func (s *EmailVerificationService) SendVerification(ctx context.Context, user *modles.User) error {
+	if err := s.tokens.InvalidateActionTokens(ctx, user.ID(), modles.EmailVerificationPurpose); err != nil {
+		return err
+	}
+
+	token, err := issueActionToken(ctx, s.tokens, user.ID(), modles.EmailVerificationPurpose, s.cfg.TokenTTL)
+	if err != nil {
+		return err
+	}
+
+	return s.mailer.Send(ctx, common.Mail{
+		To:      user.Email(),
+		Subject: "Verify your Yadwy email address",
+		Body: fmt.Sprintf("Hi %s,\n\nWelcome to Yadwy! Please confirm your email address using the link below. "+
+			"It expires in %s.\n\n%s\n", user.Name(), s.cfg.TokenTTL, actionURL(s.cfg.VerifyURL, token)),
+	})
+}
+
+// ResendVerification mails a new verification link to a user that is not
+// verified yet, at most once per ResendInterval.
+func (s *EmailVerificationService) ResendVerification(ctx context.Context, userID int) error {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.IsEmailVerified() {
+		return common.NewErrorf(modles.EmailAlreadyVerifiedError, "email is already verified")
+	}
+
+	wait, err := resendWait(ctx, s.tokens, userID, modles.EmailVerificationPurpose, s.cfg.ResendInterval)
+	if err != nil {
+		return err
+	}
+	if wait > 0 {
+		return common.NewErrorf(modles.VerificationThrottledError,
+			"a verification email was sent recently, try again in %s", wait.Round(time.Second))
+	}
+
+	return s.SendVerification(ctx, user)
+}
+
+// VerifyEmail marks the email of the token owner as verified. Tokens issued
+// afterwards carry the verified flag.
+func (s *EmailVerificationService) VerifyEmail(ctx context.Context, req VerifyEmailReq) error {
+	token, err := redeemActionToken(ctx, s.tokens, modles.EmailVerificationPurpose, req.Token)
+	if err != nil {
+		return err
+	}
+	if token == nil {
+		return common.NewErrorf(modles.InvalidVerificationTokenError, "verification token is invalid or expired")
+	}
+
+	return s.userRepo.MarkEmailVerified(ctx, token.UserID())
+}
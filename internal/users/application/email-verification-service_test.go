@@ -0,0 +1,134 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"yadwy-backend/internal/users/domain/contracts/mock"
+	"yadwy-backend/internal/users/domain/modles"
+)
+
+func newTestVerificationService(users *mock.UserRepo, mailer *mock.Mailer) *EmailVerificationService {
+	return NewEmailVerificationService(users, mock.NewActionTokenRepo(), mailer, EmailVerificationConfig{
+		TokenTTL:       time.Hour,
+		ResendInterval: time.Minute,
+		VerifyURL:      "http://localhost:3000/verify-email",
+	})
+}
+
+func TestEmailVerificationService(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should mail a link on registration and verify it once", func(t *testing.T) {
+		var verifiedID int
+		users := &mock.UserRepo{
+			CreateUserFunc: func(ctx context.Context, user *modles.User) (*modles.User, error) {
+				return testUser(), nil
+			},
+			MarkEmailVerifiedFunc: func(ctx context.Context, userID int) error {
+				verifiedID = userID
+				return nil
+			},
+		}
+		mailer := &mock.Mailer{}
+		verifier := newTestVerificationService(users, mailer)
+		service := newTestUserService(users, mock.NewRefreshTokenRepo())
+		service.verifier = verifier
+
+		res, err := service.CreateUser(ctx, CreateUserReq{
+			Name:     "John Doe",
+			Email:    "john@example.com",
+			Password: "strongpassword123",
+			Role:     "CUSTOMER",
+		}, ClientInfo{})
+		if err != nil {
+			t.Fatalf("CreateUser() error = %v", err)
+		}
+		if res.User.EmailVerified {
+			t.Errorf("CreateUser() returned a verified user")
+		}
+		if len(mailer.Sent) != 1 {
+			t.Fatalf("CreateUser() sent %d mails, want 1", len(mailer.Sent))
+		}
+
+		req := VerifyEmailReq{Token: tokenFromMail(t, mailer.Sent[0])}
+		if err := verifier.VerifyEmail(ctx, req); err != nil {
+			t.Fatalf("VerifyEmail() error = %v", err)
+		}
+		if verifiedID != testUser().ID() {
+			t.Errorf("VerifyEmail() verified user %d, want %d", verifiedID, testUser().ID())
+		}
+
+		err = verifier.VerifyEmail(ctx, req)
+		if got := errorCode(err); got != modles.InvalidVerificationTokenError {
+			t.Errorf("VerifyEmail() reuse error code = %v, want %v", got, modles.InvalidVerificationTokenError)
+		}
+	})
+
+	t.Run("should register the user when the link cannot be mailed", func(t *testing.T) {
+		created := 0
+		users := &mock.UserRepo{
+			CreateUserFunc: func(ctx context.Context, user *modles.User) (*modles.User, error) {
+				created++
+				return testUser(), nil
+			},
+		}
+		service := newTestUserService(users, mock.NewRefreshTokenRepo())
+		service.verifier = newTestVerificationService(users, &mock.Mailer{Err: errors.New("smtp unavailable")})
+
+		res, err := service.CreateUser(ctx, CreateUserReq{
+			Name:     "John Doe",
+			Email:    "john@example.com",
+			Password: "strongpassword123",
+			Role:     "CUSTOMER",
+		}, ClientInfo{})
+		if err != nil {
+			t.Fatalf("CreateUser() error = %v", err)
+		}
+		if created != 1 || res.AccessToken == "" {
+			t.Errorf("CreateUser() created %d users, returned %+v, want a logged in user", created, res)
+		}
+	})
+
+	t.Run("should throttle resends", func(t *testing.T) {
+		users := &mock.UserRepo{
+			GetUserByIDFunc: func(ctx context.Context, id int) (*modles.User, error) {
+				return testUser(), nil
+			},
+		}
+		mailer := &mock.Mailer{}
+		verifier := newTestVerificationService(users, mailer)
+
+		if err := verifier.ResendVerification(ctx, testUser().ID()); err != nil {
+			t.Fatalf("ResendVerification() error = %v", err)
+		}
+		err := verifier.ResendVerification(ctx, testUser().ID())
+		if got := errorCode(err); got != modles.VerificationThrottledError {
+			t.Errorf("ResendVerification() error code = %v, want %v", got, modles.VerificationThrottledError)
+		}
+		if len(mailer.Sent) != 1 {
+			t.Errorf("ResendVerification() sent %d mails, want 1", len(mailer.Sent))
+		}
+	})
+
+	t.Run("should not resend to verified users", func(t *testing.T) {
+		verifiedAt := time.Now()
+		users := &mock.UserRepo{
+			GetUserByIDFunc: func(ctx context.Context, id int) (*modles.User, error) {
+				return modles.NewUserFromParams(modles.UserParams{
+					ID:              1,
+					Email:           "john@example.com",
+					Role:            modles.RoleCustomer,
+					EmailVerifiedAt: &verifiedAt,
+				}), nil
+			},
+		}
+		verifier := newTestVerificationService(users, &mock.Mailer{})
+
+		err := verifier.ResendVerification(ctx, 1)
+		if got := errorCode(err); got != modles.EmailAlreadyVerifiedError {
+			t.Errorf("ResendVerification() error code = %v, want %v", got, modles.EmailAlreadyVerifiedError)
+		}
+	})
+}
@@ -0,0 +1,150 @@
+package application
+
+import (
+	"context"
+	"strings"
+	"time"
+	"yadwy-backend/internal/common"
+	"yadwy-backend/internal/users/domain/contracts"
+	"yadwy-backend/internal/users/domain/modles"
+
+	"go.uber.org/zap"
+)
+
+// LoginGuardConfig controls how failed logins slow down and lock out further
+// attempts.
+type LoginGuardConfig struct {
+	// FreeAttempts is the number of failures allowed before backoff starts.
+	FreeAttempts int
+	// BackoffBase is the delay after the first failure past FreeAttempts, it
+	// doubles with every further failure up to MaxBackoff.
+	BackoffBase time.Duration
+	MaxBackoff  time.Duration
+	// AccountLockoutThreshold and IPLockoutThreshold are the failures after
+	// which the account or client address is locked for LockoutDuration.
+	AccountLockoutThreshold int
+	IPLockoutThreshold      int
+	LockoutDuration         time.Duration
+	// ResetAfter is how long without failures before a counter starts over.
+	ResetAfter time.Duration
+}
+
+// LoginGuard tracks failed logins per account and per client address.
+type LoginGuard struct {
+	store contracts.LoginAttemptStore
+	cfg   LoginGuardConfig
+}
+
+func NewLoginGuard(store contracts.LoginAttemptStore, cfg LoginGuardConfig) *LoginGuard {
+	return &LoginGuard{
+		store: store,
+		cfg:   cfg,
+	}
+}
+
+// Check returns a throttling error when the account or the client address
+// must wait before trying again.
+func (g *LoginGuard) Check(ctx context.Context, email, clientIP string) error {
+	now := time.Now()
+	var until time.Time
+	for _, k := range g.keys(email, clientIP) {
+		attempts, err := g.store.GetLoginAttempts(ctx, k.key)
+		if err != nil {
+			return err
+		}
+		if attempts == nil {
+			continue
+		}
+		if blocked := g.blockedUntil(attempts, k.lockoutThreshold); blocked.After(until) {
+			until = blocked
+		}
+	}
+
+	if now.Before(until) {
+		return common.NewErrorf(modles.TooManyLoginAttemptsError,
+			"too many failed login attempts, try again in %s", until.Sub(now).Round(time.Second))
+	}
+	return nil
+}
+
+func (g *LoginGuard) RecordFailure(ctx context.Context, email, clientIP string) error {
+	now := time.Now()
+	for _, k := range g.keys(email, clientIP) {
+		if _, err := g.store.RecordLoginFailure(ctx, k.key, now, now.Add(-g.cfg.ResetAfter)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RecordSuccess clears the account counter. The client address counter is
+// kept so that one valid account cannot reset it.
+func (g *LoginGuard) RecordSuccess(ctx context.Context, email string) error {
+	return g.store.ResetLoginAttempts(ctx, accountKey(email))
+}
+
+// Unlock clears the failed logins of an account.
+func (g *LoginGuard) Unlock(ctx context.Context, email string) error {
+	return g.store.ResetLoginAttempts(ctx, accountKey(email))
+}
+
+func (g *LoginGuard) blockedUntil(attempts *modles.LoginAttempts, lockoutThreshold int) time.Time {
+	failures := attempts.Failures()
+	if attempts.LastFailureAt().Before(time.Now().Add(-g.cfg.ResetAfter)) {
+		return time.Time{}
+	}
+	if lockoutThreshold > 0 && failures >= lockoutThreshold {
+		return attempts.LastFailureAt().Add(g.cfg.LockoutDuration)
+	}
+	if failures <= g.cfg.FreeAttempts {
+		return time.Time{}
+	}
+
+	delay := g.cfg.BackoffBase
+	for i := g.cfg.FreeAttempts + 1; i < failures && delay < g.cfg.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > g.cfg.MaxBackoff {
+		delay = g.cfg.MaxBackoff
+	}
+	return attempts.LastFailureAt().Add(delay)
+}
+
+type attemptKey struct {
+	key              string
+	lockoutThreshold int
+}
+
+func (g *LoginGuard) keys(email, clientIP string) []attemptKey {
+	keys := []attemptKey{{key: accountKey(email), lockoutThreshold: g.cfg.AccountLockoutThreshold}}
+	if clientIP != "" {
+		keys = append(keys, attemptKey{key: "ip:" + clientIP, lockoutThreshold: g.cfg.IPLockoutThreshold})
+	}
+	return keys
+}
+
+func accountKey(email string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+// RunLoginAttemptCleanup removes counters that would have been reset anyway
+// every interval until the context is cancelled.
+func RunLoginAttemptCleanup(ctx context.Context, guard *LoginGuard, interval time.Duration, logger *zap.Logger) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := guard.store.DeleteLoginAttemptsBefore(ctx, now.Add(-guard.cfg.ResetAfter)); err != nil {
+				logger.Error("Failed to delete stale login attempts", zap.Error(err))
+			}
+		}
+	}
+}
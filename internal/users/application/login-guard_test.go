@@ -0,0 +1,130 @@
+package application
+
+import (
+	"context"
+	"testing"
+	"time"
+	"yadwy-backend/internal/common"
+	"yadwy-backend/internal/users/db"
+	"yadwy-backend/internal/users/domain/contracts/mock"
+	"yadwy-backend/internal/users/domain/modles"
+)
+
+func newTestLoginGuard() *LoginGuard {
+	return NewLoginGuard(db.NewMemoryLoginAttemptStore(), LoginGuardConfig{
+		FreeAttempts:            2,
+		BackoffBase:             time.Minute,
+		MaxBackoff:              4 * time.Minute,
+		AccountLockoutThreshold: 5,
+		IPLockoutThreshold:      20,
+		LockoutDuration:         time.Hour,
+		ResetAfter:              24 * time.Hour,
+	})
+}
+
+func TestLoginGuard_blockedUntil(t *testing.T) {
+	guard := newTestLoginGuard()
+	last := time.Now()
+
+	tests := []struct {
+		name     string
+		failures int
+		want     time.Duration
+	}{
+		{name: "free attempts", failures: 2, want: 0},
+		{name: "first backoff", failures: 3, want: time.Minute},
+		{name: "doubled backoff", failures: 4, want: 2 * time.Minute},
+		{name: "lockout", failures: 5, want: time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := guard.blockedUntil(modles.NewLoginAttempts(tt.failures, last), guard.cfg.AccountLockoutThreshold)
+			var want time.Time
+			if tt.want > 0 {
+				want = last.Add(tt.want)
+			}
+			if !got.Equal(want) {
+				t.Errorf("blockedUntil() = %v, want %v", got, want)
+			}
+		})
+	}
+
+	t.Run("caps backoff", func(t *testing.T) {
+		got := guard.blockedUntil(modles.NewLoginAttempts(10, last), 0)
+		if want := last.Add(4 * time.Minute); !got.Equal(want) {
+			t.Errorf("blockedUntil() = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("forgets old failures", func(t *testing.T) {
+		got := guard.blockedUntil(modles.NewLoginAttempts(5, last.Add(-48*time.Hour)), guard.cfg.AccountLockoutThreshold)
+		if !got.IsZero() {
+			t.Errorf("blockedUntil() = %v, want zero", got)
+		}
+	})
+}
+
+func TestUserService_LoginUser(t *testing.T) {
+	ctx := context.Background()
+	hash, err := common.HashPass("correct-password")
+	if err != nil {
+		t.Fatalf("HashPass() error = %v", err)
+	}
+	user := modles.NewUser(1, "John Doe", "john@example.com", hash, modles.RoleCustomer)
+	users := &mock.UserRepo{
+		GetUserFunc: func(ctx context.Context, email string) (*modles.User, error) {
+			if email != user.Email() {
+				return nil, common.NewErrorf(modles.UserNotFoundError, "user not found")
+			}
+			return user, nil
+		},
+	}
+
+	t.Run("should report unknown emails as invalid credentials", func(t *testing.T) {
+		service := newTestUserService(users, mock.NewRefreshTokenRepo())
+
+		_, err := service.LoginUser(ctx, LoginUserReq{Email: "nobody@example.com", Password: "x"}, ClientInfo{IP: "10.0.0.1"})
+		if got := errorCode(err); got != modles.InvalidUserCredentialsError {
+			t.Errorf("LoginUser() error code = %v, want %v", got, modles.InvalidUserCredentialsError)
+		}
+	})
+
+	t.Run("should throttle repeated failures until unlocked", func(t *testing.T) {
+		service := newTestUserService(users, mock.NewRefreshTokenRepo())
+		wrong := LoginUserReq{Email: user.Email(), Password: "wrong-password"}
+
+		for i := 0; i < service.loginGuard.cfg.FreeAttempts+1; i++ {
+			_, err := service.LoginUser(ctx, wrong, ClientInfo{IP: "10.0.0.1"})
+			if got := errorCode(err); got != modles.InvalidUserCredentialsError {
+				t.Fatalf("LoginUser() attempt %d error code = %v, want %v", i+1, got, modles.InvalidUserCredentialsError)
+			}
+		}
+
+		// The account is throttled even with the right password and another address.
+		_, err := service.LoginUser(ctx, LoginUserReq{Email: "JOHN@example.com", Password: "correct-password"}, ClientInfo{IP: "10.0.0.2"})
+		if got := errorCode(err); got != modles.TooManyLoginAttemptsError {
+			t.Fatalf("LoginUser() error code = %v, want %v", got, modles.TooManyLoginAttemptsError)
+		}
+
+		if err := service.loginGuard.Unlock(ctx, user.Email()); err != nil {
+			t.Fatalf("Unlock() error = %v", err)
+		}
+		if _, err := service.LoginUser(ctx, LoginUserReq{Email: user.Email(), Password: "correct-password"}, ClientInfo{IP: "10.0.0.2"}); err != nil {
+			t.Errorf("LoginUser() after unlock error = %v", err)
+		}
+	})
+
+	t.Run("should throttle a client address across accounts", func(t *testing.T) {
+		service := newTestUserService(users, mock.NewRefreshTokenRepo())
+		service.loginGuard.cfg.IPLockoutThreshold = 3
+
+		for _, email := range []string{"a@example.com", "b@example.com", "c@example.com"} {
+			_, _ = service.LoginUser(ctx, LoginUserReq{Email: email, Password: "x"}, ClientInfo{IP: "10.0.0.9"})
+		}
+
+		_, err := service.LoginUser(ctx, LoginUserReq{Email: user.Email(), Password: "correct-password"}, ClientInfo{IP: "10.0.0.9"})
+		if got := errorCode(err); got != modles.TooManyLoginAttemptsError {
+			t.Errorf("LoginUser() error code = %v, want %v", got, modles.TooManyLoginAttemptsError)
+		}
+	})
+}
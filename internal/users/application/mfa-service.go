@@ -0,0 +1,155 @@
+package application
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"fmt"
+	"strings"
+	"time"
+	"yadwy-backend/internal/common"
+	"yadwy-backend/internal/users/domain/contracts"
+	"yadwy-backend/internal/users/domain/modles"
+)
+
+const recoveryCodeCount = 10
+
+// recoveryCodeBytes is the randomness of a recovery code, 80 bits.
+const recoveryCodeBytes = 10
+
+// MFAPolicy decides which roles must log in with a second factor.
+type MFAPolicy struct {
+	RequiredRoles []modles.Role
+	// ChallengeTTL is the lifetime of the MFA tokens returned by LoginUser.
+	ChallengeTTL time.Duration
+}
+
+func (p MFAPolicy) requires(role modles.Role) bool {
+	for _, r := range p.RequiredRoles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// MFAPolicyValidator rejects access tokens of roles that require MFA when the
+// login did not use a second factor, such as tokens issued before the policy.
+type MFAPolicyValidator struct {
+	policy MFAPolicy
+}
+
+func NewMFAPolicyValidator(policy MFAPolicy) *MFAPolicyValidator {
+	return &MFAPolicyValidator{
+		policy: policy,
+	}
+}
+
+func (v *MFAPolicyValidator) ValidateToken(ctx context.Context, claims *common.UserClaims) error {
+	if claims.TokenType != common.AccessTokenType || claims.MFA {
+		return nil
+	}
+	if v.policy.requires(modles.Role(claims.Role)) {
+		return common.NewErrorf(common.MFARequiredErrorCode, "%s accounts must log in with MFA", claims.Role)
+	}
+	return nil
+}
+
+// MFAService enrolls users in TOTP based MFA.
+type MFAService struct {
+	userRepo contracts.UserRepo
+	mfa      contracts.MFARepo
+	issuer   string
+}
+
+func NewMFAService(repo contracts.UserRepo, mfa contracts.MFARepo, issuer string) *MFAService {
+	return &MFAService{
+		userRepo: repo,
+		mfa:      mfa,
+		issuer:   issuer,
+	}
+}
+
+// Enroll creates a new TOTP secret for the user. It replaces a pending
+// enrollment but not a confirmed one.
+func (s *MFAService) Enroll(ctx context.Context, userID int) (*MFAEnrollmentRes, error) {
+	enrollment, err := s.mfa.GetMFAEnrollment(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if enrollment != nil && enrollment.IsConfirmed() {
+		return nil, common.NewErrorf(modles.MFAAlreadyEnabledError, "MFA is already enabled")
+	}
+
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := common.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.mfa.SaveMFASecret(ctx, userID, secret); err != nil {
+		return nil, err
+	}
+
+	return &MFAEnrollmentRes{
+		Secret:     secret,
+		OTPAuthURI: common.TOTPURI(s.issuer, user.Email(), secret),
+	}, nil
+}
+
+// Confirm enables MFA with a code from the authenticator app and returns the
+// recovery codes, which are only ever shown here.
+func (s *MFAService) Confirm(ctx context.Context, userID int, req ConfirmMFAReq) (*MFARecoveryCodesRes, error) {
+	enrollment, err := s.mfa.GetMFAEnrollment(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if enrollment == nil {
+		return nil, common.NewErrorf(modles.MFANotEnrolledError, "MFA enrollment has not been started")
+	}
+	if enrollment.IsConfirmed() {
+		return nil, common.NewErrorf(modles.MFAAlreadyEnabledError, "MFA is already enabled")
+	}
+
+	step, ok := common.ValidateTOTP(enrollment.Secret(), req.Code, time.Now())
+	if !ok {
+		return nil, common.NewErrorf(modles.InvalidMFACodeError, "invalid MFA code")
+	}
+
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := newRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+
+	if err := s.mfa.ConfirmMFA(ctx, userID, step, hashes); err != nil {
+		return nil, err
+	}
+	return &MFARecoveryCodesRes{RecoveryCodes: codes}, nil
+}
+
+// newRecoveryCode returns a code such as "k3d7-q2xa-m5ve-7hzc", the base32
+// encoding of recoveryCodeBytes random bytes.
+func newRecoveryCode() (string, error) {
+	b := make([]byte, recoveryCodeBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("error generating recovery code: %w", err)
+	}
+	code := strings.ToLower(base32.StdEncoding.EncodeToString(b))
+	return code[:4] + "-" + code[4:8] + "-" + code[8:12] + "-" + code[12:], nil
+}
+
+// hashRecoveryCode ignores case, spaces and dashes so that codes can be typed
+// loosely.
+func hashRecoveryCode(code string) string {
+	normalized := strings.NewReplacer("-", "", " ", "").Replace(strings.ToLower(code))
+	return common.HashToken(normalized)
+}
@@ -0,0 +1,134 @@
+package application
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+	"yadwy-backend/internal/common"
+	"yadwy-backend/internal/users/domain/contracts/mock"
+	"yadwy-backend/internal/users/domain/modles"
+)
+
+func TestMFA_Login(t *testing.T) {
+	ctx := context.Background()
+	hash, err := common.HashPass("correct-password")
+	if err != nil {
+		t.Fatalf("HashPass() error = %v", err)
+	}
+
+	newServices := func(role modles.Role) (*UserService, *MFAService, *modles.User) {
+		user := modles.NewUser(1, "John Doe", "john@example.com", hash, role)
+		users := &mock.UserRepo{
+			GetUserFunc: func(ctx context.Context, email string) (*modles.User, error) {
+				return user, nil
+			},
+			GetUserByIDFunc: func(ctx context.Context, id int) (*modles.User, error) {
+				return user, nil
+			},
+		}
+		userSvc := newTestUserService(users, mock.NewRefreshTokenRepo())
+		return userSvc, NewMFAService(users, userSvc.mfa, "Yadwy"), user
+	}
+
+	login := LoginUserReq{Email: "john@example.com", Password: "correct-password"}
+
+	t.Run("should require a code once enrolled", func(t *testing.T) {
+		userSvc, mfaSvc, user := newServices(modles.RoleSeller)
+
+		enrollment, err := mfaSvc.Enroll(ctx, user.ID())
+		if err != nil {
+			t.Fatalf("Enroll() error = %v", err)
+		}
+		code, _ := common.TOTPCode(enrollment.Secret, common.TOTPStep(time.Now())-1)
+		recovery, err := mfaSvc.Confirm(ctx, user.ID(), ConfirmMFAReq{Code: code})
+		if err != nil {
+			t.Fatalf("Confirm() error = %v", err)
+		}
+		if len(recovery.RecoveryCodes) != recoveryCodeCount {
+			t.Fatalf("Confirm() returned %d recovery codes, want %d", len(recovery.RecoveryCodes), recoveryCodeCount)
+		}
+
+		res, err := userSvc.LoginUser(ctx, login, ClientInfo{IP: "10.0.0.1"})
+		if err != nil {
+			t.Fatalf("LoginUser() error = %v", err)
+		}
+		if !res.MFARequired || res.MFAToken == "" || res.AccessToken != "" {
+			t.Fatalf("LoginUser() = %+v, want an MFA challenge without tokens", res)
+		}
+
+		// The code used for confirmation cannot be replayed.
+		_, err = userSvc.LoginWithMFA(ctx, MFALoginReq{MFAToken: res.MFAToken, Code: code}, ClientInfo{IP: "10.0.0.1"})
+		if got := errorCode(err); got != modles.InvalidMFACodeError {
+			t.Errorf("LoginWithMFA() replay error code = %v, want %v", got, modles.InvalidMFACodeError)
+		}
+
+		code, _ = common.TOTPCode(enrollment.Secret, common.TOTPStep(time.Now()))
+		tokens, err := userSvc.LoginWithMFA(ctx, MFALoginReq{MFAToken: res.MFAToken, Code: code}, ClientInfo{IP: "10.0.0.1"})
+		if err != nil {
+			t.Fatalf("LoginWithMFA() error = %v", err)
+		}
+		claims, err := userSvc.jwt.VerifyToken(tokens.AccessToken)
+		if err != nil || !claims.MFA {
+			t.Errorf("LoginWithMFA() access token claims = %+v, %v, want mfa claim", claims, err)
+		}
+
+		recoveryReq := MFALoginReq{MFAToken: res.MFAToken, RecoveryCode: recovery.RecoveryCodes[0]}
+		if _, err := userSvc.LoginWithMFA(ctx, recoveryReq, ClientInfo{IP: "10.0.0.1"}); err != nil {
+			t.Errorf("LoginWithMFA() with recovery code error = %v", err)
+		}
+		_, err = userSvc.LoginWithMFA(ctx, recoveryReq, ClientInfo{IP: "10.0.0.1"})
+		if got := errorCode(err); got != modles.InvalidMFACodeError {
+			t.Errorf("LoginWithMFA() reused recovery code error code = %v, want %v", got, modles.InvalidMFACodeError)
+		}
+	})
+
+	t.Run("should require enrollment for admins", func(t *testing.T) {
+		userSvc, _, _ := newServices(modles.RoleAdmin)
+
+		res, err := userSvc.LoginUser(ctx, login, ClientInfo{IP: "10.0.0.1"})
+		if err != nil {
+			t.Fatalf("LoginUser() error = %v", err)
+		}
+		if !res.MFAEnrollmentRequired || res.AccessToken != "" {
+			t.Fatalf("LoginUser() = %+v, want an enrollment token without tokens", res)
+		}
+
+		// An enrollment token cannot complete a login.
+		_, err = userSvc.LoginWithMFA(ctx, MFALoginReq{MFAToken: res.MFAToken, Code: "000000"}, ClientInfo{IP: "10.0.0.1"})
+		if got := errorCode(err); got != modles.InvalidMFATokenError {
+			t.Errorf("LoginWithMFA() error code = %v, want %v", got, modles.InvalidMFATokenError)
+		}
+	})
+
+	t.Run("should reject admin tokens without MFA", func(t *testing.T) {
+		validator := NewMFAPolicyValidator(MFAPolicy{RequiredRoles: []modles.Role{modles.RoleAdmin}})
+		claims := &common.UserClaims{Role: "ADMIN", TokenType: common.AccessTokenType}
+
+		if got := errorCode(validator.ValidateToken(ctx, claims)); got != common.MFARequiredErrorCode {
+			t.Errorf("ValidateToken() error code = %v, want %v", got, common.MFARequiredErrorCode)
+		}
+		claims.MFA = true
+		if err := validator.ValidateToken(ctx, claims); err != nil {
+			t.Errorf("ValidateToken() with MFA error = %v", err)
+		}
+	})
+}
+
+func TestNewRecoveryCode(t *testing.T) {
+	format := regexp.MustCompile(`^[a-z2-7]{4}(-[a-z2-7]{4}){3}$`)
+	seen := map[string]bool{}
+	for i := 0; i < 100; i++ {
+		code, err := newRecoveryCode()
+		if err != nil {
+			t.Fatalf("newRecoveryCode() error = %v", err)
+		}
+		if !format.MatchString(code) {
+			t.Fatalf("newRecoveryCode() = %q, want four groups of four base32 characters", code)
+		}
+		if seen[code] {
+			t.Fatalf("newRecoveryCode() repeated %q", code)
+		}
+		seen[code] = true
+	}
+}
@@ -0,0 +1,242 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+	"yadwy-backend/internal/common"
+	"yadwy-backend/internal/users/domain/contracts"
+	"yadwy-backend/internal/users/domain/modles"
+
+	"go.uber.org/zap"
+)
+
+// OIDCService logs users in with external OpenID Connect providers using the
+// authorization code flow with PKCE.
+type OIDCService struct {
+	userRepo   contracts.UserRepo
+	identities contracts.IdentityRepo
+	verifier   verificationSender
+	users      *UserService
+	providers  map[string]*common.OIDCProvider
+	stateTTL   time.Duration
+	logger     *zap.Logger
+}
+
+func NewOIDCService(
+	repo contracts.UserRepo,
+	identities contracts.IdentityRepo,
+	verifier verificationSender,
+	users *UserService,
+	providers []*common.OIDCProvider,
+	stateTTL time.Duration,
+	logger *zap.Logger) *OIDCService {
+	byName := make(map[string]*common.OIDCProvider, len(providers))
+	for _, p := range providers {
+		byName[p.Name()] = p
+	}
+	return &OIDCService{
+		userRepo:   repo,
+		identities: identities,
+		verifier:   verifier,
+		users:      users,
+		providers:  byName,
+		stateTTL:   stateTTL,
+		logger:     logger,
+	}
+}
+
+// StartLogin stores the PKCE verifier and nonce of a new login and returns
+// the provider page to send the user to.
+func (s *OIDCService) StartLogin(ctx context.Context, providerName string) (*OIDCStartRes, error) {
+	provider, err := s.provider(providerName)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := common.GenerateRandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := common.GenerateRandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	verifier, err := common.GenerateRandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	stored := modles.NewOIDCLoginState(common.HashToken(state), provider.Name(), verifier, nonce, time.Now().Add(s.stateTTL))
+	if err := s.identities.SaveOIDCLoginState(ctx, stored); err != nil {
+		return nil, err
+	}
+
+	return &OIDCStartRes{
+		AuthorizationURL: provider.AuthCodeURL(state, nonce, common.PKCEChallenge(verifier)),
+		State:            state,
+	}, nil
+}
+
+// CompleteLogin redeems the code the provider redirected back with and logs
+// in the linked user. An unlinked identity is linked to the user with the same
+// email only when the provider verified that email, otherwise a new customer
+// is created.
+func (s *OIDCService) CompleteLogin(ctx context.Context, providerName string, req OIDCCallbackReq, client ClientInfo) (*LoginUserRes, error) {
+	provider, err := s.provider(providerName)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := s.identities.ConsumeOIDCLoginState(ctx, common.HashToken(req.State))
+	if err != nil {
+		return nil, err
+	}
+	if state == nil || state.Provider() != provider.Name() || state.IsExpired(time.Now()) {
+		return nil, common.NewErrorf(modles.InvalidOIDCStateError, "login state is invalid or expired")
+	}
+
+	identity, err := provider.Exchange(ctx, req.Code, state.CodeVerifier(), state.Nonce())
+	if err != nil {
+		s.logger.Warn("OIDC code exchange failed", zap.String("provider", provider.Name()), zap.Error(err))
+		return nil, common.NewErrorf(modles.OIDCLoginFailedError, "could not sign in with %s", provider.Name())
+	}
+
+	user, err := s.resolveUser(ctx, provider.Name(), identity)
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.IsActive() {
+		return nil, common.NewErrorf(modles.OIDCLoginFailedError, "could not sign in with %s", provider.Name())
+	}
+	if user.IsSuspended() {
+		return nil, common.NewErrorf(modles.UserSuspendedError, "account is suspended")
+	}
+	return s.users.completeLogin(ctx, user, client)
+}
+
+func (s *OIDCService) resolveUser(ctx context.Context, provider string, identity *common.OIDCIdentity) (*modles.User, error) {
+	linked, err := s.identities.GetIdentity(ctx, provider, identity.Subject)
+	if err != nil {
+		return nil, err
+	}
+	if linked != nil {
+		return s.userRepo.GetUserByID(ctx, linked.UserID())
+	}
+
+	if identity.Email == "" {
+		return nil, common.NewErrorf(modles.OIDCLoginFailedError, "%s did not share an email address", provider)
+	}
+
+	created := false
+	user, err := s.userRepo.GetUser(ctx, identity.Email)
+	switch {
+	case err == nil:
+		if !identity.EmailVerified {
+			return nil, common.NewErrorf(modles.OIDCEmailNotVerifiedError,
+				"an account with this email exists, verify the email with %s or log in with a password", provider)
+		}
+		if !user.IsEmailVerified() {
+			if err := s.userRepo.MarkEmailVerified(ctx, user.ID()); err != nil {
+				return nil, err
+			}
+			// reload to pick up the verification time for the token claims
+			if user, err = s.userRepo.GetUserByID(ctx, user.ID()); err != nil {
+				return nil, err
+			}
+		}
+	case isUserNotFound(err):
+		if user, err = s.createUser(ctx, identity); err != nil {
+			return nil, err
+		}
+		created = true
+	default:
+		return nil, err
+	}
+
+	link := modles.NewIdentity(0, user.ID(), provider, identity.Subject, identity.Email, time.Now())
+	if err := s.identities.CreateIdentity(ctx, link); err != nil {
+		return nil, err
+	}
+
+	// the login goes on without the mail, the user can ask for the link again
+	if created && !user.IsEmailVerified() {
+		if err := s.verifier.SendVerification(ctx, user); err != nil {
+			s.logger.Error("Failed to send email verification", zap.Int("userID", user.ID()), zap.Error(err))
+		}
+	}
+	return user, nil
+}
+
+// createUser registers a customer for a new external identity. The password
+// is random, the user can set one through the password reset flow.
+func (s *OIDCService) createUser(ctx context.Context, identity *common.OIDCIdentity) (*modles.User, error) {
+	password, err := common.GenerateRandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	hashPass, err := common.HashPass(password)
+	if err != nil {
+		return nil, err
+	}
+
+	name := identity.Name
+	if name == "" {
+		name = strings.Split(identity.Email, "@")[0]
+	}
+
+	var verifiedAt *time.Time
+	if identity.EmailVerified {
+		now := time.Now()
+		verifiedAt = &now
+	}
+
+	user, err := s.userRepo.CreateUser(ctx, modles.NewUserFromParams(modles.UserParams{
+		Name:            name,
+		Email:           identity.Email,
+		Password:        hashPass,
+		Role:            modles.RoleCustomer,
+		EmailVerifiedAt: verifiedAt,
+	}), nil)
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (s *OIDCService) provider(name string) (*common.OIDCProvider, error) {
+	p, ok := s.providers[name]
+	if !ok {
+		return nil, common.NewErrorf(modles.UnknownOIDCProviderError, "unknown login provider %q", name)
+	}
+	return p, nil
+}
+
+// RunOIDCStateCleanup periodically deletes logins that were started but never
+// completed, until ctx is cancelled.
+func RunOIDCStateCleanup(ctx context.Context, identities contracts.IdentityRepo, interval time.Duration, logger *zap.Logger) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := identities.DeleteOIDCLoginStatesBefore(ctx, now); err != nil {
+				logger.Error("Failed to delete expired OIDC login states", zap.Error(err))
+			}
+		}
+	}
+}
+
+func isUserNotFound(err error) bool {
+	var appErr *common.Error
+	return errors.As(err, &appErr) && appErr.Code() == modles.UserNotFoundError
+}
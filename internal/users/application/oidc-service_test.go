@@ -0,0 +1,347 @@
+package application
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"yadwy-backend/internal/common"
+	"yadwy-backend/internal/users/domain/contracts/mock"
+	"yadwy-backend/internal/users/domain/modles"
+
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
+)
+
+// fakeOIDCServer is a minimal OpenID Connect provider. Authorize stands in
+// for the user signing in and returns the code the provider redirects with.
+type fakeOIDCServer struct {
+	*httptest.Server
+	key *rsa.PrivateKey
+
+	mu    sync.Mutex
+	codes map[string]fakeAuthorization
+}
+
+type fakeAuthorization struct {
+	challenge string
+	claims    jwt.MapClaims
+}
+
+func newFakeOIDCServer(t *testing.T) *fakeOIDCServer {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+
+	f := &fakeOIDCServer{key: key, codes: map[string]fakeAuthorization{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		_ = common.Encode(w, http.StatusOK, common.JWKSet{Keys: []common.JWK{{
+			Kty: "RSA",
+			Kid: "fake-key",
+			Use: "sig",
+			Alg: "RS256",
+			N:   base64.RawURLEncoding.EncodeToString(key.PublicKey.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.PublicKey.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		f.mu.Lock()
+		auth, ok := f.codes[r.PostForm.Get("code")]
+		delete(f.codes, r.PostForm.Get("code"))
+		f.mu.Unlock()
+
+		if !ok || common.PKCEChallenge(r.PostForm.Get("code_verifier")) != auth.challenge ||
+			r.PostForm.Get("client_id") != "yadwy" {
+			_ = common.Encode(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+			return
+		}
+
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, auth.claims)
+		token.Header["kid"] = "fake-key"
+		idToken, err := token.SignedString(key)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		_ = common.Encode(w, http.StatusOK, map[string]string{"access_token": "at", "id_token": idToken})
+	})
+	f.Server = httptest.NewServer(mux)
+	t.Cleanup(f.Close)
+	return f
+}
+
+func (f *fakeOIDCServer) provider() *common.OIDCProvider {
+	return common.NewOIDCProvider(common.OIDCProviderConfig{
+		Name:                  "fake",
+		ClientID:              "yadwy",
+		ClientSecret:          "secret",
+		Issuer:                f.URL,
+		AuthorizationEndpoint: f.URL + "/authorize",
+		TokenEndpoint:         f.URL + "/token",
+		JWKSURI:               f.URL + "/jwks",
+		RedirectURL:           "http://localhost:3000/auth/callback/fake",
+	}, f.Client())
+}
+
+// Authorize signs the user in for the authorization URL and returns the code.
+// modify can change the ID token claims.
+func (f *fakeOIDCServer) Authorize(t *testing.T, authURL, sub, email string, verified bool, modify func(jwt.MapClaims)) string {
+	t.Helper()
+	u, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatalf("url.Parse() error = %v", err)
+	}
+	q := u.Query()
+	if q.Get("code_challenge_method") != "S256" {
+		t.Fatalf("authorization URL code_challenge_method = %q, want S256", q.Get("code_challenge_method"))
+	}
+
+	claims := jwt.MapClaims{
+		"iss":            f.URL,
+		"aud":            "yadwy",
+		"sub":            sub,
+		"email":          email,
+		"email_verified": verified,
+		"name":           "Jane Doe",
+		"nonce":          q.Get("nonce"),
+		"iat":            time.Now().Unix(),
+		"exp":            time.Now().Add(time.Minute).Unix(),
+	}
+	if modify != nil {
+		modify(claims)
+	}
+
+	code, err := common.GenerateRandomToken(16)
+	if err != nil {
+		t.Fatalf("GenerateRandomToken() error = %v", err)
+	}
+	f.mu.Lock()
+	f.codes[code] = fakeAuthorization{challenge: q.Get("code_challenge"), claims: claims}
+	f.mu.Unlock()
+	return code
+}
+
+// memoryUsers backs a mock.UserRepo with a map of users by email.
+func memoryUsers(existing ...*modles.User) *mock.UserRepo {
+	var mu sync.Mutex
+	byEmail := map[string]*modles.User{}
+	for _, u := range existing {
+		byEmail[u.Email()] = u
+	}
+	find := func(match func(*modles.User) bool) (*modles.User, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, u := range byEmail {
+			if match(u) {
+				return u, nil
+			}
+		}
+		return nil, common.NewErrorf(modles.UserNotFoundError, "user not found")
+	}
+
+	return &mock.UserRepo{
+		GetUserFunc: func(ctx context.Context, email string) (*modles.User, error) {
+			return find(func(u *modles.User) bool { return strings.EqualFold(u.Email(), email) })
+		},
+		GetUserByIDFunc: func(ctx context.Context, id int) (*modles.User, error) {
+			return find(func(u *modles.User) bool { return u.ID() == id })
+		},
+		CreateUserFunc: func(ctx context.Context, user *modles.User) (*modles.User, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			saved := modles.NewUserFromParams(modles.UserParams{
+				ID:              100 + len(byEmail),
+				Name:            user.Name(),
+				Email:           user.Email(),
+				Password:        user.Password(),
+				Role:            user.Role(),
+				EmailVerifiedAt: user.EmailVerifiedAt(),
+			})
+			byEmail[saved.Email()] = saved
+			return saved, nil
+		},
+		MarkEmailVerifiedFunc: func(ctx context.Context, userID int) error {
+			mu.Lock()
+			defer mu.Unlock()
+			for email, u := range byEmail {
+				if u.ID() == userID {
+					now := time.Now()
+					byEmail[email] = modles.NewUserFromParams(modles.UserParams{
+						ID: u.ID(), Name: u.Name(), Email: u.Email(), Password: u.Password(), Role: u.Role(), EmailVerifiedAt: &now,
+					})
+				}
+			}
+			return nil
+		},
+	}
+}
+
+func TestOIDCService(t *testing.T) {
+	ctx := context.Background()
+	server := newFakeOIDCServer(t)
+
+	newService := func(users *mock.UserRepo, mailer *mock.Mailer) *OIDCService {
+		return NewOIDCService(users, mock.NewIdentityRepo(), newTestVerificationService(users, mailer),
+			newTestUserService(users, mock.NewRefreshTokenRepo()),
+			[]*common.OIDCProvider{server.provider()}, time.Minute, zap.NewNop())
+	}
+
+	login := func(t *testing.T, service *OIDCService, sub, email string, verified bool, modify func(jwt.MapClaims)) (*LoginUserRes, error) {
+		t.Helper()
+		start, err := service.StartLogin(ctx, "fake")
+		if err != nil {
+			t.Fatalf("StartLogin() error = %v", err)
+		}
+		code := server.Authorize(t, start.AuthorizationURL, sub, email, verified, modify)
+		return service.CompleteLogin(ctx, "fake", OIDCCallbackReq{Code: code, State: start.State}, ClientInfo{})
+	}
+
+	t.Run("should create a customer and log in again through the identity", func(t *testing.T) {
+		users := memoryUsers()
+		service := newService(users, &mock.Mailer{})
+
+		res, err := login(t, service, "sub-1", "jane@example.com", true, nil)
+		if err != nil {
+			t.Fatalf("CompleteLogin() error = %v", err)
+		}
+		if res.AccessToken == "" || res.User.Role != "CUSTOMER" || !res.User.EmailVerified {
+			t.Fatalf("CompleteLogin() = %+v, want tokens for a verified customer", res)
+		}
+
+		// the provider may change the email, the subject still identifies the user
+		again, err := login(t, service, "sub-1", "jane.new@example.com", true, nil)
+		if err != nil {
+			t.Fatalf("CompleteLogin() second login error = %v", err)
+		}
+		if again.User.ID != res.User.ID {
+			t.Errorf("CompleteLogin() second login user = %d, want %d", again.User.ID, res.User.ID)
+		}
+	})
+
+	t.Run("should link a verified email to the existing user", func(t *testing.T) {
+		existing := modles.NewUser(7, "John Doe", "john@example.com", "hashed", modles.RoleCustomer)
+		service := newService(memoryUsers(existing), &mock.Mailer{})
+
+		res, err := login(t, service, "sub-2", "john@example.com", true, nil)
+		if err != nil {
+			t.Fatalf("CompleteLogin() error = %v", err)
+		}
+		if res.User.ID != 7 || !res.User.EmailVerified {
+			t.Errorf("CompleteLogin() user = %+v, want user 7 with a verified email", res.User)
+		}
+	})
+
+	t.Run("should link an existing user registered with another case", func(t *testing.T) {
+		existing := modles.NewUser(7, "Jane Doe", "Jane@Example.com", "hashed", modles.RoleCustomer)
+		service := newService(memoryUsers(existing), &mock.Mailer{})
+
+		res, err := login(t, service, "sub-6", "jane@example.com", true, nil)
+		if err != nil {
+			t.Fatalf("CompleteLogin() error = %v", err)
+		}
+		if res.User.ID != 7 {
+			t.Errorf("CompleteLogin() user = %d, want the existing user 7", res.User.ID)
+		}
+	})
+
+	t.Run("should not link an unverified email", func(t *testing.T) {
+		existing := modles.NewUser(7, "John Doe", "john@example.com", "hashed", modles.RoleCustomer)
+		service := newService(memoryUsers(existing), &mock.Mailer{})
+
+		_, err := login(t, service, "sub-3", "john@example.com", false, nil)
+		if got := errorCode(err); got != modles.OIDCEmailNotVerifiedError {
+			t.Errorf("CompleteLogin() error code = %v, want %v", got, modles.OIDCEmailNotVerifiedError)
+		}
+	})
+
+	t.Run("should mail a verification link to new users with an unverified email", func(t *testing.T) {
+		mailer := &mock.Mailer{}
+		service := newService(memoryUsers(), mailer)
+
+		res, err := login(t, service, "sub-4", "new@example.com", false, nil)
+		if err != nil {
+			t.Fatalf("CompleteLogin() error = %v", err)
+		}
+		if res.User.EmailVerified || len(mailer.Sent) != 1 {
+			t.Errorf("CompleteLogin() verified = %v with %d mails, want unverified with 1 mail", res.User.EmailVerified, len(mailer.Sent))
+		}
+	})
+
+	t.Run("should log in and link the identity when the link cannot be mailed", func(t *testing.T) {
+		service := newService(memoryUsers(), &mock.Mailer{Err: errors.New("smtp unavailable")})
+
+		first, err := login(t, service, "sub-5", "offline@example.com", false, nil)
+		if err != nil {
+			t.Fatalf("CompleteLogin() error = %v", err)
+		}
+
+		// the identity is linked, so the unverified email does not lock the user out
+		again, err := login(t, service, "sub-5", "offline@example.com", false, nil)
+		if err != nil {
+			t.Fatalf("CompleteLogin() second login error = %v", err)
+		}
+		if again.User.ID != first.User.ID {
+			t.Errorf("CompleteLogin() second login user = %d, want %d", again.User.ID, first.User.ID)
+		}
+	})
+
+	t.Run("should reject a token for another nonce", func(t *testing.T) {
+		service := newService(memoryUsers(), &mock.Mailer{})
+
+		_, err := login(t, service, "sub-5", "jane@example.com", true, func(c jwt.MapClaims) { c["nonce"] = "other" })
+		if got := errorCode(err); got != modles.OIDCLoginFailedError {
+			t.Errorf("CompleteLogin() error code = %v, want %v", got, modles.OIDCLoginFailedError)
+		}
+	})
+
+	t.Run("should reject a token for another client", func(t *testing.T) {
+		service := newService(memoryUsers(), &mock.Mailer{})
+
+		_, err := login(t, service, "sub-6", "jane@example.com", true, func(c jwt.MapClaims) { c["aud"] = "someone-else" })
+		if got := errorCode(err); got != modles.OIDCLoginFailedError {
+			t.Errorf("CompleteLogin() error code = %v, want %v", got, modles.OIDCLoginFailedError)
+		}
+	})
+
+	t.Run("should accept a state once", func(t *testing.T) {
+		service := newService(memoryUsers(), &mock.Mailer{})
+		start, err := service.StartLogin(ctx, "fake")
+		if err != nil {
+			t.Fatalf("StartLogin() error = %v", err)
+		}
+		code := server.Authorize(t, start.AuthorizationURL, "sub-7", "jane@example.com", true, nil)
+
+		if _, err := service.CompleteLogin(ctx, "fake", OIDCCallbackReq{Code: code, State: start.State}, ClientInfo{}); err != nil {
+			t.Fatalf("CompleteLogin() error = %v", err)
+		}
+		_, err = service.CompleteLogin(ctx, "fake", OIDCCallbackReq{Code: code, State: start.State}, ClientInfo{})
+		if got := errorCode(err); got != modles.InvalidOIDCStateError {
+			t.Errorf("CompleteLogin() replay error code = %v, want %v", got, modles.InvalidOIDCStateError)
+		}
+	})
+
+	t.Run("should reject unknown providers", func(t *testing.T) {
+		service := newService(memoryUsers(), &mock.Mailer{})
+
+		_, err := service.StartLogin(ctx, "myspace")
+		if got := errorCode(err); got != modles.UnknownOIDCProviderError {
+			t.Errorf("StartLogin() error code = %v, want %v", got, modles.UnknownOIDCProviderError)
+		}
+	})
+}
@@ -0,0 +1,194 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+	"yadwy-backend/internal/common"
+	"yadwy-backend/internal/users/domain/contracts"
+	"yadwy-backend/internal/users/domain/modles"
+
+	"go.uber.org/zap"
+)
+
+// sessionRevoker signs a user out of every device.
+type sessionRevoker interface {
+	LogoutAll(ctx context.Context, userID int) error
+}
+
+// PasswordResetConfig controls the reset tokens and the link mailed to users.
+type PasswordResetConfig struct {
+	TokenTTL time.Duration
+	// ResendInterval is the minimum time between two reset emails to the same
+	// user.
+	ResendInterval time.Duration
+	// ResetURL is the page that accepts the token, the token is appended as
+	// the "token" query parameter.
+	ResetURL string
+}
+
+type PasswordResetService struct {
+	userRepo contracts.UserRepo
+	tokens   contracts.ActionTokenRepo
+	mailer   common.Mailer
+	sessions sessionRevoker
+	cfg      PasswordResetConfig
+	logger   *zap.Logger
+}
+
+func NewPasswordResetService(
+	repo contracts.UserRepo,
+	tokens contracts.ActionTokenRepo,
+	mailer common.Mailer,
+	sessions sessionRevoker,
+	cfg PasswordResetConfig,
+	logger *zap.Logger) *PasswordResetService {
+	return &PasswordResetService{
+		userRepo: repo,
+		tokens:   tokens,
+		mailer:   mailer,
+		sessions: sessions,
+		cfg:      cfg,
+		logger:   logger,
+	}
+}
+
+// ForgotPassword mails a reset link when the email belongs to a user, at most
+// once per ResendInterval. It reports neither unknown emails, nor throttled
+// requests or mail failures, so that accounts cannot be enumerated.
+func (s *PasswordResetService) ForgotPassword(ctx context.Context, req ForgotPasswordReq) error {
+	user, err := s.userRepo.GetUser(ctx, req.Email)
+	if err != nil {
+		var appErr *common.Error
+		if errors.As(err, &appErr) && appErr.Code() == modles.UserNotFoundError {
+			return nil
+		}
+		return err
+	}
+
+	wait, err := resendWait(ctx, s.tokens, user.ID(), modles.PasswordResetPurpose, s.cfg.ResendInterval)
+	if err != nil {
+		return err
+	}
+	if wait > 0 {
+		return nil
+	}
+
+	// Only the most recent link stays valid.
+	if err := s.tokens.InvalidateActionTokens(ctx, user.ID(), modles.PasswordResetPurpose); err != nil {
+		return err
+	}
+
+	token, err := issueActionToken(ctx, s.tokens, user.ID(), modles.PasswordResetPurpose, s.cfg.TokenTTL)
+	if err != nil {
+		return err
+	}
+
+	err = s.mailer.Send(ctx, common.Mail{
+		To:      user.Email(),
+		Subject: "Reset your Yadwy password",
+		Body: fmt.Sprintf("Hi %s,\n\nUse the link below to choose a new password. It expires in %s.\n\n%s\n\n"+
+			"If you did not ask for a password reset you can ignore this email.\n",
+			user.Name(), s.cfg.TokenTTL, actionURL(s.cfg.ResetURL, token)),
+	})
+	if err != nil {
+		s.logger.Error("Failed to send password reset email", zap.Int("userID", user.ID()), zap.Error(err))
+	}
+	return nil
+}
+
+// ResetPassword sets a new password using a reset token and signs the user
+// out everywhere.
+func (s *PasswordResetService) ResetPassword(ctx context.Context, req ResetPasswordReq) error {
+	token, err := redeemActionToken(ctx, s.tokens, modles.PasswordResetPurpose, req.Token)
+	if err != nil {
+		return err
+	}
+	if token == nil {
+		return common.NewErrorf(modles.InvalidResetTokenError, "reset token is invalid or expired")
+	}
+
+	hashPass, err := common.HashPass(req.NewPassword)
+	if err != nil {
+		return err
+	}
+
+	if err := s.userRepo.UpdatePassword(ctx, token.UserID(), hashPass); err != nil {
+		return err
+	}
+
+	return s.sessions.LogoutAll(ctx, token.UserID())
+}
+
+// issueActionToken stores a new single-use token and returns its plain text.
+func issueActionToken(
+	ctx context.Context,
+	repo contracts.ActionTokenRepo,
+	userID int,
+	purpose modles.TokenPurpose,
+	ttl time.Duration) (string, error) {
+	token, err := common.GenerateRandomToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	stored := modles.NewActionToken(0, userID, purpose, common.HashToken(token), now.Add(ttl), nil, now)
+	if err := repo.CreateActionToken(ctx, stored); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// resendWait returns how long the user must wait before another token for
+// purpose is mailed, or zero when one can be sent.
+func resendWait(
+	ctx context.Context,
+	repo contracts.ActionTokenRepo,
+	userID int,
+	purpose modles.TokenPurpose,
+	interval time.Duration) (time.Duration, error) {
+	latest, err := repo.GetLatestActionToken(ctx, userID, purpose)
+	if err != nil || latest == nil {
+		return 0, err
+	}
+	if wait := interval - time.Since(latest.CreatedAt()); wait > 0 {
+		return wait, nil
+	}
+	return 0, nil
+}
+
+// redeemActionToken consumes a token and returns it, or returns nil when the
+// token is unknown, expired or already used.
+func redeemActionToken(
+	ctx context.Context,
+	repo contracts.ActionTokenRepo,
+	purpose modles.TokenPurpose,
+	token string) (*modles.ActionToken, error) {
+	stored, err := repo.GetActionToken(ctx, purpose, common.HashToken(token))
+	if err != nil || stored == nil {
+		return nil, err
+	}
+	if !stored.IsUsable(time.Now()) {
+		return nil, nil
+	}
+
+	consumed, err := repo.ConsumeActionToken(ctx, stored.ID())
+	if err != nil || !consumed {
+		return nil, err
+	}
+	return stored, nil
+}
+
+func actionURL(base, token string) string {
+	u, err := url.Parse(base)
+	if err != nil {
+		return base + "?token=" + url.QueryEscape(token)
+	}
+	q := u.Query()
+	q.Set("token", token)
+	u.RawQuery = q.Encode()
+	return u.String()
+}
@@ -0,0 +1,152 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+	"yadwy-backend/internal/common"
+	"yadwy-backend/internal/users/domain/contracts/mock"
+	"yadwy-backend/internal/users/domain/modles"
+
+	"go.uber.org/zap"
+)
+
+type recordingRevoker struct {
+	userIDs []int
+}
+
+func (r *recordingRevoker) LogoutAll(ctx context.Context, userID int) error {
+	r.userIDs = append(r.userIDs, userID)
+	return nil
+}
+
+func tokenFromMail(t *testing.T, mail common.Mail) string {
+	t.Helper()
+	for _, field := range strings.Fields(mail.Body) {
+		if u, err := url.Parse(field); err == nil && u.Query().Get("token") != "" {
+			return u.Query().Get("token")
+		}
+	}
+	t.Fatalf("no token link in mail body %q", mail.Body)
+	return ""
+}
+
+func TestPasswordResetService(t *testing.T) {
+	ctx := context.Background()
+
+	newService := func(users *mock.UserRepo, mailer *mock.Mailer, revoker *recordingRevoker) *PasswordResetService {
+		return NewPasswordResetService(users, mock.NewActionTokenRepo(), mailer, revoker, PasswordResetConfig{
+			TokenTTL: time.Hour,
+			ResetURL: "http://localhost:3000/reset-password",
+		}, zap.NewNop())
+	}
+	knownUser := &mock.UserRepo{
+		GetUserFunc: func(ctx context.Context, email string) (*modles.User, error) {
+			return testUser(), nil
+		},
+	}
+
+	t.Run("should not mail unknown emails", func(t *testing.T) {
+		users := &mock.UserRepo{
+			GetUserFunc: func(ctx context.Context, email string) (*modles.User, error) {
+				return nil, common.NewErrorf(modles.UserNotFoundError, "user not found")
+			},
+		}
+		mailer := &mock.Mailer{}
+		service := newService(users, mailer, &recordingRevoker{})
+
+		if err := service.ForgotPassword(ctx, ForgotPasswordReq{Email: "nobody@example.com"}); err != nil {
+			t.Fatalf("ForgotPassword() error = %v", err)
+		}
+		if len(mailer.Sent) != 0 {
+			t.Errorf("ForgotPassword() sent %d mails, want 0", len(mailer.Sent))
+		}
+	})
+
+	t.Run("should reset password once and revoke sessions", func(t *testing.T) {
+		var updatedPassword string
+		users := &mock.UserRepo{
+			GetUserFunc: func(ctx context.Context, email string) (*modles.User, error) {
+				return testUser(), nil
+			},
+			UpdatePasswordFunc: func(ctx context.Context, userID int, hashedPassword string) error {
+				updatedPassword = hashedPassword
+				return nil
+			},
+		}
+		mailer := &mock.Mailer{}
+		revoker := &recordingRevoker{}
+		service := newService(users, mailer, revoker)
+
+		if err := service.ForgotPassword(ctx, ForgotPasswordReq{Email: testUser().Email()}); err != nil {
+			t.Fatalf("ForgotPassword() error = %v", err)
+		}
+		if len(mailer.Sent) != 1 {
+			t.Fatalf("ForgotPassword() sent %d mails, want 1", len(mailer.Sent))
+		}
+		token := tokenFromMail(t, mailer.Sent[0])
+
+		req := ResetPasswordReq{Token: token, NewPassword: "newstrongpassword123"}
+		if err := service.ResetPassword(ctx, req); err != nil {
+			t.Fatalf("ResetPassword() error = %v", err)
+		}
+		if common.CheckPassword(updatedPassword, req.NewPassword) != nil {
+			t.Errorf("ResetPassword() did not store the new password hash")
+		}
+		if len(revoker.userIDs) != 1 || revoker.userIDs[0] != testUser().ID() {
+			t.Errorf("ResetPassword() revoked sessions of %v, want [%d]", revoker.userIDs, testUser().ID())
+		}
+
+		err := service.ResetPassword(ctx, req)
+		if got := errorCode(err); got != modles.InvalidResetTokenError {
+			t.Errorf("ResetPassword() reuse error code = %v, want %v", got, modles.InvalidResetTokenError)
+		}
+	})
+
+	t.Run("should not report mail failures", func(t *testing.T) {
+		service := newService(knownUser, &mock.Mailer{Err: errors.New("smtp unavailable")}, &recordingRevoker{})
+
+		if err := service.ForgotPassword(ctx, ForgotPasswordReq{Email: testUser().Email()}); err != nil {
+			t.Errorf("ForgotPassword() error = %v, want nil", err)
+		}
+	})
+
+	t.Run("should mail at most one link per interval", func(t *testing.T) {
+		mailer := &mock.Mailer{}
+		service := NewPasswordResetService(knownUser, mock.NewActionTokenRepo(), mailer, &recordingRevoker{}, PasswordResetConfig{
+			TokenTTL:       time.Hour,
+			ResendInterval: time.Minute,
+			ResetURL:       "http://localhost:3000/reset-password",
+		}, zap.NewNop())
+
+		for range 2 {
+			if err := service.ForgotPassword(ctx, ForgotPasswordReq{Email: testUser().Email()}); err != nil {
+				t.Fatalf("ForgotPassword() error = %v", err)
+			}
+		}
+		if len(mailer.Sent) != 1 {
+			t.Errorf("ForgotPassword() sent %d mails, want 1", len(mailer.Sent))
+		}
+	})
+
+	t.Run("should invalidate older reset links", func(t *testing.T) {
+		users := &mock.UserRepo{
+			GetUserFunc: func(ctx context.Context, email string) (*modles.User, error) {
+				return testUser(), nil
+			},
+		}
+		mailer := &mock.Mailer{}
+		service := newService(users, mailer, &recordingRevoker{})
+
+		_ = service.ForgotPassword(ctx, ForgotPasswordReq{Email: testUser().Email()})
+		_ = service.ForgotPassword(ctx, ForgotPasswordReq{Email: testUser().Email()})
+
+		err := service.ResetPassword(ctx, ResetPasswordReq{Token: tokenFromMail(t, mailer.Sent[0]), NewPassword: "newstrongpassword123"})
+		if got := errorCode(err); got != modles.InvalidResetTokenError {
+			t.Errorf("ResetPassword() with old link error code = %v, want %v", got, modles.InvalidResetTokenError)
+		}
+	})
+}
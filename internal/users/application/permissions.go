@@ -0,0 +1,42 @@
+package application
+
+import (
+	"context"
+	"time"
+	"yadwy-backend/internal/common"
+	"yadwy-backend/internal/users/domain/contracts"
+
+	"go.uber.org/zap"
+)
+
+// LoadPermissions replaces the policy with the roles stored in the repo.
+func LoadPermissions(ctx context.Context, repo contracts.RolePermissionRepo, policy *common.PermissionPolicy) error {
+	roles, err := repo.ListRolePermissions(ctx)
+	if err != nil {
+		return err
+	}
+	policy.Replace(roles)
+	return nil
+}
+
+// RunPermissionReload reloads the policy every interval until ctx is done. A
+// failed reload keeps the previous mapping.
+func RunPermissionReload(ctx context.Context, repo contracts.RolePermissionRepo, policy *common.PermissionPolicy, interval time.Duration, logger *zap.Logger) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := LoadPermissions(ctx, repo, policy); err != nil {
+				logger.Error("Failed to reload role permissions", zap.Error(err))
+			}
+		}
+	}
+}
@@ -0,0 +1,488 @@
+package application
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+	"yadwy-backend/internal/common"
+	"yadwy-backend/internal/users/domain/contracts"
+	"yadwy-backend/internal/users/domain/modles"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// abandonedExportAfter is when a running export is assumed lost, e.g. to
+	// a restart, and no longer blocks a new request.
+	abandonedExportAfter = time.Hour
+	// exportQueueSize bounds the requests waiting for the worker. Requests
+	// that do not fit are picked up by the next pending export scan.
+	exportQueueSize = 64
+	auditPageSize   = 100
+)
+
+// PersonalDataModules are the other modules holding personal data of users.
+type PersonalDataModules struct {
+	Sources []common.PersonalDataSource
+	Erasers []common.PersonalDataEraser
+	// Files stores the uploaded files that sources add to exports.
+	Files *common.FileService
+}
+
+type PrivacyConfig struct {
+	// ExportDir is where export archives are written.
+	ExportDir string
+	// ExportTTL is how long an archive can be downloaded.
+	ExportTTL time.Duration
+}
+
+// PrivacyService exports the personal data of a user and erases accounts on
+// request. Both are recorded in the audit log.
+type PrivacyService struct {
+	userRepo    contracts.UserRepo
+	addresses   contracts.AddressRepo
+	identities  contracts.IdentityRepo
+	sessionRepo contracts.SessionRepo
+	apiKeys     contracts.APIKeyRepo
+	audit       contracts.AuditLog
+	exports     contracts.DataExportRepo
+	sessions    sessionRevoker
+	loginGuard  *LoginGuard
+	modules     PersonalDataModules
+	cfg         PrivacyConfig
+	queue       chan *modles.DataExport
+	logger      *zap.Logger
+}
+
+func NewPrivacyService(
+	repo contracts.UserRepo,
+	addresses contracts.AddressRepo,
+	identities contracts.IdentityRepo,
+	sessionRepo contracts.SessionRepo,
+	apiKeys contracts.APIKeyRepo,
+	audit contracts.AuditLog,
+	exports contracts.DataExportRepo,
+	sessions sessionRevoker,
+	loginGuard *LoginGuard,
+	modules PersonalDataModules,
+	cfg PrivacyConfig,
+	logger *zap.Logger) *PrivacyService {
+	return &PrivacyService{
+		userRepo:    repo,
+		addresses:   addresses,
+		identities:  identities,
+		sessionRepo: sessionRepo,
+		apiKeys:     apiKeys,
+		audit:       audit,
+		exports:     exports,
+		sessions:    sessions,
+		loginGuard:  loginGuard,
+		modules:     modules,
+		cfg:         cfg,
+		queue:       make(chan *modles.DataExport, exportQueueSize),
+		logger:      logger,
+	}
+}
+
+// RequestExport starts building an archive of the personal data of the user.
+// A user has at most one export in progress.
+func (s *PrivacyService) RequestExport(ctx context.Context, userID int) (*DataExportRes, error) {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsActive() {
+		return nil, common.NewErrorf(modles.UserNotFoundError, "user not found")
+	}
+
+	active, err := s.exports.GetActiveDataExport(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if active != nil {
+		if time.Since(active.CreatedAt()) < abandonedExportAfter {
+			return nil, common.NewErrorf(modles.DataExportInProgressError, "a data export is already in progress")
+		}
+		if err := s.exports.FailDataExport(ctx, active.ID(), "export abandoned", time.Now()); err != nil {
+			return nil, err
+		}
+	}
+
+	audit := s.entry(userID, modles.AuditDataExportRequested, userID, nil)
+	export, err := s.exports.CreateDataExport(ctx, modles.NewDataExport(userID), audit)
+	if err != nil {
+		return nil, err
+	}
+
+	select {
+	case s.queue <- export:
+	default:
+	}
+
+	res := toDataExportRes(export)
+	return &res, nil
+}
+
+func (s *PrivacyService) GetExport(ctx context.Context, userID, exportID int) (*DataExportRes, error) {
+	export, err := s.userExport(ctx, userID, exportID)
+	if err != nil {
+		return nil, err
+	}
+	res := toDataExportRes(export)
+	return &res, nil
+}
+
+// ExportArchive returns the path of the archive of a completed export.
+func (s *PrivacyService) ExportArchive(ctx context.Context, userID, exportID int) (string, error) {
+	export, err := s.userExport(ctx, userID, exportID)
+	if err != nil {
+		return "", err
+	}
+	if !export.IsDownloadable(time.Now()) {
+		return "", common.NewErrorf(modles.DataExportNotReadyError, "data export %d is %s", export.ID(), export.Status())
+	}
+	return filepath.Join(s.cfg.ExportDir, export.FileName()), nil
+}
+
+func (s *PrivacyService) userExport(ctx context.Context, userID, exportID int) (*modles.DataExport, error) {
+	export, err := s.exports.GetDataExport(ctx, userID, exportID)
+	if err != nil {
+		return nil, err
+	}
+	if export == nil {
+		return nil, common.NewErrorf(modles.DataExportNotFoundError, "data export not found")
+	}
+	return export, nil
+}
+
+// ProcessPendingExports builds every export that is waiting.
+func (s *PrivacyService) ProcessPendingExports(ctx context.Context) error {
+	pending, err := s.exports.ListPendingDataExports(ctx)
+	if err != nil {
+		return err
+	}
+	for _, export := range pending {
+		s.processExport(ctx, export.ID(), export.UserID())
+	}
+	return nil
+}
+
+// processExport builds one export unless another worker claimed it. A failed
+// build is recorded on the export so the user can request a new one.
+func (s *PrivacyService) processExport(ctx context.Context, exportID, userID int) {
+	claimed, err := s.exports.ClaimDataExport(ctx, exportID)
+	if err != nil {
+		s.logger.Error("Failed to claim data export", zap.Int("exportID", exportID), zap.Error(err))
+		return
+	}
+	if !claimed {
+		return
+	}
+
+	fileName, err := s.buildArchive(ctx, userID)
+	now := time.Now()
+	if err != nil {
+		s.logger.Error("Failed to build data export", zap.Int("exportID", exportID), zap.Error(err))
+		if err := s.exports.FailDataExport(ctx, exportID, "failed to build the archive", now); err != nil {
+			s.logger.Error("Failed to record data export failure", zap.Int("exportID", exportID), zap.Error(err))
+		}
+		return
+	}
+
+	audit := s.entry(0, modles.AuditDataExported, userID, map[string]string{
+		"export_id": fmt.Sprint(exportID),
+	})
+	if err := s.exports.CompleteDataExport(ctx, exportID, fileName, now, now.Add(s.cfg.ExportTTL), audit); err != nil {
+		s.logger.Error("Failed to complete data export", zap.Int("exportID", exportID), zap.Error(err))
+		s.removeArchive(fileName)
+	}
+}
+
+// buildArchive writes a zip with the personal data of the user as
+// data.json, and the uploaded files of the user under files/.
+func (s *PrivacyService) buildArchive(ctx context.Context, userID int) (string, error) {
+	data, files, err := s.collectPersonalData(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(s.cfg.ExportDir, 0700); err != nil {
+		return "", fmt.Errorf("error creating export directory: %w", err)
+	}
+	fileName := uuid.NewString() + ".zip"
+	f, err := os.OpenFile(filepath.Join(s.cfg.ExportDir, fileName), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return "", fmt.Errorf("error creating export archive: %w", err)
+	}
+
+	if err := s.writeArchive(f, data, files); err != nil {
+		f.Close()
+		s.removeArchive(fileName)
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		s.removeArchive(fileName)
+		return "", fmt.Errorf("error writing export archive: %w", err)
+	}
+	return fileName, nil
+}
+
+func (s *PrivacyService) writeArchive(w io.Writer, data map[string]any, files []string) error {
+	archive := zip.NewWriter(w)
+
+	dataFile, err := archive.Create("data.json")
+	if err != nil {
+		return fmt.Errorf("error writing export data: %w", err)
+	}
+	enc := json.NewEncoder(dataFile)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(data); err != nil {
+		return fmt.Errorf("error writing export data: %w", err)
+	}
+
+	for _, name := range files {
+		if err := s.addFile(archive, name); err != nil {
+			return err
+		}
+	}
+	return archive.Close()
+}
+
+// addFile copies an uploaded file into the archive. Files that no longer
+// exist are skipped.
+func (s *PrivacyService) addFile(archive *zip.Writer, name string) error {
+	src, err := os.Open(filepath.Join(s.modules.Files.StoragePath, filepath.Base(name)))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			s.logger.Warn("Skipping missing file in data export", zap.String("file", name))
+			return nil
+		}
+		return fmt.Errorf("error opening uploaded file: %w", err)
+	}
+	defer src.Close()
+
+	dst, err := archive.Create("files/" + filepath.Base(name))
+	if err != nil {
+		return fmt.Errorf("error adding uploaded file: %w", err)
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		return fmt.Errorf("error adding uploaded file: %w", err)
+	}
+	return nil
+}
+
+// collectPersonalData gathers the data of the users module and of every
+// registered source, keyed by section, along with the uploaded files.
+func (s *PrivacyService) collectPersonalData(ctx context.Context, userID int) (map[string]any, []string, error) {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	now := time.Now()
+
+	addresses, err := s.addresses.ListAddresses(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	addressRes := make([]AddressRes, 0, len(addresses))
+	for i := range addresses {
+		addressRes = append(addressRes, toAddressRes(&addresses[i]))
+	}
+
+	identities, err := s.identities.ListIdentities(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	identityRes := make([]IdentityRes, 0, len(identities))
+	for i := range identities {
+		identityRes = append(identityRes, toIdentityRes(&identities[i]))
+	}
+
+	sessions, err := s.sessionRepo.ListActiveSessions(ctx, userID, now)
+	if err != nil {
+		return nil, nil, err
+	}
+	sessionRes := make([]SessionRes, 0, len(sessions))
+	for i := range sessions {
+		sessionRes = append(sessionRes, toSessionRes(&sessions[i], ""))
+	}
+
+	keys, err := s.apiKeys.ListAPIKeys(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyRes := make([]APIKeyRes, 0, len(keys))
+	for i := range keys {
+		keyRes = append(keyRes, toAPIKeyRes(&keys[i]))
+	}
+
+	auditRes := []AuditEntryRes{}
+	for offset := 0; ; offset += auditPageSize {
+		entries, err := s.audit.ListAuditEntries(ctx, userID, auditPageSize, offset)
+		if err != nil {
+			return nil, nil, err
+		}
+		for i := range entries {
+			auditRes = append(auditRes, toAuditEntryRes(&entries[i]))
+		}
+		if len(entries) < auditPageSize {
+			break
+		}
+	}
+
+	data := map[string]any{
+		"generated_at": now,
+		"profile":      toUserProfile(user),
+		"addresses":    addressRes,
+		"identities":   identityRes,
+		"sessions":     sessionRes,
+		"api_keys":     keyRes,
+		"audit_log":    auditRes,
+	}
+	var files []string
+	for _, source := range s.modules.Sources {
+		section, err := source.ExportPersonalData(ctx, int64(userID))
+		if err != nil {
+			return nil, nil, err
+		}
+		if section == nil {
+			continue
+		}
+		if _, ok := data[section.Name]; ok {
+			return nil, nil, fmt.Errorf("duplicate personal data section %q", section.Name)
+		}
+		data[section.Name] = section.Data
+		files = append(files, section.Files...)
+	}
+	if s.modules.Files == nil {
+		files = nil
+	}
+	return data, files, nil
+}
+
+// CleanupExpiredExports deletes the exports and archives past their expiry.
+func (s *PrivacyService) CleanupExpiredExports(ctx context.Context, now time.Time) error {
+	expired, err := s.exports.DeleteExpiredDataExports(ctx, now)
+	if err != nil {
+		return err
+	}
+	for _, export := range expired {
+		s.removeArchive(export.FileName())
+	}
+	return nil
+}
+
+func (s *PrivacyService) removeArchive(fileName string) {
+	if fileName == "" {
+		return
+	}
+	err := os.Remove(filepath.Join(s.cfg.ExportDir, fileName))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		s.logger.Error("Failed to remove data export archive", zap.String("file", fileName), zap.Error(err))
+	}
+}
+
+// EraseAccount erases the account of the user after checking their password.
+func (s *PrivacyService) EraseAccount(ctx context.Context, userID int, req EraseAccountReq) error {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !user.IsActive() {
+		return common.NewErrorf(modles.UserNotFoundError, "user not found")
+	}
+	if err := common.CheckPassword(user.Password(), req.Password); err != nil {
+		return common.NewErrorf(modles.InvalidCurrentPasswordError, "password is incorrect")
+	}
+	return s.erase(ctx, userID, user)
+}
+
+// EraseUser erases the account of another user on behalf of an admin, e.g.
+// for a request received by mail. Deactivated accounts can be erased too.
+func (s *PrivacyService) EraseUser(ctx context.Context, adminID, userID int) error {
+	if adminID == userID {
+		return common.NewErrorf(modles.CannotManageOwnAccountError, "admins cannot erase their own account")
+	}
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	return s.erase(ctx, adminID, user)
+}
+
+// erase anonymizes the users row and removes the personal data held by the
+// users module and the registered erasers. Records other modules must keep,
+// such as orders and payments, stay linked to the anonymized user.
+func (s *PrivacyService) erase(ctx context.Context, actorID int, user *modles.User) error {
+	email := user.Email()
+	if err := user.Erase(time.Now()); err != nil {
+		return err
+	}
+
+	for _, eraser := range s.modules.Erasers {
+		if err := eraser.ErasePersonalData(ctx, int64(user.ID())); err != nil {
+			return err
+		}
+	}
+
+	exports, err := s.exports.DeleteUserDataExports(ctx, user.ID())
+	if err != nil {
+		return err
+	}
+	for _, export := range exports {
+		s.removeArchive(export.FileName())
+	}
+
+	if err := s.sessions.LogoutAll(ctx, user.ID()); err != nil {
+		return err
+	}
+	audit := s.entry(actorID, modles.AuditAccountErased, user.ID(), nil)
+	if err := s.userRepo.EraseUser(ctx, user, audit); err != nil {
+		return err
+	}
+	return s.loginGuard.Unlock(ctx, email)
+}
+
+func (s *PrivacyService) entry(actorID int, action modles.AuditAction, userID int, details map[string]string) *modles.AuditEntry {
+	return modles.NewAuditEntry(0, actorID, action, userID, details, time.Now())
+}
+
+// RunDataExports builds requested exports as they come in, and every interval
+// scans for pending exports and deletes expired ones, until the context is
+// cancelled.
+func RunDataExports(ctx context.Context, service *PrivacyService, interval time.Duration, logger *zap.Logger) {
+	if interval <= 0 {
+		return
+	}
+
+	// pick up the exports requested before a restart
+	if err := service.ProcessPendingExports(ctx); err != nil {
+		logger.Error("Failed to process pending data exports", zap.Error(err))
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case export := <-service.queue:
+			service.processExport(ctx, export.ID(), export.UserID())
+		case now := <-ticker.C:
+			if err := service.ProcessPendingExports(ctx); err != nil {
+				logger.Error("Failed to process pending data exports", zap.Error(err))
+			}
+			if err := service.CleanupExpiredExports(ctx, now); err != nil {
+				logger.Error("Failed to delete expired data exports", zap.Error(err))
+			}
+		}
+	}
+}
@@ -0,0 +1,306 @@
+package application
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"yadwy-backend/internal/common"
+	"yadwy-backend/internal/users/domain/contracts/mock"
+	"yadwy-backend/internal/users/domain/modles"
+
+	"go.uber.org/zap"
+)
+
+// fakeCartModule stands in for a module holding personal data.
+type fakeCartModule struct {
+	file   string
+	erased []int64
+}
+
+func (m *fakeCartModule) ExportPersonalData(ctx context.Context, userID int64) (*common.PersonalDataSection, error) {
+	return &common.PersonalDataSection{
+		Name:  "cart",
+		Data:  map[string]any{"user_id": userID, "items": []int{7}},
+		Files: []string{m.file, "missing.png"},
+	}, nil
+}
+
+func (m *fakeCartModule) ErasePersonalData(ctx context.Context, userID int64) error {
+	m.erased = append(m.erased, userID)
+	return nil
+}
+
+type privacyTest struct {
+	service *PrivacyService
+	audit   *mock.AuditLog
+	exports *mock.DataExportRepo
+	revoker *recordingRevoker
+	module  *fakeCartModule
+	erased  *modles.User
+}
+
+func newPrivacyTest(t *testing.T, user *modles.User) *privacyTest {
+	t.Helper()
+	storage := t.TempDir()
+	if err := os.WriteFile(filepath.Join(storage, "logo.png"), []byte("png"), 0600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	pt := &privacyTest{
+		audit:   &mock.AuditLog{},
+		exports: mock.NewDataExportRepo(),
+		revoker: &recordingRevoker{},
+		module:  &fakeCartModule{file: "logo.png"},
+	}
+	pt.exports.Audit = pt.audit
+	users := memoryUsers(user)
+	users.Audit = pt.audit
+	users.EraseUserFunc = func(ctx context.Context, u *modles.User) error {
+		pt.erased = u
+		return nil
+	}
+	addresses := mock.NewAddressRepo()
+	if _, err := addresses.CreateAddress(context.Background(), modles.NewAddressFromParams(modles.AddressParams{
+		UserID: user.ID(), RecipientName: "John Doe", City: "Cairo",
+	})); err != nil {
+		t.Fatalf("CreateAddress() error = %v", err)
+	}
+
+	pt.service = NewPrivacyService(users, addresses, mock.NewIdentityRepo(), mock.NewSessionRepo(), mock.NewAPIKeyRepo(),
+		pt.audit, pt.exports, pt.revoker, newTestLoginGuard(), PersonalDataModules{
+			Sources: []common.PersonalDataSource{pt.module},
+			Erasers: []common.PersonalDataEraser{pt.module},
+			Files:   &common.FileService{StoragePath: storage},
+		}, PrivacyConfig{ExportDir: t.TempDir(), ExportTTL: time.Hour}, zap.NewNop())
+	return pt
+}
+
+func (pt *privacyTest) actions() []modles.AuditAction {
+	var actions []modles.AuditAction
+	for _, e := range pt.audit.Entries {
+		actions = append(actions, e.Action())
+	}
+	return actions
+}
+
+func readArchive(t *testing.T, path string) map[string][]byte {
+	t.Helper()
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatalf("OpenReader() error = %v", err)
+	}
+	defer r.Close()
+
+	files := map[string][]byte{}
+	for _, f := range r.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("Open(%s) error = %v", f.Name, err)
+		}
+		content, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatalf("ReadAll(%s) error = %v", f.Name, err)
+		}
+		files[f.Name] = content
+	}
+	return files
+}
+
+func TestPrivacyService_Export(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should build an archive with the personal data and uploaded files", func(t *testing.T) {
+		pt := newPrivacyTest(t, testUser())
+
+		export, err := pt.service.RequestExport(ctx, 1)
+		if err != nil {
+			t.Fatalf("RequestExport() error = %v", err)
+		}
+		if export.Status != string(modles.DataExportPending) {
+			t.Errorf("RequestExport() status = %v, want pending", export.Status)
+		}
+		if _, err := pt.service.ExportArchive(ctx, 1, export.ID); errorCode(err) != modles.DataExportNotReadyError {
+			t.Errorf("ExportArchive() before processing error code = %v, want %v", errorCode(err), modles.DataExportNotReadyError)
+		}
+
+		pt.service.processExport(ctx, export.ID, 1)
+
+		got, err := pt.service.GetExport(ctx, 1, export.ID)
+		if err != nil {
+			t.Fatalf("GetExport() error = %v", err)
+		}
+		if got.Status != string(modles.DataExportCompleted) || got.ExpiresAt == nil {
+			t.Fatalf("GetExport() = %+v, want completed with expiry", got)
+		}
+
+		path, err := pt.service.ExportArchive(ctx, 1, export.ID)
+		if err != nil {
+			t.Fatalf("ExportArchive() error = %v", err)
+		}
+		files := readArchive(t, path)
+
+		var data map[string]json.RawMessage
+		if err := json.Unmarshal(files["data.json"], &data); err != nil {
+			t.Fatalf("data.json is not JSON: %v", err)
+		}
+		for _, section := range []string{"profile", "addresses", "identities", "sessions", "api_keys", "audit_log", "cart"} {
+			if _, ok := data[section]; !ok {
+				t.Errorf("data.json has no %s section", section)
+			}
+		}
+		var profile UserProfile
+		if err := json.Unmarshal(data["profile"], &profile); err != nil || profile.Email != "john@example.com" {
+			t.Errorf("data.json profile = %s", data["profile"])
+		}
+		if string(files["files/logo.png"]) != "png" {
+			t.Errorf("archive files/logo.png = %q, want uploaded file", files["files/logo.png"])
+		}
+		if _, ok := files["files/missing.png"]; ok {
+			t.Errorf("archive includes a file missing from the storage")
+		}
+
+		want := []modles.AuditAction{modles.AuditDataExportRequested, modles.AuditDataExported}
+		if got := pt.actions(); len(got) != 2 || got[0] != want[0] || got[1] != want[1] {
+			t.Errorf("audit actions = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("should allow one export in progress", func(t *testing.T) {
+		pt := newPrivacyTest(t, testUser())
+
+		if _, err := pt.service.RequestExport(ctx, 1); err != nil {
+			t.Fatalf("RequestExport() error = %v", err)
+		}
+		_, err := pt.service.RequestExport(ctx, 1)
+		if got := errorCode(err); got != modles.DataExportInProgressError {
+			t.Errorf("RequestExport() error code = %v, want %v", got, modles.DataExportInProgressError)
+		}
+	})
+
+	t.Run("should not show exports of other users", func(t *testing.T) {
+		pt := newPrivacyTest(t, testUser())
+
+		export, err := pt.service.RequestExport(ctx, 1)
+		if err != nil {
+			t.Fatalf("RequestExport() error = %v", err)
+		}
+		_, err = pt.service.GetExport(ctx, 2, export.ID)
+		if got := errorCode(err); got != modles.DataExportNotFoundError {
+			t.Errorf("GetExport() error code = %v, want %v", got, modles.DataExportNotFoundError)
+		}
+	})
+
+	t.Run("should delete expired archives", func(t *testing.T) {
+		pt := newPrivacyTest(t, testUser())
+
+		export, err := pt.service.RequestExport(ctx, 1)
+		if err != nil {
+			t.Fatalf("RequestExport() error = %v", err)
+		}
+		pt.service.processExport(ctx, export.ID, 1)
+		path, err := pt.service.ExportArchive(ctx, 1, export.ID)
+		if err != nil {
+			t.Fatalf("ExportArchive() error = %v", err)
+		}
+
+		if err := pt.service.CleanupExpiredExports(ctx, time.Now().Add(2*time.Hour)); err != nil {
+			t.Fatalf("CleanupExpiredExports() error = %v", err)
+		}
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("archive still exists after expiry, stat error = %v", err)
+		}
+		if _, err := pt.service.GetExport(ctx, 1, export.ID); errorCode(err) != modles.DataExportNotFoundError {
+			t.Errorf("GetExport() after expiry error code = %v, want %v", errorCode(err), modles.DataExportNotFoundError)
+		}
+	})
+}
+
+func TestPrivacyService_Erase(t *testing.T) {
+	ctx := context.Background()
+	hash, err := common.HashPass("password123")
+	if err != nil {
+		t.Fatalf("HashPass() error = %v", err)
+	}
+	user := func() *modles.User {
+		return modles.NewUserFromParams(modles.UserParams{
+			ID: 1, Name: "John Doe", Email: "john@example.com", Password: hash, Role: modles.RoleCustomer, Phone: "01001234567",
+		})
+	}
+
+	t.Run("should anonymize the user and erase the data of other modules", func(t *testing.T) {
+		pt := newPrivacyTest(t, user())
+		export, err := pt.service.RequestExport(ctx, 1)
+		if err != nil {
+			t.Fatalf("RequestExport() error = %v", err)
+		}
+		pt.service.processExport(ctx, export.ID, 1)
+
+		if err := pt.service.EraseAccount(ctx, 1, EraseAccountReq{Password: "password123"}); err != nil {
+			t.Fatalf("EraseAccount() error = %v", err)
+		}
+
+		if pt.erased == nil {
+			t.Fatalf("EraseAccount() did not store the erased user")
+		}
+		if pt.erased.Name() != modles.ErasedUserName || pt.erased.Email() == "john@example.com" ||
+			pt.erased.Phone() != "" || pt.erased.Password() != "" {
+			t.Errorf("erased user keeps personal data: %s %s %s", pt.erased.Name(), pt.erased.Email(), pt.erased.Phone())
+		}
+		if !pt.erased.IsErased() || pt.erased.IsActive() {
+			t.Errorf("erased user is not marked erased and deactivated")
+		}
+		if len(pt.module.erased) != 1 || pt.module.erased[0] != 1 {
+			t.Errorf("module erasers called with %v, want [1]", pt.module.erased)
+		}
+		if len(pt.revoker.userIDs) != 1 {
+			t.Errorf("sessions revoked for %v, want user 1", pt.revoker.userIDs)
+		}
+		if _, err := pt.service.GetExport(ctx, 1, export.ID); errorCode(err) != modles.DataExportNotFoundError {
+			t.Errorf("GetExport() after erasure error code = %v, want %v", errorCode(err), modles.DataExportNotFoundError)
+		}
+		actions := pt.actions()
+		if last := actions[len(actions)-1]; last != modles.AuditAccountErased {
+			t.Errorf("last audit action = %v, want %v", last, modles.AuditAccountErased)
+		}
+	})
+
+	t.Run("should require the password", func(t *testing.T) {
+		pt := newPrivacyTest(t, user())
+
+		err := pt.service.EraseAccount(ctx, 1, EraseAccountReq{Password: "wrong-password"})
+		if got := errorCode(err); got != modles.InvalidCurrentPasswordError {
+			t.Errorf("EraseAccount() error code = %v, want %v", got, modles.InvalidCurrentPasswordError)
+		}
+		if pt.erased != nil || len(pt.module.erased) != 0 {
+			t.Errorf("EraseAccount() with a wrong password erased data")
+		}
+	})
+
+	t.Run("should let admins erase deactivated users once", func(t *testing.T) {
+		now := time.Now()
+		pt := newPrivacyTest(t, modles.NewUserFromParams(modles.UserParams{
+			ID: 1, Name: "John Doe", Email: "john@example.com", Role: modles.RoleCustomer, DeactivatedAt: &now,
+		}))
+
+		if err := pt.service.EraseUser(ctx, 9, 1); err != nil {
+			t.Fatalf("EraseUser() error = %v", err)
+		}
+		if entry := pt.audit.Entries[len(pt.audit.Entries)-1]; entry.ActorID() != 9 || entry.Action() != modles.AuditAccountErased {
+			t.Errorf("audit entry = %v by %d, want %v by 9", entry.Action(), entry.ActorID(), modles.AuditAccountErased)
+		}
+
+		err := pt.service.EraseUser(ctx, 9, 1)
+		if got := errorCode(err); got != modles.InvalidAccountStatusError {
+			t.Errorf("EraseUser() twice error code = %v, want %v", got, modles.InvalidAccountStatusError)
+		}
+		if err := pt.service.EraseUser(ctx, 1, 1); errorCode(err) != modles.CannotManageOwnAccountError {
+			t.Errorf("EraseUser() own account error code = %v, want %v", errorCode(err), modles.CannotManageOwnAccountError)
+		}
+	})
+}
@@ -0,0 +1,97 @@
+package application
+
+import (
+	"context"
+	"yadwy-backend/internal/common"
+	"yadwy-backend/internal/users/domain/contracts"
+	"yadwy-backend/internal/users/domain/modles"
+)
+
+// ProfileService lets signed in users manage their own account.
+type ProfileService struct {
+	userRepo contracts.UserRepo
+	sessions sessionRevoker
+}
+
+func NewProfileService(repo contracts.UserRepo, sessions sessionRevoker) *ProfileService {
+	return &ProfileService{
+		userRepo: repo,
+		sessions: sessions,
+	}
+}
+
+func (s *ProfileService) GetProfile(ctx context.Context, userID int) (*UserProfile, error) {
+	user, err := s.activeUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	profile := toUserProfile(user)
+	return &profile, nil
+}
+
+// UpdateProfile changes the fields present in the request.
+func (s *ProfileService) UpdateProfile(ctx context.Context, userID int, req UpdateProfileReq) (*UserProfile, error) {
+	user, err := s.activeUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := user.UpdateProfile(req.Name, req.Phone); err != nil {
+		return nil, err
+	}
+	if err := s.userRepo.UpdateProfile(ctx, user); err != nil {
+		return nil, err
+	}
+
+	// reload to pick up the new updated_at
+	return s.GetProfile(ctx, userID)
+}
+
+// ChangePassword sets a new password after checking the current one and signs
+// the user out everywhere, including the session making the request.
+func (s *ProfileService) ChangePassword(ctx context.Context, userID int, req ChangePasswordReq) error {
+	user, err := s.activeUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := common.CheckPassword(user.Password(), req.CurrentPassword); err != nil {
+		return common.NewErrorf(modles.InvalidCurrentPasswordError, "current password is incorrect")
+	}
+
+	hashPass, err := common.HashPass(req.NewPassword)
+	if err != nil {
+		return err
+	}
+	if err := s.userRepo.UpdatePassword(ctx, user.ID(), hashPass); err != nil {
+		return err
+	}
+
+	return s.sessions.LogoutAll(ctx, user.ID())
+}
+
+// Deactivate closes the account of the user and revokes every token. The
+// account can no longer log in but its orders are kept.
+func (s *ProfileService) Deactivate(ctx context.Context, userID int) error {
+	user, err := s.activeUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.userRepo.DeactivateUser(ctx, user.ID()); err != nil {
+		return err
+	}
+	return s.sessions.LogoutAll(ctx, user.ID())
+}
+
+func (s *ProfileService) activeUser(ctx context.Context, userID int) (*modles.User, error) {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsActive() {
+		return nil, common.NewErrorf(modles.UserNotFoundError, "user not found")
+	}
+	return user, nil
+}
@@ -0,0 +1,131 @@
+package application
+
+import (
+	"context"
+	"testing"
+	"yadwy-backend/internal/common"
+	"yadwy-backend/internal/users/domain/contracts/mock"
+	"yadwy-backend/internal/users/domain/modles"
+)
+
+func TestProfileService(t *testing.T) {
+	ctx := context.Background()
+
+	userWithPassword := func(t *testing.T, password string) *modles.User {
+		t.Helper()
+		hash, err := common.HashPass(password)
+		if err != nil {
+			t.Fatalf("HashPass() error = %v", err)
+		}
+		return modles.NewUser(1, "John Doe", "john@example.com", hash, modles.RoleCustomer)
+	}
+
+	t.Run("should update only the given fields", func(t *testing.T) {
+		user := modles.NewUserFromParams(modles.UserParams{
+			ID: 1, Name: "John Doe", Email: "john@example.com", Role: modles.RoleCustomer, Phone: "+201001234567",
+		})
+		var saved *modles.User
+		users := &mock.UserRepo{
+			GetUserByIDFunc: func(ctx context.Context, id int) (*modles.User, error) {
+				if saved != nil {
+					return saved, nil
+				}
+				return user, nil
+			},
+			UpdateProfileFunc: func(ctx context.Context, u *modles.User) error {
+				saved = u
+				return nil
+			},
+		}
+		service := NewProfileService(users, &recordingRevoker{})
+
+		name := "Jane Doe"
+		profile, err := service.UpdateProfile(ctx, 1, UpdateProfileReq{Name: &name})
+		if err != nil {
+			t.Fatalf("UpdateProfile() error = %v", err)
+		}
+		if profile.Name != name || profile.Phone != "+201001234567" {
+			t.Errorf("UpdateProfile() = %+v, want name %q and unchanged phone", profile, name)
+		}
+
+		empty := ""
+		profile, err = service.UpdateProfile(ctx, 1, UpdateProfileReq{Phone: &empty})
+		if err != nil {
+			t.Fatalf("UpdateProfile() error = %v", err)
+		}
+		if profile.Phone != "" {
+			t.Errorf("UpdateProfile() phone = %q, want it removed", profile.Phone)
+		}
+	})
+
+	t.Run("should reject an empty name", func(t *testing.T) {
+		users := &mock.UserRepo{
+			GetUserByIDFunc: func(ctx context.Context, id int) (*modles.User, error) {
+				return testUser(), nil
+			},
+		}
+		service := NewProfileService(users, &recordingRevoker{})
+
+		empty := ""
+		_, err := service.UpdateProfile(ctx, 1, UpdateProfileReq{Name: &empty})
+		if got := errorCode(err); got != modles.InvalidProfileError {
+			t.Errorf("UpdateProfile() error code = %v, want %v", got, modles.InvalidProfileError)
+		}
+	})
+
+	t.Run("should change password and revoke sessions", func(t *testing.T) {
+		var updatedPassword string
+		users := &mock.UserRepo{
+			GetUserByIDFunc: func(ctx context.Context, id int) (*modles.User, error) {
+				return userWithPassword(t, "oldpassword"), nil
+			},
+			UpdatePasswordFunc: func(ctx context.Context, userID int, hashedPassword string) error {
+				updatedPassword = hashedPassword
+				return nil
+			},
+		}
+		revoker := &recordingRevoker{}
+		service := NewProfileService(users, revoker)
+
+		err := service.ChangePassword(ctx, 1, ChangePasswordReq{CurrentPassword: "wrong", NewPassword: "newpassword123"})
+		if got := errorCode(err); got != modles.InvalidCurrentPasswordError {
+			t.Fatalf("ChangePassword() error code = %v, want %v", got, modles.InvalidCurrentPasswordError)
+		}
+		if updatedPassword != "" || len(revoker.userIDs) != 0 {
+			t.Fatalf("ChangePassword() with wrong password changed state")
+		}
+
+		err = service.ChangePassword(ctx, 1, ChangePasswordReq{CurrentPassword: "oldpassword", NewPassword: "newpassword123"})
+		if err != nil {
+			t.Fatalf("ChangePassword() error = %v", err)
+		}
+		if err := common.CheckPassword(updatedPassword, "newpassword123"); err != nil {
+			t.Errorf("ChangePassword() stored hash does not match the new password")
+		}
+		if len(revoker.userIDs) != 1 || revoker.userIDs[0] != 1 {
+			t.Errorf("ChangePassword() revoked %v, want [1]", revoker.userIDs)
+		}
+	})
+
+	t.Run("should deactivate and revoke sessions", func(t *testing.T) {
+		var deactivated int
+		users := &mock.UserRepo{
+			GetUserByIDFunc: func(ctx context.Context, id int) (*modles.User, error) {
+				return testUser(), nil
+			},
+			DeactivateUserFunc: func(ctx context.Context, userID int) error {
+				deactivated = userID
+				return nil
+			},
+		}
+		revoker := &recordingRevoker{}
+		service := NewProfileService(users, revoker)
+
+		if err := service.Deactivate(ctx, 1); err != nil {
+			t.Fatalf("Deactivate() error = %v", err)
+		}
+		if deactivated != 1 || len(revoker.userIDs) != 1 {
+			t.Errorf("Deactivate() deactivated %d and revoked %v, want user 1", deactivated, revoker.userIDs)
+		}
+	})
+}
@@ -0,0 +1,65 @@
+package application
+
+import (
+	"context"
+	"time"
+	"yadwy-backend/internal/common"
+	"yadwy-backend/internal/users/domain/contracts"
+
+	"go.uber.org/zap"
+)
+
+// RevocationValidator rejects access tokens that were revoked by a logout.
+type RevocationValidator struct {
+	store contracts.TokenRevocationStore
+}
+
+func NewRevocationValidator(store contracts.TokenRevocationStore) *RevocationValidator {
+	return &RevocationValidator{
+		store: store,
+	}
+}
+
+func (v *RevocationValidator) ValidateToken(ctx context.Context, claims *common.UserClaims) error {
+	// API keys are looked up on every request, revoking one takes effect
+	// right away
+	if claims.TokenType == common.APIKeyTokenType {
+		return nil
+	}
+
+	var issuedAt time.Time
+	if claims.IssuedAt != nil {
+		issuedAt = claims.IssuedAt.Time
+	}
+
+	revoked, err := v.store.IsRevoked(ctx, claims.TokenID, int(claims.ID), issuedAt)
+	if err != nil {
+		return err
+	}
+	if revoked {
+		return common.NewErrorf(common.AuthHeaderTokenRevokedErrorCode, "authorization token has been revoked")
+	}
+	return nil
+}
+
+// RunRevocationCleanup removes expired denylist entries every interval until
+// the context is cancelled.
+func RunRevocationCleanup(ctx context.Context, store contracts.TokenRevocationStore, interval time.Duration, logger *zap.Logger) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := store.DeleteExpired(ctx, now); err != nil {
+				logger.Error("Failed to delete expired token revocations", zap.Error(err))
+			}
+		}
+	}
+}
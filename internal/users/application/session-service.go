@@ -0,0 +1,89 @@
+package application
+
+import (
+	"context"
+	"time"
+	"yadwy-backend/internal/common"
+	"yadwy-backend/internal/users/domain/contracts"
+
+	"go.uber.org/zap"
+)
+
+// sessionTouchInterval limits last seen updates to one per session and
+// interval.
+const sessionTouchInterval = time.Minute
+
+// SessionService lists the login sessions of a user and signs devices out.
+type SessionService struct {
+	sessions      contracts.SessionRepo
+	refreshTokens contracts.RefreshTokenRepo
+}
+
+func NewSessionService(sessions contracts.SessionRepo, refreshTokens contracts.RefreshTokenRepo) *SessionService {
+	return &SessionService{
+		sessions:      sessions,
+		refreshTokens: refreshTokens,
+	}
+}
+
+// ListSessions returns the active sessions of the user and marks the one
+// making the request.
+func (s *SessionService) ListSessions(ctx context.Context, userID int, currentID string) ([]SessionRes, error) {
+	sessions, err := s.sessions.ListActiveSessions(ctx, userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]SessionRes, 0, len(sessions))
+	for i := range sessions {
+		res = append(res, toSessionRes(&sessions[i], currentID))
+	}
+	return res, nil
+}
+
+// TerminateSession signs the device of a session out. Its refresh tokens are
+// revoked and SessionValidator rejects its access tokens.
+func (s *SessionService) TerminateSession(ctx context.Context, userID int, sessionID string) error {
+	if err := s.sessions.TerminateSession(ctx, userID, sessionID); err != nil {
+		return err
+	}
+	return s.refreshTokens.RevokeTokenFamily(ctx, sessionID)
+}
+
+// SessionValidator rejects access tokens of terminated or expired sessions
+// and records when each session was last seen.
+type SessionValidator struct {
+	sessions contracts.SessionRepo
+	logger   *zap.Logger
+}
+
+func NewSessionValidator(sessions contracts.SessionRepo, logger *zap.Logger) *SessionValidator {
+	return &SessionValidator{
+		sessions: sessions,
+		logger:   logger,
+	}
+}
+
+func (v *SessionValidator) ValidateToken(ctx context.Context, claims *common.UserClaims) error {
+	// API keys and tokens issued before sessions were recorded have no session
+	if claims.SessionID == "" {
+		return nil
+	}
+
+	session, err := v.sessions.GetSession(ctx, claims.SessionID)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	if session == nil || session.UserID() != int(claims.ID) || !session.IsActive(now) {
+		return common.NewErrorf(common.AuthHeaderTokenRevokedErrorCode, "session has been terminated")
+	}
+
+	if now.Sub(session.LastSeenAt()) >= sessionTouchInterval {
+		// a failed update must not fail the request
+		if err := v.sessions.TouchSession(ctx, session.ID(), now, time.Time{}); err != nil {
+			v.logger.Error("Failed to record session use", zap.String("session_id", session.ID()), zap.Error(err))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,194 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"yadwy-backend/internal/common"
+	"yadwy-backend/internal/users/domain/contracts/mock"
+	"yadwy-backend/internal/users/domain/modles"
+
+	"go.uber.org/zap"
+)
+
+// failingTouchSessions fails every last seen update.
+type failingTouchSessions struct {
+	*mock.SessionRepo
+}
+
+func (f failingTouchSessions) TouchSession(ctx context.Context, id string, at, expiresAt time.Time) error {
+	return errors.New("connection lost")
+}
+
+func TestSessionService(t *testing.T) {
+	ctx := context.Background()
+	hash, err := common.HashPass("password123")
+	if err != nil {
+		t.Fatalf("HashPass() error = %v", err)
+	}
+	user := modles.NewUser(1, "John Doe", "john@example.com", hash, modles.RoleCustomer)
+	users := &mock.UserRepo{
+		GetUserFunc: func(ctx context.Context, email string) (*modles.User, error) {
+			return user, nil
+		},
+		GetUserByIDFunc: func(ctx context.Context, id int) (*modles.User, error) {
+			return user, nil
+		},
+	}
+
+	login := func(t *testing.T, service *UserService, client ClientInfo) (*LoginUserRes, *common.UserClaims) {
+		t.Helper()
+		res, err := service.LoginUser(ctx, LoginUserReq{Email: user.Email(), Password: "password123"}, client)
+		if err != nil {
+			t.Fatalf("LoginUser() error = %v", err)
+		}
+		claims, err := service.jwt.VerifyToken(res.AccessToken)
+		if err != nil {
+			t.Fatalf("VerifyToken() error = %v", err)
+		}
+		return res, claims
+	}
+
+	t.Run("should record a session per login", func(t *testing.T) {
+		userSvc := newTestUserService(users, mock.NewRefreshTokenRepo())
+		service := NewSessionService(userSvc.sessions, userSvc.refreshTokens)
+
+		_, phone := login(t, userSvc, ClientInfo{IP: "10.0.0.1", UserAgent: "Yadwy/1.0 (iPhone)"})
+		_, laptop := login(t, userSvc, ClientInfo{IP: "10.0.0.2", UserAgent: "Mozilla/5.0"})
+		if phone.SessionID == "" || phone.SessionID == laptop.SessionID {
+			t.Fatalf("LoginUser() session IDs = %q and %q, want two sessions", phone.SessionID, laptop.SessionID)
+		}
+
+		sessions, err := service.ListSessions(ctx, user.ID(), laptop.SessionID)
+		if err != nil {
+			t.Fatalf("ListSessions() error = %v", err)
+		}
+		if len(sessions) != 2 {
+			t.Fatalf("ListSessions() returned %d sessions, want 2", len(sessions))
+		}
+		for _, s := range sessions {
+			if s.Current != (s.ID == laptop.SessionID) {
+				t.Errorf("ListSessions() session %s current = %v", s.ID, s.Current)
+			}
+			if s.ID == phone.SessionID && (s.IP != "10.0.0.1" || s.UserAgent != "Yadwy/1.0 (iPhone)") {
+				t.Errorf("ListSessions() phone session = %+v", s)
+			}
+		}
+	})
+
+	t.Run("should reject tokens of a terminated session", func(t *testing.T) {
+		userSvc := newTestUserService(users, mock.NewRefreshTokenRepo())
+		service := NewSessionService(userSvc.sessions, userSvc.refreshTokens)
+		validator := NewSessionValidator(userSvc.sessions, zap.NewNop())
+
+		phoneTokens, phone := login(t, userSvc, ClientInfo{IP: "10.0.0.1"})
+		_, laptop := login(t, userSvc, ClientInfo{IP: "10.0.0.2"})
+		if err := validator.ValidateToken(ctx, phone); err != nil {
+			t.Fatalf("ValidateToken() error = %v", err)
+		}
+
+		if err := service.TerminateSession(ctx, user.ID(), phone.SessionID); err != nil {
+			t.Fatalf("TerminateSession() error = %v", err)
+		}
+
+		if got := errorCode(validator.ValidateToken(ctx, phone)); got != common.AuthHeaderTokenRevokedErrorCode {
+			t.Errorf("ValidateToken() error code = %v, want %v", got, common.AuthHeaderTokenRevokedErrorCode)
+		}
+		_, err := userSvc.RefreshToken(ctx, RefreshTokenReq{RefreshToken: phoneTokens.RefreshToken}, ClientInfo{})
+		if got := errorCode(err); got != modles.InvalidRefreshTokenError {
+			t.Errorf("RefreshToken() error code = %v, want %v", got, modles.InvalidRefreshTokenError)
+		}
+		if err := validator.ValidateToken(ctx, laptop); err != nil {
+			t.Errorf("ValidateToken() other session error = %v", err)
+		}
+		if got := errorCode(service.TerminateSession(ctx, 2, laptop.SessionID)); got != modles.SessionNotFoundError {
+			t.Errorf("TerminateSession() of another user error code = %v, want %v", got, modles.SessionNotFoundError)
+		}
+	})
+
+	t.Run("should accept the token when the last seen update fails", func(t *testing.T) {
+		sessions := mock.NewSessionRepo()
+		now := time.Now()
+		err := sessions.CreateSession(ctx, modles.NewSessionFromParams(modles.SessionParams{
+			ID: "s-1", UserID: user.ID(), CreatedAt: now.Add(-time.Hour), LastSeenAt: now.Add(-time.Hour), ExpiresAt: now.Add(time.Hour),
+		}))
+		if err != nil {
+			t.Fatalf("CreateSession() error = %v", err)
+		}
+		validator := NewSessionValidator(failingTouchSessions{sessions}, zap.NewNop())
+
+		if err := validator.ValidateToken(ctx, &common.UserClaims{ID: int64(user.ID()), SessionID: "s-1"}); err != nil {
+			t.Errorf("ValidateToken() error = %v, want nil", err)
+		}
+	})
+
+	t.Run("should record the session of a family issued before sessions on refresh", func(t *testing.T) {
+		userSvc := newTestUserService(users, mock.NewRefreshTokenRepo())
+		validator := NewSessionValidator(userSvc.sessions, zap.NewNop())
+
+		legacy, err := userSvc.issueTokens(ctx, user, "legacy-family", false)
+		if err != nil {
+			t.Fatalf("issueTokens() error = %v", err)
+		}
+		refreshed, err := userSvc.RefreshToken(ctx, RefreshTokenReq{RefreshToken: legacy.RefreshToken}, ClientInfo{IP: "10.0.0.3"})
+		if err != nil {
+			t.Fatalf("RefreshToken() error = %v", err)
+		}
+		claims, err := userSvc.jwt.VerifyToken(refreshed.AccessToken)
+		if err != nil {
+			t.Fatalf("VerifyToken() error = %v", err)
+		}
+		if err := validator.ValidateToken(ctx, claims); err != nil {
+			t.Errorf("ValidateToken() error = %v, want nil", err)
+		}
+
+		session, err := userSvc.sessions.GetSession(ctx, "legacy-family")
+		if err != nil {
+			t.Fatalf("GetSession() error = %v", err)
+		}
+		if session == nil || session.UserID() != user.ID() || session.IP() != "10.0.0.3" {
+			t.Errorf("GetSession() = %+v, want the session of the refreshing client", session)
+		}
+	})
+
+	t.Run("should return the new tokens when the session update fails", func(t *testing.T) {
+		userSvc := newTestUserService(users, mock.NewRefreshTokenRepo())
+		tokens, _ := login(t, userSvc, ClientInfo{IP: "10.0.0.1"})
+		userSvc.sessions = failingTouchSessions{userSvc.sessions.(*mock.SessionRepo)}
+
+		refreshed, err := userSvc.RefreshToken(ctx, RefreshTokenReq{RefreshToken: tokens.RefreshToken}, ClientInfo{})
+		if err != nil || refreshed.RefreshToken == "" {
+			t.Errorf("RefreshToken() = %v, %v, want new tokens", refreshed, err)
+		}
+	})
+
+	t.Run("should keep the session on refresh and end it on logout", func(t *testing.T) {
+		userSvc := newTestUserService(users, mock.NewRefreshTokenRepo())
+		service := NewSessionService(userSvc.sessions, userSvc.refreshTokens)
+
+		tokens, claims := login(t, userSvc, ClientInfo{IP: "10.0.0.1"})
+		refreshed, err := userSvc.RefreshToken(ctx, RefreshTokenReq{RefreshToken: tokens.RefreshToken}, ClientInfo{})
+		if err != nil {
+			t.Fatalf("RefreshToken() error = %v", err)
+		}
+		refreshedClaims, err := userSvc.jwt.VerifyToken(refreshed.AccessToken)
+		if err != nil {
+			t.Fatalf("VerifyToken() error = %v", err)
+		}
+		if refreshedClaims.SessionID != claims.SessionID {
+			t.Errorf("RefreshToken() session = %q, want %q", refreshedClaims.SessionID, claims.SessionID)
+		}
+
+		if err := userSvc.Logout(ctx, refreshedClaims, LogoutReq{}); err != nil {
+			t.Fatalf("Logout() error = %v", err)
+		}
+		sessions, err := service.ListSessions(ctx, user.ID(), "")
+		if err != nil {
+			t.Fatalf("ListSessions() error = %v", err)
+		}
+		if len(sessions) != 0 {
+			t.Errorf("ListSessions() after logout = %+v, want none", sessions)
+		}
+	})
+}
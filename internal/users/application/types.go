@@ -1,6 +1,9 @@
 package application
 
-import "time"
+import (
+	"time"
+	"yadwy-backend/internal/users/domain/modles"
+)
 
 // CreateUserReq represents the request payload for creating a new user
 // @Description User registration request payload
@@ -8,7 +11,7 @@ type CreateUserReq struct {
 	Name     string `json:"name" validate:"required" example:"John Doe"`
 	Email    string `json:"email" validate:"required,email" example:"john@example.com"`
 	Password string `json:"password" validate:"required" example:"strongpassword123"`
-	Role     string `json:"role" validate:"required" role:"CUSTOMER,ADMIN,SELLER" example:"CUSTOMER"`
+	Role     string `json:"role" validate:"required,oneof=CUSTOMER SELLER" enums:"CUSTOMER,SELLER" example:"CUSTOMER"`
 }
 
 // CreateUserRes represents the response for user creation
@@ -27,21 +30,437 @@ type LoginUserReq struct {
 	Password string `json:"password" validate:"required" example:"strongpassword123"`
 }
 
-// LoginUserRes represents the login response
+// RefreshTokenReq represents the token refresh request payload
+// @Description Token refresh request payload
+type RefreshTokenReq struct {
+	RefreshToken string `json:"refresh_token" validate:"required" example:"eyJhbGciOiJIUzI1NiIs..."`
+}
+
+// LogoutReq represents the logout request payload
+// @Description Logout request payload. The refresh token is optional.
+type LogoutReq struct {
+	RefreshToken string `json:"refresh_token" example:"eyJhbGciOiJIUzI1NiIs..."`
+}
+
+// ForgotPasswordReq represents the password reset request payload
+// @Description Forgot password request payload
+type ForgotPasswordReq struct {
+	Email string `json:"email" validate:"required,email" example:"john@example.com"`
+}
+
+// ResetPasswordReq represents the payload for setting a new password with a reset token
+// @Description Reset password request payload
+type ResetPasswordReq struct {
+	Token       string `json:"token" validate:"required" example:"kq3N2sV0cUj1u3o8Vx0e0Q"`
+	NewPassword string `json:"new_password" validate:"required,min=8" example:"newstrongpassword123"`
+}
+
+// VerifyEmailReq represents the email verification request payload
+// @Description Email verification request payload
+type VerifyEmailReq struct {
+	Token string `json:"token" validate:"required" example:"kq3N2sV0cUj1u3o8Vx0e0Q"`
+}
+
+// LoginUserRes represents the login response. When a second factor is needed
+// it carries an MFA token instead of the access and refresh tokens.
 // @Description User login response payload
 type LoginUserRes struct {
-	AccessToken           string    `json:"access_token" example:"eyJhbGciOiJIUzI1NiIs..."`
-	RefreshToken          string    `json:"refresh_token" example:"eyJhbGciOiJIUzI1NiIs..."`
-	AccessTokenExpiresAt  time.Time `json:"access_token_expires_at"`
-	RefreshTokenExpiresAt time.Time `json:"refresh_token_expires_at"`
-	User                  UserInfo  `json:"user"`
+	AccessToken           string     `json:"access_token,omitempty" example:"eyJhbGciOiJIUzI1NiIs..."`
+	RefreshToken          string     `json:"refresh_token,omitempty" example:"eyJhbGciOiJIUzI1NiIs..."`
+	AccessTokenExpiresAt  *time.Time `json:"access_token_expires_at,omitempty"`
+	RefreshTokenExpiresAt *time.Time `json:"refresh_token_expires_at,omitempty"`
+	User                  UserInfo   `json:"user"`
+	// MFARequired asks for a TOTP or recovery code at /users/login/mfa.
+	MFARequired bool `json:"mfa_required,omitempty" example:"true"`
+	// MFAEnrollmentRequired means the role requires MFA and the user must
+	// enroll with the MFA token before logging in.
+	MFAEnrollmentRequired bool       `json:"mfa_enrollment_required,omitempty" example:"false"`
+	MFAToken              string     `json:"mfa_token,omitempty" example:"eyJhbGciOiJIUzI1NiIs..."`
+	MFATokenExpiresAt     *time.Time `json:"mfa_token_expires_at,omitempty"`
+}
+
+// MFALoginReq represents the second step of a login with MFA. Either the code
+// or a recovery code is required.
+// @Description MFA login request payload
+type MFALoginReq struct {
+	MFAToken     string `json:"mfa_token" validate:"required" example:"eyJhbGciOiJIUzI1NiIs..."`
+	Code         string `json:"code" validate:"required_without=RecoveryCode" example:"123456"`
+	RecoveryCode string `json:"recovery_code" validate:"required_without=Code" example:"k3d7-q2xa-m5ve-7hzc"`
+}
+
+// MFAEnrollmentRes represents a new TOTP enrollment
+// @Description TOTP secret and otpauth URI for authenticator apps
+type MFAEnrollmentRes struct {
+	Secret     string `json:"secret" example:"JBSWY3DPEHPK3PXP"`
+	OTPAuthURI string `json:"otpauth_uri" example:"otpauth://totp/Yadwy:john%40example.com?secret=JBSWY3DPEHPK3PXP&issuer=Yadwy"`
+}
+
+// ConfirmMFAReq represents the payload confirming a TOTP enrollment
+// @Description MFA confirmation request payload
+type ConfirmMFAReq struct {
+	Code string `json:"code" validate:"required,len=6,numeric" example:"123456"`
+}
+
+// MFARecoveryCodesRes represents the one-time recovery codes, shown only once
+// @Description One-time MFA recovery codes
+type MFARecoveryCodesRes struct {
+	RecoveryCodes []string `json:"recovery_codes" example:"k3d7-q2xa-m5ve-7hzc,b6rt-2wqd-ny4f-xe3k"`
+}
+
+// UpdateProfileReq represents a partial profile update. Omitted fields keep
+// their value and an empty phone removes it.
+// @Description Profile update request payload
+type UpdateProfileReq struct {
+	Name  *string `json:"name" validate:"omitnil,min=1,max=50" example:"John Doe"`
+	Phone *string `json:"phone" validate:"omitempty,e164" example:"+201001234567"`
+}
+
+// ChangePasswordReq represents the payload for changing the password of the
+// signed in user
+// @Description Change password request payload
+type ChangePasswordReq struct {
+	CurrentPassword string `json:"current_password" validate:"required" example:"strongpassword123"`
+	NewPassword     string `json:"new_password" validate:"required,min=8" example:"newstrongpassword123"`
+}
+
+// UserProfile represents the profile of the signed in user
+// @Description User profile
+type UserProfile struct {
+	ID            int       `json:"id" example:"1"`
+	Name          string    `json:"name" example:"John Doe"`
+	Email         string    `json:"email" example:"john@example.com"`
+	EmailVerified bool      `json:"email_verified" example:"true"`
+	Phone         string    `json:"phone,omitempty" example:"+201001234567"`
+	Role          string    `json:"role" example:"CUSTOMER"`
+	SellerStatus  string    `json:"seller_status,omitempty" example:"APPROVED"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// OIDCStartRes represents a started external login
+// @Description Provider sign-in page to redirect the user to
+type OIDCStartRes struct {
+	AuthorizationURL string `json:"authorization_url" example:"https://accounts.google.com/o/oauth2/v2/auth?client_id=..."`
+	State            string `json:"state" example:"kq3N2sV0cUj1u3o8Vx0e0Q"`
+}
+
+// OIDCCallbackReq represents the code and state the provider redirected back with
+// @Description External login callback payload
+type OIDCCallbackReq struct {
+	Code  string `json:"code" validate:"required" example:"4/0AX4XfWh..."`
+	State string `json:"state" validate:"required" example:"kq3N2sV0cUj1u3o8Vx0e0Q"`
 }
 
 // UserInfo represents basic user information
 // @Description Basic user information
 type UserInfo struct {
-	ID    int    `json:"id" example:"1"`
-	Name  string `json:"name" example:"John Doe"`
-	Email string `json:"email" example:"john@example.com"`
-	Role  string `json:"role" example:"CUSTOMER"`
+	ID            int    `json:"id" example:"1"`
+	Name          string `json:"name" example:"John Doe"`
+	Email         string `json:"email" example:"john@example.com"`
+	EmailVerified bool   `json:"email_verified" example:"true"`
+	Role          string `json:"role" example:"CUSTOMER"`
+	SellerStatus  string `json:"seller_status,omitempty" example:"PENDING"`
+}
+
+// CreateAccountReq represents an admin request to create an account with any role
+// @Description Admin account creation request payload
+type CreateAccountReq struct {
+	Name     string `json:"name" validate:"required" example:"Jane Admin"`
+	Email    string `json:"email" validate:"required,email" example:"jane@example.com"`
+	Password string `json:"password" validate:"required" example:"strongpassword123"`
+	Role     string `json:"role" validate:"required,max=20" example:"ADMIN"`
+}
+
+// RejectSellerReq represents the payload for rejecting a seller application
+// @Description Seller rejection request payload
+type RejectSellerReq struct {
+	Reason string `json:"reason" example:"Incomplete business information"`
+}
+
+// ListUsersReq filters the admin user listing. Empty fields do not filter and
+// the limit is capped at 100.
+type ListUsersReq struct {
+	Role        string
+	Email       string
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	Limit       int
+	Offset      int
+}
+
+// AdminUserRes represents a user as seen by admins
+// @Description User details for admins
+type AdminUserRes struct {
+	UserProfile
+	DeactivatedAt *time.Time `json:"deactivated_at,omitempty"`
+	SuspendedAt   *time.Time `json:"suspended_at,omitempty"`
+}
+
+// UserListRes represents a page of users
+// @Description Paginated user list
+type UserListRes struct {
+	Users       []AdminUserRes `json:"users"`
+	TotalCount  int            `json:"total_count" example:"42"`
+	Limit       int            `json:"limit" example:"20"`
+	Offset      int            `json:"offset" example:"0"`
+	HasNextPage bool           `json:"has_next_page" example:"true"`
+}
+
+// ChangeRoleReq represents an admin request to change the role of a user. The
+// role must be defined in the permission config.
+// @Description Role change request payload
+type ChangeRoleReq struct {
+	Role string `json:"role" validate:"required,max=20" example:"SELLER"`
+}
+
+// SuspendUserReq represents the payload for suspending a user
+// @Description User suspension request payload
+type SuspendUserReq struct {
+	Reason string `json:"reason" validate:"max=500" example:"Fraudulent orders"`
+}
+
+// AuditEntryRes represents an action recorded on a user account
+// @Description User audit log entry
+type AuditEntryRes struct {
+	ID           int               `json:"id" example:"1"`
+	ActorID      int               `json:"actor_id,omitempty" example:"1"`
+	Action       string            `json:"action" example:"user_suspended"`
+	TargetUserID int               `json:"target_user_id" example:"2"`
+	Details      map[string]string `json:"details,omitempty"`
+	CreatedAt    time.Time         `json:"created_at"`
+}
+
+// AddressReq represents an address in the address book. The default flags
+// make the address the default shipping or billing address of the user.
+// @Description Address request payload
+type AddressReq struct {
+	Label           string `json:"label" validate:"max=50" example:"Home"`
+	RecipientName   string `json:"recipient_name" validate:"required,max=100" example:"John Doe"`
+	Phone           string `json:"phone" validate:"required,max=20" example:"01001234567"`
+	Governorate     string `json:"governorate" validate:"required,max=100" example:"Cairo"`
+	City            string `json:"city" validate:"required,max=100" example:"Nasr City"`
+	District        string `json:"district" validate:"required,max=100" example:"8th District"`
+	Street          string `json:"street" validate:"required,max=200" example:"Abbas El Akkad St."`
+	Building        string `json:"building" validate:"required,max=50" example:"12"`
+	Floor           string `json:"floor" validate:"max=20" example:"3"`
+	Apartment       string `json:"apartment" validate:"max=20" example:"7"`
+	Landmark        string `json:"landmark" validate:"max=200" example:"Next to City Stars"`
+	DefaultShipping bool   `json:"default_shipping" example:"true"`
+	DefaultBilling  bool   `json:"default_billing" example:"false"`
+}
+
+// AddressRes represents an address in the address book
+// @Description Address
+type AddressRes struct {
+	ID              int       `json:"id" example:"1"`
+	Label           string    `json:"label,omitempty" example:"Home"`
+	RecipientName   string    `json:"recipient_name" example:"John Doe"`
+	Phone           string    `json:"phone" example:"01001234567"`
+	Governorate     string    `json:"governorate" example:"Cairo"`
+	City            string    `json:"city" example:"Nasr City"`
+	District        string    `json:"district" example:"8th District"`
+	Street          string    `json:"street" example:"Abbas El Akkad St."`
+	Building        string    `json:"building" example:"12"`
+	Floor           string    `json:"floor,omitempty" example:"3"`
+	Apartment       string    `json:"apartment,omitempty" example:"7"`
+	Landmark        string    `json:"landmark,omitempty" example:"Next to City Stars"`
+	DefaultShipping bool      `json:"default_shipping" example:"true"`
+	DefaultBilling  bool      `json:"default_billing" example:"false"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+func toUserInfo(user *modles.User) UserInfo {
+	return UserInfo{
+		ID:            user.ID(),
+		Name:          user.Name(),
+		Email:         user.Email(),
+		EmailVerified: user.IsEmailVerified(),
+		Role:          user.Role().String(),
+		SellerStatus:  user.SellerStatus().String(),
+	}
+}
+
+func toUserProfile(user *modles.User) UserProfile {
+	return UserProfile{
+		ID:            user.ID(),
+		Name:          user.Name(),
+		Email:         user.Email(),
+		EmailVerified: user.IsEmailVerified(),
+		Phone:         user.Phone(),
+		Role:          user.Role().String(),
+		SellerStatus:  user.SellerStatus().String(),
+		CreatedAt:     user.CreatedAt(),
+		UpdatedAt:     user.UpdatedAt(),
+	}
+}
+
+func toAdminUserRes(user *modles.User) AdminUserRes {
+	return AdminUserRes{
+		UserProfile:   toUserProfile(user),
+		DeactivatedAt: user.DeactivatedAt(),
+		SuspendedAt:   user.SuspendedAt(),
+	}
+}
+
+func toAuditEntryRes(entry *modles.AuditEntry) AuditEntryRes {
+	return AuditEntryRes{
+		ID:           entry.ID(),
+		ActorID:      entry.ActorID(),
+		Action:       string(entry.Action()),
+		TargetUserID: entry.TargetUserID(),
+		Details:      entry.Details(),
+		CreatedAt:    entry.CreatedAt(),
+	}
+}
+
+func toAddressRes(a *modles.Address) AddressRes {
+	return AddressRes{
+		ID:              a.ID(),
+		Label:           a.Label(),
+		RecipientName:   a.RecipientName(),
+		Phone:           a.Phone(),
+		Governorate:     a.Governorate(),
+		City:            a.City(),
+		District:        a.District(),
+		Street:          a.Street(),
+		Building:        a.Building(),
+		Floor:           a.Floor(),
+		Apartment:       a.Apartment(),
+		Landmark:        a.Landmark(),
+		DefaultShipping: a.IsDefaultShipping(),
+		DefaultBilling:  a.IsDefaultBilling(),
+		CreatedAt:       a.CreatedAt(),
+		UpdatedAt:       a.UpdatedAt(),
+	}
+}
+
+func (r AddressReq) params(userID int) modles.AddressParams {
+	return modles.AddressParams{
+		UserID:          userID,
+		Label:           r.Label,
+		RecipientName:   r.RecipientName,
+		Phone:           r.Phone,
+		Governorate:     r.Governorate,
+		City:            r.City,
+		District:        r.District,
+		Street:          r.Street,
+		Building:        r.Building,
+		Floor:           r.Floor,
+		Apartment:       r.Apartment,
+		Landmark:        r.Landmark,
+		DefaultShipping: r.DefaultShipping,
+		DefaultBilling:  r.DefaultBilling,
+	}
+}
+
+// CreateAPIKeyReq represents the payload for creating an API key
+// @Description API key creation request payload
+type CreateAPIKeyReq struct {
+	Name string `json:"name" validate:"required,max=100" example:"Inventory sync"`
+	// Scopes must be permissions of the role of the user.
+	Scopes    []string   `json:"scopes" validate:"required,min=1,dive,required" example:"product:create"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty" example:"2026-01-01T00:00:00Z"`
+}
+
+// APIKeyRes represents an API key without its secret
+// @Description API key
+type APIKeyRes struct {
+	ID         int        `json:"id" example:"1"`
+	Name       string     `json:"name" example:"Inventory sync"`
+	Prefix     string     `json:"prefix" example:"ydw_3q2Zk1Xa"`
+	Scopes     []string   `json:"scopes" example:"product:create"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// CreateAPIKeyRes represents a new API key. The key is only returned once.
+// @Description New API key with its secret
+type CreateAPIKeyRes struct {
+	APIKeyRes
+	Key string `json:"key" example:"ydw_3q2Zk1Xa9v0b3TtQm0t3Jm4nYqkVdJ4xJp1mS0yZ2Gg"`
+}
+
+func toAPIKeyRes(k *modles.APIKey) APIKeyRes {
+	return APIKeyRes{
+		ID:         k.ID(),
+		Name:       k.Name(),
+		Prefix:     k.Prefix(),
+		Scopes:     k.Scopes(),
+		LastUsedAt: k.LastUsedAt(),
+		ExpiresAt:  k.ExpiresAt(),
+		CreatedAt:  k.CreatedAt(),
+	}
+}
+
+// SessionRes represents a device the user is logged in on
+// @Description Login session
+type SessionRes struct {
+	ID         string    `json:"id" example:"6f1c2a57-0b8e-4a53-9d3e-2f4f7c1b8e11"`
+	UserAgent  string    `json:"user_agent,omitempty" example:"Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X)"`
+	IP         string    `json:"ip,omitempty" example:"197.45.10.2"`
+	Current    bool      `json:"current" example:"true"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+func toSessionRes(s *modles.Session, currentID string) SessionRes {
+	return SessionRes{
+		ID:         s.ID(),
+		UserAgent:  s.UserAgent(),
+		IP:         s.IP(),
+		Current:    s.ID() == currentID,
+		CreatedAt:  s.CreatedAt(),
+		LastSeenAt: s.LastSeenAt(),
+		ExpiresAt:  s.ExpiresAt(),
+	}
+}
+
+// IdentityRes represents an external account linked to the user
+// @Description Linked identity
+type IdentityRes struct {
+	Provider  string    `json:"provider" example:"google"`
+	Subject   string    `json:"subject" example:"110169484474386276334"`
+	Email     string    `json:"email,omitempty" example:"john@gmail.com"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func toIdentityRes(i *modles.Identity) IdentityRes {
+	return IdentityRes{
+		Provider:  i.Provider(),
+		Subject:   i.Subject(),
+		Email:     i.Email(),
+		CreatedAt: i.CreatedAt(),
+	}
+}
+
+// DataExportRes represents a personal data export. The archive can be
+// downloaded once the status is completed, until it expires.
+// @Description Personal data export
+type DataExportRes struct {
+	ID          int        `json:"id" example:"1"`
+	Status      string     `json:"status" example:"completed"`
+	Error       string     `json:"error,omitempty" example:"failed to build the archive"`
+	CreatedAt   time.Time  `json:"created_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+}
+
+func toDataExportRes(e *modles.DataExport) DataExportRes {
+	return DataExportRes{
+		ID:          e.ID(),
+		Status:      string(e.Status()),
+		Error:       e.Failure(),
+		CreatedAt:   e.CreatedAt(),
+		CompletedAt: e.CompletedAt(),
+		ExpiresAt:   e.ExpiresAt(),
+	}
+}
+
+// EraseAccountReq confirms the erasure of the account with the password
+// @Description Account erasure request payload
+type EraseAccountReq struct {
+	Password string `json:"password" validate:"required" example:"password123"`
 }
@@ -2,93 +2,473 @@ package application
 
 import (
 	"context"
+	"errors"
+	"sync"
 	"time"
 	"yadwy-backend/internal/common"
 	"yadwy-backend/internal/users/domain/contracts"
 	"yadwy-backend/internal/users/domain/modles"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
 )
 
+// TokenConfig controls the lifetime of the tokens issued by UserService.
+type TokenConfig struct {
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+}
+
+// ClientInfo describes the device a login is made from.
+type ClientInfo struct {
+	IP        string
+	UserAgent string
+}
+
+// maxUserAgentLength bounds the user agent stored with a session.
+const maxUserAgentLength = 512
+
+// verificationSender mails an email verification link to a new user.
+type verificationSender interface {
+	SendVerification(ctx context.Context, user *modles.User) error
+}
+
 type UserService struct {
-	userRepo contracts.UserRepo
-	jwt      *common.JWTGenerator
+	userRepo      contracts.UserRepo
+	refreshTokens contracts.RefreshTokenRepo
+	sessions      contracts.SessionRepo
+	revocations   contracts.TokenRevocationStore
+	verifier      verificationSender
+	loginGuard    *LoginGuard
+	mfa           contracts.MFARepo
+	mfaPolicy     MFAPolicy
+	jwt           *common.JWTGenerator
+	tokenCfg      TokenConfig
+	logger        *zap.Logger
 }
 
 func NewUserService(
 	repo contracts.UserRepo,
-	jwt *common.JWTGenerator) *UserService {
+	refreshTokens contracts.RefreshTokenRepo,
+	sessions contracts.SessionRepo,
+	revocations contracts.TokenRevocationStore,
+	verifier verificationSender,
+	loginGuard *LoginGuard,
+	mfa contracts.MFARepo,
+	mfaPolicy MFAPolicy,
+	jwt *common.JWTGenerator,
+	tokenCfg TokenConfig,
+	logger *zap.Logger) *UserService {
 	return &UserService{
-		userRepo: repo,
-		jwt:      jwt,
+		userRepo:      repo,
+		refreshTokens: refreshTokens,
+		sessions:      sessions,
+		revocations:   revocations,
+		verifier:      verifier,
+		loginGuard:    loginGuard,
+		mfa:           mfa,
+		mfaPolicy:     mfaPolicy,
+		jwt:           jwt,
+		tokenCfg:      tokenCfg,
+		logger:        logger,
 	}
 }
 
-func (s *UserService) CreateUser(ctx context.Context, r CreateUserReq) (*LoginUserRes, error) {
-	hashPass, err := common.HashPass(r.Password)
+// CreateUser registers a user, mails an email verification link and logs the
+// user in. Unverified users can browse but not use routes behind
+// common.RequireVerifiedEmail. The user is registered even when the link
+// cannot be mailed, and can ask for it again.
+func (s *UserService) CreateUser(ctx context.Context, r CreateUserReq, client ClientInfo) (*LoginUserRes, error) {
+	role, err := modles.NewRole(r.Role)
 	if err != nil {
-		return nil, common.NewErrorf(modles.InvalidUserCredentialsError, "Invalid user credentials")
+		return nil, common.NewErrorf(modles.InvalidUserRoleError, "Invalid user role")
 	}
 
-	role, err := modles.NewRole(r.Role)
+	savedUser, err := createUser(ctx, s.userRepo, r.Email, r.Password, nil, func(hashPass string) (*modles.User, error) {
+		return modles.NewRegisteredUser(r.Name, r.Email, hashPass, role)
+	})
 	if err != nil {
-		return nil, common.NewErrorf(modles.InvalidUserRoleError, "Invalid user role")
+		return nil, err
+	}
+
+	if err := s.verifier.SendVerification(ctx, savedUser); err != nil {
+		s.logger.Error("Failed to send email verification", zap.Int("userID", savedUser.ID()), zap.Error(err))
+	}
+	return s.completeLogin(ctx, savedUser, client)
+}
+
+// createUser checks that the email is free, hashes the password and stores
+// the user built by newUser from the hash, along with the audit entry when it
+// is not nil.
+func createUser(
+	ctx context.Context,
+	repo contracts.UserRepo,
+	email, password string,
+	audit *modles.AuditEntry,
+	newUser func(hashPass string) (*modles.User, error)) (*modles.User, error) {
+	hashPass, err := common.HashPass(password)
+	if err != nil {
+		return nil, common.NewErrorf(modles.InvalidUserCredentialsError, "Invalid user credentials")
 	}
 
-	b, err := s.userRepo.UserExists(ctx, r.Email)
+	b, err := repo.UserExists(ctx, email)
 	if err != nil || b {
 		return nil, common.NewErrorf(modles.UserAlreadyExistsError, "user already exists")
 	}
 
-	user := modles.NewUser(
-		0,
-		r.Name,
-		r.Email,
-		hashPass,
-		role,
-	)
-
-	savedUser, err := s.userRepo.CreateUser(ctx, user)
+	user, err := newUser(hashPass)
 	if err != nil {
 		return nil, err
 	}
-	return generateTokens(savedUser, s.jwt)
+
+	return repo.CreateUser(ctx, user, audit)
 }
 
-func (s *UserService) LoginUser(ctx context.Context, req LoginUserReq) (*LoginUserRes, error) {
+// LoginUser checks the credentials of a user. Every credential failure,
+// including an unknown email, is reported as InvalidUserCredentialsError, and
+// repeated failures from the account or the client address are throttled.
+func (s *UserService) LoginUser(ctx context.Context, req LoginUserReq, client ClientInfo) (*LoginUserRes, error) {
+	clientIP := client.IP
+	if err := s.loginGuard.Check(ctx, req.Email, clientIP); err != nil {
+		return nil, err
+	}
+
 	gu, err := s.userRepo.GetUser(ctx, req.Email)
 	if err != nil {
+		var appErr *common.Error
+		if !errors.As(err, &appErr) || appErr.Code() != modles.UserNotFoundError {
+			return nil, err
+		}
+		// Compare against a dummy hash so unknown emails take as long as
+		// wrong passwords.
+		_ = common.CheckPassword(dummyPasswordHash(), req.Password)
+		return nil, s.loginFailed(ctx, req.Email, clientIP)
+	}
+
+	if err := common.CheckPassword(gu.Password(), req.Password); err != nil || !gu.IsActive() {
+		return nil, s.loginFailed(ctx, req.Email, clientIP)
+	}
+	if gu.IsSuspended() {
+		return nil, common.NewErrorf(modles.UserSuspendedError, "account is suspended")
+	}
+
+	if err := s.loginGuard.RecordSuccess(ctx, req.Email); err != nil {
 		return nil, err
 	}
+	return s.completeLogin(ctx, gu, client)
+}
 
-	err = common.CheckPassword(gu.Password(), req.Password)
+// completeLogin issues the tokens of a user that proved the password or an
+// external identity, or an MFA token when a second factor is enabled or
+// required for the role.
+func (s *UserService) completeLogin(ctx context.Context, user *modles.User, client ClientInfo) (*LoginUserRes, error) {
+	enrollment, err := s.mfa.GetMFAEnrollment(ctx, user.ID())
 	if err != nil {
 		return nil, err
 	}
 
-	return generateTokens(gu, s.jwt)
+	switch {
+	case enrollment != nil && enrollment.IsConfirmed():
+		return s.mfaTokenRes(user, common.MFAChallengeTokenType, func(res *LoginUserRes) {
+			res.MFARequired = true
+		})
+	case s.mfaPolicy.requires(user.Role()):
+		return s.mfaTokenRes(user, common.MFAEnrollmentTokenType, func(res *LoginUserRes) {
+			res.MFAEnrollmentRequired = true
+		})
+	}
+	return s.startSession(ctx, user, false, client)
 }
 
-func generateTokens(user *modles.User, jwt *common.JWTGenerator) (*LoginUserRes, error) {
-	accessToken, accessClaims, err := jwt.CreateToken(int64(user.ID()), user.Email(), user.Role().String(), 15*time.Minute)
+func (s *UserService) mfaTokenRes(user *modles.User, tokenType string, mark func(*LoginUserRes)) (*LoginUserRes, error) {
+	token, claims, err := s.jwt.CreateTokenOfType(tokenSubject(user, false), tokenType, s.mfaPolicy.ChallengeTTL)
 	if err != nil {
 		return nil, err
 	}
 
-	refreshToken, refreshClaims, err := jwt.CreateToken(int64(user.ID()), user.Email(), user.Role().String(), 15*time.Minute)
+	res := &LoginUserRes{
+		User:              toUserInfo(user),
+		MFAToken:          token,
+		MFATokenExpiresAt: &claims.ExpiresAt.Time,
+	}
+	mark(res)
+	return res, nil
+}
+
+// LoginWithMFA completes a login with the MFA token from LoginUser and a TOTP
+// or recovery code. Failed codes count towards the login throttling.
+func (s *UserService) LoginWithMFA(ctx context.Context, req MFALoginReq, client ClientInfo) (*LoginUserRes, error) {
+	clientIP := client.IP
+	claims, err := s.jwt.VerifyToken(req.MFAToken)
+	if err != nil || claims.TokenType != common.MFAChallengeTokenType {
+		return nil, common.NewErrorf(modles.InvalidMFATokenError, "invalid MFA token")
+	}
+
+	if err := s.loginGuard.Check(ctx, claims.Email, clientIP); err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetUserByID(ctx, int(claims.ID))
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsActive() || user.IsSuspended() {
+		return nil, common.NewErrorf(modles.InvalidMFATokenError, "invalid MFA token")
+	}
+	enrollment, err := s.mfa.GetMFAEnrollment(ctx, user.ID())
+	if err != nil {
+		return nil, err
+	}
+	if enrollment == nil || !enrollment.IsConfirmed() {
+		return nil, common.NewErrorf(modles.InvalidMFATokenError, "invalid MFA token")
+	}
+
+	ok, err := s.checkSecondFactor(ctx, enrollment, req)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		if err := s.loginGuard.RecordFailure(ctx, user.Email(), clientIP); err != nil {
+			return nil, err
+		}
+		return nil, common.NewErrorf(modles.InvalidMFACodeError, "invalid MFA code")
+	}
+
+	if err := s.loginGuard.RecordSuccess(ctx, user.Email()); err != nil {
+		return nil, err
+	}
+	return s.startSession(ctx, user, true, client)
+}
+
+// checkSecondFactor accepts a TOTP code once per time step, or an unused
+// recovery code.
+func (s *UserService) checkSecondFactor(ctx context.Context, enrollment *modles.MFAEnrollment, req MFALoginReq) (bool, error) {
+	if req.Code != "" {
+		step, ok := common.ValidateTOTP(enrollment.Secret(), req.Code, time.Now())
+		if !ok {
+			return false, nil
+		}
+		return s.mfa.UseMFAStep(ctx, enrollment.UserID(), step)
+	}
+	return s.mfa.ConsumeRecoveryCode(ctx, enrollment.UserID(), hashRecoveryCode(req.RecoveryCode))
+}
+
+func (s *UserService) loginFailed(ctx context.Context, email, clientIP string) error {
+	if err := s.loginGuard.RecordFailure(ctx, email, clientIP); err != nil {
+		return err
+	}
+	return common.NewErrorf(modles.InvalidUserCredentialsError, "invalid email or password")
+}
+
+var (
+	dummyHashOnce sync.Once
+	dummyHash     string
+)
+
+func dummyPasswordHash() string {
+	dummyHashOnce.Do(func() {
+		dummyHash, _ = common.HashPass("yadwy-dummy-password")
+	})
+	return dummyHash
+}
+
+// RefreshToken exchanges a valid refresh token for a new token pair. Every
+// refresh token can be used once; presenting an already rotated token is
+// treated as theft and revokes the whole token family.
+func (s *UserService) RefreshToken(ctx context.Context, req RefreshTokenReq, client ClientInfo) (*LoginUserRes, error) {
+	claims, err := s.jwt.VerifyToken(req.RefreshToken)
+	if err != nil || claims.TokenType != common.RefreshTokenType {
+		return nil, common.NewErrorf(modles.InvalidRefreshTokenError, "invalid refresh token")
+	}
+
+	stored, err := s.refreshTokens.GetRefreshToken(ctx, claims.TokenID)
 	if err != nil {
 		return nil, err
 	}
 
+	if stored.TokenHash() != common.HashToken(req.RefreshToken) ||
+		stored.IsRevoked() ||
+		stored.IsExpired(time.Now()) {
+		return nil, common.NewErrorf(modles.InvalidRefreshTokenError, "invalid refresh token")
+	}
+
+	if stored.IsUsed() {
+		return nil, s.revokeReusedFamily(ctx, stored)
+	}
+
+	consumed, err := s.refreshTokens.MarkRefreshTokenUsed(ctx, stored.ID())
+	if err != nil {
+		return nil, err
+	}
+	if !consumed {
+		// Another request rotated this token concurrently.
+		return nil, s.revokeReusedFamily(ctx, stored)
+	}
+
+	user, err := s.userRepo.GetUserByID(ctx, stored.UserID())
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsActive() || user.IsSuspended() {
+		return nil, common.NewErrorf(modles.InvalidRefreshTokenError, "invalid refresh token")
+	}
+
+	res, err := s.issueTokens(ctx, user, stored.FamilyID(), claims.MFA)
+	if err != nil {
+		return nil, err
+	}
+	// the token has been rotated already, so a failed update must not fail the request
+	if err := s.refreshSession(ctx, user.ID(), stored.FamilyID(), client, *res.RefreshTokenExpiresAt); err != nil {
+		s.logger.Error("Failed to record session refresh", zap.String("session_id", stored.FamilyID()), zap.Error(err))
+	}
+	return res, nil
+}
+
+// refreshSession extends the session of a refresh token family. Families
+// issued before sessions were recorded get their session on first refresh.
+func (s *UserService) refreshSession(ctx context.Context, userID int, id string, client ClientInfo, expiresAt time.Time) error {
+	session, err := s.sessions.GetSession(ctx, id)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	if session == nil {
+		return s.sessions.CreateSession(ctx, newSession(id, userID, client, now, expiresAt))
+	}
+	return s.sessions.TouchSession(ctx, id, now, expiresAt)
+}
+
+// Logout revokes the access token used for the request and ends its session,
+// along with the session of the refresh token given in the request.
+func (s *UserService) Logout(ctx context.Context, claims *common.UserClaims, req LogoutReq) error {
+	err := s.revocations.RevokeToken(ctx, claims.TokenID, int(claims.ID), claims.ExpiresAt.Time)
+	if err != nil {
+		return err
+	}
+
+	if claims.SessionID != "" {
+		if err := s.endSession(ctx, int(claims.ID), claims.SessionID); err != nil {
+			return err
+		}
+	}
+
+	if req.RefreshToken == "" {
+		return nil
+	}
+
+	refreshClaims, err := s.jwt.VerifyToken(req.RefreshToken)
+	if err != nil || refreshClaims.TokenType != common.RefreshTokenType || refreshClaims.ID != claims.ID {
+		return common.NewErrorf(modles.InvalidRefreshTokenError, "invalid refresh token")
+	}
+
+	stored, err := s.refreshTokens.GetRefreshToken(ctx, refreshClaims.TokenID)
+	if err != nil {
+		return err
+	}
+	if stored.FamilyID() == claims.SessionID {
+		return nil
+	}
+	return s.endSession(ctx, int(claims.ID), stored.FamilyID())
+}
+
+// endSession terminates a session and revokes its refresh tokens. A session
+// that has already ended is not an error.
+func (s *UserService) endSession(ctx context.Context, userID int, sessionID string) error {
+	err := s.sessions.TerminateSession(ctx, userID, sessionID)
+	var appErr *common.Error
+	if err != nil && (!errors.As(err, &appErr) || appErr.Code() != modles.SessionNotFoundError) {
+		return err
+	}
+	return s.refreshTokens.RevokeTokenFamily(ctx, sessionID)
+}
+
+// LogoutAll revokes every access and refresh token the user holds and ends
+// every session.
+func (s *UserService) LogoutAll(ctx context.Context, userID int) error {
+	now := time.Now()
+	err := s.revocations.RevokeUserTokens(ctx, userID, now, now.Add(s.tokenCfg.AccessTokenTTL))
+	if err != nil {
+		return err
+	}
+	if err := s.sessions.TerminateUserSessions(ctx, userID); err != nil {
+		return err
+	}
+	return s.refreshTokens.RevokeUserRefreshTokens(ctx, userID)
+}
+
+func (s *UserService) revokeReusedFamily(ctx context.Context, token *modles.RefreshToken) error {
+	if err := s.refreshTokens.RevokeTokenFamily(ctx, token.FamilyID()); err != nil {
+		return err
+	}
+	return common.NewErrorf(modles.RefreshTokenReusedError, "refresh token has already been used")
+}
+
+// startSession records a login session for the client and issues its first
+// token pair.
+func (s *UserService) startSession(ctx context.Context, user *modles.User, mfa bool, client ClientInfo) (*LoginUserRes, error) {
+	now := time.Now()
+	session := newSession(uuid.NewString(), user.ID(), client, now, now.Add(s.tokenCfg.RefreshTokenTTL))
+	if err := s.sessions.CreateSession(ctx, session); err != nil {
+		return nil, err
+	}
+	return s.issueTokens(ctx, user, session.ID(), mfa)
+}
+
+func newSession(id string, userID int, client ClientInfo, now, expiresAt time.Time) *modles.Session {
+	userAgent := client.UserAgent
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = userAgent[:maxUserAgentLength]
+	}
+	return modles.NewSession(id, userID, userAgent, client.IP, now, expiresAt)
+}
+
+// issueTokens issues an access and refresh token pair for a session. The
+// session ID doubles as the refresh token family. mfa records that the login
+// used a second factor and is carried over on refresh.
+func (s *UserService) issueTokens(ctx context.Context, user *modles.User, familyID string, mfa bool) (*LoginUserRes, error) {
+	subject := tokenSubject(user, mfa)
+	subject.SessionID = familyID
+
+	accessToken, accessClaims, err := s.jwt.CreateToken(subject, s.tokenCfg.AccessTokenTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, refreshClaims, err := s.jwt.CreateRefreshToken(subject, s.tokenCfg.RefreshTokenTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	stored := modles.NewRefreshToken(
+		refreshClaims.TokenID,
+		user.ID(),
+		familyID,
+		common.HashToken(refreshToken),
+		refreshClaims.ExpiresAt.Time,
+		nil,
+		nil,
+	)
+	if err := s.refreshTokens.SaveRefreshToken(ctx, stored); err != nil {
+		return nil, err
+	}
+
 	res := &LoginUserRes{
 		AccessToken:           accessToken,
 		RefreshToken:          refreshToken,
-		AccessTokenExpiresAt:  accessClaims.ExpiresAt.Time,
-		RefreshTokenExpiresAt: refreshClaims.ExpiresAt.Time,
-		User: UserInfo{
-			ID:    user.ID(),
-			Name:  user.Name(),
-			Email: user.Email(),
-			Role:  user.Role().String(),
-		},
+		AccessTokenExpiresAt:  &accessClaims.ExpiresAt.Time,
+		RefreshTokenExpiresAt: &refreshClaims.ExpiresAt.Time,
+		User:                  toUserInfo(user),
 	}
 	return res, nil
 }
+
+func tokenSubject(user *modles.User, mfa bool) common.TokenSubject {
+	return common.TokenSubject{
+		ID:            int64(user.ID()),
+		Email:         user.Email(),
+		Role:          user.Role().String(),
+		EmailVerified: user.IsEmailVerified(),
+		MFA:           mfa,
+		SellerStatus:  user.SellerStatus().String(),
+	}
+}
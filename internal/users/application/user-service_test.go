@@ -0,0 +1,229 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"yadwy-backend/internal/common"
+	"yadwy-backend/internal/users/db"
+	"yadwy-backend/internal/users/domain/contracts/mock"
+	"yadwy-backend/internal/users/domain/modles"
+
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
+)
+
+func newTestUserService(users *mock.UserRepo, tokens *mock.RefreshTokenRepo) *UserService {
+	verifier := newTestVerificationService(users, &mock.Mailer{})
+	guard := newTestLoginGuard()
+	policy := MFAPolicy{RequiredRoles: []modles.Role{modles.RoleAdmin}, ChallengeTTL: time.Minute}
+	return NewUserService(users, tokens, mock.NewSessionRepo(), db.NewMemoryTokenRevocationStore(), verifier, guard, mock.NewMFARepo(), policy, common.NewJWTGenerator("test-secret"), TokenConfig{
+		AccessTokenTTL:  time.Minute,
+		RefreshTokenTTL: time.Hour,
+	}, zap.NewNop())
+}
+
+func testUser() *modles.User {
+	return modles.NewUser(1, "John Doe", "john@example.com", "hashed", modles.RoleCustomer)
+}
+
+func errorCode(err error) common.ErrorCode {
+	var appErr *common.Error
+	if errors.As(err, &appErr) {
+		return appErr.Code()
+	}
+	return ""
+}
+
+func TestUserService_RefreshToken(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should rotate refresh token", func(t *testing.T) {
+		users := &mock.UserRepo{
+			GetUserByIDFunc: func(ctx context.Context, id int) (*modles.User, error) {
+				return testUser(), nil
+			},
+		}
+		service := newTestUserService(users, mock.NewRefreshTokenRepo())
+
+		login, err := service.issueTokens(ctx, testUser(), "family-1", false)
+		if err != nil {
+			t.Fatalf("issueTokens() error = %v", err)
+		}
+
+		refreshed, err := service.RefreshToken(ctx, RefreshTokenReq{RefreshToken: login.RefreshToken}, ClientInfo{})
+		if err != nil {
+			t.Fatalf("RefreshToken() error = %v", err)
+		}
+		if refreshed.RefreshToken == login.RefreshToken {
+			t.Errorf("RefreshToken() returned the same refresh token")
+		}
+		if _, err := service.RefreshToken(ctx, RefreshTokenReq{RefreshToken: refreshed.RefreshToken}, ClientInfo{}); err != nil {
+			t.Errorf("RefreshToken() with rotated token error = %v", err)
+		}
+	})
+
+	t.Run("should revoke family when a used token is replayed", func(t *testing.T) {
+		users := &mock.UserRepo{
+			GetUserByIDFunc: func(ctx context.Context, id int) (*modles.User, error) {
+				return testUser(), nil
+			},
+		}
+		service := newTestUserService(users, mock.NewRefreshTokenRepo())
+
+		login, err := service.issueTokens(ctx, testUser(), "family-1", false)
+		if err != nil {
+			t.Fatalf("issueTokens() error = %v", err)
+		}
+		refreshed, err := service.RefreshToken(ctx, RefreshTokenReq{RefreshToken: login.RefreshToken}, ClientInfo{})
+		if err != nil {
+			t.Fatalf("RefreshToken() error = %v", err)
+		}
+
+		_, err = service.RefreshToken(ctx, RefreshTokenReq{RefreshToken: login.RefreshToken}, ClientInfo{})
+		if got := errorCode(err); got != modles.RefreshTokenReusedError {
+			t.Fatalf("RefreshToken() replay error code = %v, want %v", got, modles.RefreshTokenReusedError)
+		}
+
+		_, err = service.RefreshToken(ctx, RefreshTokenReq{RefreshToken: refreshed.RefreshToken}, ClientInfo{})
+		if got := errorCode(err); got != modles.InvalidRefreshTokenError {
+			t.Errorf("RefreshToken() after family revocation error code = %v, want %v", got, modles.InvalidRefreshTokenError)
+		}
+	})
+
+	t.Run("should reject access tokens", func(t *testing.T) {
+		service := newTestUserService(&mock.UserRepo{}, mock.NewRefreshTokenRepo())
+
+		login, err := service.issueTokens(ctx, testUser(), "family-1", false)
+		if err != nil {
+			t.Fatalf("issueTokens() error = %v", err)
+		}
+
+		_, err = service.RefreshToken(ctx, RefreshTokenReq{RefreshToken: login.AccessToken}, ClientInfo{})
+		if got := errorCode(err); got != modles.InvalidRefreshTokenError {
+			t.Errorf("RefreshToken() error code = %v, want %v", got, modles.InvalidRefreshTokenError)
+		}
+	})
+}
+
+func TestUserService_Logout(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should revoke access token and refresh family", func(t *testing.T) {
+		service := newTestUserService(&mock.UserRepo{}, mock.NewRefreshTokenRepo())
+		validator := NewRevocationValidator(service.revocations)
+
+		login, err := service.issueTokens(ctx, testUser(), "family-1", false)
+		if err != nil {
+			t.Fatalf("issueTokens() error = %v", err)
+		}
+		claims, err := service.jwt.VerifyToken(login.AccessToken)
+		if err != nil {
+			t.Fatalf("VerifyToken() error = %v", err)
+		}
+
+		if err := service.Logout(ctx, claims, LogoutReq{RefreshToken: login.RefreshToken}); err != nil {
+			t.Fatalf("Logout() error = %v", err)
+		}
+
+		if got := errorCode(validator.ValidateToken(ctx, claims)); got != common.AuthHeaderTokenRevokedErrorCode {
+			t.Errorf("ValidateToken() error code = %v, want %v", got, common.AuthHeaderTokenRevokedErrorCode)
+		}
+		_, err = service.RefreshToken(ctx, RefreshTokenReq{RefreshToken: login.RefreshToken}, ClientInfo{})
+		if got := errorCode(err); got != modles.InvalidRefreshTokenError {
+			t.Errorf("RefreshToken() error code = %v, want %v", got, modles.InvalidRefreshTokenError)
+		}
+	})
+
+	t.Run("should revoke tokens issued before logout from all devices", func(t *testing.T) {
+		service := newTestUserService(&mock.UserRepo{}, mock.NewRefreshTokenRepo())
+		validator := NewRevocationValidator(service.revocations)
+
+		login, err := service.issueTokens(ctx, testUser(), "family-1", false)
+		if err != nil {
+			t.Fatalf("issueTokens() error = %v", err)
+		}
+		claims, err := service.jwt.VerifyToken(login.AccessToken)
+		if err != nil {
+			t.Fatalf("VerifyToken() error = %v", err)
+		}
+
+		// iat has second precision, the token must be issued a second
+		// before the logout to be revoked
+		claims.IssuedAt = jwt.NewNumericDate(claims.IssuedAt.Add(-time.Second))
+
+		if err := service.LogoutAll(ctx, testUser().ID()); err != nil {
+			t.Fatalf("LogoutAll() error = %v", err)
+		}
+
+		if got := errorCode(validator.ValidateToken(ctx, claims)); got != common.AuthHeaderTokenRevokedErrorCode {
+			t.Errorf("ValidateToken() error code = %v, want %v", got, common.AuthHeaderTokenRevokedErrorCode)
+		}
+		_, err = service.RefreshToken(ctx, RefreshTokenReq{RefreshToken: login.RefreshToken}, ClientInfo{})
+		if got := errorCode(err); got != modles.InvalidRefreshTokenError {
+			t.Errorf("RefreshToken() error code = %v, want %v", got, modles.InvalidRefreshTokenError)
+		}
+	})
+}
+
+func TestUserService_SellerStatusClaim(t *testing.T) {
+	service := newTestUserService(&mock.UserRepo{}, mock.NewRefreshTokenRepo())
+	seller := modles.NewUserFromParams(modles.UserParams{
+		ID: 1, Name: "Jane Doe", Email: "jane@example.com", Role: modles.RoleSeller, SellerStatus: modles.SellerPending,
+	})
+
+	login, err := service.issueTokens(context.Background(), seller, "family-1", false)
+	if err != nil {
+		t.Fatalf("issueTokens() error = %v", err)
+	}
+	claims, err := service.jwt.VerifyToken(login.AccessToken)
+	if err != nil {
+		t.Fatalf("VerifyToken() error = %v", err)
+	}
+	if !claims.SellerNotApproved() {
+		t.Errorf("claims seller status = %q, want a seller pending approval", claims.SellerStatus)
+	}
+}
+
+func TestUserService_LoginDeactivatedUser(t *testing.T) {
+	hash, err := common.HashPass("password123")
+	if err != nil {
+		t.Fatalf("HashPass() error = %v", err)
+	}
+	now := time.Now()
+	users := &mock.UserRepo{
+		GetUserFunc: func(ctx context.Context, email string) (*modles.User, error) {
+			return modles.NewUserFromParams(modles.UserParams{
+				ID: 1, Name: "John Doe", Email: email, Password: hash, Role: modles.RoleCustomer, DeactivatedAt: &now,
+			}), nil
+		},
+	}
+	service := newTestUserService(users, mock.NewRefreshTokenRepo())
+
+	_, err = service.LoginUser(context.Background(), LoginUserReq{Email: "john@example.com", Password: "password123"}, ClientInfo{IP: "127.0.0.1"})
+	if got := errorCode(err); got != modles.InvalidUserCredentialsError {
+		t.Errorf("LoginUser() error code = %v, want %v", got, modles.InvalidUserCredentialsError)
+	}
+}
+
+func TestUserService_LoginSuspendedUser(t *testing.T) {
+	hash, err := common.HashPass("password123")
+	if err != nil {
+		t.Fatalf("HashPass() error = %v", err)
+	}
+	now := time.Now()
+	users := &mock.UserRepo{
+		GetUserFunc: func(ctx context.Context, email string) (*modles.User, error) {
+			return modles.NewUserFromParams(modles.UserParams{
+				ID: 1, Name: "John Doe", Email: email, Password: hash, Role: modles.RoleCustomer, SuspendedAt: &now,
+			}), nil
+		},
+	}
+	service := newTestUserService(users, mock.NewRefreshTokenRepo())
+
+	_, err = service.LoginUser(context.Background(), LoginUserReq{Email: "john@example.com", Password: "password123"}, ClientInfo{IP: "127.0.0.1"})
+	if got := errorCode(err); got != modles.UserSuspendedError {
+		t.Errorf("LoginUser() error code = %v, want %v", got, modles.UserSuspendedError)
+	}
+}
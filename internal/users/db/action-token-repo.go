@@ -0,0 +1,122 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+	"yadwy-backend/internal/users/domain/modles"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type ActionTokenDbo struct {
+	ID        int        `db:"id"`
+	UserID    int        `db:"user_id"`
+	Purpose   string     `db:"purpose"`
+	TokenHash string     `db:"token_hash"`
+	ExpiresAt time.Time  `db:"expires_at"`
+	UsedAt    *time.Time `db:"used_at"`
+	CreatedAt time.Time  `db:"created_at"`
+}
+
+type ActionTokenRepo struct {
+	db *sqlx.DB
+}
+
+func NewActionTokenRepo(db *sqlx.DB) *ActionTokenRepo {
+	return &ActionTokenRepo{
+		db: db,
+	}
+}
+
+func (r *ActionTokenRepo) CreateActionToken(ctx context.Context, token *modles.ActionToken) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO user_action_tokens (user_id, purpose, token_hash, expires_at)
+		VALUES ($1, $2, $3, $4)`,
+		token.UserID(), token.Purpose(), token.TokenHash(), token.ExpiresAt())
+	if err != nil {
+		return fmt.Errorf("error creating action token: %w", err)
+	}
+	return nil
+}
+
+// GetActionToken returns nil without an error when no token matches.
+func (r *ActionTokenRepo) GetActionToken(ctx context.Context, purpose modles.TokenPurpose, tokenHash string) (*modles.ActionToken, error) {
+	var dbo ActionTokenDbo
+	err := r.db.GetContext(ctx, &dbo,
+		"SELECT * FROM user_action_tokens WHERE purpose = $1 AND token_hash = $2", purpose, tokenHash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error getting action token: %w", err)
+	}
+
+	return mapActionTokenToDomain(dbo), nil
+}
+
+// GetLatestActionToken returns the most recently issued token of the user for
+// the purpose, or nil when none was issued.
+func (r *ActionTokenRepo) GetLatestActionToken(ctx context.Context, userID int, purpose modles.TokenPurpose) (*modles.ActionToken, error) {
+	var dbo ActionTokenDbo
+	err := r.db.GetContext(ctx, &dbo, `
+		SELECT * FROM user_action_tokens
+		WHERE user_id = $1 AND purpose = $2
+		ORDER BY created_at DESC
+		LIMIT 1`,
+		userID, purpose)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error getting latest action token: %w", err)
+	}
+
+	return mapActionTokenToDomain(dbo), nil
+}
+
+func mapActionTokenToDomain(dbo ActionTokenDbo) *modles.ActionToken {
+	return modles.NewActionToken(
+		dbo.ID,
+		dbo.UserID,
+		modles.TokenPurpose(dbo.Purpose),
+		dbo.TokenHash,
+		dbo.ExpiresAt,
+		dbo.UsedAt,
+		dbo.CreatedAt,
+	)
+}
+
+func (r *ActionTokenRepo) ConsumeActionToken(ctx context.Context, id int) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE user_action_tokens
+		SET used_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+		AND used_at IS NULL`,
+		id)
+	if err != nil {
+		return false, fmt.Errorf("error consuming action token: %w", err)
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("error consuming action token: %w", err)
+	}
+	return rows == 1, nil
+}
+
+func (r *ActionTokenRepo) InvalidateActionTokens(ctx context.Context, userID int, purpose modles.TokenPurpose) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE user_action_tokens
+		SET used_at = CURRENT_TIMESTAMP
+		WHERE user_id = $1
+		AND purpose = $2
+		AND used_at IS NULL`,
+		userID, purpose)
+	if err != nil {
+		return fmt.Errorf("error invalidating action tokens: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,246 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+	"yadwy-backend/internal/common"
+	"yadwy-backend/internal/users/domain/modles"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type AddressDbo struct {
+	ID              int            `db:"id"`
+	UserID          int            `db:"user_id"`
+	Label           sql.NullString `db:"label"`
+	RecipientName   string         `db:"recipient_name"`
+	Phone           string         `db:"phone"`
+	Governorate     string         `db:"governorate"`
+	City            string         `db:"city"`
+	District        string         `db:"district"`
+	Street          string         `db:"street"`
+	Building        string         `db:"building"`
+	Floor           sql.NullString `db:"floor"`
+	Apartment       sql.NullString `db:"apartment"`
+	Landmark        sql.NullString `db:"landmark"`
+	DefaultShipping bool           `db:"is_default_shipping"`
+	DefaultBilling  bool           `db:"is_default_billing"`
+	CreatedAt       time.Time      `db:"created_at"`
+	UpdatedAt       time.Time      `db:"updated_at"`
+}
+
+const addressColumns = `id, user_id, label, recipient_name, phone, governorate, city, district,
+	street, building, floor, apartment, landmark, is_default_shipping, is_default_billing,
+	created_at, updated_at`
+
+type AddressRepo struct {
+	db *sqlx.DB
+}
+
+func NewAddressRepo(db *sqlx.DB) *AddressRepo {
+	return &AddressRepo{
+		db: db,
+	}
+}
+
+func (r *AddressRepo) ListAddresses(ctx context.Context, userID int) ([]modles.Address, error) {
+	var dbos []AddressDbo
+	err := r.db.SelectContext(ctx, &dbos,
+		"SELECT "+addressColumns+" FROM user_addresses WHERE user_id = $1 ORDER BY id", userID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing addresses: %w", err)
+	}
+
+	addresses := make([]modles.Address, 0, len(dbos))
+	for _, dbo := range dbos {
+		addresses = append(addresses, *mapAddressToDomain(dbo))
+	}
+	return addresses, nil
+}
+
+func (r *AddressRepo) GetAddress(ctx context.Context, userID, id int) (*modles.Address, error) {
+	var dbo AddressDbo
+	err := r.db.GetContext(ctx, &dbo,
+		"SELECT "+addressColumns+" FROM user_addresses WHERE id = $1 AND user_id = $2", id, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, common.NewErrorf(modles.AddressNotFoundError, "address not found")
+		}
+		return nil, fmt.Errorf("error getting address: %w", err)
+	}
+	return mapAddressToDomain(dbo), nil
+}
+
+func (r *AddressRepo) CountAddresses(ctx context.Context, userID int) (int, error) {
+	var count int
+	err := r.db.GetContext(ctx, &count, "SELECT COUNT(*) FROM user_addresses WHERE user_id = $1", userID)
+	if err != nil {
+		return 0, fmt.Errorf("error counting addresses: %w", err)
+	}
+	return count, nil
+}
+
+func (r *AddressRepo) CreateAddress(ctx context.Context, address *modles.Address) (*modles.Address, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := clearDefaultAddresses(ctx, tx, address); err != nil {
+		return nil, err
+	}
+
+	var dbo AddressDbo
+	err = tx.QueryRowxContext(ctx, `
+		INSERT INTO user_addresses (user_id, label, recipient_name, phone, governorate, city, district,
+			street, building, floor, apartment, landmark, is_default_shipping, is_default_billing)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		RETURNING `+addressColumns,
+		address.UserID(),
+		nullString(address.Label()),
+		address.RecipientName(),
+		address.Phone(),
+		address.Governorate(),
+		address.City(),
+		address.District(),
+		address.Street(),
+		address.Building(),
+		nullString(address.Floor()),
+		nullString(address.Apartment()),
+		nullString(address.Landmark()),
+		address.IsDefaultShipping(),
+		address.IsDefaultBilling(),
+	).StructScan(&dbo)
+	if err != nil {
+		return nil, fmt.Errorf("error creating address: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing address: %w", err)
+	}
+	return mapAddressToDomain(dbo), nil
+}
+
+func (r *AddressRepo) UpdateAddress(ctx context.Context, address *modles.Address) (*modles.Address, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := clearDefaultAddresses(ctx, tx, address); err != nil {
+		return nil, err
+	}
+
+	var dbo AddressDbo
+	err = tx.QueryRowxContext(ctx, `
+		UPDATE user_addresses
+		SET label = $1,
+			recipient_name = $2,
+			phone = $3,
+			governorate = $4,
+			city = $5,
+			district = $6,
+			street = $7,
+			building = $8,
+			floor = $9,
+			apartment = $10,
+			landmark = $11,
+			is_default_shipping = $12,
+			is_default_billing = $13,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $14 AND user_id = $15
+		RETURNING `+addressColumns,
+		nullString(address.Label()),
+		address.RecipientName(),
+		address.Phone(),
+		address.Governorate(),
+		address.City(),
+		address.District(),
+		address.Street(),
+		address.Building(),
+		nullString(address.Floor()),
+		nullString(address.Apartment()),
+		nullString(address.Landmark()),
+		address.IsDefaultShipping(),
+		address.IsDefaultBilling(),
+		address.ID(),
+		address.UserID(),
+	).StructScan(&dbo)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, common.NewErrorf(modles.AddressNotFoundError, "address not found")
+		}
+		return nil, fmt.Errorf("error updating address: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing address: %w", err)
+	}
+	return mapAddressToDomain(dbo), nil
+}
+
+func (r *AddressRepo) DeleteAddress(ctx context.Context, userID, id int) error {
+	res, err := r.db.ExecContext(ctx, "DELETE FROM user_addresses WHERE id = $1 AND user_id = $2", id, userID)
+	if err != nil {
+		return fmt.Errorf("error deleting address: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error deleting address: %w", err)
+	}
+	if n == 0 {
+		return common.NewErrorf(modles.AddressNotFoundError, "address not found")
+	}
+	return nil
+}
+
+// clearDefaultAddresses removes the default flags that the address takes over
+// from the other addresses of the user.
+func clearDefaultAddresses(ctx context.Context, tx *sqlx.Tx, address *modles.Address) error {
+	if address.IsDefaultShipping() {
+		_, err := tx.ExecContext(ctx, `
+			UPDATE user_addresses SET is_default_shipping = FALSE
+			WHERE user_id = $1 AND id <> $2 AND is_default_shipping`,
+			address.UserID(), address.ID())
+		if err != nil {
+			return fmt.Errorf("error clearing default shipping address: %w", err)
+		}
+	}
+	if address.IsDefaultBilling() {
+		_, err := tx.ExecContext(ctx, `
+			UPDATE user_addresses SET is_default_billing = FALSE
+			WHERE user_id = $1 AND id <> $2 AND is_default_billing`,
+			address.UserID(), address.ID())
+		if err != nil {
+			return fmt.Errorf("error clearing default billing address: %w", err)
+		}
+	}
+	return nil
+}
+
+func mapAddressToDomain(dbo AddressDbo) *modles.Address {
+	return modles.NewAddressFromParams(modles.AddressParams{
+		ID:              dbo.ID,
+		UserID:          dbo.UserID,
+		Label:           dbo.Label.String,
+		RecipientName:   dbo.RecipientName,
+		Phone:           dbo.Phone,
+		Governorate:     dbo.Governorate,
+		City:            dbo.City,
+		District:        dbo.District,
+		Street:          dbo.Street,
+		Building:        dbo.Building,
+		Floor:           dbo.Floor.String,
+		Apartment:       dbo.Apartment.String,
+		Landmark:        dbo.Landmark.String,
+		DefaultShipping: dbo.DefaultShipping,
+		DefaultBilling:  dbo.DefaultBilling,
+		CreatedAt:       dbo.CreatedAt,
+		UpdatedAt:       dbo.UpdatedAt,
+	})
+}
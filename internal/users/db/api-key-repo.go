@@ -0,0 +1,126 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+	"yadwy-backend/internal/common"
+	"yadwy-backend/internal/users/domain/modles"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type APIKeyDbo struct {
+	ID         int            `db:"id"`
+	UserID     int            `db:"user_id"`
+	Name       string         `db:"name"`
+	Prefix     string         `db:"prefix"`
+	KeyHash    string         `db:"key_hash"`
+	Scopes     pq.StringArray `db:"scopes"`
+	LastUsedAt *time.Time     `db:"last_used_at"`
+	ExpiresAt  *time.Time     `db:"expires_at"`
+	RevokedAt  *time.Time     `db:"revoked_at"`
+	CreatedAt  time.Time      `db:"created_at"`
+}
+
+const apiKeyColumns = `id, user_id, name, prefix, key_hash, scopes, last_used_at, expires_at, revoked_at, created_at`
+
+type APIKeyRepo struct {
+	db *sqlx.DB
+}
+
+func NewAPIKeyRepo(db *sqlx.DB) *APIKeyRepo {
+	return &APIKeyRepo{
+		db: db,
+	}
+}
+
+func (r *APIKeyRepo) ListAPIKeys(ctx context.Context, userID int) ([]modles.APIKey, error) {
+	var entities []APIKeyDbo
+	err := r.db.SelectContext(ctx, &entities, `
+		SELECT `+apiKeyColumns+`
+		FROM api_keys
+		WHERE user_id = $1 AND revoked_at IS NULL
+		ORDER BY created_at DESC, id DESC`,
+		userID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing API keys: %w", err)
+	}
+
+	keys := make([]modles.APIKey, 0, len(entities))
+	for _, entity := range entities {
+		keys = append(keys, *mapAPIKeyToDomain(entity))
+	}
+	return keys, nil
+}
+
+func (r *APIKeyRepo) GetAPIKeyByHash(ctx context.Context, keyHash string) (*modles.APIKey, error) {
+	var entity APIKeyDbo
+	err := r.db.GetContext(ctx, &entity, "SELECT "+apiKeyColumns+" FROM api_keys WHERE key_hash = $1", keyHash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error getting API key: %w", err)
+	}
+	return mapAPIKeyToDomain(entity), nil
+}
+
+func (r *APIKeyRepo) CreateAPIKey(ctx context.Context, key *modles.APIKey) (*modles.APIKey, error) {
+	var entity APIKeyDbo
+	err := r.db.QueryRowxContext(ctx, `
+		INSERT INTO api_keys (user_id, name, prefix, key_hash, scopes, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING `+apiKeyColumns,
+		key.UserID(), key.Name(), key.Prefix(), key.KeyHash(), pq.StringArray(key.Scopes()), key.ExpiresAt(),
+	).StructScan(&entity)
+	if err != nil {
+		return nil, fmt.Errorf("error creating API key: %w", err)
+	}
+	return mapAPIKeyToDomain(entity), nil
+}
+
+func (r *APIKeyRepo) RevokeAPIKey(ctx context.Context, userID, id int) error {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE api_keys
+		SET revoked_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL`,
+		id, userID)
+	if err != nil {
+		return fmt.Errorf("error revoking API key: %w", err)
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error revoking API key: %w", err)
+	}
+	if rows == 0 {
+		return common.NewErrorf(modles.APIKeyNotFoundError, "API key not found")
+	}
+	return nil
+}
+
+func (r *APIKeyRepo) TouchAPIKey(ctx context.Context, id int, at time.Time) error {
+	_, err := r.db.ExecContext(ctx, "UPDATE api_keys SET last_used_at = $1 WHERE id = $2", at, id)
+	if err != nil {
+		return fmt.Errorf("error updating API key last use: %w", err)
+	}
+	return nil
+}
+
+func mapAPIKeyToDomain(dbo APIKeyDbo) *modles.APIKey {
+	return modles.NewAPIKeyFromParams(modles.APIKeyParams{
+		ID:         dbo.ID,
+		UserID:     dbo.UserID,
+		Name:       dbo.Name,
+		Prefix:     dbo.Prefix,
+		KeyHash:    dbo.KeyHash,
+		Scopes:     dbo.Scopes,
+		LastUsedAt: dbo.LastUsedAt,
+		ExpiresAt:  dbo.ExpiresAt,
+		RevokedAt:  dbo.RevokedAt,
+		CreatedAt:  dbo.CreatedAt,
+	})
+}
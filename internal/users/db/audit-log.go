@@ -0,0 +1,112 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+	"yadwy-backend/internal/users/domain/modles"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type AuditEntryDbo struct {
+	ID           int           `db:"id"`
+	ActorID      sql.NullInt64 `db:"actor_id"`
+	Action       string        `db:"action"`
+	TargetUserID sql.NullInt64 `db:"target_user_id"`
+	Details      []byte        `db:"details"`
+	CreatedAt    time.Time     `db:"created_at"`
+}
+
+// AuditLog is the Postgres backed contracts.AuditLog.
+type AuditLog struct {
+	db *sqlx.DB
+}
+
+func NewAuditLog(db *sqlx.DB) *AuditLog {
+	return &AuditLog{
+		db: db,
+	}
+}
+
+func (l *AuditLog) RecordAudit(ctx context.Context, entry *modles.AuditEntry) error {
+	return insertAuditEntry(ctx, l.db, entry)
+}
+
+// withAudit runs fn in a transaction that also records the audit entry of
+// the change, unless it is nil.
+func withAudit(ctx context.Context, db *sqlx.DB, entry *modles.AuditEntry, fn func(tx *sqlx.Tx) error) error {
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	if entry != nil {
+		if err := insertAuditEntry(ctx, tx, entry); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func insertAuditEntry(ctx context.Context, exec sqlx.ExecerContext, entry *modles.AuditEntry) error {
+	details := entry.Details()
+	if details == nil {
+		details = map[string]string{}
+	}
+	raw, err := json.Marshal(details)
+	if err != nil {
+		return fmt.Errorf("error encoding audit details: %w", err)
+	}
+
+	_, err = exec.ExecContext(ctx, `
+		INSERT INTO user_audit_log (actor_id, action, target_user_id, details)
+		VALUES ($1, $2, $3, $4)`,
+		nullID(entry.ActorID()), entry.Action(), nullID(entry.TargetUserID()), raw)
+	if err != nil {
+		return fmt.Errorf("error recording audit entry: %w", err)
+	}
+	return nil
+}
+
+func (l *AuditLog) ListAuditEntries(ctx context.Context, targetUserID, limit, offset int) ([]modles.AuditEntry, error) {
+	var dbos []AuditEntryDbo
+	err := l.db.SelectContext(ctx, &dbos, `
+		SELECT id, actor_id, action, target_user_id, details, created_at
+		FROM user_audit_log
+		WHERE target_user_id = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2 OFFSET $3`,
+		targetUserID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("error listing audit entries: %w", err)
+	}
+
+	entries := make([]modles.AuditEntry, 0, len(dbos))
+	for _, dbo := range dbos {
+		var details map[string]string
+		if err := json.Unmarshal(dbo.Details, &details); err != nil {
+			return nil, fmt.Errorf("error decoding audit details: %w", err)
+		}
+		entries = append(entries, *modles.NewAuditEntry(
+			dbo.ID,
+			int(dbo.ActorID.Int64),
+			modles.AuditAction(dbo.Action),
+			int(dbo.TargetUserID.Int64),
+			details,
+			dbo.CreatedAt,
+		))
+	}
+	return entries, nil
+}
+
+// nullID stores 0 as NULL, for references without a user.
+func nullID(id int) sql.NullInt64 {
+	return sql.NullInt64{Int64: int64(id), Valid: id != 0}
+}
@@ -0,0 +1,185 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+	"yadwy-backend/internal/users/domain/modles"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type DataExportDbo struct {
+	ID          int            `db:"id"`
+	UserID      int            `db:"user_id"`
+	Status      string         `db:"status"`
+	FileName    sql.NullString `db:"file_name"`
+	Error       sql.NullString `db:"error"`
+	CreatedAt   time.Time      `db:"created_at"`
+	CompletedAt *time.Time     `db:"completed_at"`
+	ExpiresAt   *time.Time     `db:"expires_at"`
+}
+
+const dataExportColumns = `id, user_id, status, file_name, error, created_at, completed_at, expires_at`
+
+type DataExportRepo struct {
+	db *sqlx.DB
+}
+
+func NewDataExportRepo(db *sqlx.DB) *DataExportRepo {
+	return &DataExportRepo{
+		db: db,
+	}
+}
+
+func (r *DataExportRepo) CreateDataExport(ctx context.Context, export *modles.DataExport, audit *modles.AuditEntry) (*modles.DataExport, error) {
+	var dbo DataExportDbo
+	err := withAudit(ctx, r.db, nil, func(tx *sqlx.Tx) error {
+		err := tx.QueryRowxContext(ctx, `
+			INSERT INTO data_exports (user_id, status)
+			VALUES ($1, $2)
+			RETURNING `+dataExportColumns,
+			export.UserID(), export.Status()).StructScan(&dbo)
+		if err != nil {
+			return fmt.Errorf("error creating data export: %w", err)
+		}
+		if audit != nil {
+			return insertAuditEntry(ctx, tx, audit.WithDetail("export_id", fmt.Sprint(dbo.ID)))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return mapDataExportToDomain(dbo), nil
+}
+
+func (r *DataExportRepo) GetDataExport(ctx context.Context, userID, id int) (*modles.DataExport, error) {
+	var dbo DataExportDbo
+	err := r.db.GetContext(ctx, &dbo,
+		"SELECT "+dataExportColumns+" FROM data_exports WHERE id = $1 AND user_id = $2", id, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error getting data export: %w", err)
+	}
+	return mapDataExportToDomain(dbo), nil
+}
+
+func (r *DataExportRepo) GetActiveDataExport(ctx context.Context, userID int) (*modles.DataExport, error) {
+	var dbo DataExportDbo
+	err := r.db.GetContext(ctx, &dbo, `
+		SELECT `+dataExportColumns+`
+		FROM data_exports
+		WHERE user_id = $1
+		AND status IN ($2, $3)
+		ORDER BY id DESC
+		LIMIT 1`,
+		userID, modles.DataExportPending, modles.DataExportRunning)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error getting active data export: %w", err)
+	}
+	return mapDataExportToDomain(dbo), nil
+}
+
+func (r *DataExportRepo) ListPendingDataExports(ctx context.Context) ([]modles.DataExport, error) {
+	var entities []DataExportDbo
+	err := r.db.SelectContext(ctx, &entities,
+		"SELECT "+dataExportColumns+" FROM data_exports WHERE status = $1 ORDER BY id", modles.DataExportPending)
+	if err != nil {
+		return nil, fmt.Errorf("error listing pending data exports: %w", err)
+	}
+	return mapDataExportsToDomain(entities), nil
+}
+
+func (r *DataExportRepo) ClaimDataExport(ctx context.Context, id int) (bool, error) {
+	res, err := r.db.ExecContext(ctx,
+		"UPDATE data_exports SET status = $1 WHERE id = $2 AND status = $3",
+		modles.DataExportRunning, id, modles.DataExportPending)
+	if err != nil {
+		return false, fmt.Errorf("error claiming data export: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("error claiming data export: %w", err)
+	}
+	return n > 0, nil
+}
+
+func (r *DataExportRepo) CompleteDataExport(ctx context.Context, id int, fileName string, completedAt, expiresAt time.Time, audit *modles.AuditEntry) error {
+	return withAudit(ctx, r.db, audit, func(tx *sqlx.Tx) error {
+		_, err := tx.ExecContext(ctx, `
+			UPDATE data_exports
+			SET status = $1,
+				file_name = $2,
+				completed_at = $3,
+				expires_at = $4
+			WHERE id = $5`,
+			modles.DataExportCompleted, fileName, completedAt, expiresAt, id)
+		if err != nil {
+			return fmt.Errorf("error completing data export: %w", err)
+		}
+		return nil
+	})
+}
+
+func (r *DataExportRepo) FailDataExport(ctx context.Context, id int, reason string, at time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE data_exports
+		SET status = $1,
+			error = $2,
+			completed_at = $3
+		WHERE id = $4`,
+		modles.DataExportFailed, reason, at, id)
+	if err != nil {
+		return fmt.Errorf("error failing data export: %w", err)
+	}
+	return nil
+}
+
+func (r *DataExportRepo) DeleteExpiredDataExports(ctx context.Context, now time.Time) ([]modles.DataExport, error) {
+	var entities []DataExportDbo
+	err := r.db.SelectContext(ctx, &entities,
+		"DELETE FROM data_exports WHERE expires_at <= $1 RETURNING "+dataExportColumns, now)
+	if err != nil {
+		return nil, fmt.Errorf("error deleting expired data exports: %w", err)
+	}
+	return mapDataExportsToDomain(entities), nil
+}
+
+func (r *DataExportRepo) DeleteUserDataExports(ctx context.Context, userID int) ([]modles.DataExport, error) {
+	var entities []DataExportDbo
+	err := r.db.SelectContext(ctx, &entities,
+		"DELETE FROM data_exports WHERE user_id = $1 RETURNING "+dataExportColumns, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error deleting data exports: %w", err)
+	}
+	return mapDataExportsToDomain(entities), nil
+}
+
+func mapDataExportsToDomain(entities []DataExportDbo) []modles.DataExport {
+	exports := make([]modles.DataExport, 0, len(entities))
+	for _, entity := range entities {
+		exports = append(exports, *mapDataExportToDomain(entity))
+	}
+	return exports
+}
+
+func mapDataExportToDomain(dbo DataExportDbo) *modles.DataExport {
+	return modles.NewDataExportFromParams(modles.DataExportParams{
+		ID:          dbo.ID,
+		UserID:      dbo.UserID,
+		Status:      modles.DataExportStatus(dbo.Status),
+		FileName:    dbo.FileName.String,
+		Failure:     dbo.Error.String,
+		CreatedAt:   dbo.CreatedAt,
+		CompletedAt: dbo.CompletedAt,
+		ExpiresAt:   dbo.ExpiresAt,
+	})
+}
@@ -0,0 +1,120 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+	"yadwy-backend/internal/users/domain/modles"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type IdentityDbo struct {
+	ID        int            `db:"id"`
+	UserID    int            `db:"user_id"`
+	Provider  string         `db:"provider"`
+	Subject   string         `db:"subject"`
+	Email     sql.NullString `db:"email"`
+	CreatedAt time.Time      `db:"created_at"`
+}
+
+type OIDCLoginStateDbo struct {
+	StateHash    string    `db:"state_hash"`
+	Provider     string    `db:"provider"`
+	CodeVerifier string    `db:"code_verifier"`
+	Nonce        string    `db:"nonce"`
+	ExpiresAt    time.Time `db:"expires_at"`
+}
+
+type IdentityRepo struct {
+	db *sqlx.DB
+}
+
+func NewIdentityRepo(db *sqlx.DB) *IdentityRepo {
+	return &IdentityRepo{
+		db: db,
+	}
+}
+
+func (r *IdentityRepo) GetIdentity(ctx context.Context, provider, subject string) (*modles.Identity, error) {
+	var dbo IdentityDbo
+	err := r.db.GetContext(ctx, &dbo, `
+		SELECT id, user_id, provider, subject, email, created_at
+		FROM identities
+		WHERE provider = $1 AND subject = $2`,
+		provider, subject)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error getting identity: %w", err)
+	}
+	return modles.NewIdentity(dbo.ID, dbo.UserID, dbo.Provider, dbo.Subject, dbo.Email.String, dbo.CreatedAt), nil
+}
+
+func (r *IdentityRepo) CreateIdentity(ctx context.Context, identity *modles.Identity) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO identities (user_id, provider, subject, email)
+		VALUES ($1, $2, $3, $4)`,
+		identity.UserID(), identity.Provider(), identity.Subject(), nullString(identity.Email()))
+	if err != nil {
+		return fmt.Errorf("error creating identity: %w", err)
+	}
+	return nil
+}
+
+func (r *IdentityRepo) ListIdentities(ctx context.Context, userID int) ([]modles.Identity, error) {
+	var entities []IdentityDbo
+	err := r.db.SelectContext(ctx, &entities, `
+		SELECT id, user_id, provider, subject, email, created_at
+		FROM identities
+		WHERE user_id = $1
+		ORDER BY created_at, id`,
+		userID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing identities: %w", err)
+	}
+
+	identities := make([]modles.Identity, 0, len(entities))
+	for _, dbo := range entities {
+		identities = append(identities, *modles.NewIdentity(dbo.ID, dbo.UserID, dbo.Provider, dbo.Subject, dbo.Email.String, dbo.CreatedAt))
+	}
+	return identities, nil
+}
+
+func (r *IdentityRepo) SaveOIDCLoginState(ctx context.Context, state *modles.OIDCLoginState) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO oidc_login_states (state_hash, provider, code_verifier, nonce, expires_at)
+		VALUES ($1, $2, $3, $4, $5)`,
+		state.StateHash(), state.Provider(), state.CodeVerifier(), state.Nonce(), state.ExpiresAt())
+	if err != nil {
+		return fmt.Errorf("error saving OIDC login state: %w", err)
+	}
+	return nil
+}
+
+func (r *IdentityRepo) ConsumeOIDCLoginState(ctx context.Context, stateHash string) (*modles.OIDCLoginState, error) {
+	var dbo OIDCLoginStateDbo
+	err := r.db.GetContext(ctx, &dbo, `
+		DELETE FROM oidc_login_states
+		WHERE state_hash = $1
+		RETURNING state_hash, provider, code_verifier, nonce, expires_at`,
+		stateHash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error consuming OIDC login state: %w", err)
+	}
+	return modles.NewOIDCLoginState(dbo.StateHash, dbo.Provider, dbo.CodeVerifier, dbo.Nonce, dbo.ExpiresAt), nil
+}
+
+func (r *IdentityRepo) DeleteOIDCLoginStatesBefore(ctx context.Context, before time.Time) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM oidc_login_states WHERE expires_at < $1", before)
+	if err != nil {
+		return fmt.Errorf("error deleting OIDC login states: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+	"yadwy-backend/internal/users/domain/modles"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type LoginAttemptsDbo struct {
+	Key           string    `db:"key"`
+	Failures      int       `db:"failures"`
+	LastFailureAt time.Time `db:"last_failure_at"`
+}
+
+// LoginAttemptStore is the Postgres backed contracts.LoginAttemptStore.
+type LoginAttemptStore struct {
+	db *sqlx.DB
+}
+
+func NewLoginAttemptStore(db *sqlx.DB) *LoginAttemptStore {
+	return &LoginAttemptStore{
+		db: db,
+	}
+}
+
+func (s *LoginAttemptStore) GetLoginAttempts(ctx context.Context, key string) (*modles.LoginAttempts, error) {
+	var dbo LoginAttemptsDbo
+	err := s.db.GetContext(ctx, &dbo, "SELECT * FROM login_attempts WHERE key = $1", key)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error getting login attempts: %w", err)
+	}
+	return modles.NewLoginAttempts(dbo.Failures, dbo.LastFailureAt), nil
+}
+
+func (s *LoginAttemptStore) RecordLoginFailure(ctx context.Context, key string, at, resetBefore time.Time) (*modles.LoginAttempts, error) {
+	var dbo LoginAttemptsDbo
+	err := s.db.GetContext(ctx, &dbo, `
+		INSERT INTO login_attempts (key, failures, last_failure_at)
+		VALUES ($1, 1, $2)
+		ON CONFLICT (key) DO UPDATE
+		SET failures = CASE
+				WHEN login_attempts.last_failure_at < $3 THEN 1
+				ELSE login_attempts.failures + 1
+			END,
+			last_failure_at = EXCLUDED.last_failure_at
+		RETURNING *`,
+		key, at, resetBefore)
+	if err != nil {
+		return nil, fmt.Errorf("error recording login failure: %w", err)
+	}
+	return modles.NewLoginAttempts(dbo.Failures, dbo.LastFailureAt), nil
+}
+
+func (s *LoginAttemptStore) ResetLoginAttempts(ctx context.Context, key string) error {
+	_, err := s.db.ExecContext(ctx, "DELETE FROM login_attempts WHERE key = $1", key)
+	if err != nil {
+		return fmt.Errorf("error resetting login attempts: %w", err)
+	}
+	return nil
+}
+
+func (s *LoginAttemptStore) DeleteLoginAttemptsBefore(ctx context.Context, before time.Time) error {
+	_, err := s.db.ExecContext(ctx, "DELETE FROM login_attempts WHERE last_failure_at < $1", before)
+	if err != nil {
+		return fmt.Errorf("error deleting stale login attempts: %w", err)
+	}
+	return nil
+}
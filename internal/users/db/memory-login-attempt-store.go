@@ -0,0 +1,57 @@
+package db
+
+import (
+	"context"
+	"sync"
+	"time"
+	"yadwy-backend/internal/users/domain/modles"
+)
+
+// MemoryLoginAttemptStore keeps failed login counters in process memory. It is
+// meant for tests and single instance deployments; counters are lost on
+// restart.
+type MemoryLoginAttemptStore struct {
+	mu       sync.Mutex
+	attempts map[string]*modles.LoginAttempts
+}
+
+func NewMemoryLoginAttemptStore() *MemoryLoginAttemptStore {
+	return &MemoryLoginAttemptStore{
+		attempts: map[string]*modles.LoginAttempts{},
+	}
+}
+
+func (s *MemoryLoginAttemptStore) GetLoginAttempts(ctx context.Context, key string) (*modles.LoginAttempts, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.attempts[key], nil
+}
+
+func (s *MemoryLoginAttemptStore) RecordLoginFailure(ctx context.Context, key string, at, resetBefore time.Time) (*modles.LoginAttempts, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	failures := 1
+	if prev, ok := s.attempts[key]; ok && !prev.LastFailureAt().Before(resetBefore) {
+		failures = prev.Failures() + 1
+	}
+	s.attempts[key] = modles.NewLoginAttempts(failures, at)
+	return s.attempts[key], nil
+}
+
+func (s *MemoryLoginAttemptStore) ResetLoginAttempts(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.attempts, key)
+	return nil
+}
+
+func (s *MemoryLoginAttemptStore) DeleteLoginAttemptsBefore(ctx context.Context, before time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, a := range s.attempts {
+		if a.LastFailureAt().Before(before) {
+			delete(s.attempts, key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package db
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type userRevocation struct {
+	revokedAt time.Time
+	expiresAt time.Time
+}
+
+// MemoryTokenRevocationStore keeps the denylist in process memory. It is meant
+// for tests and single instance deployments; entries are lost on restart.
+type MemoryTokenRevocationStore struct {
+	mu     sync.RWMutex
+	tokens map[string]time.Time
+	users  map[int][]userRevocation
+}
+
+func NewMemoryTokenRevocationStore() *MemoryTokenRevocationStore {
+	return &MemoryTokenRevocationStore{
+		tokens: map[string]time.Time{},
+		users:  map[int][]userRevocation{},
+	}
+}
+
+func (s *MemoryTokenRevocationStore) RevokeToken(ctx context.Context, jti string, userID int, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens[jti] = expiresAt
+	return nil
+}
+
+func (s *MemoryTokenRevocationStore) RevokeUserTokens(ctx context.Context, userID int, revokedAt, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.users[userID] = append(s.users[userID], userRevocation{revokedAt: revokedAt, expiresAt: expiresAt})
+	return nil
+}
+
+func (s *MemoryTokenRevocationStore) IsRevoked(ctx context.Context, jti string, userID int, issuedAt time.Time) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if _, ok := s.tokens[jti]; ok {
+		return true, nil
+	}
+	for _, rev := range s.users[userID] {
+		// iat is truncated to the second
+		if rev.revokedAt.Truncate(time.Second).After(issuedAt) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *MemoryTokenRevocationStore) DeleteExpired(ctx context.Context, now time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for jti, expiresAt := range s.tokens {
+		if expiresAt.Before(now) {
+			delete(s.tokens, jti)
+		}
+	}
+	for userID, revs := range s.users {
+		active := revs[:0]
+		for _, rev := range revs {
+			if !rev.expiresAt.Before(now) {
+				active = append(active, rev)
+			}
+		}
+		if len(active) == 0 {
+			delete(s.users, userID)
+		} else {
+			s.users[userID] = active
+		}
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryTokenRevocationStore(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	t.Run("should revoke single token by jti", func(t *testing.T) {
+		store := NewMemoryTokenRevocationStore()
+		_ = store.RevokeToken(ctx, "jti-1", 1, now.Add(time.Minute))
+
+		if revoked, _ := store.IsRevoked(ctx, "jti-1", 1, now); !revoked {
+			t.Errorf("IsRevoked() = false, want true")
+		}
+		if revoked, _ := store.IsRevoked(ctx, "jti-2", 1, now); revoked {
+			t.Errorf("IsRevoked() for other token = true, want false")
+		}
+	})
+
+	t.Run("should revoke user tokens issued before revocation", func(t *testing.T) {
+		store := NewMemoryTokenRevocationStore()
+		_ = store.RevokeUserTokens(ctx, 1, now, now.Add(time.Minute))
+
+		if revoked, _ := store.IsRevoked(ctx, "jti-1", 1, now.Add(-time.Second)); !revoked {
+			t.Errorf("IsRevoked() for older token = false, want true")
+		}
+		if revoked, _ := store.IsRevoked(ctx, "jti-2", 1, now.Add(time.Second)); revoked {
+			t.Errorf("IsRevoked() for newer token = true, want false")
+		}
+		if revoked, _ := store.IsRevoked(ctx, "jti-3", 2, now.Add(-time.Second)); revoked {
+			t.Errorf("IsRevoked() for other user = true, want false")
+		}
+	})
+
+	t.Run("should keep tokens issued within the second of the revocation", func(t *testing.T) {
+		store := NewMemoryTokenRevocationStore()
+		second := now.Truncate(time.Second)
+		_ = store.RevokeUserTokens(ctx, 1, second.Add(400*time.Millisecond), now.Add(time.Minute))
+
+		if revoked, _ := store.IsRevoked(ctx, "jti-1", 1, second); revoked {
+			t.Errorf("IsRevoked() for token issued in the same second = true, want false")
+		}
+		if revoked, _ := store.IsRevoked(ctx, "jti-2", 1, second.Add(-time.Second)); !revoked {
+			t.Errorf("IsRevoked() for token issued the second before = false, want true")
+		}
+	})
+
+	t.Run("should delete expired entries", func(t *testing.T) {
+		store := NewMemoryTokenRevocationStore()
+		_ = store.RevokeToken(ctx, "jti-1", 1, now.Add(-time.Minute))
+		_ = store.RevokeUserTokens(ctx, 2, now.Add(-2*time.Minute), now.Add(-time.Minute))
+		_ = store.RevokeToken(ctx, "jti-3", 3, now.Add(time.Minute))
+
+		if err := store.DeleteExpired(ctx, now); err != nil {
+			t.Fatalf("DeleteExpired() error = %v", err)
+		}
+
+		if len(store.tokens) != 1 || len(store.users) != 0 {
+			t.Errorf("DeleteExpired() left %d tokens and %d users, want 1 and 0", len(store.tokens), len(store.users))
+		}
+	})
+}
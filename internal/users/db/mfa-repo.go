@@ -0,0 +1,126 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+	"yadwy-backend/internal/users/domain/modles"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type MFAEnrollmentDbo struct {
+	UserID       int        `db:"user_id"`
+	Secret       string     `db:"secret"`
+	ConfirmedAt  *time.Time `db:"confirmed_at"`
+	LastUsedStep int64      `db:"last_used_step"`
+}
+
+type MFARepo struct {
+	db *sqlx.DB
+}
+
+func NewMFARepo(db *sqlx.DB) *MFARepo {
+	return &MFARepo{
+		db: db,
+	}
+}
+
+func (r *MFARepo) SaveMFASecret(ctx context.Context, userID int, secret string) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO user_mfa (user_id, secret)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE
+		SET secret = EXCLUDED.secret,
+			last_used_step = 0,
+			created_at = CURRENT_TIMESTAMP
+		WHERE user_mfa.confirmed_at IS NULL`,
+		userID, secret)
+	if err != nil {
+		return fmt.Errorf("error saving MFA secret: %w", err)
+	}
+	return nil
+}
+
+func (r *MFARepo) GetMFAEnrollment(ctx context.Context, userID int) (*modles.MFAEnrollment, error) {
+	var dbo MFAEnrollmentDbo
+	err := r.db.GetContext(ctx, &dbo,
+		"SELECT user_id, secret, confirmed_at, last_used_step FROM user_mfa WHERE user_id = $1", userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error getting MFA enrollment: %w", err)
+	}
+	return modles.NewMFAEnrollment(dbo.UserID, dbo.Secret, dbo.ConfirmedAt, dbo.LastUsedStep), nil
+}
+
+func (r *MFARepo) ConfirmMFA(ctx context.Context, userID int, step int64, recoveryCodeHashes []string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE user_mfa
+		SET confirmed_at = CURRENT_TIMESTAMP,
+			last_used_step = $2
+		WHERE user_id = $1`,
+		userID, step)
+	if err != nil {
+		return fmt.Errorf("error confirming MFA: %w", err)
+	}
+
+	if _, err = tx.ExecContext(ctx, "DELETE FROM user_recovery_codes WHERE user_id = $1", userID); err != nil {
+		return fmt.Errorf("error deleting recovery codes: %w", err)
+	}
+	for _, hash := range recoveryCodeHashes {
+		_, err = tx.ExecContext(ctx,
+			"INSERT INTO user_recovery_codes (user_id, code_hash) VALUES ($1, $2)", userID, hash)
+		if err != nil {
+			return fmt.Errorf("error saving recovery code: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *MFARepo) UseMFAStep(ctx context.Context, userID int, step int64) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE user_mfa
+		SET last_used_step = $2
+		WHERE user_id = $1
+		AND last_used_step < $2`,
+		userID, step)
+	if err != nil {
+		return false, fmt.Errorf("error using MFA step: %w", err)
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("error using MFA step: %w", err)
+	}
+	return rows == 1, nil
+}
+
+func (r *MFARepo) ConsumeRecoveryCode(ctx context.Context, userID int, codeHash string) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE user_recovery_codes
+		SET used_at = CURRENT_TIMESTAMP
+		WHERE user_id = $1
+		AND code_hash = $2
+		AND used_at IS NULL`,
+		userID, codeHash)
+	if err != nil {
+		return false, fmt.Errorf("error consuming recovery code: %w", err)
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("error consuming recovery code: %w", err)
+	}
+	return rows == 1, nil
+}
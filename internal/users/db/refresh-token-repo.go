@@ -0,0 +1,119 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+	"yadwy-backend/internal/common"
+	"yadwy-backend/internal/users/domain/modles"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type RefreshTokenDbo struct {
+	ID        string     `db:"jti"`
+	UserID    int        `db:"user_id"`
+	FamilyID  string     `db:"family_id"`
+	TokenHash string     `db:"token_hash"`
+	ExpiresAt time.Time  `db:"expires_at"`
+	UsedAt    *time.Time `db:"used_at"`
+	RevokedAt *time.Time `db:"revoked_at"`
+	CreatedAt time.Time  `db:"created_at"`
+}
+
+type RefreshTokenRepo struct {
+	db *sqlx.DB
+}
+
+func NewRefreshTokenRepo(db *sqlx.DB) *RefreshTokenRepo {
+	return &RefreshTokenRepo{
+		db: db,
+	}
+}
+
+func (r *RefreshTokenRepo) SaveRefreshToken(ctx context.Context, token *modles.RefreshToken) error {
+	query := `
+		INSERT INTO refresh_tokens (jti, user_id, family_id, token_hash, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		token.ID(),
+		token.UserID(),
+		token.FamilyID(),
+		token.TokenHash(),
+		token.ExpiresAt(),
+	)
+	if err != nil {
+		return fmt.Errorf("error saving refresh token: %w", err)
+	}
+	return nil
+}
+
+func (r *RefreshTokenRepo) GetRefreshToken(ctx context.Context, id string) (*modles.RefreshToken, error) {
+	var dbo RefreshTokenDbo
+	err := r.db.GetContext(ctx, &dbo, "SELECT * FROM refresh_tokens WHERE jti = $1", id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, common.NewErrorf(modles.InvalidRefreshTokenError, "refresh token not found")
+		}
+		return nil, fmt.Errorf("error getting refresh token: %w", err)
+	}
+
+	return modles.NewRefreshToken(
+		dbo.ID,
+		dbo.UserID,
+		dbo.FamilyID,
+		dbo.TokenHash,
+		dbo.ExpiresAt,
+		dbo.UsedAt,
+		dbo.RevokedAt,
+	), nil
+}
+
+func (r *RefreshTokenRepo) MarkRefreshTokenUsed(ctx context.Context, id string) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE refresh_tokens
+		SET used_at = CURRENT_TIMESTAMP
+		WHERE jti = $1
+		AND used_at IS NULL
+		AND revoked_at IS NULL`,
+		id)
+	if err != nil {
+		return false, fmt.Errorf("error marking refresh token used: %w", err)
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("error marking refresh token used: %w", err)
+	}
+	return rows == 1, nil
+}
+
+func (r *RefreshTokenRepo) RevokeTokenFamily(ctx context.Context, familyID string) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE refresh_tokens
+		SET revoked_at = CURRENT_TIMESTAMP
+		WHERE family_id = $1
+		AND revoked_at IS NULL`,
+		familyID)
+	if err != nil {
+		return fmt.Errorf("error revoking refresh token family: %w", err)
+	}
+	return nil
+}
+
+func (r *RefreshTokenRepo) RevokeUserRefreshTokens(ctx context.Context, userID int) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE refresh_tokens
+		SET revoked_at = CURRENT_TIMESTAMP
+		WHERE user_id = $1
+		AND revoked_at IS NULL`,
+		userID)
+	if err != nil {
+		return fmt.Errorf("error revoking user refresh tokens: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type RolePermissionDbo struct {
+	Role       string         `db:"role"`
+	Permission sql.NullString `db:"permission"`
+}
+
+type RolePermissionRepo struct {
+	db *sqlx.DB
+}
+
+func NewRolePermissionRepo(db *sqlx.DB) *RolePermissionRepo {
+	return &RolePermissionRepo{
+		db: db,
+	}
+}
+
+func (r *RolePermissionRepo) ListRolePermissions(ctx context.Context) (map[string][]string, error) {
+	var rows []RolePermissionDbo
+	err := r.db.SelectContext(ctx, &rows, `
+		SELECT r.name AS role, rp.permission
+		FROM roles r
+		LEFT JOIN role_permissions rp ON rp.role = r.name
+		ORDER BY r.name, rp.permission`)
+	if err != nil {
+		return nil, fmt.Errorf("error listing role permissions: %w", err)
+	}
+
+	roles := make(map[string][]string)
+	for _, row := range rows {
+		perms := roles[row.Role]
+		if row.Permission.Valid {
+			perms = append(perms, row.Permission.String)
+		}
+		roles[row.Role] = perms
+	}
+	return roles, nil
+}
@@ -0,0 +1,142 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+	"yadwy-backend/internal/common"
+	"yadwy-backend/internal/users/domain/modles"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type SessionDbo struct {
+	ID           string         `db:"id"`
+	UserID       int            `db:"user_id"`
+	UserAgent    sql.NullString `db:"user_agent"`
+	IP           sql.NullString `db:"ip"`
+	CreatedAt    time.Time      `db:"created_at"`
+	LastSeenAt   time.Time      `db:"last_seen_at"`
+	ExpiresAt    time.Time      `db:"expires_at"`
+	TerminatedAt *time.Time     `db:"terminated_at"`
+}
+
+const sessionColumns = `id, user_id, user_agent, ip, created_at, last_seen_at, expires_at, terminated_at`
+
+type SessionRepo struct {
+	db *sqlx.DB
+}
+
+func NewSessionRepo(db *sqlx.DB) *SessionRepo {
+	return &SessionRepo{
+		db: db,
+	}
+}
+
+func (r *SessionRepo) CreateSession(ctx context.Context, session *modles.Session) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO sessions (id, user_id, user_agent, ip, created_at, last_seen_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		session.ID(), session.UserID(), nullString(session.UserAgent()), nullString(session.IP()),
+		session.CreatedAt(), session.LastSeenAt(), session.ExpiresAt())
+	if err != nil {
+		return fmt.Errorf("error creating session: %w", err)
+	}
+	return nil
+}
+
+func (r *SessionRepo) GetSession(ctx context.Context, id string) (*modles.Session, error) {
+	var dbo SessionDbo
+	err := r.db.GetContext(ctx, &dbo, "SELECT "+sessionColumns+" FROM sessions WHERE id = $1", id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error getting session: %w", err)
+	}
+	return mapSessionToDomain(dbo), nil
+}
+
+func (r *SessionRepo) ListActiveSessions(ctx context.Context, userID int, now time.Time) ([]modles.Session, error) {
+	var entities []SessionDbo
+	err := r.db.SelectContext(ctx, &entities, `
+		SELECT `+sessionColumns+`
+		FROM sessions
+		WHERE user_id = $1
+		AND terminated_at IS NULL
+		AND expires_at > $2
+		ORDER BY last_seen_at DESC`,
+		userID, now)
+	if err != nil {
+		return nil, fmt.Errorf("error listing sessions: %w", err)
+	}
+
+	sessions := make([]modles.Session, 0, len(entities))
+	for _, entity := range entities {
+		sessions = append(sessions, *mapSessionToDomain(entity))
+	}
+	return sessions, nil
+}
+
+func (r *SessionRepo) TouchSession(ctx context.Context, id string, at, expiresAt time.Time) error {
+	var expires *time.Time
+	if !expiresAt.IsZero() {
+		expires = &expiresAt
+	}
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE sessions
+		SET last_seen_at = $1,
+			expires_at = COALESCE($2, expires_at)
+		WHERE id = $3`,
+		at, expires, id)
+	if err != nil {
+		return fmt.Errorf("error touching session: %w", err)
+	}
+	return nil
+}
+
+func (r *SessionRepo) TerminateSession(ctx context.Context, userID int, id string) error {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE sessions
+		SET terminated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND user_id = $2 AND terminated_at IS NULL`,
+		id, userID)
+	if err != nil {
+		return fmt.Errorf("error terminating session: %w", err)
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error terminating session: %w", err)
+	}
+	if rows == 0 {
+		return common.NewErrorf(modles.SessionNotFoundError, "session not found")
+	}
+	return nil
+}
+
+func (r *SessionRepo) TerminateUserSessions(ctx context.Context, userID int) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE sessions
+		SET terminated_at = CURRENT_TIMESTAMP
+		WHERE user_id = $1 AND terminated_at IS NULL`,
+		userID)
+	if err != nil {
+		return fmt.Errorf("error terminating user sessions: %w", err)
+	}
+	return nil
+}
+
+func mapSessionToDomain(dbo SessionDbo) *modles.Session {
+	return modles.NewSessionFromParams(modles.SessionParams{
+		ID:           dbo.ID,
+		UserID:       dbo.UserID,
+		UserAgent:    dbo.UserAgent.String,
+		IP:           dbo.IP.String,
+		CreatedAt:    dbo.CreatedAt,
+		LastSeenAt:   dbo.LastSeenAt,
+		ExpiresAt:    dbo.ExpiresAt,
+		TerminatedAt: dbo.TerminatedAt,
+	})
+}
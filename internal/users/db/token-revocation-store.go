@@ -0,0 +1,66 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// TokenRevocationStore is the Postgres backed contracts.TokenRevocationStore.
+type TokenRevocationStore struct {
+	db *sqlx.DB
+}
+
+func NewTokenRevocationStore(db *sqlx.DB) *TokenRevocationStore {
+	return &TokenRevocationStore{
+		db: db,
+	}
+}
+
+func (s *TokenRevocationStore) RevokeToken(ctx context.Context, jti string, userID int, expiresAt time.Time) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO token_revocations (jti, user_id, revoked_at, expires_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (jti) DO NOTHING`,
+		jti, userID, time.Now(), expiresAt)
+	if err != nil {
+		return fmt.Errorf("error revoking token: %w", err)
+	}
+	return nil
+}
+
+func (s *TokenRevocationStore) RevokeUserTokens(ctx context.Context, userID int, revokedAt, expiresAt time.Time) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO token_revocations (user_id, revoked_at, expires_at)
+		VALUES ($1, $2, $3)`,
+		userID, revokedAt, expiresAt)
+	if err != nil {
+		return fmt.Errorf("error revoking user tokens: %w", err)
+	}
+	return nil
+}
+
+func (s *TokenRevocationStore) IsRevoked(ctx context.Context, jti string, userID int, issuedAt time.Time) (bool, error) {
+	var revoked bool
+	err := s.db.GetContext(ctx, &revoked, `
+		SELECT EXISTS (
+			SELECT 1 FROM token_revocations
+			WHERE jti = $1
+			OR (jti IS NULL AND user_id = $2 AND date_trunc('second', revoked_at) > $3)
+		)`,
+		jti, userID, issuedAt)
+	if err != nil {
+		return false, fmt.Errorf("error checking token revocation: %w", err)
+	}
+	return revoked, nil
+}
+
+func (s *TokenRevocationStore) DeleteExpired(ctx context.Context, now time.Time) error {
+	_, err := s.db.ExecContext(ctx, "DELETE FROM token_revocations WHERE expires_at < $1", now)
+	if err != nil {
+		return fmt.Errorf("error deleting expired token revocations: %w", err)
+	}
+	return nil
+}
@@ -5,19 +5,34 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
+	"yadwy-backend/internal/common"
 	"yadwy-backend/internal/users/domain/modles"
 
 	"github.com/jmoiron/sqlx"
 )
 
 type UserDbo struct {
-	ID       int    `db:"id"`
-	Name     string `db:"name"`
-	Email    string `db:"email"`
-	Password string `db:"password"`
-	Role     string `db:"role"`
+	ID              int            `db:"id"`
+	Name            string         `db:"name"`
+	Email           string         `db:"email"`
+	Password        string         `db:"password"`
+	Role            string         `db:"role"`
+	SellerStatus    sql.NullString `db:"seller_status"`
+	EmailVerifiedAt *time.Time     `db:"email_verified_at"`
+	Phone           sql.NullString `db:"phone"`
+	CreatedAt       time.Time      `db:"created_at"`
+	UpdatedAt       time.Time      `db:"updated_at"`
+	DeactivatedAt   *time.Time     `db:"deactivated_at"`
+	SuspendedAt     *time.Time     `db:"suspended_at"`
+	ErasedAt        *time.Time     `db:"erased_at"`
 }
 
+// userColumns lists the columns mapped by UserDbo.
+const userColumns = `id, name, email, password, role, seller_status, email_verified_at,
+	phone, created_at, updated_at, deactivated_at, suspended_at, erased_at`
+
 type UserRepo struct {
 	db *sqlx.DB
 }
@@ -30,88 +45,143 @@ func NewUserRepo(db *sqlx.DB) *UserRepo {
 
 func (r *UserRepo) UserExists(ctx context.Context, email string) (bool, error) {
 	var count int
-	err := r.db.GetContext(ctx, &count, "SELECT COUNT(1) FROM users WHERE email = $1", email)
+	err := r.db.GetContext(ctx, &count, "SELECT COUNT(1) FROM users WHERE LOWER(email) = LOWER($1)", email)
 	if err != nil {
 		return false, fmt.Errorf("error checking if user exists: %w", err)
 	}
 	return count > 0, nil
 }
 
-func (r *UserRepo) CreateUser(ctx context.Context, user *modles.User) (*modles.User, error) {
+func (r *UserRepo) CreateUser(ctx context.Context, user *modles.User, audit *modles.AuditEntry) (*modles.User, error) {
 	query := `
-        INSERT INTO users (name, email, password, role)
-        VALUES ($1, $2, $3, $4)
-        RETURNING id, name, email, password, role
-    `
+        INSERT INTO users (name, email, password, role, seller_status, email_verified_at)
+        VALUES ($1, $2, $3, $4, $5, $6)
+        RETURNING ` + userColumns
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
 
 	var dbo UserDbo
-	err := r.db.QueryRowContext(ctx, query,
+	err = tx.QueryRowxContext(ctx, query,
 		user.Name(),
 		user.Email(),
 		user.Password(),
 		user.Role(),
-	).Scan(&dbo.ID, &dbo.Name, &dbo.Email, &dbo.Password, &dbo.Role)
+		nullString(user.SellerStatus().String()),
+		user.EmailVerifiedAt(),
+	).StructScan(&dbo)
 
 	if err != nil {
 		return nil, fmt.Errorf("error creating user: %w", err)
 	}
+	if audit != nil {
+		if err := insertAuditEntry(ctx, tx, audit.About(dbo.ID)); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error creating user: %w", err)
+	}
 
 	return mapEntityToDomain(dbo, modles.Role(dbo.Role))
 }
 
+// GetUser finds a user by email regardless of case. Emails are stored as
+// typed, so the oldest account wins when two differ only in case.
 func (r *UserRepo) GetUser(ctx context.Context, email string) (*modles.User, error) {
 	var u UserDbo
-	err := r.db.GetContext(ctx, &u, "SELECT id,name,email,password FROM users WHERE email = $1", email)
+	err := r.db.GetContext(ctx, &u, "SELECT "+userColumns+" FROM users WHERE LOWER(email) = LOWER($1) ORDER BY id LIMIT 1", email)
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, common.NewErrorf(modles.UserNotFoundError, "user not found")
+		}
 		return nil, fmt.Errorf("error getting user: %w", err)
 	}
 
 	return mapEntityToDomain(u, modles.Role(u.Role))
 }
 
-func (r *UserRepo) ListUsers() ([]modles.User, error) {
-	query := `
-		SELECT id, name, email, password
-		FROM users
-	`
+func (r *UserRepo) ListUsers(ctx context.Context, filter modles.UserFilter) ([]modles.User, int, error) {
+	var whereClauses []string
+	var args []interface{}
+	argIndex := 1
+
+	if filter.Role != "" {
+		whereClauses = append(whereClauses, fmt.Sprintf("role = $%d", argIndex))
+		args = append(args, filter.Role)
+		argIndex++
+	}
+
+	if filter.Email != "" {
+		whereClauses = append(whereClauses, fmt.Sprintf("email ILIKE $%d", argIndex))
+		args = append(args, "%"+escapeLike(filter.Email)+"%")
+		argIndex++
+	}
+
+	if filter.CreatedFrom != nil {
+		whereClauses = append(whereClauses, fmt.Sprintf("created_at >= $%d", argIndex))
+		args = append(args, *filter.CreatedFrom)
+		argIndex++
+	}
+
+	if filter.CreatedTo != nil {
+		whereClauses = append(whereClauses, fmt.Sprintf("created_at < $%d", argIndex))
+		args = append(args, *filter.CreatedTo)
+		argIndex++
+	}
+
+	where := ""
+	if len(whereClauses) > 0 {
+		where = " WHERE " + strings.Join(whereClauses, " AND ")
+	}
+
+	var totalCount int
+	if err := r.db.GetContext(ctx, &totalCount, "SELECT COUNT(*) FROM users"+where, args...); err != nil {
+		return nil, 0, fmt.Errorf("error counting users: %w", err)
+	}
+
+	query := "SELECT " + userColumns + " FROM users" + where +
+		fmt.Sprintf(" ORDER BY created_at DESC, id DESC LIMIT $%d OFFSET $%d", argIndex, argIndex+1)
+	args = append(args, filter.Limit, filter.Offset)
 
 	var entities []UserDbo
-	err := r.db.SelectContext(context.Background(), &entities, query)
-	if err != nil {
-		return nil, err
+	if err := r.db.SelectContext(ctx, &entities, query, args...); err != nil {
+		return nil, 0, fmt.Errorf("error listing users: %w", err)
 	}
 
-	// Map database entities to domain models
 	users := make([]modles.User, 0, len(entities))
 	for _, entity := range entities {
 		role := modles.Role(entity.Role)
 		if !role.IsValid() {
-			return nil, errors.New("invalid role found in database: " + entity.Role)
+			return nil, 0, errors.New("invalid role found in database: " + entity.Role)
 		}
 
 		user, err := mapEntityToDomain(entity, role)
 		if err != nil {
-			return nil, err
+			return nil, 0, err
 		}
 		users = append(users, *user)
 	}
 
-	return users, nil
+	return users, totalCount, nil
 }
 
 // GetUserByID retrieves a user by ID
-func (r *UserRepo) GetUserByID(id int) (*modles.User, error) {
+func (r *UserRepo) GetUserByID(ctx context.Context, id int) (*modles.User, error) {
 	query := `
-		SELECT id, name, email, password
+		SELECT ` + userColumns + `
 		FROM users
 		WHERE id = $1
 	`
 
 	var entity UserDbo
-	err := r.db.GetContext(context.Background(), &entity, query, id)
+	err := r.db.GetContext(ctx, &entity, query, id)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, errors.New("user not found")
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, common.NewErrorf(modles.UserNotFoundError, "user not found")
 		}
 		return nil, err
 	}
@@ -124,7 +194,203 @@ func (r *UserRepo) GetUserByID(id int) (*modles.User, error) {
 	return mapEntityToDomain(entity, role)
 }
 
+// ListSellers returns the sellers in the given approval state, oldest first.
+func (r *UserRepo) ListSellers(ctx context.Context, status modles.SellerStatus) ([]modles.User, error) {
+	query := `
+		SELECT ` + userColumns + `
+		FROM users
+		WHERE role = $1 AND seller_status = $2
+		ORDER BY created_at
+	`
+
+	var entities []UserDbo
+	err := r.db.SelectContext(ctx, &entities, query, modles.RoleSeller, status)
+	if err != nil {
+		return nil, fmt.Errorf("error listing sellers: %w", err)
+	}
+
+	users := make([]modles.User, 0, len(entities))
+	for _, entity := range entities {
+		user, err := mapEntityToDomain(entity, modles.Role(entity.Role))
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, *user)
+	}
+	return users, nil
+}
+
+// UpdateSellerStatus persists a seller review along with the reviewing admin.
+func (r *UserRepo) UpdateSellerStatus(ctx context.Context, user *modles.User, reviewerID int, reason string, audit *modles.AuditEntry) error {
+	return withAudit(ctx, r.db, audit, func(tx *sqlx.Tx) error {
+		_, err := tx.ExecContext(ctx, `
+			UPDATE users
+			SET seller_status = $1,
+				seller_reviewed_by = $2,
+				seller_reviewed_at = CURRENT_TIMESTAMP,
+				seller_rejection_reason = $3,
+				updated_at = CURRENT_TIMESTAMP
+			WHERE id = $4`,
+			user.SellerStatus(), reviewerID, nullString(reason), user.ID())
+		if err != nil {
+			return fmt.Errorf("error updating seller status: %w", err)
+		}
+		return nil
+	})
+}
+
+func (r *UserRepo) UpdatePassword(ctx context.Context, userID int, hashedPassword string) error {
+	_, err := r.db.ExecContext(ctx,
+		"UPDATE users SET password = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2",
+		hashedPassword, userID)
+	if err != nil {
+		return fmt.Errorf("error updating password: %w", err)
+	}
+	return nil
+}
+
+// MarkEmailVerified sets the verification time unless the email is already
+// verified.
+func (r *UserRepo) MarkEmailVerified(ctx context.Context, userID int) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE users
+		SET email_verified_at = CURRENT_TIMESTAMP,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+		AND email_verified_at IS NULL`,
+		userID)
+	if err != nil {
+		return fmt.Errorf("error marking email verified: %w", err)
+	}
+	return nil
+}
+
+// UpdateProfile stores the name and phone of the user.
+func (r *UserRepo) UpdateProfile(ctx context.Context, user *modles.User) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE users
+		SET name = $1,
+			phone = $2,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $3`,
+		user.Name(), nullString(user.Phone()), user.ID())
+	if err != nil {
+		return fmt.Errorf("error updating profile: %w", err)
+	}
+	return nil
+}
+
+// DeactivateUser marks the user as deactivated. The row is kept so that
+// orders and audit records still resolve.
+func (r *UserRepo) DeactivateUser(ctx context.Context, userID int) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE users
+		SET deactivated_at = CURRENT_TIMESTAMP,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+		AND deactivated_at IS NULL`,
+		userID)
+	if err != nil {
+		return fmt.Errorf("error deactivating user: %w", err)
+	}
+	return nil
+}
+
+// UpdateRole stores the role and seller status of the user.
+func (r *UserRepo) UpdateRole(ctx context.Context, user *modles.User, audit *modles.AuditEntry) error {
+	return withAudit(ctx, r.db, audit, func(tx *sqlx.Tx) error {
+		_, err := tx.ExecContext(ctx, `
+			UPDATE users
+			SET role = $1,
+				seller_status = $2,
+				updated_at = CURRENT_TIMESTAMP
+			WHERE id = $3`,
+			user.Role(), nullString(user.SellerStatus().String()), user.ID())
+		if err != nil {
+			return fmt.Errorf("error updating role: %w", err)
+		}
+		return nil
+	})
+}
+
+// UpdateSuspension stores whether the user is suspended.
+func (r *UserRepo) UpdateSuspension(ctx context.Context, user *modles.User, audit *modles.AuditEntry) error {
+	return withAudit(ctx, r.db, audit, func(tx *sqlx.Tx) error {
+		_, err := tx.ExecContext(ctx, `
+			UPDATE users
+			SET suspended_at = $1,
+				updated_at = CURRENT_TIMESTAMP
+			WHERE id = $2`,
+			user.SuspendedAt(), user.ID())
+		if err != nil {
+			return fmt.Errorf("error updating suspension: %w", err)
+		}
+		return nil
+	})
+}
+
+// EraseUser overwrites the personal fields of an erased user and deletes the
+// rows holding personal data. Audit entries and orders only reference the ID
+// and are kept.
+func (r *UserRepo) EraseUser(ctx context.Context, user *modles.User, audit *modles.AuditEntry) error {
+	return withAudit(ctx, r.db, audit, func(tx *sqlx.Tx) error {
+		return eraseUser(ctx, tx, user)
+	})
+}
+
+func eraseUser(ctx context.Context, tx *sqlx.Tx, user *modles.User) error {
+	_, err := tx.ExecContext(ctx, `
+		UPDATE users
+		SET name = $1,
+			email = $2,
+			password = $3,
+			phone = NULL,
+			email_verified_at = NULL,
+			seller_rejection_reason = NULL,
+			deactivated_at = $4,
+			erased_at = $5,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $6`,
+		user.Name(), user.Email(), user.Password(), user.DeactivatedAt(), user.ErasedAt(), user.ID())
+	if err != nil {
+		return fmt.Errorf("error erasing user: %w", err)
+	}
+
+	for _, table := range []string{
+		"user_addresses", "identities", "user_recovery_codes", "user_mfa",
+		"api_keys", "sessions", "user_action_tokens",
+	} {
+		if _, err = tx.ExecContext(ctx, "DELETE FROM "+table+" WHERE user_id = $1", user.ID()); err != nil {
+			return fmt.Errorf("error erasing %s: %w", table, err)
+		}
+	}
+	return nil
+}
+
 func mapEntityToDomain(dbo UserDbo, role modles.Role) (*modles.User, error) {
-	user := modles.NewUser(dbo.ID, dbo.Name, dbo.Email, dbo.Password, role)
+	user := modles.NewUserFromParams(modles.UserParams{
+		ID:              dbo.ID,
+		Name:            dbo.Name,
+		Email:           dbo.Email,
+		Password:        dbo.Password,
+		Role:            role,
+		SellerStatus:    modles.SellerStatus(dbo.SellerStatus.String),
+		EmailVerifiedAt: dbo.EmailVerifiedAt,
+		Phone:           dbo.Phone.String,
+		CreatedAt:       dbo.CreatedAt,
+		UpdatedAt:       dbo.UpdatedAt,
+		DeactivatedAt:   dbo.DeactivatedAt,
+		SuspendedAt:     dbo.SuspendedAt,
+		ErasedAt:        dbo.ErasedAt,
+	})
 	return user, nil
 }
+
+// escapeLike escapes the LIKE wildcards so user input matches literally.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
@@ -0,0 +1,77 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"yadwy-backend/internal/users/domain/modles"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+)
+
+func newTestUserRepo(t *testing.T) (*UserRepo, sqlmock.Sqlmock) {
+	t.Helper()
+	conn, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New() error = %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return NewUserRepo(sqlx.NewDb(conn, "sqlmock")), mock
+}
+
+func TestUserRepo_GetUser(t *testing.T) {
+	repo, mock := newTestUserRepo(t)
+	rows := sqlmock.NewRows([]string{"id", "name", "email", "password", "role", "seller_status", "email_verified_at",
+		"phone", "created_at", "updated_at", "deactivated_at", "suspended_at", "erased_at"}).
+		AddRow(7, "Jane Doe", "Jane@Example.com", "hashed", "CUSTOMER", nil, nil, nil, time.Now(), time.Now(), nil, nil, nil)
+	mock.ExpectQuery(`WHERE LOWER\(email\) = LOWER\(\$1\)`).WithArgs("jane@example.com").WillReturnRows(rows)
+
+	user, err := repo.GetUser(context.Background(), "jane@example.com")
+	if err != nil {
+		t.Fatalf("GetUser() error = %v", err)
+	}
+	if user.ID() != 7 {
+		t.Errorf("GetUser() = user %d, want 7", user.ID())
+	}
+}
+
+func TestUserRepo_UpdateSuspension(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	user := modles.NewUserFromParams(modles.UserParams{ID: 7, Role: modles.RoleCustomer, SuspendedAt: &now})
+	audit := modles.NewAuditEntry(0, 1, modles.AuditUserSuspended, 7, nil, now)
+
+	t.Run("should store the change and its audit entry in one transaction", func(t *testing.T) {
+		repo, mock := newTestUserRepo(t)
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE users").WithArgs(user.SuspendedAt(), 7).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO user_audit_log").
+			WithArgs(1, modles.AuditUserSuspended, 7, []byte("{}")).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		if err := repo.UpdateSuspension(ctx, user, audit); err != nil {
+			t.Fatalf("UpdateSuspension() error = %v", err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("should roll back the change when the audit entry fails", func(t *testing.T) {
+		repo, mock := newTestUserRepo(t)
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE users").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO user_audit_log").WillReturnError(errors.New("connection lost"))
+		mock.ExpectRollback()
+
+		if err := repo.UpdateSuspension(ctx, user, audit); err == nil {
+			t.Fatal("UpdateSuspension() error = nil, want error")
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+}
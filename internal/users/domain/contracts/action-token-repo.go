@@ -0,0 +1,20 @@
+package contracts
+
+import (
+	"context"
+	"yadwy-backend/internal/users/domain/modles"
+)
+
+type ActionTokenRepo interface {
+	CreateActionToken(ctx context.Context, token *modles.ActionToken) error
+	GetActionToken(ctx context.Context, purpose modles.TokenPurpose, tokenHash string) (*modles.ActionToken, error)
+	// GetLatestActionToken returns nil when the user has no token for the
+	// purpose.
+	GetLatestActionToken(ctx context.Context, userID int, purpose modles.TokenPurpose) (*modles.ActionToken, error)
+	// ConsumeActionToken marks an unused token as used and reports whether
+	// this call was the one that consumed it.
+	ConsumeActionToken(ctx context.Context, id int) (bool, error)
+	// InvalidateActionTokens marks every unused token of the user for the
+	// purpose as used.
+	InvalidateActionTokens(ctx context.Context, userID int, purpose modles.TokenPurpose) error
+}
@@ -0,0 +1,19 @@
+package contracts
+
+import (
+	"context"
+	"yadwy-backend/internal/users/domain/modles"
+)
+
+// AddressRepo stores address books. Saving an address flagged as a default
+// clears that flag on the other addresses of the user.
+type AddressRepo interface {
+	ListAddresses(ctx context.Context, userID int) ([]modles.Address, error)
+	// GetAddress returns AddressNotFoundError unless the address belongs to
+	// the user.
+	GetAddress(ctx context.Context, userID, id int) (*modles.Address, error)
+	CountAddresses(ctx context.Context, userID int) (int, error)
+	CreateAddress(ctx context.Context, address *modles.Address) (*modles.Address, error)
+	UpdateAddress(ctx context.Context, address *modles.Address) (*modles.Address, error)
+	DeleteAddress(ctx context.Context, userID, id int) error
+}
@@ -0,0 +1,21 @@
+package contracts
+
+import (
+	"context"
+	"time"
+	"yadwy-backend/internal/users/domain/modles"
+)
+
+type APIKeyRepo interface {
+	// ListAPIKeys returns the keys of a user that are not revoked, newest
+	// first.
+	ListAPIKeys(ctx context.Context, userID int) ([]modles.APIKey, error)
+	// GetAPIKeyByHash returns nil when no key has the hash.
+	GetAPIKeyByHash(ctx context.Context, keyHash string) (*modles.APIKey, error)
+	CreateAPIKey(ctx context.Context, key *modles.APIKey) (*modles.APIKey, error)
+	// RevokeAPIKey fails with APIKeyNotFoundError unless the user has an
+	// active key with the ID.
+	RevokeAPIKey(ctx context.Context, userID, id int) error
+	// TouchAPIKey records a use of the key.
+	TouchAPIKey(ctx context.Context, id int, at time.Time) error
+}
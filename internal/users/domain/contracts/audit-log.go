@@ -0,0 +1,12 @@
+package contracts
+
+import (
+	"context"
+	"yadwy-backend/internal/users/domain/modles"
+)
+
+type AuditLog interface {
+	RecordAudit(ctx context.Context, entry *modles.AuditEntry) error
+	// ListAuditEntries returns the entries about a user, newest first.
+	ListAuditEntries(ctx context.Context, targetUserID, limit, offset int) ([]modles.AuditEntry, error)
+}
@@ -0,0 +1,32 @@
+package contracts
+
+import (
+	"context"
+	"time"
+	"yadwy-backend/internal/users/domain/modles"
+)
+
+// DataExportRepo stores data exports. The methods taking an audit entry
+// record it in the same transaction as the change, unless it is nil.
+type DataExportRepo interface {
+	// CreateDataExport records the audit entry with the export_id detail.
+	CreateDataExport(ctx context.Context, export *modles.DataExport, audit *modles.AuditEntry) (*modles.DataExport, error)
+	// GetDataExport returns nil unless the user has an export with the ID.
+	GetDataExport(ctx context.Context, userID, id int) (*modles.DataExport, error)
+	// GetActiveDataExport returns the pending or running export of a user, or
+	// nil when there is none.
+	GetActiveDataExport(ctx context.Context, userID int) (*modles.DataExport, error)
+	// ListPendingDataExports returns the exports waiting to be built, oldest
+	// first.
+	ListPendingDataExports(ctx context.Context) ([]modles.DataExport, error)
+	// ClaimDataExport marks a pending export as running and reports false
+	// when another worker claimed it first.
+	ClaimDataExport(ctx context.Context, id int) (bool, error)
+	CompleteDataExport(ctx context.Context, id int, fileName string, completedAt, expiresAt time.Time, audit *modles.AuditEntry) error
+	FailDataExport(ctx context.Context, id int, reason string, at time.Time) error
+	// DeleteExpiredDataExports deletes the exports that expired before now
+	// and returns them, so their archives can be removed.
+	DeleteExpiredDataExports(ctx context.Context, now time.Time) ([]modles.DataExport, error)
+	// DeleteUserDataExports deletes every export of a user and returns them.
+	DeleteUserDataExports(ctx context.Context, userID int) ([]modles.DataExport, error)
+}
@@ -0,0 +1,21 @@
+package contracts
+
+import (
+	"context"
+	"time"
+	"yadwy-backend/internal/users/domain/modles"
+)
+
+type IdentityRepo interface {
+	// GetIdentity returns nil when the provider subject is not linked.
+	GetIdentity(ctx context.Context, provider, subject string) (*modles.Identity, error)
+	CreateIdentity(ctx context.Context, identity *modles.Identity) error
+	// ListIdentities returns the identities linked to a user, oldest first.
+	ListIdentities(ctx context.Context, userID int) ([]modles.Identity, error)
+	// SaveOIDCLoginState stores a started login until it is consumed.
+	SaveOIDCLoginState(ctx context.Context, state *modles.OIDCLoginState) error
+	// ConsumeOIDCLoginState deletes and returns a started login, or returns
+	// nil when the state is unknown or was already used.
+	ConsumeOIDCLoginState(ctx context.Context, stateHash string) (*modles.OIDCLoginState, error)
+	DeleteOIDCLoginStatesBefore(ctx context.Context, before time.Time) error
+}
@@ -0,0 +1,19 @@
+package contracts
+
+import (
+	"context"
+	"time"
+	"yadwy-backend/internal/users/domain/modles"
+)
+
+// LoginAttemptStore keeps failed login counters keyed by account or client
+// address.
+type LoginAttemptStore interface {
+	// GetLoginAttempts returns nil when the key has no recorded failures.
+	GetLoginAttempts(ctx context.Context, key string) (*modles.LoginAttempts, error)
+	// RecordLoginFailure increments the counter of the key and returns it. A
+	// counter whose last failure is before resetBefore starts over.
+	RecordLoginFailure(ctx context.Context, key string, at, resetBefore time.Time) (*modles.LoginAttempts, error)
+	ResetLoginAttempts(ctx context.Context, key string) error
+	DeleteLoginAttemptsBefore(ctx context.Context, before time.Time) error
+}
@@ -0,0 +1,22 @@
+package contracts
+
+import (
+	"context"
+	"yadwy-backend/internal/users/domain/modles"
+)
+
+type MFARepo interface {
+	// SaveMFASecret starts or restarts an enrollment. A confirmed enrollment
+	// is left untouched.
+	SaveMFASecret(ctx context.Context, userID int, secret string) error
+	// GetMFAEnrollment returns nil when the user never enrolled.
+	GetMFAEnrollment(ctx context.Context, userID int) (*modles.MFAEnrollment, error)
+	// ConfirmMFA enables the enrollment and replaces the recovery codes.
+	ConfirmMFA(ctx context.Context, userID int, step int64, recoveryCodeHashes []string) error
+	// UseMFAStep records the time step of an accepted code and reports false
+	// when that step or a later one was already used.
+	UseMFAStep(ctx context.Context, userID int, step int64) (bool, error)
+	// ConsumeRecoveryCode marks an unused recovery code as used and reports
+	// whether it was found.
+	ConsumeRecoveryCode(ctx context.Context, userID int, codeHash string) (bool, error)
+}
@@ -0,0 +1,77 @@
+package mock
+
+import (
+	"context"
+	"sync"
+	"time"
+	"yadwy-backend/internal/users/domain/modles"
+)
+
+// ActionTokenRepo is an in-memory implementation of contracts.ActionTokenRepo
+type ActionTokenRepo struct {
+	mu     sync.Mutex
+	nextID int
+	tokens map[int]*modles.ActionToken
+}
+
+func NewActionTokenRepo() *ActionTokenRepo {
+	return &ActionTokenRepo{tokens: map[int]*modles.ActionToken{}}
+}
+
+func (m *ActionTokenRepo) CreateActionToken(ctx context.Context, token *modles.ActionToken) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.nextID++
+	m.tokens[m.nextID] = modles.NewActionToken(m.nextID, token.UserID(), token.Purpose(), token.TokenHash(), token.ExpiresAt(), nil, token.CreatedAt())
+	return nil
+}
+
+func (m *ActionTokenRepo) GetActionToken(ctx context.Context, purpose modles.TokenPurpose, tokenHash string) (*modles.ActionToken, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, t := range m.tokens {
+		if t.Purpose() == purpose && t.TokenHash() == tokenHash {
+			return t, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *ActionTokenRepo) GetLatestActionToken(ctx context.Context, userID int, purpose modles.TokenPurpose) (*modles.ActionToken, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var latest *modles.ActionToken
+	for _, t := range m.tokens {
+		if t.UserID() == userID && t.Purpose() == purpose && (latest == nil || t.ID() > latest.ID()) {
+			latest = t
+		}
+	}
+	return latest, nil
+}
+
+func (m *ActionTokenRepo) ConsumeActionToken(ctx context.Context, id int) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t, ok := m.tokens[id]
+	if !ok || !t.IsUsable(time.Now()) {
+		return false, nil
+	}
+	m.use(t)
+	return true, nil
+}
+
+func (m *ActionTokenRepo) InvalidateActionTokens(ctx context.Context, userID int, purpose modles.TokenPurpose) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, t := range m.tokens {
+		if t.UserID() == userID && t.Purpose() == purpose {
+			m.use(t)
+		}
+	}
+	return nil
+}
+
+func (m *ActionTokenRepo) use(t *modles.ActionToken) {
+	now := time.Now()
+	m.tokens[t.ID()] = modles.NewActionToken(t.ID(), t.UserID(), t.Purpose(), t.TokenHash(), t.ExpiresAt(), &now, t.CreatedAt())
+}
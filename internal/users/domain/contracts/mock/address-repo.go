@@ -0,0 +1,131 @@
+package mock
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+	c "yadwy-backend/internal/common"
+	"yadwy-backend/internal/users/domain/modles"
+)
+
+// AddressRepo is an in-memory implementation of contracts.AddressRepo
+type AddressRepo struct {
+	mu        sync.Mutex
+	nextID    int
+	addresses map[int]modles.AddressParams
+}
+
+func NewAddressRepo() *AddressRepo {
+	return &AddressRepo{addresses: map[int]modles.AddressParams{}}
+}
+
+func (m *AddressRepo) ListAddresses(ctx context.Context, userID int) ([]modles.Address, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var res []modles.Address
+	for _, p := range m.addresses {
+		if p.UserID == userID {
+			res = append(res, *modles.NewAddressFromParams(p))
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].ID() < res[j].ID() })
+	return res, nil
+}
+
+func (m *AddressRepo) GetAddress(ctx context.Context, userID, id int) (*modles.Address, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	p, ok := m.addresses[id]
+	if !ok || p.UserID != userID {
+		return nil, c.NewErrorf(modles.AddressNotFoundError, "address not found")
+	}
+	return modles.NewAddressFromParams(p), nil
+}
+
+func (m *AddressRepo) CountAddresses(ctx context.Context, userID int) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	n := 0
+	for _, p := range m.addresses {
+		if p.UserID == userID {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (m *AddressRepo) CreateAddress(ctx context.Context, address *modles.Address) (*modles.Address, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.nextID++
+	p := addressParams(address)
+	p.ID = m.nextID
+	p.CreatedAt = time.Now()
+	p.UpdatedAt = p.CreatedAt
+	m.save(p)
+	return modles.NewAddressFromParams(p), nil
+}
+
+func (m *AddressRepo) UpdateAddress(ctx context.Context, address *modles.Address) (*modles.Address, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	old, ok := m.addresses[address.ID()]
+	if !ok || old.UserID != address.UserID() {
+		return nil, c.NewErrorf(modles.AddressNotFoundError, "address not found")
+	}
+	p := addressParams(address)
+	p.CreatedAt = old.CreatedAt
+	p.UpdatedAt = time.Now()
+	m.save(p)
+	return modles.NewAddressFromParams(p), nil
+}
+
+func (m *AddressRepo) DeleteAddress(ctx context.Context, userID, id int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	p, ok := m.addresses[id]
+	if !ok || p.UserID != userID {
+		return c.NewErrorf(modles.AddressNotFoundError, "address not found")
+	}
+	delete(m.addresses, id)
+	return nil
+}
+
+func (m *AddressRepo) save(p modles.AddressParams) {
+	for id, other := range m.addresses {
+		if other.UserID != p.UserID || id == p.ID {
+			continue
+		}
+		if p.DefaultShipping {
+			other.DefaultShipping = false
+		}
+		if p.DefaultBilling {
+			other.DefaultBilling = false
+		}
+		m.addresses[id] = other
+	}
+	m.addresses[p.ID] = p
+}
+
+func addressParams(a *modles.Address) modles.AddressParams {
+	return modles.AddressParams{
+		ID:              a.ID(),
+		UserID:          a.UserID(),
+		Label:           a.Label(),
+		RecipientName:   a.RecipientName(),
+		Phone:           a.Phone(),
+		Governorate:     a.Governorate(),
+		City:            a.City(),
+		District:        a.District(),
+		Street:          a.Street(),
+		Building:        a.Building(),
+		Floor:           a.Floor(),
+		Apartment:       a.Apartment(),
+		Landmark:        a.Landmark(),
+		DefaultShipping: a.IsDefaultShipping(),
+		DefaultBilling:  a.IsDefaultBilling(),
+		CreatedAt:       a.CreatedAt(),
+		UpdatedAt:       a.UpdatedAt(),
+	}
+}
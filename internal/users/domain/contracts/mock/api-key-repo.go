@@ -0,0 +1,86 @@
+package mock
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+	c "yadwy-backend/internal/common"
+	"yadwy-backend/internal/users/domain/modles"
+)
+
+// APIKeyRepo is an in-memory implementation of contracts.APIKeyRepo
+type APIKeyRepo struct {
+	mu     sync.Mutex
+	nextID int
+	keys   map[int]modles.APIKeyParams
+}
+
+func NewAPIKeyRepo() *APIKeyRepo {
+	return &APIKeyRepo{keys: map[int]modles.APIKeyParams{}}
+}
+
+func (m *APIKeyRepo) ListAPIKeys(ctx context.Context, userID int) ([]modles.APIKey, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var res []modles.APIKey
+	for _, p := range m.keys {
+		if p.UserID == userID && p.RevokedAt == nil {
+			res = append(res, *modles.NewAPIKeyFromParams(p))
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].ID() > res[j].ID() })
+	return res, nil
+}
+
+func (m *APIKeyRepo) GetAPIKeyByHash(ctx context.Context, keyHash string) (*modles.APIKey, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, p := range m.keys {
+		if p.KeyHash == keyHash {
+			return modles.NewAPIKeyFromParams(p), nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *APIKeyRepo) CreateAPIKey(ctx context.Context, key *modles.APIKey) (*modles.APIKey, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.nextID++
+	p := modles.APIKeyParams{
+		ID:        m.nextID,
+		UserID:    key.UserID(),
+		Name:      key.Name(),
+		Prefix:    key.Prefix(),
+		KeyHash:   key.KeyHash(),
+		Scopes:    key.Scopes(),
+		ExpiresAt: key.ExpiresAt(),
+		CreatedAt: time.Now(),
+	}
+	m.keys[p.ID] = p
+	return modles.NewAPIKeyFromParams(p), nil
+}
+
+func (m *APIKeyRepo) RevokeAPIKey(ctx context.Context, userID, id int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	p, ok := m.keys[id]
+	if !ok || p.UserID != userID || p.RevokedAt != nil {
+		return c.NewErrorf(modles.APIKeyNotFoundError, "API key not found")
+	}
+	now := time.Now()
+	p.RevokedAt = &now
+	m.keys[id] = p
+	return nil
+}
+
+func (m *APIKeyRepo) TouchAPIKey(ctx context.Context, id int, at time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if p, ok := m.keys[id]; ok {
+		p.LastUsedAt = &at
+		m.keys[id] = p
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package mock
+
+import (
+	"context"
+	"sync"
+	"time"
+	"yadwy-backend/internal/users/domain/modles"
+)
+
+// AuditLog is an in-memory implementation of contracts.AuditLog
+type AuditLog struct {
+	mu      sync.Mutex
+	Entries []modles.AuditEntry
+}
+
+func (m *AuditLog) RecordAudit(ctx context.Context, entry *modles.AuditEntry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Entries = append(m.Entries, *modles.NewAuditEntry(
+		len(m.Entries)+1, entry.ActorID(), entry.Action(), entry.TargetUserID(), entry.Details(), time.Now()))
+	return nil
+}
+
+func (m *AuditLog) ListAuditEntries(ctx context.Context, targetUserID, limit, offset int) ([]modles.AuditEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var res []modles.AuditEntry
+	for i := len(m.Entries) - 1; i >= 0; i-- {
+		if m.Entries[i].TargetUserID() == targetUserID {
+			res = append(res, m.Entries[i])
+		}
+	}
+	if offset >= len(res) {
+		return nil, nil
+	}
+	res = res[offset:]
+	if limit > 0 && limit < len(res) {
+		res = res[:limit]
+	}
+	return res, nil
+}
@@ -0,0 +1,138 @@
+package mock
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+	"yadwy-backend/internal/users/domain/modles"
+)
+
+// DataExportRepo is an in-memory implementation of contracts.DataExportRepo
+type DataExportRepo struct {
+	mu      sync.Mutex
+	nextID  int
+	exports map[int]modles.DataExportParams
+
+	// Audit receives the audit entries of the changes when set.
+	Audit *AuditLog
+}
+
+func NewDataExportRepo() *DataExportRepo {
+	return &DataExportRepo{exports: map[int]modles.DataExportParams{}}
+}
+
+func (m *DataExportRepo) CreateDataExport(ctx context.Context, export *modles.DataExport, audit *modles.AuditEntry) (*modles.DataExport, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.nextID++
+	p := modles.DataExportParams{
+		ID:        m.nextID,
+		UserID:    export.UserID(),
+		Status:    export.Status(),
+		CreatedAt: time.Now(),
+	}
+	m.exports[p.ID] = p
+	if audit != nil && m.Audit != nil {
+		m.Audit.RecordAudit(ctx, audit.WithDetail("export_id", fmt.Sprint(p.ID)))
+	}
+	return modles.NewDataExportFromParams(p), nil
+}
+
+func (m *DataExportRepo) GetDataExport(ctx context.Context, userID, id int) (*modles.DataExport, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	p, ok := m.exports[id]
+	if !ok || p.UserID != userID {
+		return nil, nil
+	}
+	return modles.NewDataExportFromParams(p), nil
+}
+
+func (m *DataExportRepo) GetActiveDataExport(ctx context.Context, userID int) (*modles.DataExport, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, p := range m.exports {
+		export := modles.NewDataExportFromParams(p)
+		if p.UserID == userID && export.InProgress() {
+			return export, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *DataExportRepo) ListPendingDataExports(ctx context.Context) ([]modles.DataExport, error) {
+	return m.list(func(p modles.DataExportParams) bool { return p.Status == modles.DataExportPending }), nil
+}
+
+func (m *DataExportRepo) ClaimDataExport(ctx context.Context, id int) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	p, ok := m.exports[id]
+	if !ok || p.Status != modles.DataExportPending {
+		return false, nil
+	}
+	p.Status = modles.DataExportRunning
+	m.exports[id] = p
+	return true, nil
+}
+
+func (m *DataExportRepo) CompleteDataExport(ctx context.Context, id int, fileName string, completedAt, expiresAt time.Time, audit *modles.AuditEntry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if p, ok := m.exports[id]; ok {
+		p.Status = modles.DataExportCompleted
+		p.FileName = fileName
+		p.CompletedAt = &completedAt
+		p.ExpiresAt = &expiresAt
+		m.exports[id] = p
+	}
+	if audit != nil && m.Audit != nil {
+		m.Audit.RecordAudit(ctx, audit)
+	}
+	return nil
+}
+
+func (m *DataExportRepo) FailDataExport(ctx context.Context, id int, reason string, at time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if p, ok := m.exports[id]; ok {
+		p.Status = modles.DataExportFailed
+		p.Failure = reason
+		p.CompletedAt = &at
+		m.exports[id] = p
+	}
+	return nil
+}
+
+func (m *DataExportRepo) DeleteExpiredDataExports(ctx context.Context, now time.Time) ([]modles.DataExport, error) {
+	return m.delete(func(p modles.DataExportParams) bool { return p.ExpiresAt != nil && !now.Before(*p.ExpiresAt) }), nil
+}
+
+func (m *DataExportRepo) DeleteUserDataExports(ctx context.Context, userID int) ([]modles.DataExport, error) {
+	return m.delete(func(p modles.DataExportParams) bool { return p.UserID == userID }), nil
+}
+
+func (m *DataExportRepo) list(match func(modles.DataExportParams) bool) []modles.DataExport {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var res []modles.DataExport
+	for _, p := range m.exports {
+		if match(p) {
+			res = append(res, *modles.NewDataExportFromParams(p))
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].ID() < res[j].ID() })
+	return res
+}
+
+func (m *DataExportRepo) delete(match func(modles.DataExportParams) bool) []modles.DataExport {
+	res := m.list(match)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, export := range res {
+		delete(m.exports, export.ID())
+	}
+	return res
+}
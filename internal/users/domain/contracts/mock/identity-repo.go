@@ -0,0 +1,76 @@
+package mock
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+	"yadwy-backend/internal/users/domain/modles"
+)
+
+// IdentityRepo is an in-memory implementation of contracts.IdentityRepo
+type IdentityRepo struct {
+	mu         sync.Mutex
+	identities map[string]*modles.Identity
+	states     map[string]*modles.OIDCLoginState
+}
+
+func NewIdentityRepo() *IdentityRepo {
+	return &IdentityRepo{
+		identities: map[string]*modles.Identity{},
+		states:     map[string]*modles.OIDCLoginState{},
+	}
+}
+
+func (m *IdentityRepo) GetIdentity(ctx context.Context, provider, subject string) (*modles.Identity, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.identities[provider+"|"+subject], nil
+}
+
+func (m *IdentityRepo) CreateIdentity(ctx context.Context, identity *modles.Identity) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.identities[identity.Provider()+"|"+identity.Subject()] = modles.NewIdentity(
+		len(m.identities)+1, identity.UserID(), identity.Provider(), identity.Subject(), identity.Email(), time.Now())
+	return nil
+}
+
+func (m *IdentityRepo) ListIdentities(ctx context.Context, userID int) ([]modles.Identity, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var res []modles.Identity
+	for _, identity := range m.identities {
+		if identity.UserID() == userID {
+			res = append(res, *identity)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].ID() < res[j].ID() })
+	return res, nil
+}
+
+func (m *IdentityRepo) SaveOIDCLoginState(ctx context.Context, state *modles.OIDCLoginState) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.states[state.StateHash()] = state
+	return nil
+}
+
+func (m *IdentityRepo) ConsumeOIDCLoginState(ctx context.Context, stateHash string) (*modles.OIDCLoginState, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	state := m.states[stateHash]
+	delete(m.states, stateHash)
+	return state, nil
+}
+
+func (m *IdentityRepo) DeleteOIDCLoginStatesBefore(ctx context.Context, before time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for hash, state := range m.states {
+		if state.ExpiresAt().Before(before) {
+			delete(m.states, hash)
+		}
+	}
+	return nil
+}
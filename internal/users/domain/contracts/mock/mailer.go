@@ -0,0 +1,25 @@
+package mock
+
+import (
+	"context"
+	"sync"
+	"yadwy-backend/internal/common"
+)
+
+// Mailer records sent mail instead of delivering it
+type Mailer struct {
+	mu   sync.Mutex
+	Sent []common.Mail
+	// Err fails every Send when set.
+	Err error
+}
+
+func (m *Mailer) Send(ctx context.Context, mail common.Mail) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.Err != nil {
+		return m.Err
+	}
+	m.Sent = append(m.Sent, mail)
+	return nil
+}
@@ -0,0 +1,72 @@
+package mock
+
+import (
+	"context"
+	"sync"
+	"time"
+	"yadwy-backend/internal/users/domain/modles"
+)
+
+// MFARepo is an in-memory implementation of contracts.MFARepo
+type MFARepo struct {
+	mu            sync.Mutex
+	enrollments   map[int]*modles.MFAEnrollment
+	recoveryCodes map[int]map[string]bool
+}
+
+func NewMFARepo() *MFARepo {
+	return &MFARepo{
+		enrollments:   map[int]*modles.MFAEnrollment{},
+		recoveryCodes: map[int]map[string]bool{},
+	}
+}
+
+func (m *MFARepo) SaveMFASecret(ctx context.Context, userID int, secret string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if e, ok := m.enrollments[userID]; ok && e.IsConfirmed() {
+		return nil
+	}
+	m.enrollments[userID] = modles.NewMFAEnrollment(userID, secret, nil, 0)
+	return nil
+}
+
+func (m *MFARepo) GetMFAEnrollment(ctx context.Context, userID int) (*modles.MFAEnrollment, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.enrollments[userID], nil
+}
+
+func (m *MFARepo) ConfirmMFA(ctx context.Context, userID int, step int64, recoveryCodeHashes []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	m.enrollments[userID] = modles.NewMFAEnrollment(userID, m.enrollments[userID].Secret(), &now, step)
+	m.recoveryCodes[userID] = map[string]bool{}
+	for _, hash := range recoveryCodeHashes {
+		m.recoveryCodes[userID][hash] = false
+	}
+	return nil
+}
+
+func (m *MFARepo) UseMFAStep(ctx context.Context, userID int, step int64) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e := m.enrollments[userID]
+	if e == nil || e.LastUsedStep() >= step {
+		return false, nil
+	}
+	m.enrollments[userID] = modles.NewMFAEnrollment(userID, e.Secret(), e.ConfirmedAt(), step)
+	return true, nil
+}
+
+func (m *MFARepo) ConsumeRecoveryCode(ctx context.Context, userID int, codeHash string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	used, ok := m.recoveryCodes[userID][codeHash]
+	if !ok || used {
+		return false, nil
+	}
+	m.recoveryCodes[userID][codeHash] = true
+	return true, nil
+}
@@ -0,0 +1,69 @@
+package mock
+
+import (
+	"context"
+	"sync"
+	"time"
+	"yadwy-backend/internal/common"
+	"yadwy-backend/internal/users/domain/modles"
+)
+
+// RefreshTokenRepo is an in-memory implementation of contracts.RefreshTokenRepo
+type RefreshTokenRepo struct {
+	mu     sync.Mutex
+	tokens map[string]*modles.RefreshToken
+}
+
+func NewRefreshTokenRepo() *RefreshTokenRepo {
+	return &RefreshTokenRepo{tokens: map[string]*modles.RefreshToken{}}
+}
+
+func (m *RefreshTokenRepo) SaveRefreshToken(ctx context.Context, token *modles.RefreshToken) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tokens[token.ID()] = token
+	return nil
+}
+
+func (m *RefreshTokenRepo) GetRefreshToken(ctx context.Context, id string) (*modles.RefreshToken, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t, ok := m.tokens[id]
+	if !ok {
+		return nil, common.NewErrorf(modles.InvalidRefreshTokenError, "refresh token not found")
+	}
+	return t, nil
+}
+
+func (m *RefreshTokenRepo) MarkRefreshTokenUsed(ctx context.Context, id string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t, ok := m.tokens[id]
+	if !ok || t.IsUsed() || t.IsRevoked() {
+		return false, nil
+	}
+	now := time.Now()
+	m.tokens[id] = modles.NewRefreshToken(t.ID(), t.UserID(), t.FamilyID(), t.TokenHash(), t.ExpiresAt(), &now, nil)
+	return true, nil
+}
+
+func (m *RefreshTokenRepo) RevokeTokenFamily(ctx context.Context, familyID string) error {
+	return m.revokeWhere(func(t *modles.RefreshToken) bool { return t.FamilyID() == familyID })
+}
+
+func (m *RefreshTokenRepo) RevokeUserRefreshTokens(ctx context.Context, userID int) error {
+	return m.revokeWhere(func(t *modles.RefreshToken) bool { return t.UserID() == userID })
+}
+
+func (m *RefreshTokenRepo) revokeWhere(match func(t *modles.RefreshToken) bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	for id, t := range m.tokens {
+		if match(t) && !t.IsRevoked() {
+			// revoking keeps the use of a token, as the Postgres repo does
+			m.tokens[id] = modles.NewRefreshToken(t.ID(), t.UserID(), t.FamilyID(), t.TokenHash(), t.ExpiresAt(), t.UsedAt(), &now)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package mock
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+	c "yadwy-backend/internal/common"
+	"yadwy-backend/internal/users/domain/modles"
+)
+
+// SessionRepo is an in-memory implementation of contracts.SessionRepo
+type SessionRepo struct {
+	mu       sync.Mutex
+	sessions map[string]modles.SessionParams
+}
+
+func NewSessionRepo() *SessionRepo {
+	return &SessionRepo{sessions: map[string]modles.SessionParams{}}
+}
+
+func (m *SessionRepo) CreateSession(ctx context.Context, session *modles.Session) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sessions[session.ID()] = modles.SessionParams{
+		ID:         session.ID(),
+		UserID:     session.UserID(),
+		UserAgent:  session.UserAgent(),
+		IP:         session.IP(),
+		CreatedAt:  session.CreatedAt(),
+		LastSeenAt: session.LastSeenAt(),
+		ExpiresAt:  session.ExpiresAt(),
+	}
+	return nil
+}
+
+func (m *SessionRepo) GetSession(ctx context.Context, id string) (*modles.Session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	p, ok := m.sessions[id]
+	if !ok {
+		return nil, nil
+	}
+	return modles.NewSessionFromParams(p), nil
+}
+
+func (m *SessionRepo) ListActiveSessions(ctx context.Context, userID int, now time.Time) ([]modles.Session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var res []modles.Session
+	for _, p := range m.sessions {
+		session := modles.NewSessionFromParams(p)
+		if p.UserID == userID && session.IsActive(now) {
+			res = append(res, *session)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].LastSeenAt().After(res[j].LastSeenAt()) })
+	return res, nil
+}
+
+func (m *SessionRepo) TouchSession(ctx context.Context, id string, at, expiresAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if p, ok := m.sessions[id]; ok {
+		p.LastSeenAt = at
+		if !expiresAt.IsZero() {
+			p.ExpiresAt = expiresAt
+		}
+		m.sessions[id] = p
+	}
+	return nil
+}
+
+func (m *SessionRepo) TerminateSession(ctx context.Context, userID int, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	p, ok := m.sessions[id]
+	if !ok || p.UserID != userID || p.TerminatedAt != nil {
+		return c.NewErrorf(modles.SessionNotFoundError, "session not found")
+	}
+	now := time.Now()
+	p.TerminatedAt = &now
+	m.sessions[id] = p
+	return nil
+}
+
+func (m *SessionRepo) TerminateUserSessions(ctx context.Context, userID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	for id, p := range m.sessions {
+		if p.UserID == userID && p.TerminatedAt == nil {
+			p.TerminatedAt = &now
+			m.sessions[id] = p
+		}
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package mock
+
+import (
+	"context"
+	"yadwy-backend/internal/users/domain/modles"
+)
+
+// UserRepo is a simple mock implementation of contracts.UserRepo
+type UserRepo struct {
+	CreateUserFunc         func(ctx context.Context, user *modles.User) (*modles.User, error)
+	ListUsersFunc          func(ctx context.Context, filter modles.UserFilter) ([]modles.User, int, error)
+	GetUserFunc            func(ctx context.Context, email string) (*modles.User, error)
+	GetUserByIDFunc        func(ctx context.Context, id int) (*modles.User, error)
+	UserExistsFunc         func(ctx context.Context, email string) (bool, error)
+	ListSellersFunc        func(ctx context.Context, status modles.SellerStatus) ([]modles.User, error)
+	UpdateSellerStatusFunc func(ctx context.Context, user *modles.User, reviewerID int, reason string) error
+	UpdatePasswordFunc     func(ctx context.Context, userID int, hashedPassword string) error
+	MarkEmailVerifiedFunc  func(ctx context.Context, userID int) error
+	UpdateProfileFunc      func(ctx context.Context, user *modles.User) error
+	DeactivateUserFunc     func(ctx context.Context, userID int) error
+	UpdateRoleFunc         func(ctx context.Context, user *modles.User) error
+	UpdateSuspensionFunc   func(ctx context.Context, user *modles.User) error
+	EraseUserFunc          func(ctx context.Context, user *modles.User) error
+
+	// Audit receives the audit entries of successful changes when set.
+	Audit *AuditLog
+}
+
+func (m *UserRepo) record(ctx context.Context, audit *modles.AuditEntry, err error) error {
+	if err == nil && audit != nil && m.Audit != nil {
+		return m.Audit.RecordAudit(ctx, audit)
+	}
+	return err
+}
+
+func (m *UserRepo) CreateUser(ctx context.Context, user *modles.User, audit *modles.AuditEntry) (*modles.User, error) {
+	saved := user
+	if m.CreateUserFunc != nil {
+		var err error
+		if saved, err = m.CreateUserFunc(ctx, user); err != nil {
+			return nil, err
+		}
+	}
+	if audit != nil {
+		audit = audit.About(saved.ID())
+	}
+	return saved, m.record(ctx, audit, nil)
+}
+
+func (m *UserRepo) ListUsers(ctx context.Context, filter modles.UserFilter) ([]modles.User, int, error) {
+	if m.ListUsersFunc != nil {
+		return m.ListUsersFunc(ctx, filter)
+	}
+	return nil, 0, nil
+}
+
+func (m *UserRepo) GetUser(ctx context.Context, email string) (*modles.User, error) {
+	if m.GetUserFunc != nil {
+		return m.GetUserFunc(ctx, email)
+	}
+	return nil, nil
+}
+
+func (m *UserRepo) GetUserByID(ctx context.Context, id int) (*modles.User, error) {
+	if m.GetUserByIDFunc != nil {
+		return m.GetUserByIDFunc(ctx, id)
+	}
+	return nil, nil
+}
+
+func (m *UserRepo) UserExists(ctx context.Context, email string) (bool, error) {
+	if m.UserExistsFunc != nil {
+		return m.UserExistsFunc(ctx, email)
+	}
+	return false, nil
+}
+
+func (m *UserRepo) ListSellers(ctx context.Context, status modles.SellerStatus) ([]modles.User, error) {
+	if m.ListSellersFunc != nil {
+		return m.ListSellersFunc(ctx, status)
+	}
+	return nil, nil
+}
+
+func (m *UserRepo) UpdateSellerStatus(ctx context.Context, user *modles.User, reviewerID int, reason string, audit *modles.AuditEntry) error {
+	if m.UpdateSellerStatusFunc != nil {
+		return m.record(ctx, audit, m.UpdateSellerStatusFunc(ctx, user, reviewerID, reason))
+	}
+	return m.record(ctx, audit, nil)
+}
+
+func (m *UserRepo) UpdatePassword(ctx context.Context, userID int, hashedPassword string) error {
+	if m.UpdatePasswordFunc != nil {
+		return m.UpdatePasswordFunc(ctx, userID, hashedPassword)
+	}
+	return nil
+}
+
+func (m *UserRepo) MarkEmailVerified(ctx context.Context, userID int) error {
+	if m.MarkEmailVerifiedFunc != nil {
+		return m.MarkEmailVerifiedFunc(ctx, userID)
+	}
+	return nil
+}
+
+func (m *UserRepo) UpdateProfile(ctx context.Context, user *modles.User) error {
+	if m.UpdateProfileFunc != nil {
+		return m.UpdateProfileFunc(ctx, user)
+	}
+	return nil
+}
+
+func (m *UserRepo) DeactivateUser(ctx context.Context, userID int) error {
+	if m.DeactivateUserFunc != nil {
+		return m.DeactivateUserFunc(ctx, userID)
+	}
+	return nil
+}
+
+func (m *UserRepo) UpdateRole(ctx context.Context, user *modles.User, audit *modles.AuditEntry) error {
+	if m.UpdateRoleFunc != nil {
+		return m.record(ctx, audit, m.UpdateRoleFunc(ctx, user))
+	}
+	return m.record(ctx, audit, nil)
+}
+
+func (m *UserRepo) UpdateSuspension(ctx context.Context, user *modles.User, audit *modles.AuditEntry) error {
+	if m.UpdateSuspensionFunc != nil {
+		return m.record(ctx, audit, m.UpdateSuspensionFunc(ctx, user))
+	}
+	return m.record(ctx, audit, nil)
+}
+
+func (m *UserRepo) EraseUser(ctx context.Context, user *modles.User, audit *modles.AuditEntry) error {
+	if m.EraseUserFunc != nil {
+		return m.record(ctx, audit, m.EraseUserFunc(ctx, user))
+	}
+	return m.record(ctx, audit, nil)
+}
@@ -0,0 +1,16 @@
+package contracts
+
+import (
+	"context"
+	"yadwy-backend/internal/users/domain/modles"
+)
+
+type RefreshTokenRepo interface {
+	SaveRefreshToken(ctx context.Context, token *modles.RefreshToken) error
+	GetRefreshToken(ctx context.Context, id string) (*modles.RefreshToken, error)
+	// MarkRefreshTokenUsed flags an active token as used and reports whether
+	// this call was the one that consumed it.
+	MarkRefreshTokenUsed(ctx context.Context, id string) (bool, error)
+	RevokeTokenFamily(ctx context.Context, familyID string) error
+	RevokeUserRefreshTokens(ctx context.Context, userID int) error
+}
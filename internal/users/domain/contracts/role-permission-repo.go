@@ -0,0 +1,9 @@
+package contracts
+
+import "context"
+
+type RolePermissionRepo interface {
+	// ListRolePermissions returns the permissions of every role, including
+	// roles without any permission.
+	ListRolePermissions(ctx context.Context) (map[string][]string, error)
+}
@@ -0,0 +1,23 @@
+package contracts
+
+import (
+	"context"
+	"time"
+	"yadwy-backend/internal/users/domain/modles"
+)
+
+type SessionRepo interface {
+	CreateSession(ctx context.Context, session *modles.Session) error
+	// GetSession returns nil when there is no session with the ID.
+	GetSession(ctx context.Context, id string) (*modles.Session, error)
+	// ListActiveSessions returns the sessions of a user that are neither
+	// terminated nor expired, most recently seen first.
+	ListActiveSessions(ctx context.Context, userID int, now time.Time) ([]modles.Session, error)
+	// TouchSession records activity on the session. A zero expiresAt keeps
+	// the current expiry.
+	TouchSession(ctx context.Context, id string, at, expiresAt time.Time) error
+	// TerminateSession fails with SessionNotFoundError unless the user has an
+	// active session with the ID.
+	TerminateSession(ctx context.Context, userID int, id string) error
+	TerminateUserSessions(ctx context.Context, userID int) error
+}
@@ -0,0 +1,19 @@
+package contracts
+
+import (
+	"context"
+	"time"
+)
+
+// TokenRevocationStore is a denylist of access tokens keyed by jti and user.
+// Entries only need to live until the tokens they cover would have expired.
+type TokenRevocationStore interface {
+	// RevokeToken denies a single token until expiresAt.
+	RevokeToken(ctx context.Context, jti string, userID int, expiresAt time.Time) error
+	// RevokeUserTokens denies every token of the user issued before revokedAt.
+	// Issue times have second precision, so tokens issued within the second
+	// of revokedAt are kept, rather than denying the tokens of a new login.
+	RevokeUserTokens(ctx context.Context, userID int, revokedAt, expiresAt time.Time) error
+	IsRevoked(ctx context.Context, jti string, userID int, issuedAt time.Time) (bool, error)
+	DeleteExpired(ctx context.Context, now time.Time) error
+}
@@ -5,9 +5,28 @@ import (
 	"yadwy-backend/internal/users/domain/modles"
 )
 
+// UserRepo stores users. The methods taking an audit entry record it in the
+// same transaction as the change, unless it is nil.
 type UserRepo interface {
-	CreateUser(ctx context.Context, user *modles.User) (*modles.User, error)
-	ListUsers() ([]modles.User, error)
+	// CreateUser records the audit entry about the created user.
+	CreateUser(ctx context.Context, user *modles.User, audit *modles.AuditEntry) (*modles.User, error)
+	// ListUsers returns a page of users, newest first, and the number of
+	// users matching the filter.
+	ListUsers(ctx context.Context, filter modles.UserFilter) ([]modles.User, int, error)
+	// GetUser and UserExists match emails regardless of case.
 	GetUser(ctx context.Context, email string) (*modles.User, error)
+	GetUserByID(ctx context.Context, id int) (*modles.User, error)
 	UserExists(ctx context.Context, email string) (bool, error)
+	ListSellers(ctx context.Context, status modles.SellerStatus) ([]modles.User, error)
+	UpdateSellerStatus(ctx context.Context, user *modles.User, reviewerID int, reason string, audit *modles.AuditEntry) error
+	UpdatePassword(ctx context.Context, userID int, hashedPassword string) error
+	MarkEmailVerified(ctx context.Context, userID int) error
+	UpdateProfile(ctx context.Context, user *modles.User) error
+	DeactivateUser(ctx context.Context, userID int) error
+	UpdateRole(ctx context.Context, user *modles.User, audit *modles.AuditEntry) error
+	UpdateSuspension(ctx context.Context, user *modles.User, audit *modles.AuditEntry) error
+	// EraseUser stores an erased user and, in the same transaction, deletes
+	// the addresses, identities, MFA enrollment, API keys, sessions and
+	// action tokens of the user.
+	EraseUser(ctx context.Context, user *modles.User, audit *modles.AuditEntry) error
 }
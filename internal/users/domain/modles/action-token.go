@@ -0,0 +1,64 @@
+package modles
+
+import "time"
+
+// TokenPurpose tells what a single-use action token can be redeemed for.
+type TokenPurpose string
+
+const (
+	PasswordResetPurpose     TokenPurpose = "PASSWORD_RESET"
+	EmailVerificationPurpose TokenPurpose = "EMAIL_VERIFICATION"
+)
+
+// ActionToken is a random, single-use and time-limited token sent to a user
+// to confirm an action out of band. Only the hash of the token is stored.
+type ActionToken struct {
+	id        int
+	userID    int
+	purpose   TokenPurpose
+	tokenHash string
+	expiresAt time.Time
+	usedAt    *time.Time
+	createdAt time.Time
+}
+
+func NewActionToken(id, userID int, purpose TokenPurpose, tokenHash string, expiresAt time.Time, usedAt *time.Time, createdAt time.Time) *ActionToken {
+	return &ActionToken{
+		id:        id,
+		userID:    userID,
+		purpose:   purpose,
+		tokenHash: tokenHash,
+		expiresAt: expiresAt,
+		usedAt:    usedAt,
+		createdAt: createdAt,
+	}
+}
+
+func (t *ActionToken) ID() int {
+	return t.id
+}
+
+func (t *ActionToken) UserID() int {
+	return t.userID
+}
+
+func (t *ActionToken) Purpose() TokenPurpose {
+	return t.purpose
+}
+
+func (t *ActionToken) TokenHash() string {
+	return t.tokenHash
+}
+
+func (t *ActionToken) ExpiresAt() time.Time {
+	return t.expiresAt
+}
+
+func (t *ActionToken) CreatedAt() time.Time {
+	return t.createdAt
+}
+
+// IsUsable reports whether the token has neither been used nor expired.
+func (t *ActionToken) IsUsable(now time.Time) bool {
+	return t.usedAt == nil && now.Before(t.expiresAt)
+}
@@ -0,0 +1,235 @@
+package modles
+
+import (
+	"regexp"
+	"strings"
+	"time"
+	c "yadwy-backend/internal/common"
+)
+
+// phonePattern accepts international numbers and local Egyptian mobile
+// numbers such as 01001234567.
+var phonePattern = regexp.MustCompile(`^(\+[1-9][0-9]{7,14}|01[0125][0-9]{8})$`)
+
+// Address is a delivery or billing address from the address book of a user.
+type Address struct {
+	id              int
+	userID          int
+	label           string
+	recipientName   string
+	phone           string
+	governorate     string
+	city            string
+	district        string
+	street          string
+	building        string
+	floor           string
+	apartment       string
+	landmark        string
+	defaultShipping bool
+	defaultBilling  bool
+	createdAt       time.Time
+	updatedAt       time.Time
+}
+
+// AddressParams holds every address attribute. NewAddress validates them and
+// NewAddressFromParams rebuilds an Address from storage.
+type AddressParams struct {
+	ID              int
+	UserID          int
+	Label           string
+	RecipientName   string
+	Phone           string
+	Governorate     string
+	City            string
+	District        string
+	Street          string
+	Building        string
+	Floor           string
+	Apartment       string
+	Landmark        string
+	DefaultShipping bool
+	DefaultBilling  bool
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+// AddressSnapshot is a copy of an address taken when it is used, for example
+// by an order. Later edits or removal of the address do not change it.
+type AddressSnapshot struct {
+	AddressID     int    `json:"address_id,omitempty"`
+	RecipientName string `json:"recipient_name"`
+	Phone         string `json:"phone"`
+	Governorate   string `json:"governorate"`
+	City          string `json:"city"`
+	District      string `json:"district"`
+	Street        string `json:"street"`
+	Building      string `json:"building"`
+	Floor         string `json:"floor,omitempty"`
+	Apartment     string `json:"apartment,omitempty"`
+	Landmark      string `json:"landmark,omitempty"`
+}
+
+// NewAddress creates an address after validating the structured fields.
+// Surrounding spaces are trimmed.
+func NewAddress(p AddressParams) (*Address, error) {
+	a := NewAddressFromParams(p)
+	if err := a.normalize(); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func NewAddressFromParams(p AddressParams) *Address {
+	return &Address{
+		id:              p.ID,
+		userID:          p.UserID,
+		label:           p.Label,
+		recipientName:   p.RecipientName,
+		phone:           p.Phone,
+		governorate:     p.Governorate,
+		city:            p.City,
+		district:        p.District,
+		street:          p.Street,
+		building:        p.Building,
+		floor:           p.Floor,
+		apartment:       p.Apartment,
+		landmark:        p.Landmark,
+		defaultShipping: p.DefaultShipping,
+		defaultBilling:  p.DefaultBilling,
+		createdAt:       p.CreatedAt,
+		updatedAt:       p.UpdatedAt,
+	}
+}
+
+// Update replaces the address fields and default flags, keeping the owner.
+func (a *Address) Update(p AddressParams) error {
+	updated := NewAddressFromParams(p)
+	updated.id = a.id
+	updated.userID = a.userID
+	updated.createdAt = a.createdAt
+	updated.updatedAt = a.updatedAt
+	if err := updated.normalize(); err != nil {
+		return err
+	}
+	*a = *updated
+	return nil
+}
+
+func (a *Address) normalize() error {
+	for _, f := range []*string{
+		&a.label, &a.recipientName, &a.phone, &a.governorate, &a.city, &a.district,
+		&a.street, &a.building, &a.floor, &a.apartment, &a.landmark,
+	} {
+		*f = strings.TrimSpace(*f)
+	}
+
+	required := []struct {
+		name  string
+		value string
+	}{
+		{"recipient_name", a.recipientName},
+		{"phone", a.phone},
+		{"governorate", a.governorate},
+		{"city", a.city},
+		{"district", a.district},
+		{"street", a.street},
+		{"building", a.building},
+	}
+	for _, f := range required {
+		if f.value == "" {
+			return c.NewErrorf(InvalidAddressError, "%s is required", f.name)
+		}
+	}
+
+	a.phone = strings.NewReplacer(" ", "", "-", "").Replace(a.phone)
+	if !phonePattern.MatchString(a.phone) {
+		return c.NewErrorf(InvalidAddressError, "phone %q is not a valid phone number", a.phone)
+	}
+	return nil
+}
+
+// Snapshot copies the address for an order.
+func (a *Address) Snapshot() AddressSnapshot {
+	return AddressSnapshot{
+		AddressID:     a.id,
+		RecipientName: a.recipientName,
+		Phone:         a.phone,
+		Governorate:   a.governorate,
+		City:          a.city,
+		District:      a.district,
+		Street:        a.street,
+		Building:      a.building,
+		Floor:         a.floor,
+		Apartment:     a.apartment,
+		Landmark:      a.landmark,
+	}
+}
+
+func (a *Address) ID() int {
+	return a.id
+}
+
+func (a *Address) UserID() int {
+	return a.userID
+}
+
+func (a *Address) Label() string {
+	return a.label
+}
+
+func (a *Address) RecipientName() string {
+	return a.recipientName
+}
+
+func (a *Address) Phone() string {
+	return a.phone
+}
+
+func (a *Address) Governorate() string {
+	return a.governorate
+}
+
+func (a *Address) City() string {
+	return a.city
+}
+
+func (a *Address) District() string {
+	return a.district
+}
+
+func (a *Address) Street() string {
+	return a.street
+}
+
+func (a *Address) Building() string {
+	return a.building
+}
+
+func (a *Address) Floor() string {
+	return a.floor
+}
+
+func (a *Address) Apartment() string {
+	return a.apartment
+}
+
+func (a *Address) Landmark() string {
+	return a.landmark
+}
+
+func (a *Address) IsDefaultShipping() bool {
+	return a.defaultShipping
+}
+
+func (a *Address) IsDefaultBilling() bool {
+	return a.defaultBilling
+}
+
+func (a *Address) CreatedAt() time.Time {
+	return a.createdAt
+}
+
+func (a *Address) UpdatedAt() time.Time {
+	return a.updatedAt
+}
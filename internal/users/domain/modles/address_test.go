@@ -0,0 +1,75 @@
+package modles
+
+import (
+	"errors"
+	"testing"
+
+	c "yadwy-backend/internal/common"
+)
+
+func validAddressParams() AddressParams {
+	return AddressParams{
+		UserID:        1,
+		RecipientName: " John Doe ",
+		Phone:         "010 0123 4567",
+		Governorate:   "Cairo",
+		City:          "Nasr City",
+		District:      "8th District",
+		Street:        "Abbas El Akkad St.",
+		Building:      "12",
+	}
+}
+
+func TestNewAddress(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(p *AddressParams)
+		wantErr bool
+	}{
+		{name: "should accept a local mobile number", modify: func(p *AddressParams) {}},
+		{name: "should accept an international number", modify: func(p *AddressParams) { p.Phone = "+201001234567" }},
+		{name: "should reject a short phone", modify: func(p *AddressParams) { p.Phone = "12345" }, wantErr: true},
+		{name: "should reject a blank street", modify: func(p *AddressParams) { p.Street = "  " }, wantErr: true},
+		{name: "should reject a missing governorate", modify: func(p *AddressParams) { p.Governorate = "" }, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := validAddressParams()
+			tt.modify(&p)
+
+			_, err := NewAddress(p)
+			if tt.wantErr {
+				var appErr *c.Error
+				if !errors.As(err, &appErr) || appErr.Code() != InvalidAddressError {
+					t.Errorf("NewAddress() error = %v, want %v", err, InvalidAddressError)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("NewAddress() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestAddress_Snapshot(t *testing.T) {
+	a, err := NewAddress(validAddressParams())
+	if err != nil {
+		t.Fatalf("NewAddress() error = %v", err)
+	}
+
+	snapshot := a.Snapshot()
+	if snapshot.RecipientName != "John Doe" || snapshot.Phone != "01001234567" {
+		t.Errorf("Snapshot() = %+v, want trimmed name and phone", snapshot)
+	}
+
+	p := validAddressParams()
+	p.Street = "Makram Ebeid St."
+	if err := a.Update(p); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if snapshot.Street != "Abbas El Akkad St." {
+		t.Errorf("Snapshot() street = %q after update, want the original street", snapshot.Street)
+	}
+}
@@ -0,0 +1,113 @@
+package modles
+
+import (
+	"slices"
+	"time"
+)
+
+// APIKey lets a user call the API from another server. Only a hash of the
+// key is stored, the prefix identifies the key in listings.
+type APIKey struct {
+	id         int
+	userID     int
+	name       string
+	prefix     string
+	keyHash    string
+	scopes     []string
+	lastUsedAt *time.Time
+	expiresAt  *time.Time
+	revokedAt  *time.Time
+	createdAt  time.Time
+}
+
+// APIKeyParams holds every API key attribute, used to rebuild an APIKey from
+// storage.
+type APIKeyParams struct {
+	ID         int
+	UserID     int
+	Name       string
+	Prefix     string
+	KeyHash    string
+	Scopes     []string
+	LastUsedAt *time.Time
+	ExpiresAt  *time.Time
+	RevokedAt  *time.Time
+	CreatedAt  time.Time
+}
+
+func NewAPIKey(userID int, name, prefix, keyHash string, scopes []string, expiresAt *time.Time) *APIKey {
+	return &APIKey{
+		userID:    userID,
+		name:      name,
+		prefix:    prefix,
+		keyHash:   keyHash,
+		scopes:    scopes,
+		expiresAt: expiresAt,
+	}
+}
+
+func NewAPIKeyFromParams(p APIKeyParams) *APIKey {
+	return &APIKey{
+		id:         p.ID,
+		userID:     p.UserID,
+		name:       p.Name,
+		prefix:     p.Prefix,
+		keyHash:    p.KeyHash,
+		scopes:     p.Scopes,
+		lastUsedAt: p.LastUsedAt,
+		expiresAt:  p.ExpiresAt,
+		revokedAt:  p.RevokedAt,
+		createdAt:  p.CreatedAt,
+	}
+}
+
+func (k *APIKey) ID() int {
+	return k.id
+}
+
+func (k *APIKey) UserID() int {
+	return k.userID
+}
+
+func (k *APIKey) Name() string {
+	return k.name
+}
+
+func (k *APIKey) Prefix() string {
+	return k.prefix
+}
+
+func (k *APIKey) KeyHash() string {
+	return k.keyHash
+}
+
+// Scopes are the permissions the key may use, a subset of those of the
+// owner's role.
+func (k *APIKey) Scopes() []string {
+	return k.scopes
+}
+
+func (k *APIKey) HasScope(scope string) bool {
+	return slices.Contains(k.scopes, scope)
+}
+
+func (k *APIKey) LastUsedAt() *time.Time {
+	return k.lastUsedAt
+}
+
+func (k *APIKey) ExpiresAt() *time.Time {
+	return k.expiresAt
+}
+
+func (k *APIKey) RevokedAt() *time.Time {
+	return k.revokedAt
+}
+
+func (k *APIKey) CreatedAt() time.Time {
+	return k.createdAt
+}
+
+// IsUsable reports whether the key is neither revoked nor expired.
+func (k *APIKey) IsUsable(now time.Time) bool {
+	return k.revokedAt == nil && (k.expiresAt == nil || now.Before(*k.expiresAt))
+}
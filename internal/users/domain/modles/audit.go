@@ -0,0 +1,88 @@
+package modles
+
+import "time"
+
+// AuditAction names an action recorded in the user audit log.
+type AuditAction string
+
+const (
+	AuditAccountCreated  AuditAction = "account_created"
+	AuditSellerApproved  AuditAction = "seller_approved"
+	AuditSellerRejected  AuditAction = "seller_rejected"
+	AuditUserUnlocked    AuditAction = "user_unlocked"
+	AuditRoleChanged     AuditAction = "role_changed"
+	AuditUserSuspended   AuditAction = "user_suspended"
+	AuditUserReactivated AuditAction = "user_reactivated"
+	AuditSessionsRevoked AuditAction = "sessions_revoked"
+	// AuditDataExportRequested and AuditDataExported track personal data
+	// exports, AuditAccountErased a right to erasure request.
+	AuditDataExportRequested AuditAction = "data_export_requested"
+	AuditDataExported        AuditAction = "data_exported"
+	AuditAccountErased       AuditAction = "account_erased"
+)
+
+// AuditEntry records an action taken on a user account and who took it. The
+// actor is 0 for actions run outside of a request, such as the CLI.
+type AuditEntry struct {
+	id           int
+	actorID      int
+	action       AuditAction
+	targetUserID int
+	details      map[string]string
+	createdAt    time.Time
+}
+
+func NewAuditEntry(id, actorID int, action AuditAction, targetUserID int, details map[string]string, createdAt time.Time) *AuditEntry {
+	return &AuditEntry{
+		id:           id,
+		actorID:      actorID,
+		action:       action,
+		targetUserID: targetUserID,
+		details:      details,
+		createdAt:    createdAt,
+	}
+}
+
+func (e *AuditEntry) ID() int {
+	return e.id
+}
+
+func (e *AuditEntry) ActorID() int {
+	return e.actorID
+}
+
+func (e *AuditEntry) Action() AuditAction {
+	return e.action
+}
+
+func (e *AuditEntry) TargetUserID() int {
+	return e.targetUserID
+}
+
+func (e *AuditEntry) Details() map[string]string {
+	return e.details
+}
+
+func (e *AuditEntry) CreatedAt() time.Time {
+	return e.createdAt
+}
+
+// About returns a copy of the entry about another user, for entries on a
+// user that is not stored yet.
+func (e *AuditEntry) About(targetUserID int) *AuditEntry {
+	c := *e
+	c.targetUserID = targetUserID
+	return &c
+}
+
+// WithDetail returns a copy of the entry with one more detail, for details
+// only known once the change is stored.
+func (e *AuditEntry) WithDetail(key, value string) *AuditEntry {
+	c := *e
+	c.details = make(map[string]string, len(e.details)+1)
+	for k, v := range e.details {
+		c.details[k] = v
+	}
+	c.details[key] = value
+	return &c
+}
@@ -0,0 +1,104 @@
+package modles
+
+import "time"
+
+// DataExportStatus is the progress of a personal data export.
+type DataExportStatus string
+
+const (
+	DataExportPending   DataExportStatus = "pending"
+	DataExportRunning   DataExportStatus = "running"
+	DataExportCompleted DataExportStatus = "completed"
+	DataExportFailed    DataExportStatus = "failed"
+)
+
+// DataExport is a request of a user for an archive of their personal data. It
+// is built in the background and can be downloaded until it expires.
+type DataExport struct {
+	id          int
+	userID      int
+	status      DataExportStatus
+	fileName    string
+	failure     string
+	createdAt   time.Time
+	completedAt *time.Time
+	expiresAt   *time.Time
+}
+
+// DataExportParams holds every data export attribute, used to rebuild a
+// DataExport from storage.
+type DataExportParams struct {
+	ID          int
+	UserID      int
+	Status      DataExportStatus
+	FileName    string
+	Failure     string
+	CreatedAt   time.Time
+	CompletedAt *time.Time
+	ExpiresAt   *time.Time
+}
+
+func NewDataExport(userID int) *DataExport {
+	return &DataExport{
+		userID: userID,
+		status: DataExportPending,
+	}
+}
+
+func NewDataExportFromParams(p DataExportParams) *DataExport {
+	return &DataExport{
+		id:          p.ID,
+		userID:      p.UserID,
+		status:      p.Status,
+		fileName:    p.FileName,
+		failure:     p.Failure,
+		createdAt:   p.CreatedAt,
+		completedAt: p.CompletedAt,
+		expiresAt:   p.ExpiresAt,
+	}
+}
+
+func (e *DataExport) ID() int {
+	return e.id
+}
+
+func (e *DataExport) UserID() int {
+	return e.userID
+}
+
+func (e *DataExport) Status() DataExportStatus {
+	return e.status
+}
+
+// FileName is the name of the archive in the export directory.
+func (e *DataExport) FileName() string {
+	return e.fileName
+}
+
+// Failure tells why a failed export could not be built.
+func (e *DataExport) Failure() string {
+	return e.failure
+}
+
+func (e *DataExport) CreatedAt() time.Time {
+	return e.createdAt
+}
+
+func (e *DataExport) CompletedAt() *time.Time {
+	return e.completedAt
+}
+
+func (e *DataExport) ExpiresAt() *time.Time {
+	return e.expiresAt
+}
+
+// InProgress tells the export is still waiting for or being built.
+func (e *DataExport) InProgress() bool {
+	return e.status == DataExportPending || e.status == DataExportRunning
+}
+
+// IsDownloadable tells the archive is built and not expired.
+func (e *DataExport) IsDownloadable(now time.Time) bool {
+	return e.status == DataExportCompleted && e.fileName != "" &&
+		(e.expiresAt == nil || now.Before(*e.expiresAt))
+}
@@ -5,9 +5,42 @@ import (
 )
 
 const (
-	UserNotFoundError           c.ErrorCode = "user_not_found"
-	EmailAlreadyExistsError     c.ErrorCode = "email_already_exists"
-	InvalidUserCredentialsError c.ErrorCode = "invalid_user_credentials"
-	InvalidUserRoleError        c.ErrorCode = "invalid_user_role"
-	UserAlreadyExistsError      c.ErrorCode = "user_already_exists"
+	UserNotFoundError             c.ErrorCode = "user_not_found"
+	EmailAlreadyExistsError       c.ErrorCode = "email_already_exists"
+	InvalidUserCredentialsError   c.ErrorCode = "invalid_user_credentials"
+	InvalidUserRoleError          c.ErrorCode = "invalid_user_role"
+	UserAlreadyExistsError        c.ErrorCode = "user_already_exists"
+	InvalidRefreshTokenError      c.ErrorCode = "invalid_refresh_token"
+	RefreshTokenReusedError       c.ErrorCode = "refresh_token_reused"
+	UserNotSellerError            c.ErrorCode = "user_not_seller"
+	InvalidSellerStatusError      c.ErrorCode = "invalid_seller_status"
+	InvalidResetTokenError        c.ErrorCode = "invalid_reset_token"
+	InvalidVerificationTokenError c.ErrorCode = "invalid_verification_token"
+	EmailAlreadyVerifiedError     c.ErrorCode = "email_already_verified"
+	VerificationThrottledError    c.ErrorCode = "verification_throttled"
+	TooManyLoginAttemptsError     c.ErrorCode = "too_many_login_attempts"
+	InvalidMFATokenError          c.ErrorCode = "invalid_mfa_token"
+	InvalidMFACodeError           c.ErrorCode = "invalid_mfa_code"
+	MFAAlreadyEnabledError        c.ErrorCode = "mfa_already_enabled"
+	MFANotEnrolledError           c.ErrorCode = "mfa_not_enrolled"
+	InvalidProfileError           c.ErrorCode = "invalid_profile"
+	InvalidCurrentPasswordError   c.ErrorCode = "invalid_current_password"
+	UserSuspendedError            c.ErrorCode = "user_suspended"
+	InvalidAccountStatusError     c.ErrorCode = "invalid_account_status"
+	CannotManageOwnAccountError   c.ErrorCode = "cannot_manage_own_account"
+	AddressNotFoundError          c.ErrorCode = "address_not_found"
+	InvalidAddressError           c.ErrorCode = "invalid_address"
+	AddressLimitReachedError      c.ErrorCode = "address_limit_reached"
+	UnknownOIDCProviderError      c.ErrorCode = "unknown_oidc_provider"
+	InvalidOIDCStateError         c.ErrorCode = "invalid_oidc_state"
+	OIDCLoginFailedError          c.ErrorCode = "oidc_login_failed"
+	OIDCEmailNotVerifiedError     c.ErrorCode = "oidc_email_not_verified"
+	APIKeyNotFoundError           c.ErrorCode = "api_key_not_found"
+	InvalidAPIKeyError            c.ErrorCode = "invalid_api_key"
+	InvalidAPIKeyScopeError       c.ErrorCode = "invalid_api_key_scope"
+	APIKeyLimitReachedError       c.ErrorCode = "api_key_limit_reached"
+	SessionNotFoundError          c.ErrorCode = "session_not_found"
+	DataExportNotFoundError       c.ErrorCode = "data_export_not_found"
+	DataExportInProgressError     c.ErrorCode = "data_export_in_progress"
+	DataExportNotReadyError       c.ErrorCode = "data_export_not_ready"
 )
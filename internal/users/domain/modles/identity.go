@@ -0,0 +1,95 @@
+package modles
+
+import "time"
+
+// Identity links an account at an external OpenID Connect provider to a user.
+type Identity struct {
+	id        int
+	userID    int
+	provider  string
+	subject   string
+	email     string
+	createdAt time.Time
+}
+
+func NewIdentity(id, userID int, provider, subject, email string, createdAt time.Time) *Identity {
+	return &Identity{
+		id:        id,
+		userID:    userID,
+		provider:  provider,
+		subject:   subject,
+		email:     email,
+		createdAt: createdAt,
+	}
+}
+
+func (i *Identity) ID() int {
+	return i.id
+}
+
+func (i *Identity) UserID() int {
+	return i.userID
+}
+
+func (i *Identity) Provider() string {
+	return i.provider
+}
+
+// Subject is the stable user ID at the provider.
+func (i *Identity) Subject() string {
+	return i.subject
+}
+
+// Email is the provider email when the identity was linked.
+func (i *Identity) Email() string {
+	return i.email
+}
+
+func (i *Identity) CreatedAt() time.Time {
+	return i.createdAt
+}
+
+// OIDCLoginState is a started authorization code flow, kept until the
+// provider redirects back with the state.
+type OIDCLoginState struct {
+	stateHash    string
+	provider     string
+	codeVerifier string
+	nonce        string
+	expiresAt    time.Time
+}
+
+func NewOIDCLoginState(stateHash, provider, codeVerifier, nonce string, expiresAt time.Time) *OIDCLoginState {
+	return &OIDCLoginState{
+		stateHash:    stateHash,
+		provider:     provider,
+		codeVerifier: codeVerifier,
+		nonce:        nonce,
+		expiresAt:    expiresAt,
+	}
+}
+
+func (s *OIDCLoginState) StateHash() string {
+	return s.stateHash
+}
+
+func (s *OIDCLoginState) Provider() string {
+	return s.provider
+}
+
+// CodeVerifier is the PKCE secret sent when redeeming the code.
+func (s *OIDCLoginState) CodeVerifier() string {
+	return s.codeVerifier
+}
+
+func (s *OIDCLoginState) Nonce() string {
+	return s.nonce
+}
+
+func (s *OIDCLoginState) ExpiresAt() time.Time {
+	return s.expiresAt
+}
+
+func (s *OIDCLoginState) IsExpired(now time.Time) bool {
+	return !now.Before(s.expiresAt)
+}
@@ -0,0 +1,25 @@
+package modles
+
+import "time"
+
+// LoginAttempts counts the consecutive failed logins for an account or a
+// client address.
+type LoginAttempts struct {
+	failures      int
+	lastFailureAt time.Time
+}
+
+func NewLoginAttempts(failures int, lastFailureAt time.Time) *LoginAttempts {
+	return &LoginAttempts{
+		failures:      failures,
+		lastFailureAt: lastFailureAt,
+	}
+}
+
+func (a *LoginAttempts) Failures() int {
+	return a.failures
+}
+
+func (a *LoginAttempts) LastFailureAt() time.Time {
+	return a.lastFailureAt
+}
@@ -0,0 +1,42 @@
+package modles
+
+import "time"
+
+// MFAEnrollment is the TOTP second factor of a user. It only protects logins
+// once confirmed with a valid code.
+type MFAEnrollment struct {
+	userID       int
+	secret       string
+	confirmedAt  *time.Time
+	lastUsedStep int64
+}
+
+func NewMFAEnrollment(userID int, secret string, confirmedAt *time.Time, lastUsedStep int64) *MFAEnrollment {
+	return &MFAEnrollment{
+		userID:       userID,
+		secret:       secret,
+		confirmedAt:  confirmedAt,
+		lastUsedStep: lastUsedStep,
+	}
+}
+
+func (m *MFAEnrollment) UserID() int {
+	return m.userID
+}
+
+func (m *MFAEnrollment) Secret() string {
+	return m.secret
+}
+
+func (m *MFAEnrollment) ConfirmedAt() *time.Time {
+	return m.confirmedAt
+}
+
+// LastUsedStep is the TOTP time step of the last accepted code.
+func (m *MFAEnrollment) LastUsedStep() int64 {
+	return m.lastUsedStep
+}
+
+func (m *MFAEnrollment) IsConfirmed() bool {
+	return m.confirmedAt != nil
+}
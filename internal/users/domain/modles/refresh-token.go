@@ -0,0 +1,64 @@
+package modles
+
+import "time"
+
+// RefreshToken is the server-side record of an issued refresh token. Tokens
+// issued from the same login share a family so that reuse of a rotated token
+// can revoke every descendant.
+type RefreshToken struct {
+	id        string
+	userID    int
+	familyID  string
+	tokenHash string
+	expiresAt time.Time
+	usedAt    *time.Time
+	revokedAt *time.Time
+}
+
+func NewRefreshToken(id string, userID int, familyID, tokenHash string, expiresAt time.Time, usedAt, revokedAt *time.Time) *RefreshToken {
+	return &RefreshToken{
+		id:        id,
+		userID:    userID,
+		familyID:  familyID,
+		tokenHash: tokenHash,
+		expiresAt: expiresAt,
+		usedAt:    usedAt,
+		revokedAt: revokedAt,
+	}
+}
+
+func (t *RefreshToken) ID() string {
+	return t.id
+}
+
+func (t *RefreshToken) UserID() int {
+	return t.userID
+}
+
+func (t *RefreshToken) FamilyID() string {
+	return t.familyID
+}
+
+func (t *RefreshToken) TokenHash() string {
+	return t.tokenHash
+}
+
+func (t *RefreshToken) ExpiresAt() time.Time {
+	return t.expiresAt
+}
+
+func (t *RefreshToken) UsedAt() *time.Time {
+	return t.usedAt
+}
+
+func (t *RefreshToken) IsUsed() bool {
+	return t.usedAt != nil
+}
+
+func (t *RefreshToken) IsRevoked() bool {
+	return t.revokedAt != nil
+}
+
+func (t *RefreshToken) IsExpired(now time.Time) bool {
+	return !now.Before(t.expiresAt)
+}
@@ -1,29 +1,34 @@
 package modles
 
-import "fmt"
+import (
+	"fmt"
+	"regexp"
+)
 
 type Role string
 
+// The built-in roles. More roles can be defined in the permission config,
+// so a role is only checked to be well formed here.
 const (
 	RoleCustomer Role = "CUSTOMER"
 	RoleAdmin    Role = "ADMIN"
 	RoleSeller   Role = "SELLER"
 )
 
+var roleNamePattern = regexp.MustCompile(`^[A-Z][A-Z0-9_]{0,19}$`)
+
 func NewRole(roleStr string) (Role, error) {
 	role := Role(roleStr)
 	if !role.IsValid() {
-		return "", fmt.Errorf("invalid role: %s. Must be one of: CUSTOMER, ADMIN, SELLER", roleStr)
+		return "", fmt.Errorf("invalid role: %s. Must be upper case letters, digits and underscores", roleStr)
 	}
 	return role, nil
 }
 
+// IsValid reports whether the role name is well formed, up to 20 upper case
+// letters, digits and underscores.
 func (r Role) IsValid() bool {
-	switch r {
-	case RoleCustomer, RoleAdmin, RoleSeller:
-		return true
-	}
-	return false
+	return roleNamePattern.MatchString(string(r))
 }
 
 func (r Role) String() string {
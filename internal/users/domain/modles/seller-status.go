@@ -0,0 +1,33 @@
+package modles
+
+import "fmt"
+
+// SellerStatus tracks the approval of a seller account. It is empty for users
+// that are not sellers.
+type SellerStatus string
+
+const (
+	SellerPending  SellerStatus = "PENDING"
+	SellerApproved SellerStatus = "APPROVED"
+	SellerRejected SellerStatus = "REJECTED"
+)
+
+func NewSellerStatus(statusStr string) (SellerStatus, error) {
+	status := SellerStatus(statusStr)
+	if !status.IsValid() {
+		return "", fmt.Errorf("invalid seller status: %s. Must be one of: PENDING, APPROVED, REJECTED", statusStr)
+	}
+	return status, nil
+}
+
+func (s SellerStatus) IsValid() bool {
+	switch s {
+	case SellerPending, SellerApproved, SellerRejected:
+		return true
+	}
+	return false
+}
+
+func (s SellerStatus) String() string {
+	return string(s)
+}
@@ -0,0 +1,94 @@
+package modles
+
+import "time"
+
+// Session is a login on one device. Its ID is the family ID of the refresh
+// tokens issued for the login, and the access tokens carry it as well, so
+// terminating the session signs the device out.
+type Session struct {
+	id           string
+	userID       int
+	userAgent    string
+	ip           string
+	createdAt    time.Time
+	lastSeenAt   time.Time
+	expiresAt    time.Time
+	terminatedAt *time.Time
+}
+
+// SessionParams holds every session attribute, used to rebuild a Session from
+// storage.
+type SessionParams struct {
+	ID           string
+	UserID       int
+	UserAgent    string
+	IP           string
+	CreatedAt    time.Time
+	LastSeenAt   time.Time
+	ExpiresAt    time.Time
+	TerminatedAt *time.Time
+}
+
+func NewSession(id string, userID int, userAgent, ip string, now, expiresAt time.Time) *Session {
+	return &Session{
+		id:         id,
+		userID:     userID,
+		userAgent:  userAgent,
+		ip:         ip,
+		createdAt:  now,
+		lastSeenAt: now,
+		expiresAt:  expiresAt,
+	}
+}
+
+func NewSessionFromParams(p SessionParams) *Session {
+	return &Session{
+		id:           p.ID,
+		userID:       p.UserID,
+		userAgent:    p.UserAgent,
+		ip:           p.IP,
+		createdAt:    p.CreatedAt,
+		lastSeenAt:   p.LastSeenAt,
+		expiresAt:    p.ExpiresAt,
+		terminatedAt: p.TerminatedAt,
+	}
+}
+
+func (s *Session) ID() string {
+	return s.id
+}
+
+func (s *Session) UserID() int {
+	return s.userID
+}
+
+func (s *Session) UserAgent() string {
+	return s.userAgent
+}
+
+// IP is the client address of the login.
+func (s *Session) IP() string {
+	return s.ip
+}
+
+func (s *Session) CreatedAt() time.Time {
+	return s.createdAt
+}
+
+func (s *Session) LastSeenAt() time.Time {
+	return s.lastSeenAt
+}
+
+// ExpiresAt is when the latest refresh token of the session expires.
+func (s *Session) ExpiresAt() time.Time {
+	return s.expiresAt
+}
+
+func (s *Session) TerminatedAt() *time.Time {
+	return s.terminatedAt
+}
+
+// IsActive reports whether the session is neither terminated nor expired.
+func (s *Session) IsActive(now time.Time) bool {
+	return s.terminatedAt == nil && now.Before(s.expiresAt)
+}
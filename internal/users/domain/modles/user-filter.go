@@ -0,0 +1,14 @@
+package modles
+
+import "time"
+
+// UserFilter selects a page of users. Zero values do not filter.
+type UserFilter struct {
+	Role Role
+	// Email matches any part of the address, ignoring case.
+	Email       string
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	Limit       int
+	Offset      int
+}
@@ -1,11 +1,46 @@
 package modles
 
+import (
+	"fmt"
+	"time"
+	c "yadwy-backend/internal/common"
+)
+
+// ErasedUserName replaces the name of erased users.
+const ErasedUserName = "Deleted user"
+
 type User struct {
-	id       int
-	name     string
-	email    string
-	password string
-	role     Role
+	id              int
+	name            string
+	email           string
+	password        string
+	role            Role
+	sellerStatus    SellerStatus
+	emailVerifiedAt *time.Time
+	phone           string
+	createdAt       time.Time
+	updatedAt       time.Time
+	deactivatedAt   *time.Time
+	suspendedAt     *time.Time
+	erasedAt        *time.Time
+}
+
+// UserParams holds every persisted user attribute and is used to rebuild a
+// User from storage.
+type UserParams struct {
+	ID              int
+	Name            string
+	Email           string
+	Password        string
+	Role            Role
+	SellerStatus    SellerStatus
+	EmailVerifiedAt *time.Time
+	Phone           string
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	DeactivatedAt   *time.Time
+	SuspendedAt     *time.Time
+	ErasedAt        *time.Time
 }
 
 func NewUser(id int, name, email, password string, role Role) *User {
@@ -18,6 +53,39 @@ func NewUser(id int, name, email, password string, role Role) *User {
 	}
 }
 
+func NewUserFromParams(p UserParams) *User {
+	return &User{
+		id:              p.ID,
+		name:            p.Name,
+		email:           p.Email,
+		password:        p.Password,
+		role:            p.Role,
+		sellerStatus:    p.SellerStatus,
+		emailVerifiedAt: p.EmailVerifiedAt,
+		phone:           p.Phone,
+		createdAt:       p.CreatedAt,
+		updatedAt:       p.UpdatedAt,
+		deactivatedAt:   p.DeactivatedAt,
+		suspendedAt:     p.SuspendedAt,
+		erasedAt:        p.ErasedAt,
+	}
+}
+
+// NewRegisteredUser creates a user through public registration. Only
+// customers and sellers may register themselves, sellers start out pending
+// approval and the email is unverified.
+func NewRegisteredUser(name, email, password string, role Role) (*User, error) {
+	switch role {
+	case RoleCustomer:
+		return NewUser(0, name, email, password, role), nil
+	case RoleSeller:
+		u := NewUser(0, name, email, password, role)
+		u.sellerStatus = SellerPending
+		return u, nil
+	}
+	return nil, c.NewErrorf(InvalidUserRoleError, "role %s cannot be self-registered", role)
+}
+
 func (u *User) Name() string {
 	return u.name
 }
@@ -34,3 +102,153 @@ func (u *User) Password() string {
 func (u *User) Role() Role {
 	return u.role
 }
+
+func (u *User) SellerStatus() SellerStatus {
+	return u.sellerStatus
+}
+
+// SellerNotApproved reports whether the user is a seller that is pending
+// approval or was rejected.
+func (u *User) SellerNotApproved() bool {
+	return u.sellerStatus != "" && u.sellerStatus != SellerApproved
+}
+
+func (u *User) EmailVerifiedAt() *time.Time {
+	return u.emailVerifiedAt
+}
+
+func (u *User) IsEmailVerified() bool {
+	return u.emailVerifiedAt != nil
+}
+
+func (u *User) Phone() string {
+	return u.phone
+}
+
+func (u *User) CreatedAt() time.Time {
+	return u.createdAt
+}
+
+func (u *User) UpdatedAt() time.Time {
+	return u.updatedAt
+}
+
+func (u *User) DeactivatedAt() *time.Time {
+	return u.deactivatedAt
+}
+
+func (u *User) IsActive() bool {
+	return u.deactivatedAt == nil
+}
+
+func (u *User) SuspendedAt() *time.Time {
+	return u.suspendedAt
+}
+
+func (u *User) IsSuspended() bool {
+	return u.suspendedAt != nil
+}
+
+func (u *User) ErasedAt() *time.Time {
+	return u.erasedAt
+}
+
+func (u *User) IsErased() bool {
+	return u.erasedAt != nil
+}
+
+// Erase anonymizes the user for a right to erasure request. The account is
+// deactivated and keeps only its ID and role, so that orders and audit
+// records referencing it stay intact. The empty password never matches.
+func (u *User) Erase(at time.Time) error {
+	if u.IsErased() {
+		return c.NewErrorf(InvalidAccountStatusError, "user %d is already erased", u.id)
+	}
+	u.name = ErasedUserName
+	u.email = fmt.Sprintf("erased-%d@erased.invalid", u.id)
+	u.password = ""
+	u.phone = ""
+	u.emailVerifiedAt = nil
+	if u.deactivatedAt == nil {
+		u.deactivatedAt = &at
+	}
+	u.erasedAt = &at
+	return nil
+}
+
+// ChangeRole moves the user to another role. Admins make this change, so a
+// new seller starts out approved, and leaving the seller role clears the
+// seller status.
+func (u *User) ChangeRole(role Role) error {
+	if !role.IsValid() {
+		return c.NewErrorf(InvalidUserRoleError, "invalid role %s", role)
+	}
+	if role == u.role {
+		return c.NewErrorf(InvalidAccountStatusError, "user %d already has role %s", u.id, role)
+	}
+
+	u.role = role
+	u.sellerStatus = ""
+	if role == RoleSeller {
+		u.sellerStatus = SellerApproved
+	}
+	return nil
+}
+
+// Suspend blocks the user from logging in until reactivated.
+func (u *User) Suspend(at time.Time) error {
+	if u.IsSuspended() {
+		return c.NewErrorf(InvalidAccountStatusError, "user %d is already suspended", u.id)
+	}
+	u.suspendedAt = &at
+	return nil
+}
+
+func (u *User) Reactivate() error {
+	if !u.IsSuspended() {
+		return c.NewErrorf(InvalidAccountStatusError, "user %d is not suspended", u.id)
+	}
+	u.suspendedAt = nil
+	return nil
+}
+
+// UpdateProfile changes the name and phone, keeping the current value of
+// each nil argument. An empty phone removes it.
+func (u *User) UpdateProfile(name, phone *string) error {
+	if name != nil {
+		if *name == "" {
+			return c.NewErrorf(InvalidProfileError, "name must not be empty")
+		}
+		u.name = *name
+	}
+	if phone != nil {
+		u.phone = *phone
+	}
+	return nil
+}
+
+func (u *User) ApproveSeller() error {
+	if err := u.ensurePendingSeller(); err != nil {
+		return err
+	}
+	u.sellerStatus = SellerApproved
+	return nil
+}
+
+func (u *User) RejectSeller() error {
+	if err := u.ensurePendingSeller(); err != nil {
+		return err
+	}
+	u.sellerStatus = SellerRejected
+	return nil
+}
+
+func (u *User) ensurePendingSeller() error {
+	if u.role != RoleSeller {
+		return c.NewErrorf(UserNotSellerError, "user %d is not a seller", u.id)
+	}
+	if u.sellerStatus != SellerPending {
+		return c.NewErrorf(InvalidSellerStatusError, "seller %d is %s, not pending approval", u.id, u.sellerStatus)
+	}
+	return nil
+}
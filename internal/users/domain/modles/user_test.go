@@ -63,3 +63,58 @@ func TestNewUser(t *testing.T) {
 		})
 	}
 }
+
+func TestNewRegisteredUser(t *testing.T) {
+	tests := []struct {
+		name             string
+		role             Role
+		wantErr          bool
+		wantSellerStatus SellerStatus
+	}{
+		{name: "should register customer", role: RoleCustomer, wantSellerStatus: ""},
+		{name: "should register seller pending approval", role: RoleSeller, wantSellerStatus: SellerPending},
+		{name: "should reject admin self-registration", role: RoleAdmin, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewRegisteredUser("John Doe", "john@example.com", "hashedPassword123", tt.role)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewRegisteredUser() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.SellerStatus() != tt.wantSellerStatus {
+				t.Errorf("NewRegisteredUser().SellerStatus() = %v, want %v", got.SellerStatus(), tt.wantSellerStatus)
+			}
+		})
+	}
+}
+
+func TestUser_ReviewSeller(t *testing.T) {
+	t.Run("should approve pending seller", func(t *testing.T) {
+		u, _ := NewRegisteredUser("Seller", "seller@example.com", "hash", RoleSeller)
+		if err := u.ApproveSeller(); err != nil {
+			t.Fatalf("ApproveSeller() error = %v", err)
+		}
+		if u.SellerStatus() != SellerApproved {
+			t.Errorf("SellerStatus() = %v, want %v", u.SellerStatus(), SellerApproved)
+		}
+	})
+
+	t.Run("should not review a seller twice", func(t *testing.T) {
+		u, _ := NewRegisteredUser("Seller", "seller@example.com", "hash", RoleSeller)
+		_ = u.RejectSeller()
+		if err := u.ApproveSeller(); err == nil {
+			t.Errorf("ApproveSeller() on rejected seller error = nil, want error")
+		}
+	})
+
+	t.Run("should not review a customer", func(t *testing.T) {
+		u := NewUser(1, "John Doe", "john@example.com", "hash", RoleCustomer)
+		if err := u.ApproveSeller(); err == nil {
+			t.Errorf("ApproveSeller() on customer error = nil, want error")
+		}
+	})
+}
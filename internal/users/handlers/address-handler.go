@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"yadwy-backend/internal/common"
+	"yadwy-backend/internal/users/application"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type AddressHandler struct {
+	service *application.AddressService
+}
+
+func NewAddressHandler(service *application.AddressService) *AddressHandler {
+	return &AddressHandler{
+		service: service,
+	}
+}
+
+// @Summary List addresses
+// @Description List the address book of the authenticated user
+// @Tags addresses
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} application.AddressRes
+// @Failure 401 {object} common.ErrorResponse
+// @Router /users/me/addresses [get]
+func (h *AddressHandler) ListAddresses(w http.ResponseWriter, r *http.Request) {
+	claims, err := common.GetLoggedInUser(r)
+	if err != nil {
+		common.SendError(w, http.StatusUnauthorized, "unauthorized", "user not authenticated")
+		return
+	}
+
+	res, err := h.service.ListAddresses(r.Context(), int(claims.ID))
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	if err = common.Encode(w, http.StatusOK, res); err != nil {
+		handleError(w, err)
+		return
+	}
+}
+
+// @Summary Get an address
+// @Description Get an address from the address book of the authenticated user
+// @Tags addresses
+// @Security BearerAuth
+// @Produce json
+// @Param id path integer true "Address ID"
+// @Success 200 {object} application.AddressRes
+// @Failure 401 {object} common.ErrorResponse
+// @Failure 404 {object} common.ErrorResponse
+// @Router /users/me/addresses/{id} [get]
+func (h *AddressHandler) GetAddress(w http.ResponseWriter, r *http.Request) {
+	claims, addressID, ok := userAndAddressID(w, r)
+	if !ok {
+		return
+	}
+
+	res, err := h.service.GetAddress(r.Context(), int(claims.ID), addressID)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	if err = common.Encode(w, http.StatusOK, res); err != nil {
+		handleError(w, err)
+		return
+	}
+}
+
+// @Summary Add an address
+// @Description Add an address to the address book. The first address becomes the default shipping and billing address.
+// @Tags addresses
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body application.AddressReq true "Address"
+// @Success 201 {object} application.AddressRes
+// @Failure 400 {object} common.ErrorResponse
+// @Failure 401 {object} common.ErrorResponse
+// @Failure 409 {object} common.ErrorResponse "Address book is full"
+// @Router /users/me/addresses [post]
+func (h *AddressHandler) CreateAddress(w http.ResponseWriter, r *http.Request) {
+	claims, err := common.GetLoggedInUser(r)
+	if err != nil {
+		common.SendError(w, http.StatusUnauthorized, "unauthorized", "user not authenticated")
+		return
+	}
+
+	req, err := common.DecodeAndValidate[application.AddressReq](r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	res, err := h.service.CreateAddress(r.Context(), int(claims.ID), req)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	if err = common.Encode(w, http.StatusCreated, res); err != nil {
+		handleError(w, err)
+		return
+	}
+}
+
+// @Summary Update an address
+// @Description Replace an address in the address book. Existing orders keep the address they were placed with.
+// @Tags addresses
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path integer true "Address ID"
+// @Param request body application.AddressReq true "Address"
+// @Success 200 {object} application.AddressRes
+// @Failure 400 {object} common.ErrorResponse
+// @Failure 401 {object} common.ErrorResponse
+// @Failure 404 {object} common.ErrorResponse
+// @Router /users/me/addresses/{id} [put]
+func (h *AddressHandler) UpdateAddress(w http.ResponseWriter, r *http.Request) {
+	claims, addressID, ok := userAndAddressID(w, r)
+	if !ok {
+		return
+	}
+
+	req, err := common.DecodeAndValidate[application.AddressReq](r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	res, err := h.service.UpdateAddress(r.Context(), int(claims.ID), addressID, req)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	if err = common.Encode(w, http.StatusOK, res); err != nil {
+		handleError(w, err)
+		return
+	}
+}
+
+// @Summary Delete an address
+// @Description Remove an address from the address book
+// @Tags addresses
+// @Security BearerAuth
+// @Param id path integer true "Address ID"
+// @Success 204 "Address deleted"
+// @Failure 401 {object} common.ErrorResponse
+// @Failure 404 {object} common.ErrorResponse
+// @Router /users/me/addresses/{id} [delete]
+func (h *AddressHandler) DeleteAddress(w http.ResponseWriter, r *http.Request) {
+	claims, addressID, ok := userAndAddressID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteAddress(r.Context(), int(claims.ID), addressID); err != nil {
+		handleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// userAndAddressID reads the signed in user and the {id} path parameter,
+// writing the error response on failure.
+func userAndAddressID(w http.ResponseWriter, r *http.Request) (*common.UserClaims, int, bool) {
+	claims, err := common.GetLoggedInUser(r)
+	if err != nil {
+		common.SendError(w, http.StatusUnauthorized, "unauthorized", "user not authenticated")
+		return nil, 0, false
+	}
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		common.SendError(w, http.StatusBadRequest, "invalid-address-id", "invalid address ID")
+		return nil, 0, false
+	}
+	return claims, id, true
+}
@@ -0,0 +1,469 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+	"yadwy-backend/internal/common"
+	"yadwy-backend/internal/users/application"
+	"yadwy-backend/internal/users/domain/modles"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type AdminHandler struct {
+	service *application.AdminService
+}
+
+func NewAdminHandler(service *application.AdminService) *AdminHandler {
+	return &AdminHandler{
+		service: service,
+	}
+}
+
+// @Summary Create an account
+// @Description Create a user with any role, including ADMIN (Admin only)
+// @Tags admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param user body application.CreateAccountReq true "Account details"
+// @Success 201 {object} application.UserInfo
+// @Failure 400 {object} common.ErrorResponse
+// @Failure 403 {object} common.ErrorResponse "Forbidden - Admin only"
+// @Failure 409 {object} common.ErrorResponse
+// @Router /admin/users [post]
+func (h *AdminHandler) CreateAccount(w http.ResponseWriter, r *http.Request) {
+	admin, err := common.GetLoggedInUser(r)
+	if err != nil {
+		common.SendError(w, http.StatusUnauthorized, "unauthorized", "user not authenticated")
+		return
+	}
+
+	req, err := common.DecodeAndValidate[application.CreateAccountReq](r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	res, err := h.service.CreateAccount(r.Context(), int(admin.ID), req)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	if err = common.Encode(w, http.StatusCreated, res); err != nil {
+		handleError(w, err)
+		return
+	}
+}
+
+// @Summary List sellers by approval status
+// @Description List seller accounts in the given approval state (Admin only)
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Param status query string false "Seller status (PENDING, APPROVED, REJECTED). Defaults to PENDING"
+// @Success 200 {array} application.UserInfo
+// @Failure 400 {object} common.ErrorResponse
+// @Failure 403 {object} common.ErrorResponse "Forbidden - Admin only"
+// @Router /admin/sellers [get]
+func (h *AdminHandler) ListSellers(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	if status == "" {
+		status = modles.SellerPending.String()
+	}
+	if _, err := modles.NewSellerStatus(status); err != nil {
+		common.SendError(w, http.StatusBadRequest, "invalid-seller-status", err.Error())
+		return
+	}
+
+	res, err := h.service.ListSellers(r.Context(), status)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	if err = common.Encode(w, http.StatusOK, res); err != nil {
+		handleError(w, err)
+		return
+	}
+}
+
+// @Summary Approve a seller
+// @Description Approve a pending seller application (Admin only)
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path integer true "Seller user ID"
+// @Success 200 {object} application.UserInfo
+// @Failure 403 {object} common.ErrorResponse "Forbidden - Admin only"
+// @Failure 404 {object} common.ErrorResponse
+// @Failure 409 {object} common.ErrorResponse "Seller is not pending approval"
+// @Router /admin/sellers/{id}/approve [post]
+func (h *AdminHandler) ApproveSeller(w http.ResponseWriter, r *http.Request) {
+	admin, sellerID, ok := adminAndTargetID(w, r)
+	if !ok {
+		return
+	}
+
+	res, err := h.service.ApproveSeller(r.Context(), int(admin.ID), sellerID)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	if err = common.Encode(w, http.StatusOK, res); err != nil {
+		handleError(w, err)
+		return
+	}
+}
+
+// @Summary Reject a seller
+// @Description Reject a pending seller application (Admin only)
+// @Tags admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path integer true "Seller user ID"
+// @Param request body application.RejectSellerReq false "Rejection reason"
+// @Success 200 {object} application.UserInfo
+// @Failure 403 {object} common.ErrorResponse "Forbidden - Admin only"
+// @Failure 404 {object} common.ErrorResponse
+// @Failure 409 {object} common.ErrorResponse "Seller is not pending approval"
+// @Router /admin/sellers/{id}/reject [post]
+func (h *AdminHandler) RejectSeller(w http.ResponseWriter, r *http.Request) {
+	admin, sellerID, ok := adminAndTargetID(w, r)
+	if !ok {
+		return
+	}
+
+	var req application.RejectSellerReq
+	if r.ContentLength != 0 {
+		var err error
+		req, err = common.DecodeAndValidate[application.RejectSellerReq](r)
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+	}
+
+	res, err := h.service.RejectSeller(r.Context(), int(admin.ID), sellerID, req.Reason)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	if err = common.Encode(w, http.StatusOK, res); err != nil {
+		handleError(w, err)
+		return
+	}
+}
+
+// @Summary Unlock a user
+// @Description Clear the failed login attempts that locked out a user (Admin only)
+// @Tags admin
+// @Security BearerAuth
+// @Param id path integer true "User ID"
+// @Success 204 "User unlocked"
+// @Failure 403 {object} common.ErrorResponse "Forbidden - Admin only"
+// @Failure 404 {object} common.ErrorResponse
+// @Router /admin/users/{id}/unlock [post]
+func (h *AdminHandler) UnlockUser(w http.ResponseWriter, r *http.Request) {
+	admin, userID, ok := adminAndTargetID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.UnlockUser(r.Context(), int(admin.ID), userID); err != nil {
+		handleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// @Summary List users
+// @Description List users, newest first, filtered by role, email and creation date (Admin only)
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Param role query string false "Role (CUSTOMER, SELLER, ADMIN)"
+// @Param email query string false "Part of the email address"
+// @Param created_from query string false "Created at or after, as a date (2006-01-02) or RFC 3339 time"
+// @Param created_to query string false "Created before, as a date (2006-01-02) or RFC 3339 time"
+// @Param limit query integer false "Number of items to return (default: 20, max: 100)"
+// @Param offset query integer false "Number of items to skip (default: 0)"
+// @Success 200 {object} application.UserListRes
+// @Failure 400 {object} common.ErrorResponse
+// @Failure 403 {object} common.ErrorResponse "Forbidden - Admin only"
+// @Router /admin/users [get]
+func (h *AdminHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	req := application.ListUsersReq{
+		Role:  query.Get("role"),
+		Email: query.Get("email"),
+	}
+
+	if limitStr := query.Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err == nil && limit > 0 {
+			req.Limit = limit
+		}
+	}
+
+	if offsetStr := query.Get("offset"); offsetStr != "" {
+		offset, err := strconv.Atoi(offsetStr)
+		if err == nil && offset >= 0 {
+			req.Offset = offset
+		}
+	}
+
+	var err error
+	if req.CreatedFrom, err = parseDateParam(query.Get("created_from")); err != nil {
+		common.SendError(w, http.StatusBadRequest, "invalid-created-from", err.Error())
+		return
+	}
+	if req.CreatedTo, err = parseDateParam(query.Get("created_to")); err != nil {
+		common.SendError(w, http.StatusBadRequest, "invalid-created-to", err.Error())
+		return
+	}
+
+	res, err := h.service.ListUsers(r.Context(), req)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	if err = common.Encode(w, http.StatusOK, res); err != nil {
+		handleError(w, err)
+		return
+	}
+}
+
+// @Summary Get a user
+// @Description Get the details of any user (Admin only)
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path integer true "User ID"
+// @Success 200 {object} application.AdminUserRes
+// @Failure 403 {object} common.ErrorResponse "Forbidden - Admin only"
+// @Failure 404 {object} common.ErrorResponse
+// @Router /admin/users/{id} [get]
+func (h *AdminHandler) GetUser(w http.ResponseWriter, r *http.Request) {
+	_, userID, ok := adminAndTargetID(w, r)
+	if !ok {
+		return
+	}
+
+	res, err := h.service.GetUser(r.Context(), userID)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	if err = common.Encode(w, http.StatusOK, res); err != nil {
+		handleError(w, err)
+		return
+	}
+}
+
+// @Summary Change the role of a user
+// @Description Change the role of a user and sign the user out of every device. New sellers are approved. (Admin only)
+// @Tags admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path integer true "User ID"
+// @Param request body application.ChangeRoleReq true "New role"
+// @Success 200 {object} application.AdminUserRes
+// @Failure 400 {object} common.ErrorResponse
+// @Failure 403 {object} common.ErrorResponse "Forbidden - Admin only"
+// @Failure 404 {object} common.ErrorResponse
+// @Failure 409 {object} common.ErrorResponse "User already has the role"
+// @Router /admin/users/{id}/role [post]
+func (h *AdminHandler) ChangeRole(w http.ResponseWriter, r *http.Request) {
+	admin, userID, ok := adminAndTargetID(w, r)
+	if !ok {
+		return
+	}
+
+	req, err := common.DecodeAndValidate[application.ChangeRoleReq](r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	res, err := h.service.ChangeRole(r.Context(), int(admin.ID), userID, req)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	if err = common.Encode(w, http.StatusOK, res); err != nil {
+		handleError(w, err)
+		return
+	}
+}
+
+// @Summary Suspend a user
+// @Description Block a user from logging in and revoke every token (Admin only)
+// @Tags admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path integer true "User ID"
+// @Param request body application.SuspendUserReq false "Suspension reason"
+// @Success 200 {object} application.AdminUserRes
+// @Failure 400 {object} common.ErrorResponse
+// @Failure 403 {object} common.ErrorResponse "Forbidden - Admin only"
+// @Failure 404 {object} common.ErrorResponse
+// @Failure 409 {object} common.ErrorResponse "User is already suspended"
+// @Router /admin/users/{id}/suspend [post]
+func (h *AdminHandler) SuspendUser(w http.ResponseWriter, r *http.Request) {
+	admin, userID, ok := adminAndTargetID(w, r)
+	if !ok {
+		return
+	}
+
+	var req application.SuspendUserReq
+	if r.ContentLength != 0 {
+		var err error
+		req, err = common.DecodeAndValidate[application.SuspendUserReq](r)
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+	}
+
+	res, err := h.service.SuspendUser(r.Context(), int(admin.ID), userID, req)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	if err = common.Encode(w, http.StatusOK, res); err != nil {
+		handleError(w, err)
+		return
+	}
+}
+
+// @Summary Reactivate a user
+// @Description Lift the suspension of a user (Admin only)
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path integer true "User ID"
+// @Success 200 {object} application.AdminUserRes
+// @Failure 403 {object} common.ErrorResponse "Forbidden - Admin only"
+// @Failure 404 {object} common.ErrorResponse
+// @Failure 409 {object} common.ErrorResponse "User is not suspended"
+// @Router /admin/users/{id}/reactivate [post]
+func (h *AdminHandler) ReactivateUser(w http.ResponseWriter, r *http.Request) {
+	admin, userID, ok := adminAndTargetID(w, r)
+	if !ok {
+		return
+	}
+
+	res, err := h.service.ReactivateUser(r.Context(), int(admin.ID), userID)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	if err = common.Encode(w, http.StatusOK, res); err != nil {
+		handleError(w, err)
+		return
+	}
+}
+
+// @Summary Log a user out
+// @Description Revoke every access and refresh token of a user (Admin only)
+// @Tags admin
+// @Security BearerAuth
+// @Param id path integer true "User ID"
+// @Success 204 "User logged out"
+// @Failure 403 {object} common.ErrorResponse "Forbidden - Admin only"
+// @Failure 404 {object} common.ErrorResponse
+// @Router /admin/users/{id}/logout [post]
+func (h *AdminHandler) LogoutUser(w http.ResponseWriter, r *http.Request) {
+	admin, userID, ok := adminAndTargetID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.LogoutUser(r.Context(), int(admin.ID), userID); err != nil {
+		handleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// @Summary Get the audit log of a user
+// @Description List the admin actions recorded on a user, newest first (Admin only)
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path integer true "User ID"
+// @Param limit query integer false "Number of items to return (default: 20, max: 100)"
+// @Param offset query integer false "Number of items to skip (default: 0)"
+// @Success 200 {array} application.AuditEntryRes
+// @Failure 403 {object} common.ErrorResponse "Forbidden - Admin only"
+// @Router /admin/users/{id}/audit [get]
+func (h *AdminHandler) ListAuditEntries(w http.ResponseWriter, r *http.Request) {
+	_, userID, ok := adminAndTargetID(w, r)
+	if !ok {
+		return
+	}
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+
+	res, err := h.service.ListAuditEntries(r.Context(), userID, limit, offset)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	if err = common.Encode(w, http.StatusOK, res); err != nil {
+		handleError(w, err)
+		return
+	}
+}
+
+// parseDateParam accepts a date or an RFC 3339 time and returns nil for an
+// empty value.
+func parseDateParam(v string) (*time.Time, error) {
+	if v == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.DateOnly, v); err == nil {
+		return &t, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date %q, use 2006-01-02 or RFC 3339", v)
+	}
+	return &t, nil
+}
+
+// adminAndTargetID reads the acting admin from the context and the target
+// user from the {id} path parameter, writing the error response on failure.
+func adminAndTargetID(w http.ResponseWriter, r *http.Request) (*common.UserClaims, int, bool) {
+	admin, err := common.GetLoggedInUser(r)
+	if err != nil {
+		common.SendError(w, http.StatusUnauthorized, "unauthorized", "user not authenticated")
+		return nil, 0, false
+	}
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		common.SendError(w, http.StatusBadRequest, "invalid-user-id", "invalid user ID")
+		return nil, 0, false
+	}
+	return admin, id, true
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"yadwy-backend/internal/common"
+	"yadwy-backend/internal/users/application"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type APIKeyHandler struct {
+	service *application.APIKeyService
+}
+
+func NewAPIKeyHandler(service *application.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{
+		service: service,
+	}
+}
+
+// @Summary List API keys
+// @Description List the active API keys of the authenticated user
+// @Tags api-keys
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} application.APIKeyRes
+// @Failure 401 {object} common.ErrorResponse
+// @Failure 403 {object} common.ErrorResponse
+// @Router /users/me/api-keys [get]
+func (h *APIKeyHandler) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	claims, err := common.GetLoggedInUser(r)
+	if err != nil {
+		common.SendError(w, http.StatusUnauthorized, "unauthorized", "user not authenticated")
+		return
+	}
+
+	res, err := h.service.ListAPIKeys(r.Context(), int(claims.ID))
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	if err = common.Encode(w, http.StatusOK, res); err != nil {
+		handleError(w, err)
+		return
+	}
+}
+
+// @Summary Create an API key
+// @Description Create an API key limited to the given scopes. Send it in the X-API-Key header. The key is only shown in this response.
+// @Tags api-keys
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body application.CreateAPIKeyReq true "Key name, scopes and optional expiry"
+// @Success 201 {object} application.CreateAPIKeyRes
+// @Failure 400 {object} common.ErrorResponse
+// @Failure 401 {object} common.ErrorResponse
+// @Failure 403 {object} common.ErrorResponse
+// @Failure 409 {object} common.ErrorResponse "Too many API keys"
+// @Router /users/me/api-keys [post]
+func (h *APIKeyHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	claims, err := common.GetLoggedInUser(r)
+	if err != nil {
+		common.SendError(w, http.StatusUnauthorized, "unauthorized", "user not authenticated")
+		return
+	}
+
+	req, err := common.DecodeAndValidate[application.CreateAPIKeyReq](r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	res, err := h.service.CreateAPIKey(r.Context(), int(claims.ID), req)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	if err = common.Encode(w, http.StatusCreated, res); err != nil {
+		handleError(w, err)
+		return
+	}
+}
+
+// @Summary Revoke an API key
+// @Description Revoke an API key of the authenticated user. Requests with the key fail right away.
+// @Tags api-keys
+// @Security BearerAuth
+// @Param id path integer true "API key ID"
+// @Success 204 "API key revoked"
+// @Failure 401 {object} common.ErrorResponse
+// @Failure 404 {object} common.ErrorResponse
+// @Router /users/me/api-keys/{id} [delete]
+func (h *APIKeyHandler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	claims, err := common.GetLoggedInUser(r)
+	if err != nil {
+		common.SendError(w, http.StatusUnauthorized, "unauthorized", "user not authenticated")
+		return
+	}
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		common.SendError(w, http.StatusBadRequest, "invalid-api-key-id", "invalid API key ID")
+		return
+	}
+
+	if err = h.service.RevokeAPIKey(r.Context(), int(claims.ID), id); err != nil {
+		handleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"net/http"
+	"yadwy-backend/internal/common"
+	"yadwy-backend/internal/users/application"
+)
+
+type MFAHandler struct {
+	service     *application.MFAService
+	userService *application.UserService
+}
+
+func NewMFAHandler(service *application.MFAService, userService *application.UserService) *MFAHandler {
+	return &MFAHandler{
+		service:     service,
+		userService: userService,
+	}
+}
+
+// @Summary Complete an MFA login
+// @Description Exchange the MFA token from /users/login and a TOTP or recovery code for access and refresh tokens
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body application.MFALoginReq true "MFA token and code"
+// @Success 200 {object} application.LoginUserRes
+// @Failure 400 {object} common.ErrorResponse
+// @Failure 401 {object} common.ErrorResponse
+// @Failure 429 {object} common.ErrorResponse
+// @Router /users/login/mfa [post]
+func (h *MFAHandler) LoginWithMFA(w http.ResponseWriter, r *http.Request) {
+	req, err := common.DecodeAndValidate[application.MFALoginReq](r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	res, err := h.userService.LoginWithMFA(r.Context(), req, clientInfo(r))
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	if err = common.Encode(w, http.StatusOK, res); err != nil {
+		handleError(w, err)
+		return
+	}
+}
+
+// @Summary Start MFA enrollment
+// @Description Create a TOTP secret and otpauth URI. Accepts an access token or the MFA token of a login that requires enrollment.
+// @Tags users
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} application.MFAEnrollmentRes
+// @Failure 401 {object} common.ErrorResponse
+// @Failure 409 {object} common.ErrorResponse "MFA is already enabled"
+// @Router /users/me/mfa/enroll [post]
+func (h *MFAHandler) Enroll(w http.ResponseWriter, r *http.Request) {
+	claims, err := common.GetLoggedInUser(r)
+	if err != nil {
+		common.SendError(w, http.StatusUnauthorized, "unauthorized", "user not authenticated")
+		return
+	}
+
+	res, err := h.service.Enroll(r.Context(), int(claims.ID))
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	if err = common.Encode(w, http.StatusOK, res); err != nil {
+		handleError(w, err)
+		return
+	}
+}
+
+// @Summary Confirm MFA enrollment
+// @Description Enable MFA with a code from the authenticator app and return one-time recovery codes. Log in again afterwards.
+// @Tags users
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body application.ConfirmMFAReq true "TOTP code"
+// @Success 200 {object} application.MFARecoveryCodesRes
+// @Failure 400 {object} common.ErrorResponse
+// @Failure 401 {object} common.ErrorResponse
+// @Failure 409 {object} common.ErrorResponse "MFA is already enabled"
+// @Router /users/me/mfa/confirm [post]
+func (h *MFAHandler) Confirm(w http.ResponseWriter, r *http.Request) {
+	claims, err := common.GetLoggedInUser(r)
+	if err != nil {
+		common.SendError(w, http.StatusUnauthorized, "unauthorized", "user not authenticated")
+		return
+	}
+
+	req, err := common.DecodeAndValidate[application.ConfirmMFAReq](r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	res, err := h.service.Confirm(r.Context(), int(claims.ID), req)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	if err = common.Encode(w, http.StatusOK, res); err != nil {
+		handleError(w, err)
+		return
+	}
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"net/http"
+	"yadwy-backend/internal/common"
+	"yadwy-backend/internal/users/application"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type OIDCHandler struct {
+	service *application.OIDCService
+}
+
+func NewOIDCHandler(service *application.OIDCService) *OIDCHandler {
+	return &OIDCHandler{
+		service: service,
+	}
+}
+
+// @Summary Start an external login
+// @Description Start the authorization code flow with an OpenID Connect provider. Redirect the user to the returned URL.
+// @Tags users
+// @Produce json
+// @Param provider path string true "Provider name, e.g. google or apple"
+// @Success 200 {object} application.OIDCStartRes
+// @Failure 404 {object} common.ErrorResponse "Unknown provider"
+// @Router /users/oidc/{provider}/start [post]
+func (h *OIDCHandler) StartLogin(w http.ResponseWriter, r *http.Request) {
+	res, err := h.service.StartLogin(r.Context(), chi.URLParam(r, "provider"))
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	if err = common.Encode(w, http.StatusOK, res); err != nil {
+		handleError(w, err)
+		return
+	}
+}
+
+// @Summary Complete an external login
+// @Description Exchange the code and state the provider redirected back with for the same tokens as a password login. A verified provider email links the identity to the existing account with that email.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param provider path string true "Provider name, e.g. google or apple"
+// @Param request body application.OIDCCallbackReq true "Code and state from the provider redirect"
+// @Success 200 {object} application.LoginUserRes
+// @Failure 400 {object} common.ErrorResponse "Invalid or expired state"
+// @Failure 401 {object} common.ErrorResponse "Provider login failed"
+// @Failure 404 {object} common.ErrorResponse "Unknown provider"
+// @Failure 409 {object} common.ErrorResponse "Email exists but is not verified by the provider"
+// @Router /users/oidc/{provider}/callback [post]
+func (h *OIDCHandler) CompleteLogin(w http.ResponseWriter, r *http.Request) {
+	req, err := common.DecodeAndValidate[application.OIDCCallbackReq](r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	res, err := h.service.CompleteLogin(r.Context(), chi.URLParam(r, "provider"), req, clientInfo(r))
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	if err = common.Encode(w, http.StatusOK, res); err != nil {
+		handleError(w, err)
+		return
+	}
+}
@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"net/http"
+	"yadwy-backend/internal/common"
+	"yadwy-backend/internal/users/application"
+)
+
+type PasswordHandler struct {
+	service *application.PasswordResetService
+}
+
+func NewPasswordHandler(service *application.PasswordResetService) *PasswordHandler {
+	return &PasswordHandler{
+		service: service,
+	}
+}
+
+// @Summary Request a password reset
+// @Description Mail a password reset link. The response is the same whether or not the email is registered.
+// @Tags users
+// @Accept json
+// @Param request body application.ForgotPasswordReq true "Account email"
+// @Success 202 "Reset link sent if the account exists"
+// @Failure 400 {object} common.ErrorResponse
+// @Router /users/password/forgot [post]
+func (h *PasswordHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	req, err := common.DecodeAndValidate[application.ForgotPasswordReq](r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	if err = h.service.ForgotPassword(r.Context(), req); err != nil {
+		handleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// @Summary Reset password
+// @Description Set a new password with a reset token. All existing sessions are signed out.
+// @Tags users
+// @Accept json
+// @Param request body application.ResetPasswordReq true "Reset token and new password"
+// @Success 204 "Password updated"
+// @Failure 400 {object} common.ErrorResponse
+// @Router /users/password/reset [post]
+func (h *PasswordHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	req, err := common.DecodeAndValidate[application.ResetPasswordReq](r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	if err = h.service.ResetPassword(r.Context(), req); err != nil {
+		handleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
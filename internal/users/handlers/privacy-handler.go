@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"yadwy-backend/internal/common"
+	"yadwy-backend/internal/users/application"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type PrivacyHandler struct {
+	service *application.PrivacyService
+}
+
+func NewPrivacyHandler(service *application.PrivacyService) *PrivacyHandler {
+	return &PrivacyHandler{
+		service: service,
+	}
+}
+
+// @Summary Request a data export
+// @Description Start building an archive of the personal data of the authenticated user: profile, addresses, carts, orders and uploaded files. Poll the export until it is completed, then download it.
+// @Tags users
+// @Security BearerAuth
+// @Produce json
+// @Success 202 {object} application.DataExportRes
+// @Failure 401 {object} common.ErrorResponse
+// @Failure 409 {object} common.ErrorResponse "An export is already in progress"
+// @Router /users/me/export [post]
+func (h *PrivacyHandler) RequestExport(w http.ResponseWriter, r *http.Request) {
+	claims, err := common.GetLoggedInUser(r)
+	if err != nil {
+		common.SendError(w, http.StatusUnauthorized, "unauthorized", "user not authenticated")
+		return
+	}
+
+	res, err := h.service.RequestExport(r.Context(), int(claims.ID))
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	if err = common.Encode(w, http.StatusAccepted, res); err != nil {
+		handleError(w, err)
+		return
+	}
+}
+
+// @Summary Get a data export
+// @Description Get the status of a personal data export of the authenticated user
+// @Tags users
+// @Security BearerAuth
+// @Produce json
+// @Param id path integer true "Export ID"
+// @Success 200 {object} application.DataExportRes
+// @Failure 401 {object} common.ErrorResponse
+// @Failure 404 {object} common.ErrorResponse
+// @Router /users/me/export/{id} [get]
+func (h *PrivacyHandler) GetExport(w http.ResponseWriter, r *http.Request) {
+	claims, id, ok := userAndExportID(w, r)
+	if !ok {
+		return
+	}
+
+	res, err := h.service.GetExport(r.Context(), int(claims.ID), id)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	if err = common.Encode(w, http.StatusOK, res); err != nil {
+		handleError(w, err)
+		return
+	}
+}
+
+// @Summary Download a data export
+// @Description Download the ZIP archive of a completed personal data export. The archive holds data.json and the uploaded files.
+// @Tags users
+// @Security BearerAuth
+// @Produce application/zip
+// @Param id path integer true "Export ID"
+// @Success 200 {file} file
+// @Failure 401 {object} common.ErrorResponse
+// @Failure 404 {object} common.ErrorResponse
+// @Failure 409 {object} common.ErrorResponse "The export is not completed or expired"
+// @Router /users/me/export/{id}/download [get]
+func (h *PrivacyHandler) DownloadExport(w http.ResponseWriter, r *http.Request) {
+	claims, id, ok := userAndExportID(w, r)
+	if !ok {
+		return
+	}
+
+	path, err := h.service.ExportArchive(r.Context(), int(claims.ID), id)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="yadwy-data-export-%d.zip"`, id))
+	http.ServeFile(w, r, path)
+}
+
+// @Summary Erase account
+// @Description Permanently anonymize the account of the authenticated user and delete its personal data. Orders and payments are kept without personal data.
+// @Tags users
+// @Security BearerAuth
+// @Accept json
+// @Param request body application.EraseAccountReq true "Password confirmation"
+// @Success 204 "Account erased"
+// @Failure 400 {object} common.ErrorResponse
+// @Failure 401 {object} common.ErrorResponse
+// @Router /users/me/erase [post]
+func (h *PrivacyHandler) EraseAccount(w http.ResponseWriter, r *http.Request) {
+	claims, err := common.GetLoggedInUser(r)
+	if err != nil {
+		common.SendError(w, http.StatusUnauthorized, "unauthorized", "user not authenticated")
+		return
+	}
+
+	req, err := common.DecodeAndValidate[application.EraseAccountReq](r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	if err = h.service.EraseAccount(r.Context(), int(claims.ID), req); err != nil {
+		handleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// @Summary Erase a user
+// @Description Permanently anonymize the account of a user and delete its personal data. Orders and payments are kept without personal data.
+// @Tags admin
+// @Security BearerAuth
+// @Param id path integer true "User ID"
+// @Success 204 "User erased"
+// @Failure 400 {object} common.ErrorResponse
+// @Failure 403 {object} common.ErrorResponse "Forbidden - Admin only"
+// @Failure 404 {object} common.ErrorResponse
+// @Failure 409 {object} common.ErrorResponse "User is already erased"
+// @Router /admin/users/{id}/erase [post]
+func (h *PrivacyHandler) EraseUser(w http.ResponseWriter, r *http.Request) {
+	admin, id, ok := adminAndTargetID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.EraseUser(r.Context(), int(admin.ID), id); err != nil {
+		handleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// userAndExportID reads the authenticated user and the {id} path parameter,
+// writing the error response on failure.
+func userAndExportID(w http.ResponseWriter, r *http.Request) (*common.UserClaims, int, bool) {
+	claims, err := common.GetLoggedInUser(r)
+	if err != nil {
+		common.SendError(w, http.StatusUnauthorized, "unauthorized", "user not authenticated")
+		return nil, 0, false
+	}
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		common.SendError(w, http.StatusBadRequest, "invalid-export-id", "invalid export ID")
+		return nil, 0, false
+	}
+	return claims, id, true
+}
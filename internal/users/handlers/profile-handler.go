@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"net/http"
+	"yadwy-backend/internal/common"
+	"yadwy-backend/internal/users/application"
+)
+
+type ProfileHandler struct {
+	service *application.ProfileService
+}
+
+func NewProfileHandler(service *application.ProfileService) *ProfileHandler {
+	return &ProfileHandler{
+		service: service,
+	}
+}
+
+// @Summary Get profile
+// @Description Get the profile of the authenticated user
+// @Tags users
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} application.UserProfile
+// @Failure 401 {object} common.ErrorResponse
+// @Failure 404 {object} common.ErrorResponse
+// @Router /users/me [get]
+func (h *ProfileHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
+	claims, err := common.GetLoggedInUser(r)
+	if err != nil {
+		common.SendError(w, http.StatusUnauthorized, "unauthorized", "user not authenticated")
+		return
+	}
+
+	res, err := h.service.GetProfile(r.Context(), int(claims.ID))
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	if err = common.Encode(w, http.StatusOK, res); err != nil {
+		handleError(w, err)
+		return
+	}
+}
+
+// @Summary Update profile
+// @Description Update the name and phone of the authenticated user. Omitted fields are unchanged and an empty phone removes it.
+// @Tags users
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body application.UpdateProfileReq true "Profile fields to change"
+// @Success 200 {object} application.UserProfile
+// @Failure 400 {object} common.ErrorResponse
+// @Failure 401 {object} common.ErrorResponse
+// @Router /users/me [patch]
+func (h *ProfileHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
+	claims, err := common.GetLoggedInUser(r)
+	if err != nil {
+		common.SendError(w, http.StatusUnauthorized, "unauthorized", "user not authenticated")
+		return
+	}
+
+	req, err := common.DecodeAndValidate[application.UpdateProfileReq](r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	res, err := h.service.UpdateProfile(r.Context(), int(claims.ID), req)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	if err = common.Encode(w, http.StatusOK, res); err != nil {
+		handleError(w, err)
+		return
+	}
+}
+
+// @Summary Change password
+// @Description Change the password of the authenticated user. All sessions, including the current one, are signed out.
+// @Tags users
+// @Security BearerAuth
+// @Accept json
+// @Param request body application.ChangePasswordReq true "Current and new password"
+// @Success 204 "Password changed"
+// @Failure 400 {object} common.ErrorResponse
+// @Failure 401 {object} common.ErrorResponse
+// @Router /users/me/password [post]
+func (h *ProfileHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	claims, err := common.GetLoggedInUser(r)
+	if err != nil {
+		common.SendError(w, http.StatusUnauthorized, "unauthorized", "user not authenticated")
+		return
+	}
+
+	req, err := common.DecodeAndValidate[application.ChangePasswordReq](r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	if err = h.service.ChangePassword(r.Context(), int(claims.ID), req); err != nil {
+		handleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// @Summary Deactivate account
+// @Description Deactivate the account of the authenticated user and sign out all sessions
+// @Tags users
+// @Security BearerAuth
+// @Success 204 "Account deactivated"
+// @Failure 401 {object} common.ErrorResponse
+// @Router /users/me [delete]
+func (h *ProfileHandler) Deactivate(w http.ResponseWriter, r *http.Request) {
+	claims, err := common.GetLoggedInUser(r)
+	if err != nil {
+		common.SendError(w, http.StatusUnauthorized, "unauthorized", "user not authenticated")
+		return
+	}
+
+	if err = h.service.Deactivate(r.Context(), int(claims.ID)); err != nil {
+		handleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
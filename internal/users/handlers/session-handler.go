@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"net/http"
+	"yadwy-backend/internal/common"
+	"yadwy-backend/internal/users/application"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type SessionHandler struct {
+	service *application.SessionService
+}
+
+func NewSessionHandler(service *application.SessionService) *SessionHandler {
+	return &SessionHandler{
+		service: service,
+	}
+}
+
+// @Summary List sessions
+// @Description List the devices the authenticated user is logged in on. The session of the request is marked as current.
+// @Tags users
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} application.SessionRes
+// @Failure 401 {object} common.ErrorResponse
+// @Router /users/me/sessions [get]
+func (h *SessionHandler) ListSessions(w http.ResponseWriter, r *http.Request) {
+	claims, err := common.GetLoggedInUser(r)
+	if err != nil {
+		common.SendError(w, http.StatusUnauthorized, "unauthorized", "user not authenticated")
+		return
+	}
+
+	res, err := h.service.ListSessions(r.Context(), int(claims.ID), claims.SessionID)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	if err = common.Encode(w, http.StatusOK, res); err != nil {
+		handleError(w, err)
+		return
+	}
+}
+
+// @Summary Terminate a session
+// @Description Sign the authenticated user out of a device. Tokens of the session stop working right away.
+// @Tags users
+// @Security BearerAuth
+// @Param id path string true "Session ID"
+// @Success 204 "Session terminated"
+// @Failure 401 {object} common.ErrorResponse
+// @Failure 404 {object} common.ErrorResponse
+// @Router /users/me/sessions/{id} [delete]
+func (h *SessionHandler) TerminateSession(w http.ResponseWriter, r *http.Request) {
+	claims, err := common.GetLoggedInUser(r)
+	if err != nil {
+		common.SendError(w, http.StatusUnauthorized, "unauthorized", "user not authenticated")
+		return
+	}
+
+	if err = h.service.TerminateSession(r.Context(), int(claims.ID), chi.URLParam(r, "id")); err != nil {
+		handleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -2,15 +2,14 @@ package handlers
 
 import (
 	"errors"
+	"net"
 	"net/http"
 	"yadwy-backend/internal/common"
 	"yadwy-backend/internal/users/application"
-	"yadwy-backend/internal/users/db"
 	"yadwy-backend/internal/users/domain/modles"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-playground/validator/v10"
-	"github.com/jmoiron/sqlx"
 )
 
 type UserHandler struct {
@@ -24,32 +23,36 @@ func NewUserHandler(service *application.UserService) *UserHandler {
 }
 
 // @Summary Register a new user
-// @Description Register a new user in the system
+// @Description Register a new customer or seller and mail an email verification link. Seller accounts start pending admin approval.
 // @Tags users
 // @Accept json
 // @Produce json
 // @Param user body application.CreateUserReq true "User registration details"
-// @Success 201 {object} application.CreateUserRes
+// @Success 201 {object} application.LoginUserRes
 // @Failure 400 {object} common.ErrorResponse
 // @Failure 409 {object} common.ErrorResponse
 // @Router /users/register [post]
 func (h *UserHandler) RegisterUser(w http.ResponseWriter, r *http.Request) {
-	req, _ := common.DecodeAndValidate[application.CreateUserReq](r)
+	req, err := common.DecodeAndValidate[application.CreateUserReq](r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
 
-	res, err := h.service.CreateUser(r.Context(), req)
+	res, err := h.service.CreateUser(r.Context(), req, clientInfo(r))
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusConflict)
+		handleError(w, err)
 		return
 	}
 
 	if err = common.Encode(w, http.StatusCreated, res); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		handleError(w, err)
 		return
 	}
 }
 
 // @Summary Login user
-// @Description Authenticate a user and return a JWT token
+// @Description Authenticate a user and return a JWT token. Users with MFA get an MFA token for /users/login/mfa instead. Repeated failures are throttled per account and per client address.
 // @Tags users
 // @Accept json
 // @Produce json
@@ -57,6 +60,7 @@ func (h *UserHandler) RegisterUser(w http.ResponseWriter, r *http.Request) {
 // @Success 200 {object} application.LoginUserRes
 // @Failure 400 {object} common.ErrorResponse
 // @Failure 401 {object} common.ErrorResponse
+// @Failure 429 {object} common.ErrorResponse
 // @Router /users/login [post]
 func (h *UserHandler) LoginUser(w http.ResponseWriter, r *http.Request) {
 	req, err := common.DecodeAndValidate[application.LoginUserReq](r)
@@ -65,7 +69,36 @@ func (h *UserHandler) LoginUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	res, err := h.service.LoginUser(r.Context(), req)
+	res, err := h.service.LoginUser(r.Context(), req, clientInfo(r))
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	if err = common.Encode(w, http.StatusOK, res); err != nil {
+		handleError(w, err)
+		return
+	}
+}
+
+// @Summary Refresh tokens
+// @Description Exchange a refresh token for a new access and refresh token pair. Refresh tokens are single use.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body application.RefreshTokenReq true "Refresh token"
+// @Success 200 {object} application.LoginUserRes
+// @Failure 400 {object} common.ErrorResponse
+// @Failure 401 {object} common.ErrorResponse
+// @Router /users/token/refresh [post]
+func (h *UserHandler) RefreshToken(w http.ResponseWriter, r *http.Request) {
+	req, err := common.DecodeAndValidate[application.RefreshTokenReq](r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	res, err := h.service.RefreshToken(r.Context(), req, clientInfo(r))
 	if err != nil {
 		handleError(w, err)
 		return
@@ -77,6 +110,61 @@ func (h *UserHandler) LoginUser(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// @Summary Logout
+// @Description Revoke the current access token and, if provided, its refresh token
+// @Tags users
+// @Security BearerAuth
+// @Accept json
+// @Param request body application.LogoutReq false "Refresh token to revoke"
+// @Success 204 "Logged out"
+// @Failure 401 {object} common.ErrorResponse
+// @Router /users/logout [post]
+func (h *UserHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	claims, err := common.GetLoggedInUser(r)
+	if err != nil {
+		common.SendError(w, http.StatusUnauthorized, "unauthorized", "user not authenticated")
+		return
+	}
+
+	var req application.LogoutReq
+	if r.ContentLength != 0 {
+		req, err = common.DecodeAndValidate[application.LogoutReq](r)
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+	}
+
+	if err = h.service.Logout(r.Context(), claims, req); err != nil {
+		handleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// @Summary Logout from all devices
+// @Description Revoke every access and refresh token of the current user
+// @Tags users
+// @Security BearerAuth
+// @Success 204 "Logged out from all devices"
+// @Failure 401 {object} common.ErrorResponse
+// @Router /users/logout/all [post]
+func (h *UserHandler) LogoutAll(w http.ResponseWriter, r *http.Request) {
+	claims, err := common.GetLoggedInUser(r)
+	if err != nil {
+		common.SendError(w, http.StatusUnauthorized, "unauthorized", "user not authenticated")
+		return
+	}
+
+	if err = h.service.LogoutAll(r.Context(), int(claims.ID)); err != nil {
+		handleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
 // @Summary Get private user information
 // @Description Get authenticated user's information
 // @Tags users
@@ -94,20 +182,138 @@ func (h *UserHandler) privateHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func LoadUserRoutes(b *sqlx.DB, r chi.Router, jwt *common.JWTGenerator) {
-	userRepo := db.NewUserRepo(b)
-	userSvc := application.NewUserService(userRepo, jwt)
-	userHandler := NewUserHandler(userSvc)
+// UserServices are the services behind the users routes, built by the app.
+type UserServices struct {
+	Users         *application.UserService
+	Admin         *application.AdminService
+	Verification  *application.EmailVerificationService
+	Profile       *application.ProfileService
+	Sessions      *application.SessionService
+	Addresses     *application.AddressService
+	APIKeys       *application.APIKeyService
+	Privacy       *application.PrivacyService
+	OIDC          *application.OIDCService
+	MFA           *application.MFAService
+	PasswordReset *application.PasswordResetService
+}
+
+// LoadUserRoutes registers the /users routes and the admin user management
+// routes on the root router.
+func LoadUserRoutes(router chi.Router, services UserServices, jwt *common.JWTGenerator, policy *common.PermissionPolicy) {
+	userHandler := NewUserHandler(services.Users)
+	adminHandler := NewAdminHandler(services.Admin)
+	verificationHandler := NewVerificationHandler(services.Verification)
+	profileHandler := NewProfileHandler(services.Profile)
+	sessionHandler := NewSessionHandler(services.Sessions)
+	addressHandler := NewAddressHandler(services.Addresses)
+	apiKeyHandler := NewAPIKeyHandler(services.APIKeys)
+	privacyHandler := NewPrivacyHandler(services.Privacy)
+	oidcHandler := NewOIDCHandler(services.OIDC)
+	mfaHandler := NewMFAHandler(services.MFA, services.Users)
+	passwordHandler := NewPasswordHandler(services.PasswordReset)
+
+	router.Route("/users", func(r chi.Router) {
+		// Public routes group
+		r.Post("/register", userHandler.RegisterUser)
+		r.Post("/login", userHandler.LoginUser)
+		r.Post("/login/mfa", mfaHandler.LoginWithMFA)
+		r.Post("/token/refresh", userHandler.RefreshToken)
+		r.Post("/password/forgot", passwordHandler.ForgotPassword)
+		r.Post("/password/reset", passwordHandler.ResetPassword)
+		r.Post("/verify-email", verificationHandler.VerifyEmail)
+		r.Post("/oidc/{provider}/start", oidcHandler.StartLogin)
+		r.Post("/oidc/{provider}/callback", oidcHandler.CompleteLogin)
 
-	// Public routes group
-	r.Post("/register", userHandler.RegisterUser)
-	r.Post("/login", userHandler.LoginUser)
+		//Protected routes group
+		r.Group(func(r chi.Router) {
+			r.Use(common.GetAuthMiddlewareFunc(jwt))
+			r.Get("/private", userHandler.privateHandler)
+			r.Post("/logout", userHandler.Logout)
+			r.Post("/logout/all", userHandler.LogoutAll)
+			r.Post("/verify-email/resend", verificationHandler.ResendVerification)
+			r.Get("/me", profileHandler.GetProfile)
+			r.Patch("/me", profileHandler.UpdateProfile)
+			r.Delete("/me", profileHandler.Deactivate)
+			r.Post("/me/password", profileHandler.ChangePassword)
+			r.Get("/me/sessions", sessionHandler.ListSessions)
+			r.Delete("/me/sessions/{id}", sessionHandler.TerminateSession)
+			r.Post("/me/export", privacyHandler.RequestExport)
+			r.Get("/me/export/{id}", privacyHandler.GetExport)
+			r.Get("/me/export/{id}/download", privacyHandler.DownloadExport)
+			r.Post("/me/erase", privacyHandler.EraseAccount)
 
-	//Protected routes group
-	r.Group(func(r chi.Router) {
+			r.Route("/me/addresses", func(r chi.Router) {
+				r.Get("/", addressHandler.ListAddresses)
+				r.Post("/", addressHandler.CreateAddress)
+				r.Get("/{id}", addressHandler.GetAddress)
+				r.Put("/{id}", addressHandler.UpdateAddress)
+				r.Delete("/{id}", addressHandler.DeleteAddress)
+			})
+
+			// API keys cannot manage API keys, this group only accepts access tokens
+			r.Route("/me/api-keys", func(r chi.Router) {
+				r.Use(common.RequirePermission(policy, common.PermissionAPIKeyManage))
+				r.Get("/", apiKeyHandler.ListAPIKeys)
+				r.Post("/", apiKeyHandler.CreateAPIKey)
+				r.Delete("/{id}", apiKeyHandler.RevokeAPIKey)
+			})
+		})
+
+		// Users that must enroll in MFA can only reach these routes
+		r.Group(func(r chi.Router) {
+			r.Use(common.GetTokenTypeMiddlewareFunc(jwt, common.AccessTokenType, common.MFAEnrollmentTokenType))
+			r.Post("/me/mfa/enroll", mfaHandler.Enroll)
+			r.Post("/me/mfa/confirm", mfaHandler.Confirm)
+		})
+	})
+
+	router.Route("/admin/users", func(r chi.Router) {
 		r.Use(common.GetAuthMiddlewareFunc(jwt))
-		r.Get("/private", userHandler.privateHandler)
+		r.Group(func(r chi.Router) {
+			r.Use(common.RequirePermission(policy, common.PermissionUserRead))
+			r.Get("/", adminHandler.ListUsers)
+			r.Get("/{id}", adminHandler.GetUser)
+			r.Get("/{id}/audit", adminHandler.ListAuditEntries)
+		})
+		r.Group(func(r chi.Router) {
+			r.Use(common.RequirePermission(policy, common.PermissionUserManage))
+			r.Post("/{id}/suspend", adminHandler.SuspendUser)
+			r.Post("/{id}/reactivate", adminHandler.ReactivateUser)
+			r.Post("/{id}/logout", adminHandler.LogoutUser)
+			r.Post("/{id}/unlock", adminHandler.UnlockUser)
+			r.Post("/{id}/erase", privacyHandler.EraseUser)
+		})
+		r.Group(func(r chi.Router) {
+			r.Use(common.RequirePermission(policy, common.PermissionUserManage, common.PermissionRoleAssign))
+			r.Post("/", adminHandler.CreateAccount)
+			r.Post("/{id}/role", adminHandler.ChangeRole)
+		})
 	})
+
+	router.Route("/admin/sellers", func(r chi.Router) {
+		r.Use(common.GetAuthMiddlewareFunc(jwt), common.RequirePermission(policy, common.PermissionSellerReview))
+		r.Get("/", adminHandler.ListSellers)
+		r.Post("/{id}/approve", adminHandler.ApproveSeller)
+		r.Post("/{id}/reject", adminHandler.RejectSeller)
+	})
+}
+
+// clientIP returns the client address without the port. RemoteAddr already
+// honours proxy headers through the RealIP middleware.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// clientInfo describes the device making a login request.
+func clientInfo(r *http.Request) application.ClientInfo {
+	return application.ClientInfo{
+		IP:        clientIP(r),
+		UserAgent: r.UserAgent(),
+	}
 }
 
 func handleError(w http.ResponseWriter, err error) {
@@ -115,14 +321,28 @@ func handleError(w http.ResponseWriter, err error) {
 	if errors.As(err, &appErr) {
 		// Handle custom application errors
 		switch appErr.Code() {
-		case modles.UserNotFoundError:
+		case modles.UserNotFoundError, modles.AddressNotFoundError, modles.UnknownOIDCProviderError,
+			modles.APIKeyNotFoundError, modles.SessionNotFoundError, modles.DataExportNotFoundError:
 			common.SendError(w, http.StatusNotFound, string(appErr.Code()), appErr.Error())
-		case modles.EmailAlreadyExistsError:
+		case modles.EmailAlreadyExistsError, modles.UserAlreadyExistsError, modles.InvalidSellerStatusError,
+			modles.EmailAlreadyVerifiedError, modles.MFAAlreadyEnabledError, modles.InvalidAccountStatusError,
+			modles.AddressLimitReachedError, modles.OIDCEmailNotVerifiedError, modles.APIKeyLimitReachedError,
+			modles.DataExportInProgressError, modles.DataExportNotReadyError:
 			common.SendError(w, http.StatusConflict, string(appErr.Code()), appErr.Error())
 		case modles.InvalidUserCredentialsError:
 			common.SendError(w, http.StatusUnauthorized, string(appErr.Code()), appErr.Error())
-		case modles.InvalidUserRoleError:
+		case modles.InvalidRefreshTokenError, modles.RefreshTokenReusedError,
+			modles.InvalidMFATokenError, modles.InvalidMFACodeError, modles.OIDCLoginFailedError:
+			common.SendError(w, http.StatusUnauthorized, string(appErr.Code()), appErr.Error())
+		case modles.InvalidUserRoleError, modles.UserNotSellerError, modles.InvalidResetTokenError,
+			modles.InvalidVerificationTokenError, modles.MFANotEnrolledError, modles.InvalidProfileError,
+			modles.InvalidCurrentPasswordError, modles.CannotManageOwnAccountError, modles.InvalidAddressError,
+			modles.InvalidOIDCStateError, modles.InvalidAPIKeyError, modles.InvalidAPIKeyScopeError:
 			common.SendError(w, http.StatusBadRequest, string(appErr.Code()), appErr.Error())
+		case modles.UserSuspendedError, common.PermissionDeniedErrorCode:
+			common.SendError(w, http.StatusForbidden, string(appErr.Code()), appErr.Error())
+		case modles.VerificationThrottledError, modles.TooManyLoginAttemptsError:
+			common.SendError(w, http.StatusTooManyRequests, string(appErr.Code()), appErr.Error())
 		default:
 			common.SendError(w, http.StatusInternalServerError, string(appErr.Code()), appErr.Error())
 		}
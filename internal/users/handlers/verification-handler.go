@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"net/http"
+	"yadwy-backend/internal/common"
+	"yadwy-backend/internal/users/application"
+)
+
+type VerificationHandler struct {
+	service *application.EmailVerificationService
+}
+
+func NewVerificationHandler(service *application.EmailVerificationService) *VerificationHandler {
+	return &VerificationHandler{
+		service: service,
+	}
+}
+
+// @Summary Verify email address
+// @Description Verify the email address with the token from the verification email. Refresh the tokens afterwards to get a verified access token.
+// @Tags users
+// @Accept json
+// @Param request body application.VerifyEmailReq true "Verification token"
+// @Success 204 "Email verified"
+// @Failure 400 {object} common.ErrorResponse
+// @Router /users/verify-email [post]
+func (h *VerificationHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	req, err := common.DecodeAndValidate[application.VerifyEmailReq](r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	if err = h.service.VerifyEmail(r.Context(), req); err != nil {
+		handleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// @Summary Resend verification email
+// @Description Mail a new email verification link to the current user
+// @Tags users
+// @Security BearerAuth
+// @Success 202 "Verification email sent"
+// @Failure 401 {object} common.ErrorResponse
+// @Failure 409 {object} common.ErrorResponse
+// @Failure 429 {object} common.ErrorResponse
+// @Router /users/verify-email/resend [post]
+func (h *VerificationHandler) ResendVerification(w http.ResponseWriter, r *http.Request) {
+	claims, err := common.GetLoggedInUser(r)
+	if err != nil {
+		common.SendError(w, http.StatusUnauthorized, "unauthorized", "user not authenticated")
+		return
+	}
+
+	if err = h.service.ResendVerification(r.Context(), int(claims.ID)); err != nil {
+		handleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
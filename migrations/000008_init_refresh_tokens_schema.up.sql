@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS refresh_tokens
+(
+    jti        VARCHAR(36) PRIMARY KEY,
+    user_id    INT         NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    family_id  VARCHAR(36) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL,
+    expires_at TIMESTAMP   NOT NULL,
+    used_at    TIMESTAMP,
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_refresh_tokens_family_id ON refresh_tokens (family_id);
+CREATE INDEX idx_refresh_tokens_user_id ON refresh_tokens (user_id);
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS token_revocations
+(
+    id         serial PRIMARY KEY,
+    jti        VARCHAR(36) UNIQUE,
+    user_id    INT       NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    revoked_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_token_revocations_user_id ON token_revocations (user_id);
+CREATE INDEX idx_token_revocations_expires_at ON token_revocations (expires_at);
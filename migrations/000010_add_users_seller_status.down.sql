@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_users_seller_status;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS seller_rejection_reason,
+    DROP COLUMN IF EXISTS seller_reviewed_at,
+    DROP COLUMN IF EXISTS seller_reviewed_by,
+    DROP COLUMN IF EXISTS seller_status;
@@ -0,0 +1,13 @@
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS seller_status           VARCHAR(20),
+    ADD COLUMN IF NOT EXISTS seller_reviewed_by      INT REFERENCES users (id),
+    ADD COLUMN IF NOT EXISTS seller_reviewed_at      TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS seller_rejection_reason TEXT;
+
+-- Sellers that registered before the approval workflow keep their access.
+UPDATE users
+SET seller_status = 'APPROVED'
+WHERE role = 'SELLER'
+  AND seller_status IS NULL;
+
+CREATE INDEX idx_users_seller_status ON users (seller_status) WHERE seller_status IS NOT NULL;
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS user_action_tokens
+(
+    id         serial PRIMARY KEY,
+    user_id    INT                NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    purpose    VARCHAR(30)        NOT NULL,
+    token_hash VARCHAR(64) UNIQUE NOT NULL,
+    expires_at TIMESTAMP          NOT NULL,
+    used_at    TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_user_action_tokens_user_purpose ON user_action_tokens (user_id, purpose);
@@ -0,0 +1,7 @@
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMP;
+
+-- Accounts created before email verification existed are trusted.
+UPDATE users
+SET email_verified_at = COALESCE(created_at, CURRENT_TIMESTAMP)
+WHERE email_verified_at IS NULL;
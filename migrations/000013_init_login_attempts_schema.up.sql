@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS login_attempts
+(
+    key             VARCHAR(320) PRIMARY KEY,
+    failures        INT       NOT NULL,
+    last_failure_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_login_attempts_last_failure_at ON login_attempts (last_failure_at);
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS user_mfa
+(
+    user_id        INT PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    secret         VARCHAR(64) NOT NULL,
+    confirmed_at   TIMESTAMP,
+    last_used_step BIGINT      NOT NULL DEFAULT 0,
+    created_at     TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS user_recovery_codes
+(
+    id        serial PRIMARY KEY,
+    user_id   INT         NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    code_hash VARCHAR(64) NOT NULL,
+    used_at   TIMESTAMP,
+    UNIQUE (user_id, code_hash)
+);
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS user_audit_log;
+
+DROP INDEX IF EXISTS idx_users_created_at;
+DROP INDEX IF EXISTS idx_users_role;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS suspended_at;
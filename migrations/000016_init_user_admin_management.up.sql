@@ -0,0 +1,18 @@
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS suspended_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_users_role ON users (role);
+CREATE INDEX IF NOT EXISTS idx_users_created_at ON users (created_at);
+
+CREATE TABLE IF NOT EXISTS user_audit_log
+(
+    id             serial PRIMARY KEY,
+    actor_id       INT         REFERENCES users (id) ON DELETE SET NULL,
+    action         VARCHAR(50) NOT NULL,
+    target_user_id INT         REFERENCES users (id) ON DELETE SET NULL,
+    details        JSONB       NOT NULL DEFAULT '{}',
+    created_at     TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_audit_log_target ON user_audit_log (target_user_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_user_audit_log_actor ON user_audit_log (actor_id, created_at);
@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS user_addresses
+(
+    id                  serial PRIMARY KEY,
+    user_id             INT          NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    label               VARCHAR(50),
+    recipient_name      VARCHAR(100) NOT NULL,
+    phone               VARCHAR(20)  NOT NULL,
+    governorate         VARCHAR(100) NOT NULL,
+    city                VARCHAR(100) NOT NULL,
+    district            VARCHAR(100) NOT NULL,
+    street              VARCHAR(200) NOT NULL,
+    building            VARCHAR(50)  NOT NULL,
+    floor               VARCHAR(20),
+    apartment           VARCHAR(20),
+    landmark            VARCHAR(200),
+    is_default_shipping BOOLEAN      NOT NULL DEFAULT FALSE,
+    is_default_billing  BOOLEAN      NOT NULL DEFAULT FALSE,
+    created_at          TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at          TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_addresses_user_id ON user_addresses (user_id);
+-- at most one default shipping and one default billing address per user
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_addresses_default_shipping
+    ON user_addresses (user_id) WHERE is_default_shipping;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_addresses_default_billing
+    ON user_addresses (user_id) WHERE is_default_billing;
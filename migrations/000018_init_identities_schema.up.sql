@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS identities
+(
+    id         serial PRIMARY KEY,
+    user_id    INT          NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    provider   VARCHAR(50)  NOT NULL,
+    subject    VARCHAR(255) NOT NULL,
+    email      VARCHAR(255),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (provider, subject)
+);
+
+CREATE INDEX IF NOT EXISTS idx_identities_user_id ON identities (user_id);
+
+-- pending authorization code flows, keyed by the hash of the state parameter
+CREATE TABLE IF NOT EXISTS oidc_login_states
+(
+    state_hash    VARCHAR(64) PRIMARY KEY,
+    provider      VARCHAR(50) NOT NULL,
+    code_verifier VARCHAR(128) NOT NULL,
+    nonce         VARCHAR(128) NOT NULL,
+    expires_at    TIMESTAMP   NOT NULL
+);
@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS roles
+(
+    name       VARCHAR(20) PRIMARY KEY,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS role_permissions
+(
+    role       VARCHAR(20)  NOT NULL REFERENCES roles (name) ON DELETE CASCADE,
+    permission VARCHAR(100) NOT NULL,
+    PRIMARY KEY (role, permission)
+);
+
+INSERT INTO roles (name)
+VALUES ('ADMIN'),
+       ('SELLER'),
+       ('CUSTOMER')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions (role, permission)
+VALUES ('ADMIN', '*'),
+       ('SELLER', 'product:create')
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS api_keys
+(
+    id           serial PRIMARY KEY,
+    user_id      INT          NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name         VARCHAR(100) NOT NULL,
+    prefix       VARCHAR(20)  NOT NULL,
+    key_hash     VARCHAR(64)  NOT NULL UNIQUE,
+    scopes       TEXT[]       NOT NULL DEFAULT '{}',
+    last_used_at TIMESTAMP,
+    expires_at   TIMESTAMP,
+    revoked_at   TIMESTAMP,
+    created_at   TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_user_id ON api_keys (user_id);
+
+INSERT INTO role_permissions (role, permission)
+VALUES ('SELLER', 'api_key:manage')
+ON CONFLICT DO NOTHING;
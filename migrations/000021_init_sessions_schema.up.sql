@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS sessions
+(
+    id            VARCHAR(36) PRIMARY KEY,
+    user_id       INT         NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    user_agent    VARCHAR(512),
+    ip            VARCHAR(45),
+    created_at    TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    last_seen_at  TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at    TIMESTAMP   NOT NULL,
+    terminated_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_sessions_user_id ON sessions (user_id);
@@ -0,0 +1,17 @@
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS erased_at TIMESTAMP;
+
+CREATE TABLE IF NOT EXISTS data_exports
+(
+    id           serial PRIMARY KEY,
+    user_id      INT         NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    status       VARCHAR(20) NOT NULL,
+    file_name    VARCHAR(100),
+    error        TEXT,
+    created_at   TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP,
+    expires_at   TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_data_exports_user_id ON data_exports (user_id);
+CREATE INDEX IF NOT EXISTS idx_data_exports_status ON data_exports (status);
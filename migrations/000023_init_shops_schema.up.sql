@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS shops
+(
+    id                serial PRIMARY KEY,
+    seller_id         INT          NOT NULL UNIQUE REFERENCES users (id) ON DELETE CASCADE,
+    name              VARCHAR(100) NOT NULL,
+    slug              VARCHAR(60)  NOT NULL UNIQUE,
+    description       TEXT         NOT NULL DEFAULT '',
+    logo_url          TEXT         NOT NULL DEFAULT '',
+    banner_url        TEXT         NOT NULL DEFAULT '',
+    governorate       VARCHAR(100) NOT NULL DEFAULT '',
+    city              VARCHAR(100) NOT NULL DEFAULT '',
+    address           TEXT         NOT NULL DEFAULT '',
+    shipping_policy   TEXT         NOT NULL DEFAULT '',
+    return_policy     TEXT         NOT NULL DEFAULT '',
+    status            VARCHAR(20)  NOT NULL DEFAULT 'PENDING',
+    suspension_reason TEXT         NOT NULL DEFAULT '',
+    reviewed_by       INT REFERENCES users (id) ON DELETE SET NULL,
+    reviewed_at       TIMESTAMP,
+    created_at        TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at        TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_shops_status ON shops (status);
+
+INSERT INTO role_permissions (role, permission)
+VALUES ('SELLER', 'shop:create')
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_product_images_product_id;
+
+ALTER TABLE product_images
+    DROP COLUMN IF EXISTS position;
+
+ALTER TABLE products
+    DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,7 @@
+ALTER TABLE products
+    ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+
+ALTER TABLE product_images
+    ADD COLUMN IF NOT EXISTS position INT NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_product_images_product_id ON product_images (product_id, position);
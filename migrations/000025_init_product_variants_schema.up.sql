@@ -0,0 +1,51 @@
+CREATE TABLE IF NOT EXISTS product_options
+(
+    id         serial PRIMARY KEY,
+    product_id BIGINT      NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    name       VARCHAR(50) NOT NULL,
+    position   INT         NOT NULL DEFAULT 0,
+    UNIQUE (product_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS product_option_values
+(
+    id        serial PRIMARY KEY,
+    option_id INT         NOT NULL REFERENCES product_options (id) ON DELETE CASCADE,
+    value     VARCHAR(50) NOT NULL,
+    position  INT         NOT NULL DEFAULT 0,
+    UNIQUE (option_id, value)
+);
+
+CREATE TABLE IF NOT EXISTS product_variants
+(
+    id           serial PRIMARY KEY,
+    product_id   BIGINT      NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    sku          VARCHAR(64) NOT NULL UNIQUE,
+    -- Overrides the product price when set
+    price        DECIMAL(10, 2),
+    stock        INT         NOT NULL DEFAULT 0 CHECK (stock >= 0),
+    is_available BOOLEAN     NOT NULL DEFAULT true,
+    created_at   TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at   TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_variants_product_id ON product_variants (product_id);
+
+-- The option values a variant is made of, one per option of the product
+CREATE TABLE IF NOT EXISTS product_variant_values
+(
+    variant_id      INT NOT NULL REFERENCES product_variants (id) ON DELETE CASCADE,
+    option_value_id INT NOT NULL REFERENCES product_option_values (id) ON DELETE RESTRICT,
+    PRIMARY KEY (variant_id, option_value_id)
+);
+
+ALTER TABLE product_images
+    ADD COLUMN IF NOT EXISTS variant_id INT REFERENCES product_variants (id) ON DELETE SET NULL;
+
+-- Products with variants are added to carts by variant
+ALTER TABLE cart_items
+    ADD COLUMN IF NOT EXISTS variant_id INT REFERENCES product_variants (id) ON DELETE CASCADE;
+ALTER TABLE cart_items
+    DROP CONSTRAINT IF EXISTS cart_items_cart_id_product_id_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_cart_items_cart_product_variant
+    ON cart_items (cart_id, product_id, COALESCE(variant_id, 0));
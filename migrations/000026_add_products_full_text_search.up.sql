@@ -0,0 +1,23 @@
+-- The labels of a product as text, kept in sync by the repository, since a
+-- generated column cannot read product_labels
+ALTER TABLE products
+    ADD COLUMN IF NOT EXISTS search_labels TEXT NOT NULL DEFAULT '';
+
+UPDATE products p
+SET search_labels = COALESCE((SELECT string_agg(pl.label_name, ' ')
+                              FROM product_labels pl
+                              WHERE pl.product_id = p.id), '');
+
+-- The catalog mixes Arabic and English, so every field is indexed with both
+-- configurations. The name weighs above the description, above the labels.
+ALTER TABLE products
+    ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+        setweight(to_tsvector('english', name), 'A') ||
+        setweight(to_tsvector('arabic', name), 'A') ||
+        setweight(to_tsvector('english', COALESCE(description, '')), 'B') ||
+        setweight(to_tsvector('arabic', COALESCE(description, '')), 'B') ||
+        setweight(to_tsvector('english', search_labels), 'C') ||
+        setweight(to_tsvector('arabic', search_labels), 'C')
+        ) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_products_search_vector ON products USING GIN (search_vector);
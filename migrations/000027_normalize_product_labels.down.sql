@@ -0,0 +1 @@
+-- The original case and spacing of the labels is not kept
@@ -0,0 +1,16 @@
+-- Labels are stored lowercased with single spaces. Labels of a product that
+-- only differed in case or spacing are merged.
+INSERT INTO product_labels (product_id, label_name)
+SELECT product_id, lower(btrim(regexp_replace(label_name, '\s+', ' ', 'g')))
+FROM product_labels
+ON CONFLICT DO NOTHING;
+
+DELETE
+FROM product_labels
+WHERE label_name <> lower(btrim(regexp_replace(label_name, '\s+', ' ', 'g')))
+   OR label_name = '';
+
+UPDATE products p
+SET search_labels = COALESCE((SELECT string_agg(pl.label_name, ' ')
+                              FROM product_labels pl
+                              WHERE pl.product_id = p.id), '');
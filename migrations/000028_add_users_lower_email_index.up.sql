@@ -0,0 +1,2 @@
+-- Emails are matched regardless of case.
+CREATE INDEX IF NOT EXISTS idx_users_lower_email ON users (LOWER(email));